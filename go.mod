@@ -3,14 +3,14 @@ module github.com/cbrgm/pgp-sign-artifact-action
 go 1.25.6
 
 require (
+	github.com/ProtonMail/go-crypto v1.4.1
 	github.com/ProtonMail/gopenpgp/v3 v3.4.1
 	github.com/alexflint/go-arg v1.6.1
+	golang.org/x/crypto v0.41.0
 )
 
 require (
-	github.com/ProtonMail/go-crypto v1.4.1 // indirect
 	github.com/alexflint/go-scalar v1.2.0 // indirect
 	github.com/cloudflare/circl v1.6.2 // indirect
-	golang.org/x/crypto v0.41.0 // indirect
 	golang.org/x/sys v0.35.0 // indirect
 )
@@ -0,0 +1,128 @@
+package pgpsign
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+// dirArchiveExtension is appended to a directory's path to name its archive.
+const dirArchiveExtension = ".tar"
+
+// archiveDirectories replaces every directory in files with a tar archive of it, written
+// next to the directory as dir.tar, so the archive is signed instead. A non-zero mtime
+// replaces the modification times in the archives. With dryRun, no archives are written.
+func archiveDirectories(files []string, mtime time.Time, dryRun bool, log *slog.Logger) ([]string, error) {
+	result := make([]string, 0, len(files))
+	seen := make(map[string]bool)
+
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err == nil && info.IsDir() {
+			archivePath := filepath.Clean(file) + dirArchiveExtension
+			if !dryRun {
+				if err := writeDirArchive(file, archivePath, mtime); err != nil {
+					return nil, fmt.Errorf("failed to archive directory %s: %w", file, err)
+				}
+				log.Info("Directory archived", slog.String("dir", file), slog.String("archive", archivePath))
+			}
+			file = archivePath
+		}
+
+		// An archive left by an earlier run may have been matched as a file as well.
+		if !seen[file] {
+			seen[file] = true
+			result = append(result, file)
+		}
+	}
+
+	return result, nil
+}
+
+// writeDirArchive writes the tree below dir as a tar archive to archivePath. Entries are
+// stored in lexical order below the directory's name, without owners or access times,
+// so the same tree always produces the same archive.
+func writeDirArchive(dir, archivePath string, mtime time.Time) error {
+	out, err := os.Create(archivePath)
+	if err != nil {
+		return err
+	}
+
+	tw := tar.NewWriter(out)
+	root := filepath.Base(filepath.Clean(dir))
+	err = filepath.WalkDir(dir, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+		return addArchiveEntry(tw, file, path.Join(root, filepath.ToSlash(rel)), mtime)
+	})
+	if err == nil {
+		err = tw.Close()
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(archivePath)
+	}
+	return err
+}
+
+// addArchiveEntry writes the header and content of file to tw under name.
+// Special files such as sockets and devices are skipped.
+func addArchiveEntry(tw *tar.Writer, file, name string, mtime time.Time) error {
+	info, err := os.Lstat(file)
+	if err != nil {
+		return err
+	}
+
+	var link string
+	switch {
+	case info.Mode()&fs.ModeSymlink != 0:
+		if link, err = os.Readlink(file); err != nil {
+			return err
+		}
+	case !info.IsDir() && !info.Mode().IsRegular():
+		return nil
+	}
+
+	header, err := tar.FileInfoHeader(info, link)
+	if err != nil {
+		return err
+	}
+	header.Name = name
+	if info.IsDir() {
+		header.Name += "/"
+	}
+	header.Uid, header.Gid, header.Uname, header.Gname = 0, 0, "", ""
+	header.AccessTime, header.ChangeTime = time.Time{}, time.Time{}
+	header.ModTime = info.ModTime().Truncate(time.Second)
+	if !mtime.IsZero() {
+		header.ModTime = mtime
+	}
+
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return nil
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(tw, f)
+	return err
+}
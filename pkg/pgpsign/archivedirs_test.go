@@ -0,0 +1,153 @@
+package pgpsign
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRunArchiveDirs(t *testing.T) {
+	t.Setenv("SOURCE_DATE_EPOCH", "1700000000")
+
+	armoredKey := generateTestKeyArmored(t, "Test", "test@test.com", "")
+	signer, err := NewGoPGPSigner(armoredKey, "", "")
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+
+	workDir := t.TempDir()
+	for name, content := range map[string]string{
+		"site/index.html":     "<html></html>",
+		"site/css/style.css":  "body {}",
+		"site/b/z.txt":        "z",
+		"site/b/a.txt":        "a",
+		"release-notes.txt":   "notes",
+		"other/ignored.bin":   "bin",
+		"site/empty/.keep":    "",
+		"site/css/theme.css":  "dark",
+		"site/b/nested/x.txt": "x",
+	} {
+		path := filepath.Join(workDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to create file: %v", err)
+		}
+	}
+
+	args := Config{
+		PrivateKey:  "key",
+		Files:       "site*\nrelease-notes.txt",
+		Armor:       true,
+		DetachSign:  true,
+		WorkDir:     workDir,
+		ArchiveDirs: true,
+		// SOURCE_DATE_EPOCH predates the test key, so the signatures use the current time.
+		SignatureTime: time.Now().Format(time.RFC3339),
+	}
+
+	archivePath := filepath.Join(workDir, "site.tar")
+	var archives [][]byte
+	for i := range 2 {
+		// Modification times must not leak into the archive.
+		touched := time.Now().Add(time.Duration(i) * time.Hour)
+		if err := os.Chtimes(filepath.Join(workDir, "site", "index.html"), touched, touched); err != nil {
+			t.Fatalf("failed to touch file: %v", err)
+		}
+
+		if err := run(context.Background(), args, signer, nil, nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		data, err := os.ReadFile(archivePath)
+		if err != nil {
+			t.Fatalf("expected archive to be written: %v", err)
+		}
+		archives = append(archives, data)
+	}
+
+	if !bytes.Equal(archives[0], archives[1]) {
+		t.Error("expected the archive to be identical across runs")
+	}
+
+	if err := signer.Verify(archivePath, archivePath+".asc", SignOptions{Armor: true, DetachSign: true}); err != nil {
+		t.Errorf("signature of the archive does not verify: %v", err)
+	}
+
+	var names []string
+	tr := tar.NewReader(bytes.NewReader(archives[0]))
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("failed to read archive: %v", err)
+		}
+		if header.ModTime.Unix() != 1700000000 || header.Uname != "" || header.Uid != 0 {
+			t.Errorf("entry %s is not normalized: mtime %v, owner %q/%d", header.Name, header.ModTime, header.Uname, header.Uid)
+		}
+		names = append(names, header.Name)
+	}
+
+	expectedNames := []string{
+		"site/", "site/b/", "site/b/a.txt", "site/b/nested/", "site/b/nested/x.txt", "site/b/z.txt",
+		"site/css/", "site/css/style.css", "site/css/theme.css", "site/empty/", "site/empty/.keep", "site/index.html",
+	}
+	if !slices.Equal(names, expectedNames) {
+		t.Errorf("expected sorted entries %v, got %v", expectedNames, names)
+	}
+}
+
+func TestFindFiles_IncludeDirs(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(tempDir, "dist", "site"), 0o755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "dist", "app.bin"), []byte("bin"), 0o644); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+
+	for _, includeDirs := range []bool{false, true} {
+		finder := &DefaultFileFinder{IncludeDirs: includeDirs}
+		files, err := finder.FindFiles(tempDir, []string{"dist/*"}, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := slices.Contains(files, filepath.Join(tempDir, "dist", "site")); got != includeDirs {
+			t.Errorf("IncludeDirs=%v: expected directory match %v, got files %v", includeDirs, includeDirs, files)
+		}
+	}
+}
+
+func TestArchiveDirectoriesDryRun(t *testing.T) {
+	tempDir := t.TempDir()
+	dir := filepath.Join(tempDir, "site")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	file := filepath.Join(tempDir, "app.bin")
+	if err := os.WriteFile(file, []byte("bin"), 0o644); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+
+	// An archive from an earlier run matched next to its directory is only signed once.
+	files, err := archiveDirectories([]string{dir + ".tar", file, dir}, time.Time{}, true, slog.New(slog.DiscardHandler))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []string{dir + ".tar", file}; !slices.Equal(files, expected) {
+		t.Errorf("expected %v, got %v", expected, files)
+	}
+	if _, err := os.Stat(dir + ".tar"); !os.IsNotExist(err) {
+		t.Errorf("expected no archive to be written in a dry run, got %v", err)
+	}
+}
@@ -0,0 +1,105 @@
+package pgpsign
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// outputFile is written under a temporary name next to its destination and renamed into
+// place by Commit. A run killed mid-write therefore never leaves a truncated signature that
+// looks complete, and a failed write keeps the file that was at the destination before.
+type outputFile struct {
+	file *os.File
+	path string
+}
+
+// createOutputFile creates a temporary file that replaces path on Commit, with the permission
+// bits mode. The mode is set explicitly, so it is not narrowed by the umask.
+func createOutputFile(path string, mode os.FileMode) (*outputFile, error) {
+	f, err := createTempOutput(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.Chmod(mode); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, err
+	}
+	return &outputFile{file: f, path: path}, nil
+}
+
+// createTempOutput creates a hidden temporary file in the directory of path. Staying in the
+// same directory keeps the final rename on one file system, where it is atomic.
+func createTempOutput(path string) (*os.File, error) {
+	return os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+}
+
+// Write writes p to the temporary file.
+func (f *outputFile) Write(p []byte) (int, error) {
+	return f.file.Write(p)
+}
+
+// Commit flushes the temporary file to disk and renames it to its destination, replacing
+// any file there. The temporary file is removed if that fails.
+func (f *outputFile) Commit() error {
+	if err := f.file.Sync(); err != nil {
+		f.Abort()
+		return err
+	}
+	if err := f.file.Close(); err != nil {
+		os.Remove(f.file.Name())
+		return err
+	}
+	if err := os.Rename(f.file.Name(), f.path); err != nil {
+		os.Remove(f.file.Name())
+		return err
+	}
+	return nil
+}
+
+// Abort discards the temporary file and leaves the destination untouched.
+func (f *outputFile) Abort() {
+	f.file.Close()
+	os.Remove(f.file.Name())
+}
+
+// writeOutputFile atomically writes data to path with the permission bits mode.
+func writeOutputFile(path string, data []byte, mode os.FileMode) error {
+	f, err := createOutputFile(path, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Abort()
+		return err
+	}
+	return f.Commit()
+}
+
+// writeOutputFileWith lets write fill a temporary file next to path, for tools such as gpg
+// that write their output by name, and renames it to path with the permission bits mode once
+// write succeeds.
+func writeOutputFileWith(path string, mode os.FileMode, write func(tempPath string) error) error {
+	f, err := createTempOutput(path)
+	if err != nil {
+		return err
+	}
+	tempPath := f.Name()
+	f.Close()
+
+	if err := write(tempPath); err != nil {
+		os.Remove(tempPath)
+		return err
+	}
+	// The tool may have recreated the file with permissions of its own.
+	if err := os.Chmod(tempPath, mode); err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("failed to set signature file permissions: %w", err)
+	}
+	if err := os.Rename(tempPath, path); err != nil {
+		os.Remove(tempPath)
+		return err
+	}
+	return nil
+}
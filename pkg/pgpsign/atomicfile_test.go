@@ -0,0 +1,154 @@
+package pgpsign
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// dirEntries returns the sorted names of the entries in dir.
+func dirEntries(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("failed to read directory: %v", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	slices.Sort(names)
+	return names
+}
+
+// failingReader returns data and then err.
+type failingReader struct {
+	data []byte
+	err  error
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, r.err
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+func TestOutputFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "file.txt.asc")
+	if err := os.WriteFile(path, []byte("old signature"), 0o644); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+
+	f, err := createOutputFile(path, 0o600)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := f.Write([]byte("partial")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if content, _ := os.ReadFile(path); string(content) != "old signature" {
+		t.Errorf("expected the destination to be untouched while writing, got %q", content)
+	}
+	f.Abort()
+	if content, _ := os.ReadFile(path); string(content) != "old signature" {
+		t.Errorf("expected the destination to be untouched after Abort, got %q", content)
+	}
+	if entries := dirEntries(t, dir); !slices.Equal(entries, []string{"file.txt.asc"}) {
+		t.Errorf("expected no temporary files after Abort, got %v", entries)
+	}
+
+	if err := writeOutputFile(path, []byte("new signature"), 0o600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if content, _ := os.ReadFile(path); string(content) != "new signature" {
+		t.Errorf("expected the committed content, got %q", content)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("expected mode 0600, got %v, %v", info.Mode().Perm(), err)
+	}
+	if entries := dirEntries(t, dir); !slices.Equal(entries, []string{"file.txt.asc"}) {
+		t.Errorf("expected no temporary files after Commit, got %v", entries)
+	}
+}
+
+func TestWriteOutputFileWith(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "file.txt.sig")
+
+	err := writeOutputFileWith(path, 0o644, func(tempPath string) error {
+		if err := os.WriteFile(tempPath, []byte("partial"), 0o600); err != nil {
+			return err
+		}
+		return errors.New("gpg failed")
+	})
+	if err == nil || !strings.Contains(err.Error(), "gpg failed") {
+		t.Fatalf("expected the write error, got %v", err)
+	}
+	if entries := dirEntries(t, dir); len(entries) != 0 {
+		t.Errorf("expected no files after a failed write, got %v", entries)
+	}
+
+	err = writeOutputFileWith(path, 0o644, func(tempPath string) error {
+		return os.WriteFile(tempPath, []byte("signature"), 0o600)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o644 {
+		t.Errorf("expected a signature with mode 0644, got %v", err)
+	}
+}
+
+func TestSignFile_FailedWriteLeavesNoPartialOutput(t *testing.T) {
+	signer, err := NewGoPGPSigner(generateTestKeyArmored(t, "Test", "test@test.com", ""), "", "")
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+
+	t.Run("failed read keeps the previous signature", func(t *testing.T) {
+		dir := t.TempDir()
+		outputPath := filepath.Join(dir, "member.txt.asc")
+		if err := os.WriteFile(outputPath, []byte("old signature"), 0o644); err != nil {
+			t.Fatalf("failed to create file: %v", err)
+		}
+
+		// Inline signatures are written while the input is read, so part of one is written before the error.
+		input := &failingReader{data: []byte(strings.Repeat("data", 4096)), err: io.ErrUnexpectedEOF}
+		if err := signMember(signer, input, outputPath, SignOptions{Armor: true}); err == nil {
+			t.Fatal("expected error")
+		}
+		if content, _ := os.ReadFile(outputPath); string(content) != "old signature" {
+			t.Errorf("expected the previous signature to be kept, got %q", content)
+		}
+		if entries := dirEntries(t, dir); !slices.Equal(entries, []string{"member.txt.asc"}) {
+			t.Errorf("expected no partial files, got %v", entries)
+		}
+	})
+
+	t.Run("unwritable target", func(t *testing.T) {
+		dir := t.TempDir()
+		file := filepath.Join(dir, "file.txt")
+		if err := os.WriteFile(file, []byte("content"), 0o644); err != nil {
+			t.Fatalf("failed to create file: %v", err)
+		}
+		// A directory in place of the signature cannot be replaced by the finished file.
+		if err := os.Mkdir(file+".asc", 0o755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+
+		if _, err := signer.SignFile(file, SignOptions{Armor: true, DetachSign: true}); err == nil {
+			t.Fatal("expected error")
+		}
+		if entries := dirEntries(t, dir); !slices.Equal(entries, []string{"file.txt", "file.txt.asc"}) {
+			t.Errorf("expected no partial files, got %v", entries)
+		}
+	})
+}
@@ -0,0 +1,167 @@
+package pgpsign
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ProtonMail/gopenpgp/v3/armor"
+	"github.com/ProtonMail/gopenpgp/v3/crypto"
+)
+
+// BundleFormat defines the available signature bundle formats.
+type BundleFormat string
+
+const (
+	BundleNone        BundleFormat = ""
+	BundleSigstorePGP BundleFormat = "sigstore-pgp"
+)
+
+const (
+	sigstoreBundleMediaType = "application/vnd.dev.sigstore.bundle.v0.3+json"
+	sigstoreBundleExtension = ".sigstore.json"
+)
+
+// sigstoreBundle is the subset of the Sigstore bundle format populated for PGP signatures.
+type sigstoreBundle struct {
+	MediaType            string                       `json:"mediaType"`
+	VerificationMaterial sigstoreVerificationMaterial `json:"verificationMaterial"`
+	MessageSignature     sigstoreMessageSignature     `json:"messageSignature"`
+}
+
+type sigstoreVerificationMaterial struct {
+	PublicKey sigstorePublicKey `json:"publicKey"`
+}
+
+type sigstorePublicKey struct {
+	Hint     string `json:"hint"`
+	RawBytes []byte `json:"rawBytes"`
+}
+
+type sigstoreMessageSignature struct {
+	MessageDigest sigstoreMessageDigest `json:"messageDigest"`
+	Signature     []byte                `json:"signature"`
+}
+
+type sigstoreMessageDigest struct {
+	Algorithm string `json:"algorithm"`
+	Digest    []byte `json:"digest"`
+}
+
+// PublicKeyProvider is implemented by signers that can expose the public half of their signing key.
+type PublicKeyProvider interface {
+	PublicKey() (*crypto.Key, error)
+}
+
+// bundleWriter wraps freshly written signatures into bundle files.
+type bundleWriter struct {
+	format    BundleFormat
+	publicKey *crypto.Key
+}
+
+// newBundleWriter validates the bundle configuration and returns nil when no bundle is requested.
+func newBundleWriter(format BundleFormat, signer Signer, opts SignOptions) (*bundleWriter, error) {
+	switch format {
+	case BundleNone:
+		return nil, nil
+	case BundleSigstorePGP:
+	default:
+		return nil, fmt.Errorf("unknown bundle format: %s", format)
+	}
+
+	if !opts.DetachSign {
+		return nil, fmt.Errorf("bundle format %s requires detached signatures", format)
+	}
+
+	provider, ok := signer.(PublicKeyProvider)
+	if !ok {
+		return nil, fmt.Errorf("signer does not expose a public key for bundle format %s", format)
+	}
+
+	publicKey, err := provider.PublicKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get public key: %w", err)
+	}
+
+	return &bundleWriter{
+		format:    format,
+		publicKey: publicKey,
+	}, nil
+}
+
+// Write creates the bundle for filePath from the detached signature at sigPath and returns its path.
+func (b *bundleWriter) Write(filePath, sigPath string, opts SignOptions) (string, error) {
+	signature, err := os.ReadFile(sigPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read signature: %w", err)
+	}
+
+	if opts.Armor {
+		signature, err = armor.UnarmorBytes(signature)
+		if err != nil {
+			return "", fmt.Errorf("failed to unarmor signature: %w", err)
+		}
+	}
+
+	digest, err := sha256File(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	publicKey, err := b.publicKey.GetPublicKey()
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize public key: %w", err)
+	}
+
+	bundle := sigstoreBundle{
+		MediaType: sigstoreBundleMediaType,
+		VerificationMaterial: sigstoreVerificationMaterial{
+			PublicKey: sigstorePublicKey{
+				Hint:     b.publicKey.GetFingerprint(),
+				RawBytes: publicKey,
+			},
+		},
+		MessageSignature: sigstoreMessageSignature{
+			MessageDigest: sigstoreMessageDigest{
+				Algorithm: "SHA2_256",
+				Digest:    digest,
+			},
+			Signature: signature,
+		},
+	}
+
+	data, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode bundle: %w", err)
+	}
+
+	bundlePath := b.Path(filePath, opts)
+	if err := writeOutputFile(bundlePath, append(data, '\n'), opts.outputMode()); err != nil {
+		return "", fmt.Errorf("failed to write bundle: %w", err)
+	}
+
+	return bundlePath, nil
+}
+
+// Path returns where the bundle for filePath is written.
+func (b *bundleWriter) Path(filePath string, opts SignOptions) string {
+	return outputBase(filePath, opts) + sigstoreBundleExtension
+}
+
+// sha256File computes the SHA-256 digest of a file without loading it into memory.
+func sha256File(filePath string) ([]byte, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, fmt.Errorf("failed to hash file: %w", err)
+	}
+
+	return h.Sum(nil), nil
+}
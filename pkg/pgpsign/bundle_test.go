@@ -0,0 +1,160 @@
+package pgpsign
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v3/crypto"
+)
+
+func TestBundleWriter_SigstorePGP(t *testing.T) {
+	tests := []struct {
+		name string
+		opts SignOptions
+	}{
+		{name: "armored detached signature", opts: SignOptions{Armor: true, DetachSign: true}},
+		{name: "binary detached signature", opts: SignOptions{DetachSign: true}},
+	}
+
+	armoredKey := generateTestKeyArmored(t, "Test", "test@test.com", "")
+	signer, err := NewGoPGPSigner(armoredKey, "", "")
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := []byte("Hello, World!")
+			testFile := filepath.Join(t.TempDir(), "test.txt")
+			if err := os.WriteFile(testFile, content, 0o644); err != nil {
+				t.Fatalf("failed to create test file: %v", err)
+			}
+
+			if _, err := signer.SignFile(testFile, tt.opts); err != nil {
+				t.Fatalf("failed to sign file: %v", err)
+			}
+
+			writer, err := newBundleWriter(BundleSigstorePGP, signer, tt.opts)
+			if err != nil {
+				t.Fatalf("failed to create bundle writer: %v", err)
+			}
+
+			bundlePath, err := writer.Write(testFile, getOutputPath(testFile, tt.opts), tt.opts)
+			if err != nil {
+				t.Fatalf("failed to write bundle: %v", err)
+			}
+			if bundlePath != testFile+".sigstore.json" {
+				t.Errorf("unexpected bundle path: %s", bundlePath)
+			}
+
+			data, err := os.ReadFile(bundlePath)
+			if err != nil {
+				t.Fatalf("failed to read bundle: %v", err)
+			}
+
+			var bundle sigstoreBundle
+			if err := json.Unmarshal(data, &bundle); err != nil {
+				t.Fatalf("failed to decode bundle: %v", err)
+			}
+
+			if bundle.MediaType != sigstoreBundleMediaType {
+				t.Errorf("unexpected media type: %s", bundle.MediaType)
+			}
+
+			digest := sha256.Sum256(content)
+			if bundle.MessageSignature.MessageDigest.Algorithm != "SHA2_256" {
+				t.Errorf("unexpected digest algorithm: %s", bundle.MessageSignature.MessageDigest.Algorithm)
+			}
+			if !bytes.Equal(bundle.MessageSignature.MessageDigest.Digest, digest[:]) {
+				t.Error("bundle digest does not match file content")
+			}
+
+			publicKey, err := crypto.NewKey(bundle.VerificationMaterial.PublicKey.RawBytes)
+			if err != nil {
+				t.Fatalf("failed to parse bundled public key: %v", err)
+			}
+			if publicKey.IsPrivate() {
+				t.Error("bundle must not contain private key material")
+			}
+			if bundle.VerificationMaterial.PublicKey.Hint != publicKey.GetFingerprint() {
+				t.Error("public key hint does not match the bundled key fingerprint")
+			}
+
+			verifyHandle, err := crypto.PGP().Verify().VerificationKey(publicKey).New()
+			if err != nil {
+				t.Fatalf("failed to create verification handle: %v", err)
+			}
+			result, err := verifyHandle.VerifyDetached(content, bundle.MessageSignature.Signature, crypto.Bytes)
+			if err != nil {
+				t.Fatalf("failed to verify bundled signature: %v", err)
+			}
+			if err := result.SignatureError(); err != nil {
+				t.Errorf("bundled signature is invalid: %v", err)
+			}
+		})
+	}
+}
+
+func TestNewBundleWriter_Validation(t *testing.T) {
+	armoredKey := generateTestKeyArmored(t, "Test", "test@test.com", "")
+	signer, err := NewGoPGPSigner(armoredKey, "", "")
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		format      BundleFormat
+		signer      Signer
+		opts        SignOptions
+		expectNil   bool
+		expectError bool
+	}{
+		{
+			name:      "no bundle",
+			format:    BundleNone,
+			signer:    signer,
+			expectNil: true,
+		},
+		{
+			name:        "unknown format",
+			format:      "cosign",
+			signer:      signer,
+			opts:        SignOptions{DetachSign: true},
+			expectError: true,
+		},
+		{
+			name:        "requires detached signatures",
+			format:      BundleSigstorePGP,
+			signer:      signer,
+			opts:        SignOptions{ClearSign: true},
+			expectError: true,
+		},
+		{
+			name:        "signer without public key",
+			format:      BundleSigstorePGP,
+			signer:      &MockSigner{},
+			opts:        SignOptions{DetachSign: true},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writer, err := newBundleWriter(tt.format, tt.signer, tt.opts)
+			if tt.expectError && err == nil {
+				t.Error("expected error but got nil")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if tt.expectNil && writer != nil {
+				t.Error("expected no bundle writer")
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package pgpsign
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// filterChangedFiles keeps the files that changed since the changed-since ref in the git
+// repository containing workDir, so pull request builds only sign what they touched. The
+// filter applies on top of the patterns and excludes; without the input files are returned
+// unchanged. Cancelling ctx kills the git invocations.
+func filterChangedFiles(ctx context.Context, args Config, files []string, workDir string, log *slog.Logger) ([]string, error) {
+	if args.ChangedSince == "" {
+		return files, nil
+	}
+
+	changed, err := gitChangedFiles(ctx, workDir, args.ChangedSince)
+	if err != nil {
+		return nil, err
+	}
+
+	var kept []string
+	for _, file := range files {
+		if changed[gitPathKey(file)] {
+			kept = append(kept, file)
+		} else {
+			log.Debug("File unchanged, skipping", slog.String("file", file), slog.String("ref", args.ChangedSince))
+		}
+	}
+	log.Info("Files filtered by changes",
+		slog.String("ref", args.ChangedSince),
+		slog.Int("matched", len(files)),
+		slog.Int("changed", len(kept)),
+	)
+	return kept, nil
+}
+
+// gitChangedFiles returns the paths, keyed by gitPathKey, of the files in the git repository
+// containing dir that differ from ref: tracked files changed in commits since ref or in the
+// working tree, and untracked files that are not ignored. ref is resolved to a commit first,
+// so git diff only ever sees a commit ID and never reads the ref as an option.
+func gitChangedFiles(ctx context.Context, dir, ref string) (map[string]bool, error) {
+	root, err := gitOutput(ctx, dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, fmt.Errorf("changed-since requires %s to be inside a git repository: %w", dir, err)
+	}
+	root = strings.TrimSpace(root)
+
+	commit, err := gitOutput(ctx, root, "rev-parse", "--verify", "--quiet", "--end-of-options", ref+"^{commit}")
+	if err != nil {
+		return nil, fmt.Errorf("changed-since ref %s is not a commit in the repository: %w", ref, err)
+	}
+	diff, err := gitOutput(ctx, root, "diff", "--name-only", "--no-renames", "-z", strings.TrimSpace(commit), "--")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files changed since %s: %w", ref, err)
+	}
+	untracked, err := gitOutput(ctx, root, "ls-files", "--others", "--exclude-standard", "-z")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list untracked files: %w", err)
+	}
+
+	changed := make(map[string]bool)
+	for _, name := range strings.Split(diff+untracked, "\x00") {
+		if name != "" {
+			changed[gitPathKey(filepath.Join(root, filepath.FromSlash(name)))] = true
+		}
+	}
+	return changed, nil
+}
+
+// gitPathKey returns file with symlinks in its directory resolved, as git reports paths
+// below the resolved repository root. The file itself is not resolved, since git tracks
+// a symlink under its own name.
+func gitPathKey(file string) string {
+	dir, err := filepath.EvalSymlinks(filepath.Dir(file))
+	if err != nil {
+		return filepath.Clean(file)
+	}
+	return filepath.Join(dir, filepath.Base(file))
+}
+
+// gitOutput runs git with args in dir and returns its standard output. The last lines git
+// wrote to stderr are included in the error when it fails. git is killed once ctx is done.
+func gitOutput(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if output := strings.TrimSpace(stderr.String()); output != "" {
+			return "", fmt.Errorf("git %s failed: %w: %s", args[0], err, lastLines(output, stderrErrorLines))
+		}
+		return "", fmt.Errorf("git %s failed: %w", args[0], err)
+	}
+	return string(out), nil
+}
@@ -0,0 +1,150 @@
+package pgpsign
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// initTestRepo creates a git repository with app.bin, lib.bin and docs/guide.md committed
+// and tagged base, then changes app.bin in a commit, docs/guide.md in the working tree and
+// adds the untracked new.bin and the ignored build.log.
+func initTestRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	// Keep the user's git configuration out of the test.
+	t.Setenv("GIT_CONFIG_GLOBAL", filepath.Join(t.TempDir(), "gitconfig"))
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=Test", "-c", "user.email=test@test.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s failed: %v: %s", strings.Join(args, " "), err, out)
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	git("init", "--quiet")
+	write("app.bin", "app v1")
+	write("lib.bin", "lib v1")
+	write("docs/guide.md", "guide v1")
+	write(".gitignore", "*.log\n")
+	git("add", ".")
+	git("commit", "--quiet", "-m", "initial")
+	git("tag", "base")
+
+	write("app.bin", "app v2")
+	git("commit", "--quiet", "-am", "update app")
+	write("docs/guide.md", "guide v2")
+	write("new.bin", "new")
+	write("build.log", "log")
+	return dir
+}
+
+func TestGitChangedFiles(t *testing.T) {
+	dir := initTestRepo(t)
+
+	changed, err := gitChangedFiles(context.Background(), filepath.Join(dir, "docs"), "base")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for name, want := range map[string]bool{
+		"app.bin":       true, // Committed since base
+		"docs/guide.md": true, // Modified in the working tree
+		"new.bin":       true, // Untracked
+		"lib.bin":       false,
+		"build.log":     false, // Ignored
+	} {
+		if got := changed[gitPathKey(filepath.Join(dir, filepath.FromSlash(name)))]; got != want {
+			t.Errorf("%s: expected changed=%v, got %v", name, want, got)
+		}
+	}
+
+	if _, err := gitChangedFiles(context.Background(), dir, "no-such-ref"); err == nil || !strings.Contains(err.Error(), "no-such-ref") {
+		t.Errorf("expected an error naming the unknown ref, got %v", err)
+	}
+
+	// A ref looking like an option is resolved as a ref, never passed to git diff as one.
+	output := filepath.Join(t.TempDir(), "diff.txt")
+	if _, err := gitChangedFiles(context.Background(), dir, "--output="+output); err == nil {
+		t.Error("expected an option-like ref to be rejected")
+	}
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Errorf("expected git not to write %s, got %v", output, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := gitChangedFiles(ctx, dir, "base"); err == nil {
+		t.Error("expected an error with a cancelled context")
+	}
+}
+
+func TestFilterChangedFiles(t *testing.T) {
+	dir := initTestRepo(t)
+	log := slog.New(slog.DiscardHandler)
+	files := []string{filepath.Join(dir, "app.bin"), filepath.Join(dir, "lib.bin"), filepath.Join(dir, "new.bin")}
+
+	got, err := filterChangedFiles(context.Background(), Config{}, files, dir, log)
+	if err != nil || !slices.Equal(got, files) {
+		t.Errorf("expected all files without changed-since, got %v, %v", got, err)
+	}
+
+	got, err = filterChangedFiles(context.Background(), Config{ChangedSince: "base"}, files, dir, log)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{files[0], files[2]}; !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	outside := t.TempDir()
+	if _, err := filterChangedFiles(context.Background(), Config{ChangedSince: "base"}, files, outside, log); err == nil || !strings.Contains(err.Error(), "inside a git repository") {
+		t.Errorf("expected an error outside a git repository, got %v", err)
+	}
+}
+
+func TestRunChangedSince(t *testing.T) {
+	dir := initTestRepo(t)
+
+	signer := &MockSigner{}
+	args := Config{
+		PrivateKey:   "key",
+		Files:        "*.bin",
+		DetachSign:   true,
+		Armor:        true,
+		WorkDir:      dir,
+		ChangedSince: "base",
+	}
+	if err := run(context.Background(), args, signer, nil, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{filepath.Join(dir, "app.bin"), filepath.Join(dir, "new.bin")}
+	if !slices.Equal(signer.SignedFiles, want) {
+		t.Errorf("expected only the changed files %v to be signed, got %v", want, signer.SignedFiles)
+	}
+
+	args.ChangedSince = "--output=/tmp/x"
+	if err := run(context.Background(), args, signer, nil, nil); err == nil || !strings.Contains(err.Error(), "must be a git ref") {
+		t.Errorf("expected a ref starting with a dash to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package pgpsign
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// CleartextEncoding defines how non-UTF-8 input is handled when clear-signing.
+type CleartextEncoding string
+
+const (
+	CleartextStrict          CleartextEncoding = "strict"
+	CleartextConvert         CleartextEncoding = "convert"
+	DefaultCleartextEncoding                   = CleartextStrict
+)
+
+// Byte order marks recognized when clear-signing text.
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// normalizeCleartext ensures data is BOM-free UTF-8 before it is clear-signed.
+// In strict mode any byte order mark is rejected; in convert mode UTF-8 BOMs are
+// stripped and UTF-16 input is transcoded to UTF-8.
+func normalizeCleartext(data []byte, mode CleartextEncoding) ([]byte, error) {
+	if mode == "" {
+		mode = DefaultCleartextEncoding
+	}
+	if mode != CleartextStrict && mode != CleartextConvert {
+		return nil, fmt.Errorf("unknown cleartext encoding: %s", mode)
+	}
+
+	encoding, body := detectBOM(data)
+	if encoding == "" {
+		return data, checkUTF8Text(data)
+	}
+
+	if mode == CleartextStrict {
+		return nil, fmt.Errorf("clear-sign expects UTF-8 text without a byte order mark, but detected %s input (use cleartext encoding %q to transcode)", encoding, CleartextConvert)
+	}
+
+	switch encoding {
+	case "UTF-16LE":
+		return decodeUTF16(body, binary.LittleEndian)
+	case "UTF-16BE":
+		return decodeUTF16(body, binary.BigEndian)
+	default:
+		return body, checkUTF8Text(body)
+	}
+}
+
+// checkUTF8Text returns an error unless data is valid UTF-8 text. NUL bytes are rejected as
+// well: they are valid UTF-8 but do not occur in text, while UTF-16 text without a byte
+// order mark, which could otherwise pass as UTF-8, is full of them.
+func checkUTF8Text(data []byte) error {
+	if !utf8.Valid(data) {
+		return fmt.Errorf("clear-sign expects UTF-8 text, but the input is not valid UTF-8")
+	}
+	if bytes.IndexByte(data, 0) >= 0 {
+		return fmt.Errorf("clear-sign expects UTF-8 text, but the input contains NUL bytes, as UTF-16 text without a byte order mark or binary data does")
+	}
+	return nil
+}
+
+// detectBOM returns the encoding indicated by a leading byte order mark and the data without it.
+func detectBOM(data []byte) (string, []byte) {
+	switch {
+	case bytes.HasPrefix(data, bomUTF8):
+		return "UTF-8 BOM", data[len(bomUTF8):]
+	case bytes.HasPrefix(data, bomUTF16LE):
+		return "UTF-16LE", data[len(bomUTF16LE):]
+	case bytes.HasPrefix(data, bomUTF16BE):
+		return "UTF-16BE", data[len(bomUTF16BE):]
+	default:
+		return "", data
+	}
+}
+
+// decodeUTF16 transcodes UTF-16 data in the given byte order to UTF-8. Unpaired surrogates
+// fail the conversion instead of being replaced, so the signed text is never altered.
+func decodeUTF16(data []byte, order binary.ByteOrder) ([]byte, error) {
+	if len(data)%2 != 0 {
+		return nil, fmt.Errorf("failed to convert UTF-16 input to UTF-8: odd number of bytes")
+	}
+
+	units := make([]uint16, 0, len(data)/2)
+	for i := 0; i+1 < len(data); i += 2 {
+		units = append(units, order.Uint16(data[i:]))
+	}
+
+	var buf bytes.Buffer
+	for i := 0; i < len(units); i++ {
+		r := rune(units[i])
+		if utf16.IsSurrogate(r) {
+			if i+1 < len(units) {
+				r = utf16.DecodeRune(r, rune(units[i+1]))
+			}
+			if r == utf8.RuneError || r == rune(units[i]) {
+				return nil, fmt.Errorf("failed to convert UTF-16 input to UTF-8: unpaired surrogate %#04x", units[i])
+			}
+			i++
+		}
+		buf.WriteRune(r)
+	}
+	return buf.Bytes(), checkUTF8Text(buf.Bytes())
+}
@@ -0,0 +1,128 @@
+package pgpsign
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestNormalizeCleartext(t *testing.T) {
+	utf16LE := []byte{0xFF, 0xFE, 'H', 0x00, 'i', 0x00, 0xE4, 0x00}
+	utf16BE := []byte{0xFE, 0xFF, 0x00, 'H', 0x00, 'i', 0x00, 0xE4}
+	utf8BOM := append([]byte{0xEF, 0xBB, 0xBF}, []byte("Hiä")...)
+
+	tests := []struct {
+		name        string
+		input       []byte
+		mode        CleartextEncoding
+		expected    []byte
+		expectError bool
+	}{
+		{
+			name:     "plain utf-8 strict",
+			input:    []byte("Hello, World!"),
+			mode:     CleartextStrict,
+			expected: []byte("Hello, World!"),
+		},
+		{
+			name:     "plain utf-8 default mode",
+			input:    []byte("Hiä"),
+			expected: []byte("Hiä"),
+		},
+		{
+			name:        "utf-8 bom strict",
+			input:       utf8BOM,
+			mode:        CleartextStrict,
+			expectError: true,
+		},
+		{
+			name:     "utf-8 bom convert",
+			input:    utf8BOM,
+			mode:     CleartextConvert,
+			expected: []byte("Hiä"),
+		},
+		{
+			name:        "utf-16le strict",
+			input:       utf16LE,
+			mode:        CleartextStrict,
+			expectError: true,
+		},
+		{
+			name:     "utf-16le convert",
+			input:    utf16LE,
+			mode:     CleartextConvert,
+			expected: []byte("Hiä"),
+		},
+		{
+			name:     "utf-16be convert",
+			input:    utf16BE,
+			mode:     CleartextConvert,
+			expected: []byte("Hiä"),
+		},
+		{
+			name:        "utf-16 odd length convert",
+			input:       []byte{0xFF, 0xFE, 'H'},
+			mode:        CleartextConvert,
+			expectError: true,
+		},
+		{
+			name:        "invalid utf-8 without bom",
+			input:       []byte{'H', 0xC3, 0x28},
+			mode:        CleartextConvert,
+			expectError: true,
+		},
+		{
+			name:        "utf-16le without bom",
+			input:       []byte{'H', 0x00, 'i', 0x00},
+			mode:        CleartextConvert,
+			expectError: true,
+		},
+		{
+			name:        "utf-16le unpaired high surrogate",
+			input:       []byte{0xFF, 0xFE, 'H', 0x00, 0x3D, 0xD8, 'i', 0x00},
+			mode:        CleartextConvert,
+			expectError: true,
+		},
+		{
+			name:        "utf-16be unpaired low surrogate",
+			input:       []byte{0xFE, 0xFF, 0x00, 'H', 0xDE, 0x00},
+			mode:        CleartextConvert,
+			expectError: true,
+		},
+		{
+			name:        "utf-16le trailing high surrogate",
+			input:       []byte{0xFF, 0xFE, 'H', 0x00, 0x3D, 0xD8},
+			mode:        CleartextConvert,
+			expectError: true,
+		},
+		{
+			name:     "utf-16le surrogate pair",
+			input:    []byte{0xFF, 0xFE, 'H', 0x00, 0x3D, 0xD8, 0x00, 0xDE},
+			mode:     CleartextConvert,
+			expected: []byte("H\U0001F600"),
+		},
+		{
+			name:        "unknown mode",
+			input:       []byte("Hello"),
+			mode:        "latin1",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := normalizeCleartext(tt.input, tt.mode)
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !bytes.Equal(result, tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
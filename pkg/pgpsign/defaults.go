@@ -0,0 +1,57 @@
+package pgpsign
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// DefaultConfig returns a Config holding the default of every input, as the CLI applies
+// them when it parses flags. Library callers start from it and set the inputs they need,
+// since fields left at their zero value do not always mean the default, such as Armor.
+func DefaultConfig() Config {
+	var config Config
+	value := reflect.ValueOf(&config).Elem()
+	for i := range value.NumField() {
+		field := value.Type().Field(i)
+		if def, ok := field.Tag.Lookup("default"); ok {
+			if err := setDefault(value.Field(i), def); err != nil {
+				panic(fmt.Sprintf("invalid default of Config.%s: %v", field.Name, err))
+			}
+		}
+	}
+	return config
+}
+
+// setDefault parses the default tag value def into field.
+func setDefault(field reflect.Value, def string) error {
+	if field.Type() == reflect.TypeFor[time.Duration]() {
+		d, err := time.ParseDuration(def)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(def)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(def)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int:
+		n, err := strconv.Atoi(def)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(n))
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package pgpsign
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDefaultConfig(t *testing.T) {
+	config := DefaultConfig()
+
+	if config.Backend != string(BackendGoPGP) || config.Mode != "sign" || config.OnOversize != "error" || config.OutputMode != "0644" {
+		t.Errorf("unexpected string defaults: %+v", config)
+	}
+	if !config.Armor || !config.Dereference || config.DetachSign {
+		t.Errorf("unexpected bool defaults: armor %v, dereference %v, detach-sign %v", config.Armor, config.Dereference, config.DetachSign)
+	}
+	if config.Concurrency != 1 || config.NetworkRetries != 3 {
+		t.Errorf("unexpected int defaults: concurrency %d, network retries %d", config.Concurrency, config.NetworkRetries)
+	}
+	if config.GPGTimeout != 10*time.Minute || config.ExpiryWarnWindow != 720*time.Hour {
+		t.Errorf("unexpected duration defaults: gpg timeout %s, expiry warn window %s", config.GPGTimeout, config.ExpiryWarnWindow)
+	}
+	if config.PrivateKey != "" || config.Files != "" {
+		t.Error("expected inputs without a default to stay empty")
+	}
+}
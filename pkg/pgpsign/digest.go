@@ -0,0 +1,96 @@
+package pgpsign
+
+import (
+	stdcrypto "crypto"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	openpgp "github.com/ProtonMail/go-crypto/openpgp/v2"
+)
+
+// DigestAlgorithm defines the hash algorithm used for signatures.
+type DigestAlgorithm string
+
+const (
+	DigestDefault DigestAlgorithm = ""
+	DigestSHA256  DigestAlgorithm = "sha256"
+	DigestSHA384  DigestAlgorithm = "sha384"
+	DigestSHA512  DigestAlgorithm = "sha512"
+)
+
+// digestHashes maps each digest algorithm to its hash function.
+var digestHashes = map[DigestAlgorithm]stdcrypto.Hash{
+	DigestSHA256: stdcrypto.SHA256,
+	DigestSHA384: stdcrypto.SHA384,
+	DigestSHA512: stdcrypto.SHA512,
+}
+
+// parseDigestAlgorithm parses the digest-algo input. Matching is case-insensitive,
+// and an empty value keeps the backend's default.
+func parseDigestAlgorithm(value string) (DigestAlgorithm, error) {
+	algo := DigestAlgorithm(strings.ToLower(strings.TrimSpace(value)))
+	if algo == DigestDefault {
+		return algo, nil
+	}
+	if _, ok := digestHashes[algo]; !ok {
+		return "", fmt.Errorf("unknown digest algorithm %q: expected sha256, sha384 or sha512", value)
+	}
+	return algo, nil
+}
+
+// gnupgName returns the algorithm name gpg expects for --digest-algo.
+func (d DigestAlgorithm) gnupgName() string {
+	return strings.ToUpper(string(d))
+}
+
+// checkDigestPreference makes sure go-crypto will sign with hash for entity. go-crypto only
+// honors a configured hash that the key lists among its preferred hashes, and otherwise
+// silently falls back to another one, so a mismatch is reported as an error instead.
+func checkDigestPreference(entity *openpgp.Entity, hash stdcrypto.Hash, config *packet.Config) error {
+	selfSignature, err := entity.PrimarySelfSignature(config.Now(), config)
+	if err != nil {
+		return fmt.Errorf("failed to read key preferences: %w", err)
+	}
+
+	// Keys without preferences are only signed with SHA-256.
+	preferred := []stdcrypto.Hash{stdcrypto.SHA256}
+	if len(selfSignature.PreferredHash) > 0 {
+		preferred = preferred[:0]
+		for _, id := range selfSignature.PreferredHash {
+			if h, ok := packetHash(id); ok {
+				preferred = append(preferred, h)
+			}
+		}
+	}
+
+	if !slices.Contains(preferred, hash) {
+		return fmt.Errorf("the signing key does not list %s among its preferred hash algorithms", hash)
+	}
+	return nil
+}
+
+// packetHash converts an OpenPGP hash algorithm ID to its hash function.
+func packetHash(id uint8) (stdcrypto.Hash, bool) {
+	for _, h := range digestHashes {
+		if hashID(h) == id {
+			return h, true
+		}
+	}
+	return 0, false
+}
+
+// hashID returns the OpenPGP hash algorithm ID of h as defined in RFC 9580.
+func hashID(h stdcrypto.Hash) uint8 {
+	switch h {
+	case stdcrypto.SHA256:
+		return 8
+	case stdcrypto.SHA384:
+		return 9
+	case stdcrypto.SHA512:
+		return 10
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,36 @@
+package pgpsign
+
+import "testing"
+
+func TestParseDigestAlgorithm(t *testing.T) {
+	tests := []struct {
+		value     string
+		expected  DigestAlgorithm
+		expectErr bool
+	}{
+		{value: "", expected: DigestDefault},
+		{value: "sha256", expected: DigestSHA256},
+		{value: "SHA384", expected: DigestSHA384},
+		{value: " sha512 ", expected: DigestSHA512},
+		{value: "sha1", expectErr: true},
+		{value: "md5", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			algo, err := parseDigestAlgorithm(tt.value)
+			if tt.expectErr {
+				if err == nil {
+					t.Fatalf("expected error for %q", tt.value)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if algo != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, algo)
+			}
+		})
+	}
+}
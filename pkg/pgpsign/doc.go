@@ -0,0 +1,4 @@
+// Package pgpsign signs release artifacts with OpenPGP, SSH or minisign keys. It is the
+// library behind the pgp-sign-artifact-action CLI: Sign runs the same steps as the action
+// for a Config, while NewSigner and the Signer interface sign single files directly.
+package pgpsign
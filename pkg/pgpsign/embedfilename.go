@@ -0,0 +1,27 @@
+package pgpsign
+
+import "path/filepath"
+
+// filenameNotation is the notation that records the signed file's base name in detached
+// and clear-text signatures, which carry no literal data packet of their own.
+const filenameNotation = "filename@pgp-sign-artifact-action"
+
+// forFile returns opts for signing file. With EmbedFilename, the file's base name is
+// recorded in the signature.
+func (opts SignOptions) forFile(file string) SignOptions {
+	if opts.EmbedFilename {
+		opts.Filename = filepath.Base(file)
+	}
+	return opts
+}
+
+// signatureNotations returns the notations added to the signature: the configured ones
+// and, for detached and clear-text signatures, the embedded filename. Inline signatures
+// record the filename in their literal data packet instead.
+func (opts SignOptions) signatureNotations() []Notation {
+	if opts.Filename == "" || (!opts.DetachSign && !opts.ClearSign) {
+		return opts.Notations
+	}
+	notations := append([]Notation(nil), opts.Notations...)
+	return append(notations, Notation{Name: filenameNotation, Value: opts.Filename})
+}
@@ -0,0 +1,128 @@
+package pgpsign
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+)
+
+func TestGoPGPSigner_EmbedFilename(t *testing.T) {
+	signer, err := NewGoPGPSigner(generateTestKeyArmored(t, "Test", "test@test.com", ""), "", "")
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+
+	testFile := filepath.Join(t.TempDir(), "release-1.0.tar.gz")
+	if err := os.WriteFile(testFile, []byte("release content"), 0o644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	t.Run("detached", func(t *testing.T) {
+		opts := SignOptions{DetachSign: true, EmbedFilename: true}.forFile(testFile)
+		if _, err := signer.SignFile(testFile, opts); err != nil {
+			t.Fatalf("failed to sign file: %v", err)
+		}
+
+		sig := readSignaturePacket(t, getOutputPath(testFile, opts))
+		var recorded string
+		for _, notation := range sig.Notations {
+			if notation.Name == filenameNotation {
+				recorded = string(notation.Value)
+			}
+		}
+		if recorded != "release-1.0.tar.gz" {
+			t.Errorf("expected filename notation release-1.0.tar.gz, got %q", recorded)
+		}
+
+		if err := signer.Verify(testFile, getOutputPath(testFile, opts), opts); err != nil {
+			t.Errorf("failed to verify signature: %v", err)
+		}
+	})
+
+	t.Run("inline", func(t *testing.T) {
+		opts := SignOptions{EmbedFilename: true}.forFile(testFile)
+		if _, err := signer.SignFile(testFile, opts); err != nil {
+			t.Fatalf("failed to sign file: %v", err)
+		}
+
+		if name := readLiteralFilename(t, getOutputPath(testFile, opts)); name != "release-1.0.tar.gz" {
+			t.Errorf("expected literal data filename release-1.0.tar.gz, got %q", name)
+		}
+		for _, notation := range readSignaturePacket(t, getOutputPath(testFile, opts)).Notations {
+			if notation.Name == filenameNotation {
+				t.Errorf("expected no filename notation in inline signature, got %q", notation.Value)
+			}
+		}
+
+		if err := signer.Verify(testFile, getOutputPath(testFile, opts), opts); err != nil {
+			t.Errorf("failed to verify signature: %v", err)
+		}
+	})
+}
+
+func TestSignOptions_ForFile(t *testing.T) {
+	opts := SignOptions{DetachSign: true, Notations: []Notation{{Name: "build-id@ci.example.com", Value: "1"}}}
+	if got := opts.forFile("dist/app.bin"); got.Filename != "" || len(got.signatureNotations()) != 1 {
+		t.Errorf("expected no filename without EmbedFilename, got %+v", got)
+	}
+
+	opts.EmbedFilename = true
+	got := opts.forFile("dist/app.bin")
+	if got.Filename != "app.bin" {
+		t.Errorf("expected filename app.bin, got %q", got.Filename)
+	}
+	if notations := got.signatureNotations(); !slices.Contains(notations, Notation{Name: filenameNotation, Value: "app.bin"}) || len(opts.Notations) != 1 {
+		t.Errorf("expected filename notation added without changing the configured notations, got %v", notations)
+	}
+}
+
+func TestGnuPGSigner_BuildArgsEmbedFilename(t *testing.T) {
+	signer := &GnuPGSigner{}
+
+	args := strings.Join(signer.buildArgs(SignOptions{DetachSign: true, EmbedFilename: true}.forFile("dist/app.bin"), 0), " ")
+	if !strings.Contains(args, "--set-notation "+filenameNotation+"=app.bin") || strings.Contains(args, "--set-filename") {
+		t.Errorf("expected filename notation for detached signature, got %s", args)
+	}
+
+	args = strings.Join(signer.buildArgs(SignOptions{EmbedFilename: true}.forFile("dist/app.bin"), 0), " ")
+	if !strings.Contains(args, "--set-filename app.bin") || strings.Contains(args, "--set-notation") {
+		t.Errorf("expected literal filename for inline signature, got %s", args)
+	}
+}
+
+// readLiteralFilename returns the filename recorded in the literal data packet of a
+// binary signed message.
+func readLiteralFilename(t *testing.T, path string) string {
+	t.Helper()
+	return readLiteralData(t, path).FileName
+}
+
+// readLiteralData returns the literal data packet of a binary signed message.
+func readLiteralData(t *testing.T, path string) *packet.LiteralData {
+	t.Helper()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open signed message: %v", err)
+	}
+	defer f.Close()
+
+	packets := packet.NewReader(f)
+	for {
+		p, err := packets.Next()
+		if err != nil {
+			t.Fatalf("failed to find literal data packet: %v", err)
+		}
+		if literal, ok := p.(*packet.LiteralData); ok {
+			if _, err := io.Copy(io.Discard, literal.Body); err != nil {
+				t.Fatalf("failed to read literal data: %v", err)
+			}
+			return literal
+		}
+	}
+}
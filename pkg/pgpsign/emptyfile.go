@@ -0,0 +1,64 @@
+package pgpsign
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// EmptyFilePolicy selects what happens to matched files of zero bytes.
+type EmptyFilePolicy string
+
+const (
+	EmptyFileSign  EmptyFilePolicy = "sign"  // Sign the file like any other
+	EmptyFileSkip  EmptyFilePolicy = "skip"  // Leave the file unsigned and log a warning
+	EmptyFileError EmptyFilePolicy = "error" // Fail before anything is signed
+)
+
+// parseEmptyFilePolicy parses the on-empty-file input. An empty value selects EmptyFileSign.
+func parseEmptyFilePolicy(value string) (EmptyFilePolicy, error) {
+	switch policy := EmptyFilePolicy(strings.ToLower(strings.TrimSpace(value))); policy {
+	case "":
+		return EmptyFileSign, nil
+	case EmptyFileSign, EmptyFileSkip, EmptyFileError:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown on-empty-file policy %q, expected %s, %s or %s", value, EmptyFileSign, EmptyFileSkip, EmptyFileError)
+	}
+}
+
+// checkEmptyFiles applies the on-empty-file input to files, so an accidentally empty build
+// output is noticed instead of being published with a valid signature. It returns the files
+// that are still to be signed.
+func checkEmptyFiles(args Config, files []string, log *slog.Logger) ([]string, error) {
+	policy, err := parseEmptyFilePolicy(args.OnEmptyFile)
+	if err != nil {
+		return nil, err
+	}
+	if policy == EmptyFileSign {
+		return files, nil
+	}
+
+	kept := make([]string, 0, len(files))
+	var empty []string
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check file size: %w", err)
+		}
+		switch {
+		case info.Size() > 0:
+			kept = append(kept, file)
+		case policy == EmptyFileSkip:
+			log.Warn("Skipping empty file", slog.String("file", file))
+		default:
+			empty = append(empty, file)
+		}
+	}
+
+	if len(empty) > 0 {
+		return nil, fmt.Errorf("files are empty: %s", strings.Join(empty, ", "))
+	}
+	return kept, nil
+}
@@ -0,0 +1,78 @@
+package pgpsign
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseEmptyFilePolicy(t *testing.T) {
+	if policy, err := parseEmptyFilePolicy(""); err != nil || policy != EmptyFileSign {
+		t.Errorf("expected sign policy by default, got %q, %v", policy, err)
+	}
+	if policy, err := parseEmptyFilePolicy("Error"); err != nil || policy != EmptyFileError {
+		t.Errorf("expected error policy, got %q, %v", policy, err)
+	}
+	if _, err := parseEmptyFilePolicy("warn"); err == nil {
+		t.Error("expected error for unknown policy")
+	}
+}
+
+func TestRunOnEmptyFile(t *testing.T) {
+	workDir := t.TempDir()
+	writeTestFiles(t, workDir, "app.bin", "lib.bin")
+	if err := os.WriteFile(filepath.Join(workDir, "empty.bin"), nil, 0o644); err != nil {
+		t.Fatalf("failed to create empty file: %v", err)
+	}
+
+	tests := []struct {
+		policy   string
+		expected []string
+		wantErr  bool
+	}{
+		{policy: "", expected: []string{"app.bin", "empty.bin", "lib.bin"}},
+		{policy: "sign", expected: []string{"app.bin", "empty.bin", "lib.bin"}},
+		{policy: "skip", expected: []string{"app.bin", "lib.bin"}},
+		{policy: "error", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run("policy "+tt.policy, func(t *testing.T) {
+			signer := &MockSigner{}
+			args := Config{
+				PrivateKey:  "unused",
+				Files:       "*.bin",
+				DetachSign:  true,
+				WorkDir:     workDir,
+				OnEmptyFile: tt.policy,
+			}
+
+			err := run(context.Background(), args, signer, nil, slog.New(slog.DiscardHandler))
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "empty.bin") {
+					t.Errorf("expected an error naming the empty file, got %v", err)
+				}
+				if len(signer.SignedFiles) != 0 {
+					t.Errorf("expected nothing to be signed, got %v", signer.SignedFiles)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var signed []string
+			for _, file := range signer.SignedFiles {
+				signed = append(signed, filepath.Base(file))
+			}
+			slices.Sort(signed)
+			if !slices.Equal(signed, tt.expected) {
+				t.Errorf("expected %v to be signed, got %v", tt.expected, signed)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package pgpsign
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ExpiryGuardPolicy selects what happens when a signing key expires before the run is
+// projected to finish.
+type ExpiryGuardPolicy string
+
+const (
+	ExpiryGuardOff   ExpiryGuardPolicy = "off"   // Do not project the run duration
+	ExpiryGuardWarn  ExpiryGuardPolicy = "warn"  // Log a warning once and keep signing
+	ExpiryGuardAbort ExpiryGuardPolicy = "abort" // Stop before signing the next file
+)
+
+// parseExpiryGuardPolicy parses the expiry-guard input. An empty value selects ExpiryGuardOff.
+func parseExpiryGuardPolicy(value string) (ExpiryGuardPolicy, error) {
+	switch policy := ExpiryGuardPolicy(strings.ToLower(strings.TrimSpace(value))); policy {
+	case "":
+		return ExpiryGuardOff, nil
+	case ExpiryGuardOff, ExpiryGuardWarn, ExpiryGuardAbort:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown expiry-guard policy %q, expected %s, %s or %s", value, ExpiryGuardOff, ExpiryGuardWarn, ExpiryGuardAbort)
+	}
+}
+
+// expiryGuard projects when signing total files finishes from the time taken by the files
+// done so far, and reacts if the earliest expiring signing key expires before that. This
+// keeps a large batch from ending up partly signed by a key that expired halfway through.
+// It is safe for concurrent use, and its methods do nothing on a nil guard.
+type expiryGuard struct {
+	policy  ExpiryGuardPolicy
+	expires time.Time
+	total   int
+	start   time.Time
+	now     func() time.Time
+	log     *slog.Logger
+
+	mu     sync.Mutex
+	done   int
+	warned bool
+}
+
+// newExpiryGuard returns a guard applying the expiry-guard input to signing total files with
+// keys, or nil if the guard is off or none of the keys expires.
+func newExpiryGuard(value string, keys []signingKey, total int, log *slog.Logger) (*expiryGuard, error) {
+	policy, err := parseExpiryGuardPolicy(value)
+	if err != nil || policy == ExpiryGuardOff {
+		return nil, err
+	}
+
+	var expires time.Time
+	for _, key := range keys {
+		info, ok, err := signingKeyInfo(key.signer)
+		if err != nil {
+			return nil, err
+		}
+		if ok && !info.Expires.IsZero() && (expires.IsZero() || info.Expires.Before(expires)) {
+			expires = info.Expires
+		}
+	}
+	if expires.IsZero() {
+		return nil, nil
+	}
+
+	return &expiryGuard{policy: policy, expires: expires, total: total, start: time.Now(), now: time.Now, log: log}, nil
+}
+
+// fileDone records that a file has been processed.
+func (g *expiryGuard) fileDone() {
+	if g == nil {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.done++
+}
+
+// check projects the end of the run from the average time per file so far. If the key
+// expires before then, it returns an error with the abort policy and logs a warning once
+// with the warn policy. Nothing is projected before the first file is done.
+func (g *expiryGuard) check() error {
+	if g == nil {
+		return nil
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.done == 0 {
+		return nil
+	}
+	now := g.now()
+	perFile := now.Sub(g.start) / time.Duration(g.done)
+	projectedEnd := now.Add(perFile * time.Duration(g.total-g.done))
+	if projectedEnd.Before(g.expires) {
+		return nil
+	}
+
+	expires := g.expires.UTC().Format(time.RFC3339)
+	finish := projectedEnd.UTC().Format(time.RFC3339)
+	if g.policy == ExpiryGuardAbort {
+		return fmt.Errorf("signing key expires on %s, before the run is projected to finish at %s; aborted after %d of %d files", expires, finish, g.done, g.total)
+	}
+	if !g.warned {
+		g.warned = true
+		g.log.Warn("SIGNING KEY EXPIRES BEFORE THE RUN IS PROJECTED TO FINISH; signatures made after that cannot be verified",
+			slog.String("expires", expires),
+			slog.String("projected_finish", finish),
+			slog.Int("done", g.done),
+			slog.Int("total", g.total),
+		)
+	}
+	return nil
+}
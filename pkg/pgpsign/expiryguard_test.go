@@ -0,0 +1,147 @@
+package pgpsign
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// expiringSigner is a MockSigner whose key expires at expires and that takes delay per file.
+type expiringSigner struct {
+	*MockSigner
+	expires time.Time
+	delay   time.Duration
+}
+
+func (s *expiringSigner) SignFile(filePath string, opts SignOptions) (SignResult, error) {
+	time.Sleep(s.delay)
+	return s.MockSigner.SignFile(filePath, opts)
+}
+
+func (s *expiringSigner) KeyInfo() (KeyInfo, error) {
+	return KeyInfo{UserIDs: []string{"Expiring <expiring@test.com>"}, Expires: s.expires}, nil
+}
+
+func TestParseExpiryGuardPolicy(t *testing.T) {
+	for value, expected := range map[string]ExpiryGuardPolicy{
+		"":      ExpiryGuardOff,
+		"off":   ExpiryGuardOff,
+		"Warn":  ExpiryGuardWarn,
+		"abort": ExpiryGuardAbort,
+	} {
+		got, err := parseExpiryGuardPolicy(value)
+		if err != nil || got != expected {
+			t.Errorf("parseExpiryGuardPolicy(%q) = %q, %v, expected %q", value, got, err, expected)
+		}
+	}
+
+	if _, err := parseExpiryGuardPolicy("fail"); err == nil {
+		t.Error("expected error for unknown policy")
+	}
+}
+
+func TestExpiryGuard(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		policy      ExpiryGuardPolicy
+		expires     time.Time
+		done        int
+		elapsed     time.Duration
+		expectError bool
+		expectWarn  bool
+	}{
+		{name: "nothing done yet", policy: ExpiryGuardAbort, expires: start.Add(time.Second)},
+		{name: "finishes in time", policy: ExpiryGuardAbort, expires: start.Add(time.Hour), done: 10, elapsed: time.Minute},
+		{name: "abort", policy: ExpiryGuardAbort, expires: start.Add(5 * time.Minute), done: 10, elapsed: time.Minute, expectError: true},
+		{name: "warn", policy: ExpiryGuardWarn, expires: start.Add(5 * time.Minute), done: 10, elapsed: time.Minute, expectWarn: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs strings.Builder
+			guard := &expiryGuard{
+				policy:  tt.policy,
+				expires: tt.expires,
+				total:   100,
+				start:   start,
+				now:     func() time.Time { return start.Add(tt.elapsed) },
+				log:     slog.New(slog.NewTextHandler(&logs, nil)),
+			}
+			for range tt.done {
+				guard.fileDone()
+			}
+
+			// Projected: 10 of 100 files in a minute leaves nine more minutes.
+			err := guard.check()
+			if tt.expectError != (err != nil) {
+				t.Errorf("expected error %v, got %v", tt.expectError, err)
+			}
+			if err := guard.check(); tt.expectError != (err != nil) {
+				t.Errorf("expected the guard to keep reporting, got %v", err)
+			}
+			expectedWarnings := 0
+			if tt.expectWarn {
+				expectedWarnings = 1
+			}
+			if warnings := strings.Count(logs.String(), "level=WARN"); warnings != expectedWarnings {
+				t.Errorf("expected %d warnings, got %d in %q", expectedWarnings, warnings, logs.String())
+			}
+		})
+	}
+}
+
+func TestRunExpiryGuard(t *testing.T) {
+	workDir := t.TempDir()
+	for _, name := range []string{"a.bin", "b.bin", "c.bin", "d.bin", "e.bin", "f.bin"} {
+		if err := os.WriteFile(filepath.Join(workDir, name), []byte("test"), 0o644); err != nil {
+			t.Fatalf("failed to create file: %v", err)
+		}
+	}
+
+	tests := []struct {
+		policy        string
+		expectError   bool
+		expectedCount int
+	}{
+		{policy: "off", expectedCount: 6},
+		{policy: "warn", expectedCount: 6},
+		{policy: "abort", expectError: true, expectedCount: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			// Each file takes 50ms, so the key expiring in 150ms cannot outlast the six files.
+			signer := &expiringSigner{MockSigner: &MockSigner{}, expires: time.Now().Add(150 * time.Millisecond), delay: 50 * time.Millisecond}
+			args := Config{
+				PrivateKey:  "key",
+				Files:       "*.bin",
+				DetachSign:  true,
+				WorkDir:     workDir,
+				ExpiryGuard: tt.policy,
+			}
+
+			err := run(context.Background(), args, signer, nil, nil)
+			if tt.expectError {
+				if err == nil || !strings.Contains(err.Error(), "projected to finish") {
+					t.Errorf("expected expiry guard error, got %v", err)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(signer.SignedFiles) != tt.expectedCount {
+				t.Errorf("expected %d signed files, got %v", tt.expectedCount, signer.SignedFiles)
+			}
+		})
+	}
+
+	args := Config{PrivateKey: "key", Files: "*.bin", WorkDir: workDir, ExpiryGuard: "fail"}
+	if err := run(context.Background(), args, &MockSigner{}, nil, nil); err == nil || !strings.Contains(err.Error(), "expiry-guard") {
+		t.Errorf("expected expiry-guard error, got %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+package pgpsign
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+)
+
+// parseExtensions parses the only-extensions input: extensions separated by commas or
+// newlines, with or without a leading dot or "*.". Multi-dot extensions such as tar.gz
+// are kept whole.
+func parseExtensions(value string) ([]string, error) {
+	var extensions []string
+	for _, field := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == '\n' }) {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		ext := strings.TrimPrefix(strings.TrimPrefix(field, "*"), ".")
+		if ext == "" || strings.ContainsAny(ext, `/\*?[`) || strings.HasSuffix(ext, ".") {
+			return nil, fmt.Errorf("invalid only-extensions entry %q, expected an extension such as tar.gz", field)
+		}
+		extensions = append(extensions, "."+ext)
+	}
+	return extensions, nil
+}
+
+// validateExtensionInputs checks the only-extensions input, which filters files on disk.
+func validateExtensionInputs(args Config) error {
+	if args.OnlyExtensions == "" {
+		return nil
+	}
+	if _, err := parseExtensions(args.OnlyExtensions); err != nil {
+		return err
+	}
+	if args.TarMembers || isStdinMode(args) || args.ImageDigests != "" {
+		return fmt.Errorf("only-extensions filters files on disk and cannot be combined with tar-members, stdin or image-digests")
+	}
+	return nil
+}
+
+// filterExtensions keeps the files whose name ends with one of the extensions of the
+// only-extensions input, after the patterns and excludes matched them. Extensions are
+// compared case-sensitively unless case-insensitive is set, like the patterns. Without
+// the input files are returned unchanged.
+func filterExtensions(args Config, files []string, log *slog.Logger) ([]string, error) {
+	if args.OnlyExtensions == "" {
+		return files, nil
+	}
+	extensions, err := parseExtensions(args.OnlyExtensions)
+	if err != nil {
+		return nil, err
+	}
+
+	var kept []string
+	for _, file := range files {
+		if hasExtension(filepath.Base(file), extensions, args.CaseInsensitive) {
+			kept = append(kept, file)
+		} else {
+			log.Debug("File extension not allowed, skipping", slog.String("file", file))
+		}
+	}
+	log.Info("Files filtered by extension",
+		slog.Any("extensions", extensions),
+		slog.Int("matched", len(files)),
+		slog.Int("kept", len(kept)),
+	)
+	return kept, nil
+}
+
+// hasExtension reports whether name ends with one of extensions and has a name before it,
+// so a file named .zip has no zip extension.
+func hasExtension(name string, extensions []string, caseInsensitive bool) bool {
+	if caseInsensitive {
+		name = strings.ToLower(name)
+	}
+	for _, ext := range extensions {
+		if caseInsensitive {
+			ext = strings.ToLower(ext)
+		}
+		if len(name) > len(ext) && strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
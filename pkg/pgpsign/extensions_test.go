@@ -0,0 +1,121 @@
+package pgpsign
+
+import (
+	"context"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseExtensions(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    []string
+		wantErr bool
+	}{
+		{value: "", want: nil},
+		{value: "tar.gz,zip,deb", want: []string{".tar.gz", ".zip", ".deb"}},
+		{value: ".tar.gz, *.zip\n deb ,", want: []string{".tar.gz", ".zip", ".deb"}},
+		{value: ".", wantErr: true},
+		{value: "*", wantErr: true},
+		{value: "tar.", wantErr: true},
+		{value: "dist/zip", wantErr: true},
+		{value: "t?r", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseExtensions(tt.value)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "invalid only-extensions entry") {
+					t.Errorf("expected an invalid entry error, got %v, %v", got, err)
+				}
+				return
+			}
+			if err != nil || !slices.Equal(got, tt.want) {
+				t.Errorf("expected %v, got %v, %v", tt.want, got, err)
+			}
+		})
+	}
+}
+
+func TestFilterExtensions(t *testing.T) {
+	log := slog.New(slog.DiscardHandler)
+	files := []string{
+		"/dist/app.tar.gz",
+		"/dist/app.gz",
+		"/dist/app.tar",
+		"/dist/APP.TAR.GZ",
+		"/dist/pkg.deb",
+		"/dist/pkg.deb.sha256",
+		"/dist/.zip",
+		"/dist/archive.zip",
+		"/dist.zip/readme",
+	}
+
+	tests := []struct {
+		name            string
+		extensions      string
+		caseInsensitive bool
+		want            []string
+	}{
+		{name: "unset", want: files},
+		{name: "multi-dot", extensions: "tar.gz", want: []string{"/dist/app.tar.gz"}},
+		{name: "single and multi-dot", extensions: "gz,deb", want: []string{"/dist/app.tar.gz", "/dist/app.gz", "/dist/pkg.deb"}},
+		{name: "name required", extensions: "zip", want: []string{"/dist/archive.zip"}},
+		{name: "case-insensitive", extensions: "TAR.gz", caseInsensitive: true, want: []string{"/dist/app.tar.gz", "/dist/APP.TAR.GZ"}},
+		{name: "case-sensitive", extensions: "TAR.GZ", want: []string{"/dist/APP.TAR.GZ"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := Config{OnlyExtensions: tt.extensions, CaseInsensitive: tt.caseInsensitive}
+			got, err := filterExtensions(args, files, log)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestValidateExtensionInputs(t *testing.T) {
+	if err := validateExtensionInputs(Config{OnlyExtensions: "zip", Files: "*"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := validateExtensionInputs(Config{OnlyExtensions: "zip", TarMembers: true}); err == nil || !strings.Contains(err.Error(), "files on disk") {
+		t.Errorf("expected only-extensions to be rejected with tar-members, got %v", err)
+	}
+	if err := validateExtensionInputs(Config{OnlyExtensions: "zip,."}); err == nil {
+		t.Error("expected an invalid extension to be rejected")
+	}
+}
+
+func TestRunOnlyExtensions(t *testing.T) {
+	workDir := t.TempDir()
+	writeTestFiles(t, workDir, "app.tar.gz", "app.zip", "notes.txt", "sub/lib.tar.gz", "sub/lib.gz")
+
+	signer := &MockSigner{}
+	args := Config{
+		PrivateKey:     "key",
+		Files:          "**/*",
+		Excludes:       "sub/lib.tar.gz",
+		DetachSign:     true,
+		Armor:          true,
+		WorkDir:        workDir,
+		OnlyExtensions: "tar.gz,zip",
+	}
+	if err := run(context.Background(), args, signer, nil, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{filepath.Join(workDir, "app.tar.gz"), filepath.Join(workDir, "app.zip")}
+	got := slices.Sorted(slices.Values(signer.SignedFiles))
+	if !slices.Equal(got, want) {
+		t.Errorf("expected the excludes and extensions to both apply, signing %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,66 @@
+package pgpsign
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// FileChangePolicy selects what happens when a file changes while it is being signed.
+type FileChangePolicy string
+
+const (
+	FileChangeError FileChangePolicy = "error" // Fail the file
+	FileChangeRetry FileChangePolicy = "retry" // Sign the file again, up to fileChangeAttempts times in total
+)
+
+// fileChangeAttempts is how often a file that keeps changing is signed with FileChangeRetry.
+const fileChangeAttempts = 3
+
+// errFileChanged is returned by SignFile when the file changed while it was read.
+var errFileChanged = errors.New("file changed while it was being signed")
+
+// afterSignedRead is called once the content of a file has been read for signing, before
+// the file is checked for changes. Tests replace it to modify the file at that point.
+var afterSignedRead = func(string) {}
+
+// parseFileChangePolicy parses the on-file-change input. An empty value selects FileChangeError.
+func parseFileChangePolicy(value string) (FileChangePolicy, error) {
+	switch policy := FileChangePolicy(strings.ToLower(strings.TrimSpace(value))); policy {
+	case "":
+		return FileChangeError, nil
+	case FileChangeError, FileChangeRetry:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown on-file-change policy %q, expected %s or %s", value, FileChangeError, FileChangeRetry)
+	}
+}
+
+// checkFileUnchanged returns errFileChanged if filePath no longer has the size and
+// modification time in before, which was taken when the file was opened.
+func checkFileUnchanged(filePath string, before os.FileInfo) error {
+	afterSignedRead(filePath)
+
+	after, err := os.Stat(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to stat file after signing: %w", err)
+	}
+	if after.Size() != before.Size() || !after.ModTime().Equal(before.ModTime()) {
+		return fmt.Errorf("%w: %s (size %d -> %d, modified %s -> %s)", errFileChanged, filePath,
+			before.Size(), after.Size(), before.ModTime().Format(time.RFC3339Nano), after.ModTime().Format(time.RFC3339Nano))
+	}
+	return nil
+}
+
+// retryOnFileChange calls sign until it succeeds or fails for a reason other than the
+// file changing, up to fileChangeAttempts times with FileChangeRetry.
+func retryOnFileChange(policy FileChangePolicy, sign func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := sign()
+		if !errors.Is(err, errFileChanged) || policy != FileChangeRetry || attempt == fileChangeAttempts {
+			return err
+		}
+	}
+}
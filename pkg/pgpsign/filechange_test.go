@@ -0,0 +1,88 @@
+package pgpsign
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseFileChangePolicy(t *testing.T) {
+	for input, expected := range map[string]FileChangePolicy{"": FileChangeError, "error": FileChangeError, "Retry": FileChangeRetry} {
+		policy, err := parseFileChangePolicy(input)
+		if err != nil || policy != expected {
+			t.Errorf("parseFileChangePolicy(%q) = %q, %v; expected %q", input, policy, err, expected)
+		}
+	}
+	if _, err := parseFileChangePolicy("ignore"); err == nil {
+		t.Error("expected error for unknown policy")
+	}
+}
+
+func TestGoPGPSigner_FileChangedDuringSigning(t *testing.T) {
+	signer, err := NewGoPGPSigner(generateTestKeyArmored(t, "Test", "test@test.com", ""), "", "")
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		policy      FileChangePolicy
+		changes     int
+		expectError bool
+	}{
+		{name: "error", policy: FileChangeError, changes: 1, expectError: true},
+		{name: "retry", policy: FileChangeRetry, changes: 1},
+		{name: "retry keeps changing", policy: FileChangeRetry, changes: fileChangeAttempts, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testFile := filepath.Join(t.TempDir(), "build.bin")
+			if err := os.WriteFile(testFile, []byte("first build"), 0o644); err != nil {
+				t.Fatalf("failed to create test file: %v", err)
+			}
+
+			// Rewrite the file after its content was read, as a concurrent build would.
+			changes := 0
+			original := afterSignedRead
+			afterSignedRead = func(path string) {
+				if changes == tt.changes {
+					return
+				}
+				changes++
+				content := []byte("rebuilt " + string(rune('a'+changes)))
+				if err := os.WriteFile(path, content, 0o644); err != nil {
+					t.Errorf("failed to rewrite file: %v", err)
+				}
+				mtime := time.Now().Add(time.Duration(changes) * time.Minute)
+				if err := os.Chtimes(path, mtime, mtime); err != nil {
+					t.Errorf("failed to touch file: %v", err)
+				}
+			}
+			t.Cleanup(func() { afterSignedRead = original })
+
+			opts := SignOptions{DetachSign: true, OnFileChange: tt.policy}
+			_, err := signer.SignFile(testFile, opts)
+			outputPath := getOutputPath(testFile, opts)
+
+			if tt.expectError {
+				if !errors.Is(err, errFileChanged) {
+					t.Fatalf("expected file change error, got %v", err)
+				}
+				if _, err := os.Stat(outputPath); !os.IsNotExist(err) {
+					t.Error("expected no signature over changed content")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := signer.Verify(testFile, outputPath, opts); err != nil {
+				t.Errorf("expected signature over the final content: %v", err)
+			}
+		})
+	}
+}
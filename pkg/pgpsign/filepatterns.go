@@ -0,0 +1,99 @@
+package pgpsign
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// scopedExcludeSeparator separates an include pattern from the excludes scoped to it.
+const scopedExcludeSeparator = "=>"
+
+// FilePattern is an include pattern together with the exclude patterns that only apply
+// to the files it matches.
+type FilePattern struct {
+	Include  string
+	Excludes []string // Checked after the global excludes, so "!" patterns can re-include files
+}
+
+// excludesWith returns the global excludes followed by the pattern's scoped excludes.
+func (p FilePattern) excludesWith(global []string) []string {
+	if len(p.Excludes) == 0 {
+		return global
+	}
+	return append(slices.Clone(global), p.Excludes...)
+}
+
+// parseFilePatterns parses file pattern lines. A line is either a plain include pattern or
+// "pattern => exclude1,exclude2", which applies the listed excludes only to that pattern's matches.
+func parseFilePatterns(lines []string) ([]FilePattern, error) {
+	patterns := make([]FilePattern, 0, len(lines))
+	for _, line := range lines {
+		include, scoped, found := strings.Cut(line, scopedExcludeSeparator)
+		pattern := FilePattern{Include: strings.TrimSpace(include)}
+		if !found {
+			patterns = append(patterns, pattern)
+			continue
+		}
+
+		if pattern.Include == "" {
+			return nil, fmt.Errorf("missing include pattern before %q in %q", scopedExcludeSeparator, line)
+		}
+		for _, exclude := range splitExcludeList(scoped) {
+			if exclude = strings.TrimSpace(exclude); exclude != "" {
+				pattern.Excludes = append(pattern.Excludes, exclude)
+			}
+		}
+		if len(pattern.Excludes) == 0 {
+			return nil, fmt.Errorf("missing excludes after %q in %q", scopedExcludeSeparator, line)
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
+// splitExcludeList splits a comma separated exclude list. Commas inside brace groups
+// or escaped with a backslash belong to the pattern.
+func splitExcludeList(list string) []string {
+	var excludes []string
+	depth, start := 0, 0
+	for i := 0; i < len(list); i++ {
+		switch list[i] {
+		case '\\':
+			i++
+		case '{':
+			depth++
+		case '}':
+			if depth > 0 {
+				depth--
+			}
+		case ',':
+			if depth == 0 {
+				excludes = append(excludes, list[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(excludes, list[start:])
+}
+
+// recursivePatterns prefixes every include pattern without a path separator or "**" with
+// "**/", so "*.jar" matches at any depth below the working directory. Patterns with a
+// directory part and scoped excludes are kept as written.
+func recursivePatterns(lines []string) []string {
+	rewritten := make([]string, len(lines))
+	for i, line := range lines {
+		include, scoped, found := strings.Cut(line, scopedExcludeSeparator)
+		include = strings.TrimSpace(include)
+		if include == "" || strings.Contains(include, "/") || strings.Contains(include, "**") {
+			rewritten[i] = line
+			continue
+		}
+
+		rewritten[i] = "**/" + include
+		if found {
+			rewritten[i] += " " + scopedExcludeSeparator + scoped
+		}
+	}
+	return rewritten
+}
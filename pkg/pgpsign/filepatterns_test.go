@@ -0,0 +1,217 @@
+package pgpsign
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseFilePatterns(t *testing.T) {
+	tests := []struct {
+		name        string
+		lines       []string
+		expected    []FilePattern
+		errContains string
+	}{
+		{name: "plain", lines: []string{"dist/*", "*.txt"}, expected: []FilePattern{{Include: "dist/*"}, {Include: "*.txt"}}},
+		{name: "scoped", lines: []string{"dist/* => *.txt, *.md"}, expected: []FilePattern{{Include: "dist/*", Excludes: []string{"*.txt", "*.md"}}}},
+		{name: "braces", lines: []string{"dist/* => *.{txt,md},a\\,b"}, expected: []FilePattern{{Include: "dist/*", Excludes: []string{"*.{txt,md}", "a\\,b"}}}},
+		{name: "negation", lines: []string{"**/*.bin=>test/**,!test/keep.bin"}, expected: []FilePattern{{Include: "**/*.bin", Excludes: []string{"test/**", "!test/keep.bin"}}}},
+		{name: "missing include", lines: []string{" => *.txt"}, errContains: "missing include pattern"},
+		{name: "missing excludes", lines: []string{"dist/* => , "}, errContains: "missing excludes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseFilePatterns(tt.lines)
+			if tt.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Errorf("expected error containing %q, got %v", tt.errContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestFindFiles_ScopedExcludes(t *testing.T) {
+	tempDir := t.TempDir()
+
+	for _, f := range []string{"dist/app.tar.gz", "dist/notes.txt", "dist/readme.md", "docs/guide.txt", "docs/draft.txt", "root.txt"} {
+		path := filepath.Join(tempDir, filepath.FromSlash(f))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("test"), 0o644); err != nil {
+			t.Fatalf("failed to create file: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		patterns []string
+		excludes []string
+		expected []string
+	}{
+		{
+			name:     "global only",
+			patterns: []string{"dist/*", "docs/*"},
+			excludes: []string{"*.txt"},
+			expected: []string{"dist/app.tar.gz", "dist/readme.md"},
+		},
+		{
+			name:     "scoped only",
+			patterns: []string{"dist/* => *.txt", "docs/*"},
+			expected: []string{"dist/app.tar.gz", "dist/readme.md", "docs/draft.txt", "docs/guide.txt"},
+		},
+		{
+			name:     "mixed",
+			patterns: []string{"dist/* => *.txt", "docs/*", "*.txt"},
+			excludes: []string{"*.md", "draft.*"},
+			expected: []string{"dist/app.tar.gz", "docs/guide.txt", "root.txt"},
+		},
+		{
+			name:     "scoped re-include",
+			patterns: []string{"dist/* => !*.md"},
+			excludes: []string{"*.md"},
+			expected: []string{"dist/app.tar.gz", "dist/notes.txt", "dist/readme.md"},
+		},
+		{
+			name:     "excluded by one pattern, matched by another",
+			patterns: []string{"dist/* => *.txt", "**/notes.txt"},
+			expected: []string{"dist/app.tar.gz", "dist/notes.txt", "dist/readme.md"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			finder := &DefaultFileFinder{}
+			files, err := finder.FindFiles(tempDir, tt.patterns, tt.excludes)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var got []string
+			for _, file := range files {
+				rel, _ := filepath.Rel(tempDir, file)
+				got = append(got, filepath.ToSlash(rel))
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestMatchMember_ScopedExcludes(t *testing.T) {
+	patterns, err := parseFilePatterns([]string{"dist/* => *.txt", "docs/*"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for name, expected := range map[string]bool{
+		"dist/app.tar.gz": true,
+		"dist/notes.txt":  false,
+		"docs/notes.txt":  true,
+		"docs/readme.md":  false,
+	} {
+		if got := matchMember(name, patterns, []string{"*.md"}); got != expected {
+			t.Errorf("matchMember(%q) = %v, expected %v", name, got, expected)
+		}
+	}
+}
+
+func TestRecursivePatterns(t *testing.T) {
+	lines := []string{"*.jar", "dist/*.jar", "**/*.zip", "**", "lib-*.jar => *-sources.jar", ""}
+	expected := []string{"**/*.jar", "dist/*.jar", "**/*.zip", "**", "**/lib-*.jar => *-sources.jar", ""}
+
+	if got := recursivePatterns(lines); !slices.Equal(got, expected) {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestFindFiles_Recursive(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestFiles(t, tempDir, "app.jar", "lib/core.jar", "lib/deep/util.jar", "lib/deep/util-sources.jar", "dist/app.jar", "notes.txt")
+
+	tests := []struct {
+		name      string
+		patterns  []string
+		excludes  []string
+		recursive bool
+		expected  []string
+	}{
+		{
+			name:     "simple pattern without recursive",
+			patterns: []string{"*.jar"},
+			expected: []string{"app.jar"},
+		},
+		{
+			name:      "simple pattern with recursive",
+			patterns:  []string{"*.jar"},
+			recursive: true,
+			expected:  []string{"app.jar", "dist/app.jar", "lib/core.jar", "lib/deep/util-sources.jar", "lib/deep/util.jar"},
+		},
+		{
+			name:      "directory pattern is kept",
+			patterns:  []string{"lib/*.jar"},
+			recursive: true,
+			expected:  []string{"lib/core.jar"},
+		},
+		{
+			name:      "explicit globstar is not applied twice",
+			patterns:  []string{"**/*.jar"},
+			recursive: true,
+			expected:  []string{"app.jar", "dist/app.jar", "lib/core.jar", "lib/deep/util-sources.jar", "lib/deep/util.jar"},
+		},
+		{
+			name:      "base name excludes apply at any depth",
+			patterns:  []string{"*.jar"},
+			excludes:  []string{"*-sources.jar"},
+			recursive: true,
+			expected:  []string{"app.jar", "dist/app.jar", "lib/core.jar", "lib/deep/util.jar"},
+		},
+		{
+			name:      "directory excludes stay relative to the working directory",
+			patterns:  []string{"*.jar"},
+			excludes:  []string{"lib/deep/*"},
+			recursive: true,
+			expected:  []string{"app.jar", "dist/app.jar", "lib/core.jar"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patterns := tt.patterns
+			if tt.recursive {
+				patterns = recursivePatterns(patterns)
+			}
+
+			finder := &DefaultFileFinder{}
+			files, err := finder.FindFiles(tempDir, patterns, tt.excludes)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var got []string
+			for _, file := range files {
+				rel, _ := filepath.Rel(tempDir, file)
+				got = append(got, filepath.ToSlash(rel))
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,555 @@
+package pgpsign
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// FileFinder defines the interface for finding files.
+type FileFinder interface {
+	FindFiles(workDir string, patterns, excludes []string) ([]string, error)
+}
+
+// DefaultFileFinder implements FileFinder using the standard library.
+type DefaultFileFinder struct {
+	FollowSymlinks    bool          // Descend into symlinked directories when expanding ** patterns
+	IncludeDirs       bool          // Return directories matched by patterns without ** instead of skipping them
+	ExcludeOlderThan  time.Duration // Skip files modified longer than this before startTime (0 disables)
+	ExcludeLargerThan int64         // Skip files larger than this many bytes (0 disables)
+	SkipSymlinks      bool          // Skip matched symlinks instead of signing the content of their targets
+	Log               *slog.Logger  // Receives debug messages about skipped symlinks; nil discards them
+}
+
+// newFileFinder returns the CommandFileFinder running files-command if it is set, and the
+// DefaultFileFinder configured by args otherwise.
+func newFileFinder(ctx context.Context, args Config, log *slog.Logger) (FileFinder, error) {
+	if args.FilesCommand != "" {
+		return &CommandFileFinder{Command: args.FilesCommand, Context: ctx, Log: log}, nil
+	}
+	largerThan, err := parseFileSize("exclude-larger-than", args.ExcludeLargerThan)
+	if err != nil {
+		return nil, err
+	}
+	return &DefaultFileFinder{
+		FollowSymlinks:    args.FollowSymlinks,
+		IncludeDirs:       args.ArchiveDirs,
+		ExcludeOlderThan:  args.ExcludeOlderThan,
+		ExcludeLargerThan: largerThan,
+		SkipSymlinks:      !args.Dereference,
+		Log:               log,
+	}, nil
+}
+
+// FindFiles finds files matching patterns while excluding others and returns their clean
+// absolute paths. Patterns may scope additional excludes to their own matches, as in
+// "dist/* => *.txt" (see parseFilePatterns).
+func (f *DefaultFileFinder) FindFiles(workDir string, patterns, excludes []string) ([]string, error) {
+	if workDir == "" {
+		workDir = "."
+	}
+
+	filePatterns, err := parseFilePatterns(patterns)
+	if err != nil {
+		return nil, err
+	}
+	absWorkDir, err := filepath.Abs(workDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve working directory: %w", err)
+	}
+
+	var matchedFiles []string
+	seen := make(map[string]bool)
+
+	for _, filePattern := range filePatterns {
+		patternExcludes := filePattern.excludesWith(excludes)
+
+		for _, pattern := range expandBraces(filePattern.Include) {
+			if pattern == "" {
+				continue
+			}
+
+			matches, err := f.match(workDir, pattern)
+			if err != nil {
+				return nil, err
+			}
+
+			// A file excluded by one pattern's scoped excludes may still be matched by another pattern.
+			for _, match := range matches {
+				// Glob and the globstar walk may spell the same file differently, so compare absolute paths.
+				file, err := filepath.Abs(match)
+				if err != nil {
+					return nil, fmt.Errorf("failed to resolve %s: %w", match, err)
+				}
+				if !seen[file] && !shouldExclude(file, absWorkDir, patternExcludes) && !f.excludedByStat(file) && !f.skippedSymlink(file) {
+					seen[file] = true
+					matchedFiles = append(matchedFiles, file)
+				}
+			}
+		}
+	}
+
+	return matchedFiles, nil
+}
+
+// excludedByStat reports whether file is older or larger than the ExcludeOlderThan and
+// ExcludeLargerThan limits allow. Age is measured from startTime, so files written while
+// the run is in progress are never too old. Directories are not filtered.
+func (f *DefaultFileFinder) excludedByStat(file string) bool {
+	if f.ExcludeOlderThan <= 0 && f.ExcludeLargerThan <= 0 {
+		return false
+	}
+	info, err := os.Stat(file)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	if f.ExcludeOlderThan > 0 && info.ModTime().Before(startTime.Add(-f.ExcludeOlderThan)) {
+		return true
+	}
+	return f.ExcludeLargerThan > 0 && info.Size() > f.ExcludeLargerThan
+}
+
+// skippedSymlink reports whether file is a symlink that SkipSymlinks excludes.
+// Files reached through a symlinked directory are not symlinks themselves and are kept.
+func (f *DefaultFileFinder) skippedSymlink(file string) bool {
+	if !f.SkipSymlinks {
+		return false
+	}
+	info, err := os.Lstat(file)
+	if err != nil || info.Mode()&fs.ModeSymlink == 0 {
+		return false
+	}
+	if f.Log != nil {
+		f.Log.Debug("Skipping symlink", slog.String("file", file))
+	}
+	return true
+}
+
+// match returns the files below workDir matching a single pattern.
+func (f *DefaultFileFinder) match(workDir, pattern string) ([]string, error) {
+	// Handle ** globstar patterns by walking the directory
+	if strings.Contains(pattern, "**") {
+		return findWithGlobstar(workDir, pattern, f.FollowSymlinks)
+	}
+
+	matches, err := filepath.Glob(filepath.Join(workDir, pattern))
+	if err != nil {
+		return nil, fmt.Errorf("invalid glob pattern %q: %w", pattern, err)
+	}
+
+	files := matches[:0]
+	for _, match := range matches {
+		// Stat follows symlinks, so links to directories, broken links and
+		// symlink cycles are all skipped here.
+		info, err := os.Stat(match)
+		if err != nil {
+			continue
+		}
+		if !info.Mode().IsRegular() && (!f.IncludeDirs || !info.IsDir()) {
+			continue
+		}
+		files = append(files, match)
+	}
+
+	return files, nil
+}
+
+// findWithGlobstar handles patterns containing ** for recursive matching.
+// A ** path segment matches zero or more directories and may appear any number of
+// times, so a/**/b/**/*.jar, **/*.txt and dist/** all work. Only the part of the tree
+// below the pattern's literal prefix is walked.
+func findWithGlobstar(workDir, pattern string, followSymlinks bool) ([]string, error) {
+	segments := splitGlobstarPattern(pattern)
+
+	// Walk only below the leading segments that contain no wildcards.
+	literal := 0
+	for literal < len(segments) && !hasGlobMeta(segments[literal]) {
+		literal++
+	}
+	searchDir := filepath.Join(workDir, filepath.FromSlash(path.Join(segments[:literal]...)))
+
+	var matches []string
+	err := walkFiles(searchDir, followSymlinks, func(file string) {
+		relPath, err := filepath.Rel(workDir, file)
+		if err != nil {
+			return
+		}
+		if matchGlobstar(segments, pathSegments(relPath)) {
+			matches = append(matches, file)
+		}
+	})
+
+	return matches, err
+}
+
+// walkFiles calls fn for every regular file below root, including symlinks to regular files.
+// Symlinked directories are only descended into when followSymlinks is set. Each directory
+// is walked at most once, so symlink cycles terminate. Entries that cannot be read, broken
+// symlinks and special files are skipped.
+func walkFiles(root string, followSymlinks bool, fn func(file string)) error {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil
+	}
+	if !info.IsDir() {
+		if info.Mode().IsRegular() {
+			fn(root)
+		}
+		return nil
+	}
+
+	visited := make(map[string]bool)
+	var walk func(dir string)
+	walk = func(dir string) {
+		// Symlinks can lead back into a directory that is already being walked.
+		realDir, err := filepath.EvalSymlinks(dir)
+		if err != nil || visited[realDir] {
+			return
+		}
+		visited[realDir] = true
+
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return
+		}
+		for _, entry := range entries {
+			file := filepath.Join(dir, entry.Name())
+			switch {
+			case entry.IsDir():
+				walk(file)
+			case entry.Type()&fs.ModeSymlink != 0:
+				target, err := os.Stat(file)
+				if err != nil {
+					continue // Broken or cyclic symlink
+				}
+				if target.IsDir() {
+					if followSymlinks {
+						walk(file)
+					}
+				} else if target.Mode().IsRegular() {
+					fn(file)
+				}
+			case entry.Type().IsRegular():
+				fn(file)
+			}
+		}
+	}
+	walk(root)
+
+	return nil
+}
+
+// splitGlobstarPattern splits a pattern into slash-separated segments. A ** inside a
+// segment, as in **.txt, cannot span directories and is treated like *.
+func splitGlobstarPattern(pattern string) []string {
+	var segments []string
+	for _, segment := range strings.Split(filepath.ToSlash(pattern), "/") {
+		switch {
+		case segment == "" || segment == ".":
+			continue
+		case segment != "**":
+			for strings.Contains(segment, "**") {
+				segment = strings.ReplaceAll(segment, "**", "*")
+			}
+		}
+		segments = append(segments, segment)
+	}
+	return segments
+}
+
+// matchGlobstarPath reports whether relPath matches pattern, where a ** segment matches
+// zero or more directories. It is shared by file patterns and excludes, so **/testdata/**
+// selects the same subtrees in both.
+func matchGlobstarPath(pattern, relPath string) bool {
+	return matchGlobstar(splitGlobstarPattern(pattern), pathSegments(relPath))
+}
+
+// pathSegments splits a relative path into its slash-separated segments.
+func pathSegments(relPath string) []string {
+	return strings.Split(filepath.ToSlash(relPath), "/")
+}
+
+// matchGlobstar reports whether the path segments match the pattern segments,
+// where a ** segment matches zero or more path segments.
+func matchGlobstar(pattern, segments []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(segments); i++ {
+				if matchGlobstar(pattern[1:], segments[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(segments) == 0 {
+			return false
+		}
+		if matched, _ := path.Match(pattern[0], segments[0]); !matched {
+			return false
+		}
+		pattern, segments = pattern[1:], segments[1:]
+	}
+
+	return len(segments) == 0
+}
+
+// hasGlobMeta reports whether a pattern segment contains wildcard characters.
+func hasGlobMeta(segment string) bool {
+	return strings.ContainsAny(segment, `*?[\`)
+}
+
+// expandBraces expands {a,b,c} alternations in pattern into one pattern per alternative,
+// so dist/*.{tar.gz,zip} becomes dist/*.tar.gz and dist/*.zip. Groups may be nested.
+// Braces and commas escaped with a backslash, and groups without a comma, are kept literally.
+func expandBraces(pattern string) []string {
+	start, end, alternatives := findBraceGroup(pattern)
+	if start < 0 {
+		return []string{pattern}
+	}
+
+	var expanded []string
+	for _, alternative := range alternatives {
+		expanded = append(expanded, expandBraces(pattern[:start]+alternative+pattern[end+1:])...)
+	}
+	return expanded
+}
+
+// findBraceGroup returns the position of the first expandable brace group in pattern and
+// its comma-separated alternatives. start is -1 if pattern contains no such group.
+func findBraceGroup(pattern string) (start, end int, alternatives []string) {
+	for start = 0; start < len(pattern); start++ {
+		switch pattern[start] {
+		case '\\':
+			start++
+		case '{':
+			end, alternatives = splitBraceGroup(pattern, start)
+			if end >= 0 && len(alternatives) > 1 {
+				return start, end, alternatives
+			}
+		}
+	}
+	return -1, -1, nil
+}
+
+// splitBraceGroup splits the brace group opening at start at its top-level commas.
+// It returns the position of the closing brace, or -1 if the group is not closed.
+func splitBraceGroup(pattern string, start int) (int, []string) {
+	var alternatives []string
+	depth := 0
+	from := start + 1
+
+	for i := start + 1; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			i++
+		case '{':
+			depth++
+		case ',':
+			if depth == 0 {
+				alternatives = append(alternatives, pattern[from:i])
+				from = i + 1
+			}
+		case '}':
+			if depth == 0 {
+				return i, append(alternatives, pattern[from:i])
+			}
+			depth--
+		}
+	}
+	return -1, nil
+}
+
+// foldCasePattern rewrites pattern so that it matches names regardless of case. Every
+// letter is replaced with a character class of both cases, as in *.[jJ][pP][gG], and
+// letters in existing character classes get their other case added to the class.
+func foldCasePattern(pattern string) string {
+	runes := []rune(pattern)
+	var b strings.Builder
+
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; {
+		case r == '\\' && i+1 < len(runes):
+			i++
+			if other := otherCase(runes[i]); other != runes[i] {
+				b.WriteString("[" + string(runes[i]) + string(other) + "]")
+			} else {
+				b.WriteString(string(runes[i-1 : i+1]))
+			}
+		case r == '[':
+			end := classEnd(runes, i)
+			if end < 0 {
+				b.WriteString(string(runes[i:]))
+				return b.String()
+			}
+			b.WriteString(foldCaseClass(runes[i : end+1]))
+			i = end
+		case otherCase(r) != r:
+			b.WriteString("[" + string(r) + string(otherCase(r)) + "]")
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// foldCasePatterns applies foldCasePattern to every pattern.
+func foldCasePatterns(patterns []string) []string {
+	folded := make([]string, len(patterns))
+	for i, pattern := range patterns {
+		folded[i] = foldCasePattern(pattern)
+	}
+	return folded
+}
+
+// classEnd returns the index of the bracket closing the character class starting at
+// start, or -1 if the class is not closed.
+func classEnd(runes []rune, start int) int {
+	i := start + 1
+	if i < len(runes) && runes[i] == '^' {
+		i++
+	}
+	for first := true; i < len(runes); i, first = i+1, false {
+		switch runes[i] {
+		case '\\':
+			i++
+		case ']':
+			if !first {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// foldCaseClass adds the other case of every letter and letter range to a character class.
+func foldCaseClass(class []rune) string {
+	var b strings.Builder
+	b.WriteRune('[')
+	i := 1
+	if class[i] == '^' {
+		b.WriteRune('^')
+		i++
+	}
+
+	var folded []rune
+	for end := len(class) - 1; i < end; i++ {
+		lo := class[i]
+		item := []rune{lo}
+		if lo == '\\' {
+			i++
+			lo = class[i]
+			item = append(item, lo)
+		}
+		hi := lo
+		if i+2 < end && class[i+1] == '-' {
+			hi = class[i+2]
+			item = append(item, '-', hi)
+			i += 2
+		}
+		b.WriteString(string(item))
+
+		if otherCase(lo) != lo && otherCase(hi) != hi && unicode.IsUpper(lo) == unicode.IsUpper(hi) {
+			folded = append(folded, otherCase(lo))
+			if hi != lo {
+				folded = append(folded, '-', otherCase(hi))
+			}
+		}
+	}
+
+	b.WriteString(string(folded))
+	b.WriteRune(']')
+	return b.String()
+}
+
+// otherCase returns the upper case of a lower case letter and the lower case of any
+// other rune. Runes without case are returned unchanged.
+func otherCase(r rune) rune {
+	if unicode.IsLower(r) {
+		return unicode.ToUpper(r)
+	}
+	return unicode.ToLower(r)
+}
+
+// shouldExclude checks if a file is excluded by the exclusion patterns.
+// Patterns are evaluated in order like .gitignore: a pattern prefixed with "!" re-includes
+// a file excluded by an earlier pattern, and the last matching pattern wins. Brace
+// alternations are expanded as in file patterns.
+func shouldExclude(file, workDir string, excludes []string) bool {
+	relPath, err := filepath.Rel(workDir, file)
+	if err != nil {
+		relPath = file
+	}
+
+	excluded := false
+	for _, exclude := range excludes {
+		exclude = strings.TrimSpace(exclude)
+
+		negate := strings.HasPrefix(exclude, "!")
+		exclude = strings.TrimPrefix(exclude, "!")
+		if exclude == "" {
+			continue
+		}
+
+		for _, alternative := range expandBraces(exclude) {
+			if matchesExclude(alternative, file, relPath, workDir) {
+				excluded = !negate
+				break
+			}
+		}
+	}
+
+	return excluded
+}
+
+// matchesExclude reports whether a single exclusion pattern matches file.
+func matchesExclude(exclude, file, relPath, workDir string) bool {
+	// Direct match against relative path
+	if matched, _ := filepath.Match(exclude, relPath); matched {
+		return true
+	}
+
+	// Match against base name
+	if matched, _ := filepath.Match(exclude, filepath.Base(file)); matched {
+		return true
+	}
+
+	// ** segments match any number of directories, as in file patterns, so
+	// **/testdata/** excludes every file below a testdata directory at any depth.
+	if strings.Contains(exclude, "**") && matchGlobstarPath(exclude, relPath) {
+		return true
+	}
+
+	// Full path match
+	excludePattern := filepath.Join(workDir, exclude)
+	if matched, _ := filepath.Match(excludePattern, file); matched {
+		return true
+	}
+
+	return matchesExcludedDir(exclude, relPath)
+}
+
+// matchesExcludedDir reports whether exclude matches a directory containing relPath.
+// * does not cross path separators, so this makes "vendor/*" and "vendor/" exclude
+// everything below vendor at any depth. Only whole directory names match, so vendor
+// does not exclude vendored.
+func matchesExcludedDir(exclude, relPath string) bool {
+	exclude = strings.TrimRight(exclude, "/"+string(filepath.Separator))
+	if exclude == "" {
+		return false
+	}
+
+	for dir := filepath.Dir(relPath); dir != "." && filepath.IsLocal(dir); dir = filepath.Dir(dir) {
+		if matched, _ := filepath.Match(exclude, dir); matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,981 @@
+package pgpsign
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDefaultFileFinder_FindFiles(t *testing.T) {
+	tempDir := t.TempDir()
+
+	// Create test files
+	testFiles := []string{
+		"file1.txt",
+		"file2.txt",
+		"file.bin",
+		"data.json",
+		"subdir/file3.txt",
+		"subdir/file4.bin",
+		"dist/release.tar.gz",
+		"dist/release.sha256",
+	}
+
+	for _, f := range testFiles {
+		path := filepath.Join(tempDir, f)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("test"), 0o644); err != nil {
+			t.Fatalf("failed to create file: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name          string
+		patterns      []string
+		excludes      []string
+		expectedFiles []string
+	}{
+		{
+			name:     "single pattern match txt files",
+			patterns: []string{"*.txt"},
+			expectedFiles: []string{
+				filepath.Join(tempDir, "file1.txt"),
+				filepath.Join(tempDir, "file2.txt"),
+			},
+		},
+		{
+			name:     "multiple patterns",
+			patterns: []string{"*.txt", "*.bin"},
+			expectedFiles: []string{
+				filepath.Join(tempDir, "file1.txt"),
+				filepath.Join(tempDir, "file2.txt"),
+				filepath.Join(tempDir, "file.bin"),
+			},
+		},
+		{
+			name:     "pattern with subdirectory",
+			patterns: []string{"subdir/*.txt"},
+			expectedFiles: []string{
+				filepath.Join(tempDir, "subdir/file3.txt"),
+			},
+		},
+		{
+			name:     "pattern with exclusion",
+			patterns: []string{"dist/*"},
+			excludes: []string{"*.sha256"},
+			expectedFiles: []string{
+				filepath.Join(tempDir, "dist/release.tar.gz"),
+			},
+		},
+		{
+			name:     "exclude by filename",
+			patterns: []string{"*.txt"},
+			excludes: []string{"file1.txt"},
+			expectedFiles: []string{
+				filepath.Join(tempDir, "file2.txt"),
+			},
+		},
+		{
+			name:     "exclude with negation",
+			patterns: []string{"*.txt", "*.bin"},
+			excludes: []string{"*.txt", "!file2.txt"},
+			expectedFiles: []string{
+				filepath.Join(tempDir, "file2.txt"),
+				filepath.Join(tempDir, "file.bin"),
+			},
+		},
+		{
+			name:          "no matches",
+			patterns:      []string{"*.nonexistent"},
+			expectedFiles: []string{},
+		},
+		{
+			name:          "empty patterns",
+			patterns:      []string{},
+			expectedFiles: []string{},
+		},
+	}
+
+	finder := &DefaultFileFinder{}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := finder.FindFiles(tempDir, tt.patterns, tt.excludes)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			sort.Strings(files)
+			sort.Strings(tt.expectedFiles)
+
+			if len(files) != len(tt.expectedFiles) {
+				t.Errorf("expected %d files, got %d\nexpected: %v\ngot: %v",
+					len(tt.expectedFiles), len(files), tt.expectedFiles, files)
+				return
+			}
+
+			for i := range files {
+				if files[i] != tt.expectedFiles[i] {
+					t.Errorf("file %d: expected %q, got %q", i, tt.expectedFiles[i], files[i])
+				}
+			}
+		})
+	}
+}
+
+func TestShouldExclude(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		workDir  string
+		excludes []string
+		expected bool
+	}{
+		{
+			name:     "no excludes",
+			file:     "/work/file.txt",
+			workDir:  "/work",
+			excludes: nil,
+			expected: false,
+		},
+		{
+			name:     "match by extension",
+			file:     "/work/file.sha256",
+			workDir:  "/work",
+			excludes: []string{"*.sha256"},
+			expected: true,
+		},
+		{
+			name:     "no match different extension",
+			file:     "/work/file.txt",
+			workDir:  "/work",
+			excludes: []string{"*.sha256"},
+			expected: false,
+		},
+		{
+			name:     "match by exact name",
+			file:     "/work/secret.txt",
+			workDir:  "/work",
+			excludes: []string{"secret.txt"},
+			expected: true,
+		},
+		{
+			name:     "multiple excludes first matches",
+			file:     "/work/file.bak",
+			workDir:  "/work",
+			excludes: []string{"*.bak", "*.tmp"},
+			expected: true,
+		},
+		{
+			name:     "multiple excludes none match",
+			file:     "/work/file.txt",
+			workDir:  "/work",
+			excludes: []string{"*.bak", "*.tmp"},
+			expected: false,
+		},
+		{
+			name:     "negation re-includes file",
+			file:     "/work/README.txt",
+			workDir:  "/work",
+			excludes: []string{"*.txt", "!README.txt"},
+			expected: false,
+		},
+		{
+			name:     "negation leaves other files excluded",
+			file:     "/work/notes.txt",
+			workDir:  "/work",
+			excludes: []string{"*.txt", "!README.txt"},
+			expected: true,
+		},
+		{
+			name:     "negation before broad pattern is overridden",
+			file:     "/work/README.txt",
+			workDir:  "/work",
+			excludes: []string{"!README.txt", "*.txt"},
+			expected: true,
+		},
+		{
+			name:     "later exclude after negation",
+			file:     "/work/docs/README.txt",
+			workDir:  "/work",
+			excludes: []string{"*.txt", "!README.txt", "docs/*"},
+			expected: true,
+		},
+		{
+			name:     "negation without earlier match",
+			file:     "/work/file.bin",
+			workDir:  "/work",
+			excludes: []string{"!*.bin"},
+			expected: false,
+		},
+		{
+			name:     "escaped exclamation mark",
+			file:     "/work/!important.txt",
+			workDir:  "/work",
+			excludes: []string{`\!important.txt`},
+			expected: true,
+		},
+		{
+			name:     "directory glob excludes nested files",
+			file:     "/work/vendor/sub/deep/file.go",
+			workDir:  "/work",
+			excludes: []string{"vendor/*"},
+			expected: true,
+		},
+		{
+			name:     "trailing slash excludes directory",
+			file:     "/work/vendor/sub/deep/file.go",
+			workDir:  "/work",
+			excludes: []string{"vendor/"},
+			expected: true,
+		},
+		{
+			name:     "trailing slash excludes direct children",
+			file:     "/work/vendor/file.go",
+			workDir:  "/work",
+			excludes: []string{"vendor/"},
+			expected: true,
+		},
+		{
+			name:     "nested directory pattern",
+			file:     "/work/build/cache/objects/a/b/c.o",
+			workDir:  "/work",
+			excludes: []string{"build/cache"},
+			expected: true,
+		},
+		{
+			name:     "partial directory prefix not excluded",
+			file:     "/work/vendored/sub/file.go",
+			workDir:  "/work",
+			excludes: []string{"vendor/", "vendor/*"},
+			expected: false,
+		},
+		{
+			name:     "negation inside excluded directory",
+			file:     "/work/vendor/sub/keep.go",
+			workDir:  "/work",
+			excludes: []string{"vendor/", "!vendor/sub/keep.go"},
+			expected: false,
+		},
+		{
+			name:     "globstar directory excludes nested subtree",
+			file:     "/work/pkg/api/testdata/fixtures/a.bin",
+			workDir:  "/work",
+			excludes: []string{"**/testdata/**"},
+			expected: true,
+		},
+		{
+			name:     "globstar directory requires whole directory name",
+			file:     "/work/pkg/testdata2/a.bin",
+			workDir:  "/work",
+			excludes: []string{"**/testdata/**"},
+			expected: false,
+		},
+		{
+			name:     "globstar between directories",
+			file:     "/work/build/x/y/cache/a.o",
+			workDir:  "/work",
+			excludes: []string{"build/**/cache/*.o"},
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := shouldExclude(tt.file, tt.workDir, tt.excludes)
+			if result != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestFindFiles_ExcludesNestedDirectories(t *testing.T) {
+	tempDir := t.TempDir()
+
+	for _, f := range []string{"main.go", "vendor/a.go", "vendor/x/y/z/b.go", "vendored/c.go", "pkg/vendor/d.go"} {
+		path := filepath.Join(tempDir, filepath.FromSlash(f))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("test"), 0o644); err != nil {
+			t.Fatalf("failed to create file: %v", err)
+		}
+	}
+
+	for _, exclude := range []string{"vendor/*", "vendor/", "vendor"} {
+		t.Run(exclude, func(t *testing.T) {
+			finder := &DefaultFileFinder{}
+			files, err := finder.FindFiles(tempDir, []string{"**/*.go"}, []string{exclude})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var got []string
+			for _, file := range files {
+				rel, _ := filepath.Rel(tempDir, file)
+				got = append(got, filepath.ToSlash(rel))
+			}
+			slices.Sort(got)
+			expected := []string{"main.go", "pkg/vendor/d.go", "vendored/c.go"}
+			if !slices.Equal(got, expected) {
+				t.Errorf("expected %v, got %v", expected, got)
+			}
+		})
+	}
+}
+
+func TestFindFiles_ExcludesGlobstarDirectories(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestFiles(t, tempDir,
+		"main.go", "testdata/a.go", "pkg/testdata/b.go", "pkg/api/v1/testdata/fixtures/c.go",
+		"pkg/testdata.go", "pkg/mytestdata/d.go",
+	)
+
+	finder := &DefaultFileFinder{}
+	files, err := finder.FindFiles(tempDir, []string{"**/*.go"}, []string{"**/testdata/**"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got []string
+	for _, file := range files {
+		rel, _ := filepath.Rel(tempDir, file)
+		got = append(got, filepath.ToSlash(rel))
+	}
+	slices.Sort(got)
+	expected := []string{"main.go", "pkg/mytestdata/d.go", "pkg/testdata.go"}
+	if !slices.Equal(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestFindFiles_DirectoriesExcluded(t *testing.T) {
+	tempDir := t.TempDir()
+
+	// Create a directory that matches the pattern
+	if err := os.Mkdir(filepath.Join(tempDir, "matches.txt"), 0o755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+
+	// Create a file that matches the pattern
+	filePath := filepath.Join(tempDir, "file.txt")
+	if err := os.WriteFile(filePath, []byte("content"), 0o644); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+
+	finder := &DefaultFileFinder{}
+	files, err := finder.FindFiles(tempDir, []string{"*.txt"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(files) != 1 {
+		t.Errorf("expected 1 file, got %d: %v", len(files), files)
+	}
+}
+
+func TestFindFiles_NoDuplicates(t *testing.T) {
+	tempDir := t.TempDir()
+
+	filePath := filepath.Join(tempDir, "file.txt")
+	if err := os.WriteFile(filePath, []byte("content"), 0o644); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+
+	finder := &DefaultFileFinder{}
+	files, err := finder.FindFiles(tempDir, []string{"*.txt", "file.*", "file.txt"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(files) != 1 {
+		t.Errorf("expected 1 file (no duplicates), got %d: %v", len(files), files)
+	}
+}
+
+func TestFindFiles_NoDuplicatesAcrossGlobAndGlobstar(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestFiles(t, tempDir, "dist/app.txt")
+	t.Chdir(tempDir)
+
+	want := filepath.Join(tempDir, "dist", "app.txt")
+	for _, workDir := range []string{".", "./dist/..", tempDir, tempDir + "/"} {
+		finder := &DefaultFileFinder{}
+		files, err := finder.FindFiles(workDir, []string{"**/*.txt", "dist/*.txt", "./dist/app.txt", "dist/**"}, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(files) != 1 || files[0] != want {
+			t.Errorf("workdir %q: expected only %s, got %v", workDir, want, files)
+		}
+	}
+}
+
+func TestFindFiles_Globstar(t *testing.T) {
+	tempDir := t.TempDir()
+
+	testFiles := []string{
+		"root.txt",
+		"docs/readme.txt",
+		"docs/deep/notes.txt",
+		"dist/one.bin",
+		"dist/sub/two.bin",
+		"a/lib.jar",
+		"a/b/lib.jar",
+		"a/x/b/y/lib.jar",
+		"a/b/c/app.jar",
+		"other/b/lib.jar",
+	}
+	for _, f := range testFiles {
+		path := filepath.Join(tempDir, filepath.FromSlash(f))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("test"), 0o644); err != nil {
+			t.Fatalf("failed to create file: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		patterns []string
+		expected []string
+	}{
+		{
+			name:     "leading globstar",
+			patterns: []string{"**/*.txt"},
+			expected: []string{"root.txt", "docs/readme.txt", "docs/deep/notes.txt"},
+		},
+		{
+			name:     "single globstar with prefix",
+			patterns: []string{"docs/**/*.txt"},
+			expected: []string{"docs/readme.txt", "docs/deep/notes.txt"},
+		},
+		{
+			name:     "trailing globstar",
+			patterns: []string{"dist/**"},
+			expected: []string{"dist/one.bin", "dist/sub/two.bin"},
+		},
+		{
+			name:     "multiple globstars",
+			patterns: []string{"a/**/b/**/*.jar"},
+			expected: []string{"a/b/lib.jar", "a/x/b/y/lib.jar", "a/b/c/app.jar"},
+		},
+		{
+			name:     "globstar in the middle of a literal path",
+			patterns: []string{"**/b/lib.jar"},
+			expected: []string{"a/b/lib.jar", "other/b/lib.jar"},
+		},
+		{
+			name:     "overlapping patterns are deduplicated",
+			patterns: []string{"**/*.jar", "a/**/lib.jar"},
+			expected: []string{"a/lib.jar", "a/b/lib.jar", "a/x/b/y/lib.jar", "a/b/c/app.jar", "other/b/lib.jar"},
+		},
+		{
+			name:     "missing prefix directory",
+			patterns: []string{"missing/**/*.txt"},
+		},
+	}
+
+	finder := &DefaultFileFinder{}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := finder.FindFiles(tempDir, tt.patterns, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			expected := make([]string, len(tt.expected))
+			for i, f := range tt.expected {
+				expected[i] = filepath.Join(tempDir, filepath.FromSlash(f))
+			}
+			sort.Strings(files)
+			sort.Strings(expected)
+
+			if strings.Join(files, "\n") != strings.Join(expected, "\n") {
+				t.Errorf("expected:\n%v\ngot:\n%v", expected, files)
+			}
+		})
+	}
+}
+
+func TestMatchGlobstar(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		path     string
+		expected bool
+	}{
+		{"**", "a/b/c", true},
+		{"**/*.go", "main.go", true},
+		{"**/*.go", "cmd/app/main.go", true},
+		{"a/**/b", "a/b", true},
+		{"a/**/b", "a/x/y/b", true},
+		{"a/**/b", "a/x/y/c", false},
+		{"a/**/b/**/*.jar", "a/b/lib.jar", true},
+		{"a/**/b/**/*.jar", "a/x/b/y/z/lib.jar", true},
+		{"a/**/b/**/*.jar", "a/x/c/lib.jar", false},
+		{"dist/**", "dist/x", true},
+		{"dist/**", "other/x", false},
+		{"**.txt", "notes.txt", true},
+		{"**.txt", "docs/notes.txt", false},
+		{"**/testdata/**", "testdata/a.bin", true},
+		{"**/testdata/**", "pkg/x/testdata/y/a.bin", true},
+		{"**/testdata/**", "pkg/testdata.bin", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.path, func(t *testing.T) {
+			got := matchGlobstar(splitGlobstarPattern(tt.pattern), strings.Split(tt.path, "/"))
+			if got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestFindFiles_Symlinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks require extra privileges on Windows")
+	}
+
+	tempDir := t.TempDir()
+	for _, f := range []string{"real/file.txt", "target/linked.txt"} {
+		path := filepath.Join(tempDir, filepath.FromSlash(f))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("test"), 0o644); err != nil {
+			t.Fatalf("failed to create file: %v", err)
+		}
+	}
+	links := map[string]string{
+		"real/file-link.txt": "file.txt",                    // symlink to a file
+		"real/dir-link":      filepath.Join("..", "target"), // symlink to a directory
+		"real/loop":          ".",                           // symlink back to its own directory
+		"real/self.txt":      "self.txt",                    // self-referential symlink
+		"real/broken.txt":    "missing.txt",                 // dangling symlink
+	}
+	for link, target := range links {
+		if err := os.Symlink(target, filepath.Join(tempDir, filepath.FromSlash(link))); err != nil {
+			t.Fatalf("failed to create symlink: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name           string
+		patterns       []string
+		followSymlinks bool
+		skipSymlinks   bool
+		expected       []string
+	}{
+		{
+			name:     "glob signs symlinked files and skips the rest",
+			patterns: []string{"real/*"},
+			expected: []string{"real/file.txt", "real/file-link.txt"},
+		},
+		{
+			name:     "globstar does not follow symlinked directories by default",
+			patterns: []string{"real/**/*.txt"},
+			expected: []string{"real/file.txt", "real/file-link.txt"},
+		},
+		{
+			name:           "globstar follows symlinked directories without looping",
+			patterns:       []string{"real/**/*.txt"},
+			followSymlinks: true,
+			expected:       []string{"real/file.txt", "real/file-link.txt", "real/dir-link/linked.txt"},
+		},
+		{
+			name:         "skip symlinks",
+			patterns:     []string{"real/*"},
+			skipSymlinks: true,
+			expected:     []string{"real/file.txt"},
+		},
+		{
+			name:           "skip symlinks keeps files below followed directories",
+			patterns:       []string{"real/**/*.txt"},
+			followSymlinks: true,
+			skipSymlinks:   true,
+			expected:       []string{"real/file.txt", "real/dir-link/linked.txt"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			finder := &DefaultFileFinder{FollowSymlinks: tt.followSymlinks, SkipSymlinks: tt.skipSymlinks}
+
+			done := make(chan struct{})
+			var files []string
+			var err error
+			go func() {
+				defer close(done)
+				files, err = finder.FindFiles(tempDir, tt.patterns, nil)
+			}()
+			select {
+			case <-done:
+			case <-time.After(10 * time.Second):
+				t.Fatal("FindFiles did not return; symlink loop not detected")
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			expected := make([]string, len(tt.expected))
+			for i, f := range tt.expected {
+				expected[i] = filepath.Join(tempDir, filepath.FromSlash(f))
+			}
+			sort.Strings(files)
+			sort.Strings(expected)
+
+			if strings.Join(files, "\n") != strings.Join(expected, "\n") {
+				t.Errorf("expected:\n%v\ngot:\n%v", expected, files)
+			}
+		})
+	}
+}
+
+func TestExpandBraces(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		expected []string
+	}{
+		{"dist/*.txt", []string{"dist/*.txt"}},
+		{"dist/*.{tar.gz,zip,deb}", []string{"dist/*.tar.gz", "dist/*.zip", "dist/*.deb"}},
+		{"{a,b}/{x,y}.txt", []string{"a/x.txt", "a/y.txt", "b/x.txt", "b/y.txt"}},
+		{"app.{tar.{gz,xz},zip}", []string{"app.tar.gz", "app.tar.xz", "app.zip"}},
+		{"file{,.bak}", []string{"file", "file.bak"}},
+		{`\{a,b\}.txt`, []string{`\{a,b\}.txt`}},
+		{`{a\,b,c}.txt`, []string{`a\,b.txt`, "c.txt"}},
+		{"{single}.txt", []string{"{single}.txt"}},
+		{"{a,b.txt", []string{"{a,b.txt"}},
+		{"{x}.{a,b}", []string{"{x}.a", "{x}.b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			got := expandBraces(tt.pattern)
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestFindFiles_BraceExpansion(t *testing.T) {
+	tempDir := t.TempDir()
+
+	for _, f := range []string{"app.tar.gz", "app.zip", "app.deb", "app.rpm", "docs/a.md", "{b}.txt"} {
+		path := filepath.Join(tempDir, filepath.FromSlash(f))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("test"), 0o644); err != nil {
+			t.Fatalf("failed to create file: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		patterns []string
+		excludes []string
+		expected []string
+	}{
+		{name: "simple", patterns: []string{"*.{tar.gz,zip,deb}"}, expected: []string{"app.deb", "app.tar.gz", "app.zip"}},
+		{name: "nested", patterns: []string{"app.{tar.{gz,xz},z{ip,st}}"}, expected: []string{"app.tar.gz", "app.zip"}},
+		{name: "globstar", patterns: []string{"**/*.{md,rpm}"}, expected: []string{"app.rpm", "docs/a.md"}},
+		{name: "overlapping alternatives", patterns: []string{"app.{zip,z*}", "*.zip"}, expected: []string{"app.zip"}},
+		{name: "escaped braces", patterns: []string{`\{b\}.txt`}, expected: []string{"{b}.txt"}},
+		{name: "excludes", patterns: []string{"app.*"}, excludes: []string{"*.{deb,rpm}"}, expected: []string{"app.tar.gz", "app.zip"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if runtime.GOOS == "windows" && strings.Contains(strings.Join(tt.patterns, ""), `\`) {
+				t.Skip("backslash escapes are path separators on windows")
+			}
+
+			finder := &DefaultFileFinder{}
+			files, err := finder.FindFiles(tempDir, tt.patterns, tt.excludes)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var got []string
+			for _, file := range files {
+				rel, _ := filepath.Rel(tempDir, file)
+				got = append(got, filepath.ToSlash(rel))
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestFoldCasePattern(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		expected string
+	}{
+		{"*.jpg", "*.[jJ][pP][gG]"},
+		{"IMG_?.JPG", "[Ii][Mm][Gg]_?.[Jj][Pp][Gg]"},
+		{"v1.0/*", "[vV]1.0/*"},
+		{"[a-c]x", "[a-cA-C][xX]"},
+		{"[^Xy]", "[^XyxY]"},
+		{"[0-9]", "[0-9]"},
+		{`\a\*`, `[aA]\*`},
+		{"é", "[éÉ]"},
+		{"[ab", "[ab"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			if got := foldCasePattern(tt.pattern); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRunCaseInsensitive(t *testing.T) {
+	workDir := t.TempDir()
+	for _, f := range []string{"photo.JPG", "image.jpg", "Docs/Notes.TXT", "skip.Jpg"} {
+		path := filepath.Join(workDir, filepath.FromSlash(f))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("test"), 0o644); err != nil {
+			t.Fatalf("failed to create file: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name            string
+		caseInsensitive bool
+		expected        []string
+	}{
+		{name: "case-sensitive by default", expected: []string{"image.jpg"}},
+		{name: "case-insensitive", caseInsensitive: true, expected: []string{"Docs/Notes.TXT", "image.jpg", "photo.JPG"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer := &MockSigner{}
+			args := Config{
+				PrivateKey:      "key",
+				Files:           "*.jpg\ndocs/**/*.txt",
+				Excludes:        "SKIP.*",
+				DetachSign:      true,
+				WorkDir:         workDir,
+				CaseInsensitive: tt.caseInsensitive,
+			}
+			if err := run(context.Background(), args, signer, nil, nil); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var got []string
+			for _, file := range signer.SignedFiles {
+				rel, _ := filepath.Rel(workDir, file)
+				got = append(got, filepath.ToSlash(rel))
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestFindFiles_ExcludeByAgeAndSize(t *testing.T) {
+	workDir := t.TempDir()
+	files := map[string]struct {
+		age  time.Duration
+		size int
+	}{
+		"fresh-small.bin": {age: time.Minute, size: 10},
+		"fresh-large.bin": {age: time.Minute, size: 2000},
+		"stale-small.bin": {age: 48 * time.Hour, size: 10},
+		"stale-large.bin": {age: 48 * time.Hour, size: 2000},
+		"fresh-small.txt": {age: time.Minute, size: 10},
+	}
+	for name, f := range files {
+		path := filepath.Join(workDir, name)
+		if err := os.WriteFile(path, make([]byte, f.size), 0o644); err != nil {
+			t.Fatalf("failed to create file: %v", err)
+		}
+		mtime := startTime.Add(-f.age)
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatalf("failed to set modification time: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		finder   DefaultFileFinder
+		excludes []string
+		expected []string
+	}{
+		{
+			name:     "no limits",
+			expected: []string{"fresh-large.bin", "fresh-small.bin", "stale-large.bin", "stale-small.bin"},
+		},
+		{
+			name:     "older than",
+			finder:   DefaultFileFinder{ExcludeOlderThan: 24 * time.Hour},
+			expected: []string{"fresh-large.bin", "fresh-small.bin"},
+		},
+		{
+			name:     "larger than",
+			finder:   DefaultFileFinder{ExcludeLargerThan: 1000},
+			expected: []string{"fresh-small.bin", "stale-small.bin"},
+		},
+		{
+			name:     "both limits",
+			finder:   DefaultFileFinder{ExcludeOlderThan: 24 * time.Hour, ExcludeLargerThan: 1000},
+			expected: []string{"fresh-small.bin"},
+		},
+		{
+			name:     "composes with glob excludes",
+			finder:   DefaultFileFinder{ExcludeOlderThan: 24 * time.Hour},
+			excludes: []string{"*-large.*"},
+			expected: []string{"fresh-small.bin"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, err := tt.finder.FindFiles(workDir, []string{"*.bin"}, tt.excludes)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var got []string
+			for _, file := range found {
+				got = append(got, filepath.Base(file))
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRunExcludeLargerThan(t *testing.T) {
+	workDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(workDir, "small.bin"), make([]byte, 10), 0o644); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(workDir, "large.bin"), make([]byte, 2048), 0o644); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+
+	signer := &MockSigner{}
+	args := Config{
+		PrivateKey:        "key",
+		Files:             "*.bin",
+		DetachSign:        true,
+		WorkDir:           workDir,
+		ExcludeLargerThan: "1KiB",
+	}
+	if err := run(context.Background(), args, signer, nil, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(signer.SignedFiles) != 1 || filepath.Base(signer.SignedFiles[0]) != "small.bin" {
+		t.Errorf("expected only small.bin to be signed, got %v", signer.SignedFiles)
+	}
+
+	args.ExcludeLargerThan = "1XB"
+	if err := run(context.Background(), args, &MockSigner{}, nil, nil); err == nil || !strings.Contains(err.Error(), "exclude-larger-than") {
+		t.Errorf("expected exclude-larger-than error, got %v", err)
+	}
+}
+
+func TestRunDereference(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks require extra privileges on Windows")
+	}
+
+	workDir := t.TempDir()
+	writeTestFiles(t, workDir, "target/app.bin")
+	if err := os.Mkdir(filepath.Join(workDir, "dist"), 0o755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	link := filepath.Join(workDir, "dist", "app.bin")
+	if err := os.Symlink(filepath.Join("..", "target", "app.bin"), link); err != nil {
+		t.Fatalf("failed to create symlink: %v", err)
+	}
+
+	args := Config{
+		PrivateKey:  generateTestKeyArmored(t, "Test", "test@test.com", ""),
+		Backend:     string(BackendGoPGP),
+		Files:       "dist/*",
+		DetachSign:  true,
+		WorkDir:     workDir,
+		Dereference: true,
+	}
+	if err := run(context.Background(), args, nil, nil, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(link + ".sig"); err != nil {
+		t.Errorf("expected signature next to the symlink: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(workDir, "target", "app.bin.sig")); err == nil {
+		t.Error("expected no signature next to the symlink target")
+	}
+
+	signer := &MockSigner{}
+	args.Dereference = false
+	if err := run(context.Background(), args, signer, nil, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(signer.SignedFiles) != 0 {
+		t.Errorf("expected symlinks to be skipped, got %v", signer.SignedFiles)
+	}
+}
+
+// writeBenchmarkTree creates n files spread over 100 directories below dir and returns
+// their paths.
+func writeBenchmarkTree(b *testing.B, dir string, n int) []string {
+	b.Helper()
+	names := make([]string, n)
+	for i := range names {
+		names[i] = fmt.Sprintf("dir%02d/file%05d.bin", i%100, i)
+	}
+	writeTestFiles(b, dir, names...)
+
+	files := make([]string, n)
+	for i, name := range names {
+		files[i] = filepath.Join(dir, filepath.FromSlash(name))
+	}
+	return files
+}
+
+func BenchmarkFindFiles_10kFiles(b *testing.B) {
+	dir := b.TempDir()
+	writeBenchmarkTree(b, dir, 10000)
+	// A few excludes of each kind, none of which matches, so every file is checked against all of them.
+	excludes := []string{"*.tmp", "**/*.log", "vendor/", "build/*", "!dir00/keep.bin", "*.{asc,sig}"}
+	finder := &DefaultFileFinder{}
+
+	for b.Loop() {
+		files, err := finder.FindFiles(dir, []string{"**/*.bin"}, excludes)
+		if err != nil {
+			b.Fatalf("unexpected error: %v", err)
+		}
+		if len(files) != 10000 {
+			b.Fatalf("expected 10000 files, got %d", len(files))
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package pgpsign
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// CommandFileFinder implements FileFinder by running a shell command that prints the files
+// to sign, for selection logic that globs cannot express, such as asking a build system
+// for its outputs. Patterns are ignored; excludes are applied to the printed files.
+type CommandFileFinder struct {
+	Command string          // Run with sh -c in the working directory; prints one path per line
+	Context context.Context // Cancels the command; nil means context.Background()
+	Log     *slog.Logger    // Receives the command's file count; nil discards it
+
+	// outputs holds the command's output per working directory, so that searching a
+	// directory again, as the fail-on-no-match check does without excludes, reuses it.
+	outputs map[string]string
+}
+
+// validateFilesCommand checks that files-command is not combined with inputs selecting files
+// in other ways. files-from may still add files to those printed by the command.
+func validateFilesCommand(args Config) error {
+	if args.FilesCommand == "" {
+		return nil
+	}
+	if args.Files != "" {
+		return fmt.Errorf("files-command replaces the files patterns and cannot be combined with files")
+	}
+	if args.TarMembers || args.ImageDigests != "" {
+		return fmt.Errorf("files-command selects files on disk and cannot be combined with tar-members or image-digests")
+	}
+	// These filters are applied while walking for the patterns, which the command replaces.
+	if args.ExcludeOlderThan != 0 || args.ExcludeLargerThan != "" || args.ArchiveDirs {
+		return fmt.Errorf("files-command cannot be combined with exclude-older-than, exclude-larger-than or archive-dirs")
+	}
+	return nil
+}
+
+// FindFiles runs the command in workDir and returns the clean absolute paths of the files it
+// prints, one per line, in order and without duplicates. Relative paths are resolved against
+// workDir and blank lines are ignored. Files matching excludes are left out. A printed path
+// that is not a regular file fails the search, as does a command exiting with an error.
+// The command runs once per working directory; later searches reuse its output.
+func (f *CommandFileFinder) FindFiles(workDir string, _, excludes []string) ([]string, error) {
+	if workDir == "" {
+		workDir = "."
+	}
+	absWorkDir, err := filepath.Abs(workDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve working directory: %w", err)
+	}
+
+	output, ok := f.outputs[absWorkDir]
+	if !ok {
+		if output, err = f.run(absWorkDir); err != nil {
+			return nil, err
+		}
+		if f.outputs == nil {
+			f.outputs = make(map[string]string)
+		}
+		f.outputs[absWorkDir] = output
+	}
+
+	var files []string
+	seen := make(map[string]bool)
+	for _, entry := range parseMultilineInput(output) {
+		file := filepath.Clean(resolvePath(absWorkDir, filepath.FromSlash(entry)))
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, fmt.Errorf("files-command printed %s: %w", entry, err)
+		}
+		if !info.Mode().IsRegular() {
+			return nil, fmt.Errorf("files-command printed %s, which is not a regular file", entry)
+		}
+		if seen[file] || shouldExclude(file, absWorkDir, excludes) {
+			continue
+		}
+		seen[file] = true
+		files = append(files, file)
+	}
+
+	f.logger().Debug("Files listed by files-command", slog.String("workdir", absWorkDir), slog.Int("count", len(files)))
+	return files, nil
+}
+
+// run runs the command in dir and returns its standard output. The last lines the command
+// wrote to stderr are included in the error when it fails.
+func (f *CommandFileFinder) run(dir string) (string, error) {
+	ctx := f.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", f.Command)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if output := strings.TrimSpace(stderr.String()); output != "" {
+			return "", fmt.Errorf("files-command %q failed: %w: %s", f.Command, err, lastLines(output, stderrErrorLines))
+		}
+		return "", fmt.Errorf("files-command %q failed: %w", f.Command, err)
+	}
+	return string(out), nil
+}
+
+// logger returns the configured logger, or one that discards everything.
+func (f *CommandFileFinder) logger() *slog.Logger {
+	if f.Log == nil {
+		return slog.New(slog.DiscardHandler)
+	}
+	return f.Log
+}
@@ -0,0 +1,171 @@
+package pgpsign
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writeFilesScript writes a shell script printing output to dir and returns the command
+// running it.
+func writeFilesScript(t *testing.T, dir, output string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("files-command script requires a POSIX shell")
+	}
+
+	script := filepath.Join(dir, "list-files.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\nprintf '"+output+"'\n"), 0o755); err != nil {
+		t.Fatalf("failed to write script: %v", err)
+	}
+	return "sh " + script
+}
+
+func TestCommandFileFinder(t *testing.T) {
+	workDir := t.TempDir()
+	writeTestFiles(t, workDir, "app.tar.gz", "dist/tool.bin", "dist/testdata/fixture.bin", "notes.txt")
+	command := writeFilesScript(t, t.TempDir(), `app.tar.gz\n\ndist/tool.bin\n./dist/testdata/fixture.bin\nnotes.txt\napp.tar.gz\n`)
+
+	tests := []struct {
+		name     string
+		excludes []string
+		expected []string
+	}{
+		{name: "all printed files", expected: []string{"app.tar.gz", "dist/tool.bin", "dist/testdata/fixture.bin", "notes.txt"}},
+		{name: "filtered by excludes", excludes: []string{"*.txt", "**/testdata/**"}, expected: []string{"app.tar.gz", "dist/tool.bin"}},
+		{name: "negated exclude", excludes: []string{"dist/", "!dist/tool.bin"}, expected: []string{"app.tar.gz", "dist/tool.bin", "notes.txt"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			finder := &CommandFileFinder{Command: command}
+			files, err := finder.FindFiles(workDir, nil, tt.excludes)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var got []string
+			for _, file := range files {
+				if !filepath.IsAbs(file) {
+					t.Errorf("expected an absolute path, got %s", file)
+				}
+				rel, _ := filepath.Rel(workDir, file)
+				got = append(got, filepath.ToSlash(rel))
+			}
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestCommandFileFinder_Errors(t *testing.T) {
+	workDir := t.TempDir()
+	writeTestFiles(t, workDir, "app.bin", "dist/tool.bin")
+	scripts := t.TempDir()
+
+	tests := []struct {
+		name        string
+		command     string
+		errContains string
+	}{
+		{name: "command fails", command: "echo 'build system unavailable' >&2; exit 3", errContains: "exit status 3: build system unavailable"},
+		{name: "missing file", command: writeFilesScript(t, scripts, `app.bin\nmissing.bin\n`), errContains: "printed missing.bin"},
+		{name: "directory", command: "echo dist", errContains: "not a regular file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			finder := &CommandFileFinder{Command: tt.command}
+			_, err := finder.FindFiles(workDir, nil, nil)
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
+
+func TestValidateFilesCommand(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        Config
+		errContains string
+	}{
+		{name: "unset", args: Config{Files: "*"}},
+		{name: "alone", args: Config{FilesCommand: "make list-artifacts"}},
+		{name: "with files-from", args: Config{FilesCommand: "make list-artifacts", FilesFrom: "extra.txt"}},
+		{name: "with files", args: Config{FilesCommand: "make list-artifacts", Files: "*"}, errContains: "cannot be combined with files"},
+		{name: "with tar-members", args: Config{FilesCommand: "make list-artifacts", TarMembers: true}, errContains: "tar-members"},
+		{name: "with archive-dirs", args: Config{FilesCommand: "make list-artifacts", ArchiveDirs: true}, errContains: "archive-dirs"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateFilesCommand(tt.args)
+			if tt.errContains == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
+
+func TestRunFilesCommand(t *testing.T) {
+	workDir := t.TempDir()
+	writeTestFiles(t, workDir, "app.tar.gz", "dist/tool.bin", "dist/tool.bin.sha256")
+	command := writeFilesScript(t, t.TempDir(), `app.tar.gz\ndist/tool.bin\ndist/tool.bin.sha256\n`)
+
+	signer := &MockSigner{}
+	args := Config{
+		PrivateKey:   "unused",
+		FilesCommand: command,
+		Excludes:     "*.sha256",
+		DetachSign:   true,
+		WorkDir:      workDir,
+	}
+	if err := run(context.Background(), args, signer, nil, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{filepath.Join(workDir, "app.tar.gz"), filepath.Join(workDir, "dist", "tool.bin")}
+	if !slices.Equal(signer.SignedFiles, expected) {
+		t.Errorf("expected %v to be signed, got %v", expected, signer.SignedFiles)
+	}
+}
+
+func TestRunFilesCommandRunsOnce(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("files-command requires a POSIX shell")
+	}
+	workDir := t.TempDir()
+	writeTestFiles(t, workDir, "notes.txt")
+	counter := filepath.Join(t.TempDir(), "runs")
+
+	args := Config{
+		PrivateKey:    "unused",
+		FilesCommand:  "echo run >> " + counter + "; echo notes.txt",
+		Excludes:      "*.txt",
+		FailOnNoMatch: true,
+		WorkDir:       workDir,
+	}
+	err := run(context.Background(), args, &MockSigner{}, nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "were excluded") {
+		t.Fatalf("expected an error about excluded files, got %v", err)
+	}
+	runs, err := os.ReadFile(counter)
+	if err != nil {
+		t.Fatalf("failed to read counter: %v", err)
+	}
+	if n := strings.Count(string(runs), "run"); n != 1 {
+		t.Errorf("expected files-command to run once, ran %d times", n)
+	}
+}
@@ -0,0 +1,120 @@
+package pgpsign
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// OversizePolicy selects what happens to files larger than max-file-size.
+type OversizePolicy string
+
+const (
+	OversizeError OversizePolicy = "error" // Fail before anything is signed
+	OversizeSkip  OversizePolicy = "skip"  // Leave the file unsigned and log a warning
+	OversizeWarn  OversizePolicy = "warn"  // Sign the file anyway and log a warning
+)
+
+// fileSizeUnits maps the accepted size suffixes to their multiplier.
+var fileSizeUnits = map[string]int64{
+	"":    1,
+	"b":   1,
+	"kb":  1000,
+	"mb":  1000 * 1000,
+	"gb":  1000 * 1000 * 1000,
+	"tb":  1000 * 1000 * 1000 * 1000,
+	"kib": 1 << 10,
+	"mib": 1 << 20,
+	"gib": 1 << 30,
+	"tib": 1 << 40,
+}
+
+// parseFileSize parses the size given to input, such as 500MB, 1.5GiB or 1048576. Decimal units
+// are powers of 1000 and binary units powers of 1024. An empty value or zero disables the limit.
+func parseFileSize(input, value string) (int64, error) {
+	trimmed := strings.TrimSpace(value)
+	if trimmed == "" {
+		return 0, nil
+	}
+
+	unitStart := strings.IndexFunc(trimmed, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if unitStart < 0 {
+		unitStart = len(trimmed)
+	}
+	number, unit := trimmed[:unitStart], strings.ToLower(strings.TrimSpace(trimmed[unitStart:]))
+
+	multiplier, ok := fileSizeUnits[unit]
+	amount, err := strconv.ParseFloat(number, 64)
+	if !ok || err != nil || amount*float64(multiplier) >= math.MaxInt64 {
+		return 0, fmt.Errorf("invalid %s %q: expected a size such as 500MB or 2GiB", input, value)
+	}
+	return int64(amount * float64(multiplier)), nil
+}
+
+// parseOversizePolicy parses the on-oversize input. An empty value selects OversizeError.
+func parseOversizePolicy(value string) (OversizePolicy, error) {
+	switch policy := OversizePolicy(strings.ToLower(strings.TrimSpace(value))); policy {
+	case "":
+		return OversizeError, nil
+	case OversizeError, OversizeSkip, OversizeWarn:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown on-oversize policy %q, expected %s, %s or %s", value, OversizeError, OversizeSkip, OversizeWarn)
+	}
+}
+
+// limitFileSizes applies the max-file-size and on-oversize inputs to files.
+func limitFileSizes(args Config, files []string, log *slog.Logger) ([]string, error) {
+	limit, err := parseFileSize("max-file-size", args.MaxFileSize)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := parseOversizePolicy(args.OnOversize)
+	if err != nil {
+		return nil, err
+	}
+	return applySizeLimit(files, limit, policy, log)
+}
+
+// applySizeLimit checks the size of every file against limit before anything is read and
+// applies policy to larger files. It returns the files that are still to be signed.
+// A limit of zero disables the check.
+func applySizeLimit(files []string, limit int64, policy OversizePolicy, log *slog.Logger) ([]string, error) {
+	if limit <= 0 {
+		return files, nil
+	}
+
+	kept := make([]string, 0, len(files))
+	var oversized []string
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check file size: %w", err)
+		}
+		if info.Size() <= limit {
+			kept = append(kept, file)
+			continue
+		}
+
+		attrs := []any{slog.String("file", file), slog.String("size", formatSize(info.Size())), slog.String("limit", formatSize(limit))}
+		switch policy {
+		case OversizeSkip:
+			log.Warn("Skipping file larger than max-file-size", attrs...)
+		case OversizeWarn:
+			log.Warn("Signing file larger than max-file-size", attrs...)
+			kept = append(kept, file)
+		default:
+			oversized = append(oversized, fmt.Sprintf("%s (%s)", file, formatSize(info.Size())))
+		}
+	}
+
+	if len(oversized) > 0 {
+		return nil, fmt.Errorf("files exceed max-file-size of %s: %s", formatSize(limit), strings.Join(oversized, ", "))
+	}
+	return kept, nil
+}
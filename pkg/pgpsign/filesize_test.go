@@ -0,0 +1,162 @@
+package pgpsign
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseFileSize(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected int64
+		wantErr  bool
+	}{
+		{value: "", expected: 0},
+		{value: "0", expected: 0},
+		{value: "1024", expected: 1024},
+		{value: "100B", expected: 100},
+		{value: "500MB", expected: 500_000_000},
+		{value: "500 mb", expected: 500_000_000},
+		{value: "2GiB", expected: 2 << 30},
+		{value: "1.5KiB", expected: 1536},
+		{value: "10XB", wantErr: true},
+		{value: "-1MB", wantErr: true},
+		{value: "MB", wantErr: true},
+		{value: "99999999TiB", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseFileSize("max-file-size", tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got %d", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %d, got %d", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseOversizePolicy(t *testing.T) {
+	if policy, err := parseOversizePolicy(""); err != nil || policy != OversizeError {
+		t.Errorf("expected error policy by default, got %q, %v", policy, err)
+	}
+	if policy, err := parseOversizePolicy("Skip"); err != nil || policy != OversizeSkip {
+		t.Errorf("expected skip policy, got %q, %v", policy, err)
+	}
+	if _, err := parseOversizePolicy("ignore"); err == nil {
+		t.Error("expected error for unknown policy")
+	}
+}
+
+func TestApplySizeLimit(t *testing.T) {
+	dir := t.TempDir()
+	under := filepath.Join(dir, "under.bin")
+	exact := filepath.Join(dir, "exact.bin")
+	over := filepath.Join(dir, "over.bin")
+	for file, size := range map[string]int{under: 99, exact: 100, over: 101} {
+		if err := os.WriteFile(file, make([]byte, size), 0o644); err != nil {
+			t.Fatalf("failed to create file: %v", err)
+		}
+	}
+	files := []string{under, exact, over}
+
+	tests := []struct {
+		policy   OversizePolicy
+		limit    int64
+		expected []string
+		wantErr  bool
+	}{
+		{policy: OversizeError, limit: 100, wantErr: true},
+		{policy: OversizeSkip, limit: 100, expected: []string{under, exact}},
+		{policy: OversizeWarn, limit: 100, expected: files},
+		{policy: OversizeError, limit: 101, expected: files},
+		{policy: OversizeError, limit: 0, expected: files},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			got, err := applySizeLimit(files, tt.limit, tt.policy, slog.New(slog.DiscardHandler))
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "over.bin") || strings.Contains(err.Error(), "exact.bin") {
+					t.Errorf("expected error naming only over.bin, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRunMaxFileSize(t *testing.T) {
+	workDir := t.TempDir()
+	for name, size := range map[string]int{"small.bin": 1000, "large.bin": 1001} {
+		if err := os.WriteFile(filepath.Join(workDir, name), make([]byte, size), 0o644); err != nil {
+			t.Fatalf("failed to create file: %v", err)
+		}
+	}
+
+	tests := []struct {
+		policy   string
+		expected []string
+		wantErr  bool
+	}{
+		{policy: "error", wantErr: true},
+		{policy: "skip", expected: []string{"small.bin"}},
+		{policy: "warn", expected: []string{"large.bin", "small.bin"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			signer := &MockSigner{}
+			args := Config{
+				PrivateKey:  "unused",
+				Files:       "*.bin",
+				DetachSign:  true,
+				WorkDir:     workDir,
+				MaxFileSize: "1KB",
+				OnOversize:  tt.policy,
+			}
+
+			err := run(context.Background(), args, signer, nil, slog.New(slog.DiscardHandler))
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "max-file-size") {
+					t.Errorf("expected max-file-size error, got %v", err)
+				}
+				if len(signer.SignedFiles) != 0 {
+					t.Errorf("expected nothing to be signed, got %v", signer.SignedFiles)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var signed []string
+			for _, file := range signer.SignedFiles {
+				signed = append(signed, filepath.Base(file))
+			}
+			slices.Sort(signed)
+			if !slices.Equal(signed, tt.expected) {
+				t.Errorf("expected %v to be signed, got %v", tt.expected, signed)
+			}
+		})
+	}
+}
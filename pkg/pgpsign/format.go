@@ -0,0 +1,91 @@
+package pgpsign
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SignatureFormat selects the kind of signature written for every file.
+type SignatureFormat string
+
+const (
+	FormatPGP      SignatureFormat = "pgp"
+	FormatMinisign SignatureFormat = "minisign"
+	FormatSSH      SignatureFormat = "ssh"
+)
+
+// formatBackends maps the formats that are not PGP to the only backend writing them.
+var formatBackends = map[SignatureFormat]SignerBackend{
+	FormatMinisign: BackendMinisign,
+	FormatSSH:      BackendSSH,
+}
+
+// parseSignatureFormat parses the format input. An empty value selects PGP signatures.
+func parseSignatureFormat(value string) (SignatureFormat, error) {
+	switch format := SignatureFormat(strings.ToLower(strings.TrimSpace(value))); format {
+	case "", FormatPGP:
+		return FormatPGP, nil
+	case FormatMinisign, FormatSSH:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unknown signature format %q, expected %s, %s or %s", value, FormatPGP, FormatMinisign, FormatSSH)
+	}
+}
+
+// resolveSignatureFormat returns the signature format selected by the format and backend inputs.
+// The minisign and ssh backends imply their format, and choosing either format selects its backend.
+// Inputs that only apply to PGP signatures are rejected for the other formats.
+func resolveSignatureFormat(args Config) (SignatureFormat, error) {
+	format, err := parseSignatureFormat(args.Format)
+	if err != nil {
+		return "", err
+	}
+
+	backend := SignerBackend(args.Backend)
+	if format == FormatPGP {
+		for f, b := range formatBackends {
+			if b == backend {
+				format = f
+			}
+		}
+		if format == FormatPGP {
+			return format, nil
+		}
+	}
+
+	switch backend {
+	case formatBackends[format], BackendGoPGP, "":
+	default:
+		return "", fmt.Errorf("format %s is not supported by the %s backend", format, backend)
+	}
+
+	unsupported := []struct {
+		name string
+		set  bool
+	}{
+		{"clear-sign", args.ClearSign},
+		{"sign-modes", args.SignModes != ""},
+		{"notation", args.Notation != ""},
+		{"embed-filename", args.EmbedFilename},
+		{"signature-type", args.SignatureType != "" && args.SignatureType != string(SignatureTypeBinary)},
+		{"digest-algo", args.DigestAlgo != ""},
+		{"gnupg-compat", args.GnuPGCompat},
+		{"bundle-format", args.BundleFormat != ""},
+		{"key-id", args.KeyID != ""},
+		{"skip-import", args.SkipImport},
+		{"key-dir", args.KeyDir != ""},
+		{"armor-comment", args.ArmorComment != ""},
+		{"signer-comment", args.SignerComment != ""},
+		{"strip-armor-version", args.StripArmorVersion},
+		{"minimal-armor", args.MinimalArmor},
+		{"sidecar-header", args.SidecarHeader},
+		{"verify-keyring", args.VerifyKeyring != ""},
+	}
+	for _, input := range unsupported {
+		if input.set {
+			return "", fmt.Errorf("%s cannot be combined with format %s", input.name, format)
+		}
+	}
+
+	return format, nil
+}
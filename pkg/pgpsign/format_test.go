@@ -0,0 +1,45 @@
+package pgpsign
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResolveSignatureFormat(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        Config
+		want        SignatureFormat
+		errContains string
+	}{
+		{name: "default", args: Config{Backend: "gnupg"}, want: FormatPGP},
+		{name: "pgp", args: Config{Format: "PGP", ClearSign: true}, want: FormatPGP},
+		{name: "minisign", args: Config{Format: "minisign", Backend: "gopgp", DetachSign: true}, want: FormatMinisign},
+		{name: "minisign backend", args: Config{Backend: "minisign"}, want: FormatMinisign},
+		{name: "ssh", args: Config{Format: "ssh"}, want: FormatSSH},
+		{name: "ssh backend", args: Config{Backend: "ssh", Format: "pgp"}, want: FormatSSH},
+		{name: "unknown", args: Config{Format: "x509"}, errContains: "unknown signature format"},
+		{name: "minisign with gnupg", args: Config{Format: "minisign", Backend: "gnupg"}, errContains: "not supported by the gnupg backend"},
+		{name: "ssh with minisign backend", args: Config{Format: "ssh", Backend: "minisign"}, errContains: "not supported by the minisign backend"},
+		{name: "clear-sign", args: Config{Format: "minisign", ClearSign: true}, errContains: "clear-sign cannot be combined"},
+		{name: "digest-algo", args: Config{Backend: "ssh", DigestAlgo: "sha512"}, errContains: "digest-algo cannot be combined with format ssh"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveSignatureFormat(tt.args)
+			if tt.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Errorf("expected error containing %q, got %v", tt.errContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected format %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package pgpsign
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+)
+
+// prepareGnuPGHome creates a temporary gpg home directory for the run and stores it in
+// args.GnuPGHome, so the imported key never touches the runner's own keyring and does not
+// leak into later runs. It does nothing unless the gnupg backend imports the key and no
+// gnupg-home is set. The directory is created below temp-dir if it is set. The returned
+// function removes the directory again.
+func prepareGnuPGHome(args *Config, log *slog.Logger) (func(), error) {
+	if SignerBackend(args.Backend) != BackendGnuPG || args.SkipImport || args.GnuPGHome != "" {
+		return func() {}, nil
+	}
+
+	home, err := os.MkdirTemp(args.TempDir, "pgp-sign-gnupg-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gpg home directory: %w", err)
+	}
+	args.GnuPGHome = home
+	log.Debug("Using temporary gpg home directory", slog.String("gnupg_home", home))
+
+	return func() { removeGnuPGHome(home, log) }, nil
+}
+
+// removeGnuPGHome stops the gpg-agent gpg started for home and removes the directory.
+func removeGnuPGHome(home string, log *slog.Logger) {
+	// The agent keeps running, and may recreate its sockets, until it is told to stop.
+	// gpgconf may be missing when gpg is, so failing to stop the agent is not an error.
+	if err := exec.Command("gpgconf", "--homedir", home, "--kill", "all").Run(); err != nil {
+		log.Debug("Failed to stop gpg-agent", slog.String("gnupg_home", home), slog.String("error", err.Error()))
+	}
+	if err := os.RemoveAll(home); err != nil {
+		log.Warn("Failed to remove temporary gpg home directory", slog.String("gnupg_home", home), slog.String("error", err.Error()))
+	}
+}
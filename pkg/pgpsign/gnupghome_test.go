@@ -0,0 +1,125 @@
+package pgpsign
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGnuPGSigner_Home(t *testing.T) {
+	homesFile := filepath.Join(t.TempDir(), "homes")
+	installFakeGPG(t, `echo "$GNUPGHOME" >> '`+homesFile+`'; cat > /dev/null
+while [ $# -gt 0 ]; do [ "$1" = --output ] && : > "$2"; shift; done`)
+	t.Setenv("GNUPGHOME", "/root/.gnupg")
+
+	home := t.TempDir()
+	gpg := GnuPGOptions{Home: home}
+	if err := importGPGKey("key", gpg); err != nil {
+		t.Fatalf("unexpected import error: %v", err)
+	}
+	testFile := filepath.Join(t.TempDir(), "test.txt")
+	if err := os.WriteFile(testFile, []byte("test content"), 0o644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+	if _, err := (&GnuPGSigner{gpg: gpg}).SignFile(testFile, SignOptions{DetachSign: true}); err != nil {
+		t.Fatalf("unexpected sign error: %v", err)
+	}
+
+	homes, err := os.ReadFile(homesFile)
+	if err != nil {
+		t.Fatalf("failed to read recorded homes: %v", err)
+	}
+	if expected := home + "\n" + home + "\n"; string(homes) != expected {
+		t.Errorf("expected both invocations to use %s, got:\n%s", home, homes)
+	}
+}
+
+func TestPrepareGnuPGHome(t *testing.T) {
+	log := slog.New(slog.DiscardHandler)
+
+	for _, args := range []Config{
+		{Backend: string(BackendGoPGP)},
+		{Backend: string(BackendGnuPG), SkipImport: true},
+		{Backend: string(BackendGnuPG), GnuPGHome: "/custom/home"},
+	} {
+		want := args.GnuPGHome
+		cleanup, err := prepareGnuPGHome(&args, log)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		cleanup()
+		if args.GnuPGHome != want {
+			t.Errorf("expected gnupg-home %q to be kept for %+v, got %q", want, args, args.GnuPGHome)
+		}
+	}
+
+	args := Config{Backend: string(BackendGnuPG)}
+	cleanup, err := prepareGnuPGHome(&args, log)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	info, err := os.Stat(args.GnuPGHome)
+	if err != nil || !info.IsDir() {
+		t.Fatalf("expected a temporary gpg home directory, got %q: %v", args.GnuPGHome, err)
+	}
+	if info.Mode().Perm() != 0o700 {
+		t.Errorf("expected gpg home to be private, got mode %v", info.Mode().Perm())
+	}
+	cleanup()
+	if _, err := os.Stat(args.GnuPGHome); !os.IsNotExist(err) {
+		t.Errorf("expected temporary gpg home to be removed, got %v", err)
+	}
+}
+
+func TestRunGnuPGIsolatedHome(t *testing.T) {
+	homesFile := filepath.Join(t.TempDir(), "homes")
+	installFakeGPG(t, `echo "$GNUPGHOME" >> '`+homesFile+`'; cat > /dev/null
+case "$*" in *--list-secret-keys*) echo "sec:u:255:22:0123456789ABCDEF:1700000000::::::scESC:"; exit 0;; esac
+while [ $# -gt 0 ]; do [ "$1" = --output ] && : > "$2"; shift; done`)
+	t.Setenv("GNUPGHOME", "/root/.gnupg")
+
+	workDir := t.TempDir()
+	writeTestFiles(t, workDir, "app.bin")
+	args := Config{
+		PrivateKey: generateTestKeyArmored(t, "Test", "test@test.com", ""),
+		Backend:    string(BackendGnuPG),
+		Files:      "*.bin",
+		DetachSign: true,
+		WorkDir:    workDir,
+	}
+
+	var homes []string
+	for range 2 {
+		if err := os.Remove(homesFile); err != nil && !os.IsNotExist(err) {
+			t.Fatalf("failed to reset recorded homes: %v", err)
+		}
+		if err := run(context.Background(), args, nil, nil, nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		recorded, err := os.ReadFile(homesFile)
+		if err != nil {
+			t.Fatalf("failed to read recorded homes: %v", err)
+		}
+		lines := strings.Fields(string(recorded))
+		if len(lines) < 2 {
+			t.Fatalf("expected import and sign invocations, got %v", lines)
+		}
+		for _, home := range lines {
+			if home != lines[0] || home == "/root/.gnupg" {
+				t.Fatalf("expected every invocation to use one temporary home, got %v", lines)
+			}
+		}
+		if _, err := os.Stat(lines[0]); !os.IsNotExist(err) {
+			t.Errorf("expected temporary home %s to be removed after the run", lines[0])
+		}
+		homes = append(homes, lines[0])
+	}
+
+	if homes[0] == homes[1] {
+		t.Errorf("expected each run to use its own home, both used %s", homes[0])
+	}
+}
@@ -0,0 +1,149 @@
+package pgpsign
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// imageDigestSizes maps the digest algorithms accepted in image references to their hex length.
+var imageDigestSizes = map[string]int{
+	"sha256": 64,
+	"sha512": 128,
+}
+
+// ImageDigest is a container image reference pinned to a manifest digest.
+type ImageDigest struct {
+	Reference string // Full reference as given, e.g. ghcr.io/org/app@sha256:...
+	Digest    string // Manifest digest, e.g. sha256:...
+}
+
+// fileName returns the name signature files for the digest are based on, such as
+// sha256-<hex>, which is safe on every file system.
+func (d ImageDigest) fileName() string {
+	return strings.Replace(d.Digest, ":", "-", 1)
+}
+
+// parseImageDigests parses newline separated image@algorithm:hex references. References
+// to a digest that was already listed are skipped, since they share one signature.
+func parseImageDigests(input string) ([]ImageDigest, error) {
+	var digests []ImageDigest
+	seen := make(map[string]bool)
+
+	for _, reference := range parseMultilineInput(input) {
+		name, digest, ok := cutLast(reference, "@")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("image reference %q is not pinned to a digest, expected image@sha256:<hex>", reference)
+		}
+		algorithm, encoded, _ := strings.Cut(digest, ":")
+		size, known := imageDigestSizes[algorithm]
+		if !known {
+			return nil, fmt.Errorf("image reference %q has unsupported digest algorithm %q, expected sha256 or sha512", reference, algorithm)
+		}
+		if _, err := hex.DecodeString(encoded); err != nil || len(encoded) != size || strings.ToLower(encoded) != encoded {
+			return nil, fmt.Errorf("image reference %q has an invalid %s digest, expected %d lowercase hex characters", reference, algorithm, size)
+		}
+
+		if !seen[digest] {
+			seen[digest] = true
+			digests = append(digests, ImageDigest{Reference: reference, Digest: digest})
+		}
+	}
+
+	return digests, nil
+}
+
+// cutLast slices s around the last instance of sep.
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
+
+// validateImageDigestMode rejects inputs that cannot be combined with signing image digests.
+func validateImageDigestMode(args Config) error {
+	if args.ImageDigests == "" {
+		return nil
+	}
+	if args.Files != "" || args.FilesFrom != "" || args.TarMembers {
+		return fmt.Errorf("image-digests cannot be combined with files, files-from or tar-members")
+	}
+	if args.ClearSign || args.SignModes != "" {
+		return fmt.Errorf("image-digests only creates detached signatures and cannot be combined with clear-sign or sign-modes")
+	}
+	if args.SignAndVerify || args.VerifyAfterSign || args.VerifyKeyring != "" || args.AssertReproducible || BundleFormat(args.BundleFormat) != BundleNone {
+		return fmt.Errorf("image-digests does not support sign-and-verify, verify-after-sign, verify-keyring, assert-reproducible or bundle-format")
+	}
+	_, err := parseImageDigests(args.ImageDigests)
+	return err
+}
+
+// runImageDigests signs the digests of the configured image references. Each detached
+// signature covers the digest string, such as sha256:<hex>, and is written to the output
+// directory, or the working directory, as sha256-<hex> with the signature extension.
+// Once ctx is done no further digests are signed.
+func runImageDigests(ctx context.Context, args Config, keys []signingKey, workDir string, log *slog.Logger) error {
+	digests, err := parseImageDigests(args.ImageDigests)
+	if err != nil {
+		return err
+	}
+
+	outputDir := workDir
+	if args.OutputDir != "" {
+		outputDir = resolvePath(workDir, args.OutputDir)
+	}
+	if !args.DryRun {
+		if err := os.MkdirAll(outputDir, 0o755); err != nil {
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
+	}
+
+	var signatures []string
+	for i, digest := range digests {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("signing cancelled with %d of %d image digests signed: %w", i, len(digests), err)
+		}
+		for _, key := range keys {
+			opts := key.opts
+			opts.OutputDir = ""
+
+			sigPath := getOutputPath(filepath.Join(outputDir, digest.fileName()), opts)
+			log.Info("Signing image digest", slog.String("image", digest.Reference), slog.String("signature", sigPath))
+			if args.DryRun {
+				continue
+			}
+			if err := writeImageDigestSignature(key.signer, digest, sigPath, opts); err != nil {
+				return fmt.Errorf("failed to sign %s: %w", digest.Reference, err)
+			}
+			signatures = append(signatures, sigPath)
+		}
+	}
+
+	if args.DryRun {
+		return nil
+	}
+	args.setSignatureOutputs(signatures)
+	log.Info("Successfully signed all image digests", slog.Int("count", len(digests)))
+	return nil
+}
+
+// writeImageDigestSignature signs the digest string of digest and writes the signature to sigPath.
+func writeImageDigestSignature(signer Signer, digest ImageDigest, sigPath string, opts SignOptions) error {
+	if err := checkOverwrite(sigPath, opts); err != nil {
+		return err
+	}
+	signature, err := signer.SignBytes([]byte(digest.Digest), opts)
+	if err != nil {
+		return err
+	}
+
+	if err := writeOutputFile(sigPath, signature, opts.outputMode()); err != nil {
+		return fmt.Errorf("failed to write signature: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package pgpsign
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	testImageDigest = "sha256:4f0b9cde0c1c3b0cd1e1d0eac4b1a3a2d3f2c1b0a9f8e7d6c5b4a3928170605f"
+	testImageRef    = "ghcr.io/cbrgm/app@" + testImageDigest
+)
+
+func TestParseImageDigests(t *testing.T) {
+	sha512Digest := "sha512:" + strings.Repeat("ab", 64)
+
+	tests := []struct {
+		name        string
+		input       string
+		want        []ImageDigest
+		errContains string
+	}{
+		{
+			name:  "single reference",
+			input: testImageRef,
+			want:  []ImageDigest{{Reference: testImageRef, Digest: testImageDigest}},
+		},
+		{
+			name:  "tag, registry port and duplicate digest",
+			input: "localhost:5000/app:v1@" + testImageDigest + "\n\n" + testImageRef + "\napp@" + sha512Digest,
+			want: []ImageDigest{
+				{Reference: "localhost:5000/app:v1@" + testImageDigest, Digest: testImageDigest},
+				{Reference: "app@" + sha512Digest, Digest: sha512Digest},
+			},
+		},
+		{name: "tag only", input: "ghcr.io/cbrgm/app:v1", errContains: "not pinned to a digest"},
+		{name: "missing image", input: "@" + testImageDigest, errContains: "not pinned to a digest"},
+		{name: "unknown algorithm", input: "app@md5:d41d8cd98f00b204e9800998ecf8427e", errContains: `unsupported digest algorithm "md5"`},
+		{name: "short digest", input: "app@sha256:4f0b9cde", errContains: "expected 64 lowercase hex characters"},
+		{name: "upper case digest", input: "app@" + strings.ToUpper(testImageDigest[:7]) + testImageDigest[7:], errContains: "unsupported digest algorithm"},
+		{name: "upper case hex", input: "app@sha256:" + strings.ToUpper(testImageDigest[7:]), errContains: "invalid sha256 digest"},
+		{name: "not hex", input: "app@sha256:" + strings.Repeat("z", 64), errContains: "invalid sha256 digest"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseImageDigests(tt.input)
+			if tt.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Fatalf("expected error containing %q, got %v", tt.errContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("digest %d: expected %+v, got %+v", i, tt.want[i], got[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRunImageDigests(t *testing.T) {
+	privateKey := generateTestKeyArmored(t, "Release", "release@test.com", "")
+	signer, err := NewGoPGPSigner(privateKey, "", "")
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+
+	workDir := t.TempDir()
+	args := Config{
+		PrivateKey:   privateKey,
+		Backend:      string(BackendGoPGP),
+		ImageDigests: testImageRef,
+		Armor:        true,
+		WorkDir:      workDir,
+		OutputDir:    "signatures",
+	}
+	if err := run(context.Background(), args, nil, nil, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sigPath := filepath.Join(workDir, "signatures", "sha256-"+testImageDigest[len("sha256:"):]+".asc")
+	signature, err := os.ReadFile(sigPath)
+	if err != nil {
+		t.Fatalf("expected signature named after the digest: %v", err)
+	}
+	if !strings.HasPrefix(string(signature), "-----BEGIN PGP SIGNATURE-----") {
+		t.Errorf("expected a detached armored signature, got:\n%s", signature)
+	}
+
+	// The signature covers the digest string itself.
+	digestFile := filepath.Join(t.TempDir(), "digest")
+	if err := os.WriteFile(digestFile, []byte(testImageDigest), 0o644); err != nil {
+		t.Fatalf("failed to write digest: %v", err)
+	}
+	opts := SignOptions{Armor: true, DetachSign: true}
+	if err := signer.Verify(digestFile, sigPath, opts); err != nil {
+		t.Errorf("signature does not verify over the digest: %v", err)
+	}
+	if err := os.WriteFile(digestFile, []byte(testImageRef), 0o644); err != nil {
+		t.Fatalf("failed to write reference: %v", err)
+	}
+	if err := signer.Verify(digestFile, sigPath, opts); err == nil {
+		t.Error("expected the signature not to verify over the full reference")
+	}
+}
+
+func TestRunImageDigestsDryRun(t *testing.T) {
+	workDir := t.TempDir()
+	signer := &MockSigner{}
+	args := Config{
+		PrivateKey:   "unused",
+		ImageDigests: testImageRef,
+		WorkDir:      workDir,
+		DryRun:       true,
+	}
+	if err := run(context.Background(), args, signer, nil, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	entries, err := os.ReadDir(workDir)
+	if err != nil {
+		t.Fatalf("failed to read workdir: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected no signatures in a dry run, got %v", entries)
+	}
+}
+
+func TestValidateImageDigestMode(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        Config
+		errContains string
+	}{
+		{name: "unset", args: Config{Files: "*"}},
+		{name: "valid", args: Config{ImageDigests: testImageRef}},
+		{name: "with files", args: Config{ImageDigests: testImageRef, Files: "*"}, errContains: "cannot be combined with files"},
+		{name: "with clear-sign", args: Config{ImageDigests: testImageRef, ClearSign: true}, errContains: "only creates detached signatures"},
+		{name: "with sign-and-verify", args: Config{ImageDigests: testImageRef, SignAndVerify: true}, errContains: "does not support sign-and-verify"},
+		{name: "invalid reference", args: Config{ImageDigests: "app:latest"}, errContains: "not pinned to a digest"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateImageDigestMode(tt.args)
+			if tt.errContains == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
+
+func TestRunImageDigestsCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	workDir := t.TempDir()
+	signer := &MockSigner{}
+	args := Config{
+		PrivateKey:   "unused",
+		ImageDigests: testImageRef,
+		WorkDir:      workDir,
+	}
+	err := run(ctx, args, signer, nil, nil)
+	if !errors.Is(err, context.Canceled) || !strings.Contains(err.Error(), "0 of 1 image digests signed") {
+		t.Errorf("expected a cancellation error, got %v", err)
+	}
+	if len(signer.SignedFiles) != 0 {
+		t.Errorf("expected nothing to be signed, got %v", signer.SignedFiles)
+	}
+}
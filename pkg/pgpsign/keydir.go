@@ -0,0 +1,97 @@
+package pgpsign
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// keyFileExtension is the extension of the private key files loaded from key-dir.
+	keyFileExtension = ".asc"
+	// keyPassphraseExtension is the extension of the file next to a key file that holds
+	// the passphrase of that key only, as in release.asc and release.pass.
+	keyPassphraseExtension = ".pass"
+)
+
+// keyMaterial is an armored private key together with its passphrase and a name that
+// identifies it in errors.
+type keyMaterial struct {
+	name       string
+	armored    string
+	passphrase string
+}
+
+// validateKeyDir checks the inputs that cannot be combined with key-dir.
+func validateKeyDir(args Config) error {
+	if args.PrivateKey != "" || args.SkipImport {
+		return fmt.Errorf("key-dir cannot be combined with private-key or skip-import")
+	}
+	if args.KeyID != "" {
+		return fmt.Errorf("key-id cannot be combined with key-dir")
+	}
+	if backend := SignerBackend(args.Backend); backend != BackendGoPGP && backend != "" {
+		return fmt.Errorf("key-dir is only supported by the %s backend", BackendGoPGP)
+	}
+	return nil
+}
+
+// loadKeyDir reads every private key from the *.asc files in dir, in file name order.
+// A key uses the passphrase in the .pass file of the same name if there is one and
+// passphrase otherwise. Files that hold no private key are skipped with a warning.
+func loadKeyDir(dir, passphrase string, log *slog.Logger) ([]keyMaterial, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key-dir: %w", err)
+	}
+
+	var keys []keyMaterial
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != keyFileExtension {
+			continue
+		}
+
+		keyPath := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(keyPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read key file: %w", err)
+		}
+		if kind := classifyArmoredBlock(string(data)); kind != armoredPrivateKey {
+			log.Warn("Skipping key-dir file that is not a private key", slog.String("file", keyPath), slog.String("kind", kind))
+			continue
+		}
+
+		keyPassphrase, err := keyFilePassphrase(keyPath, passphrase)
+		if err != nil {
+			return nil, err
+		}
+		for _, armored := range splitArmoredKeys(string(data)) {
+			keys = append(keys, keyMaterial{name: entry.Name(), armored: armored, passphrase: keyPassphrase})
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("key-dir %s contains no private keys in %s files", dir, keyFileExtension)
+	}
+	log.Info("Loaded private keys from key-dir", slog.String("dir", dir), slog.Int("count", len(keys)))
+	return keys, nil
+}
+
+// keyFilePassphrase returns the passphrase in the .pass file next to keyPath, or shared
+// if there is none.
+func keyFilePassphrase(keyPath, shared string) (string, error) {
+	path := strings.TrimSuffix(keyPath, keyFileExtension) + keyPassphraseExtension
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return shared, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to open passphrase file: %w", err)
+	}
+	defer f.Close()
+	return readPassphrase(f, "passphrase file "+path)
+}
@@ -0,0 +1,124 @@
+package pgpsign
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v3/crypto"
+)
+
+func TestLoadKeyDir(t *testing.T) {
+	first := generateTestKeyArmored(t, "First", "first@test.com", "first-secret")
+	second := generateTestKeyArmored(t, "Second", "second@test.com", "shared-secret")
+	public, err := mustParseKey(t, second).GetArmoredPublicKey()
+	if err != nil {
+		t.Fatalf("failed to armor public key: %v", err)
+	}
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"first.asc":  first,
+		"first.pass": "first-secret\n",
+		"second.asc": second,
+		"public.asc": public,
+		"notes.txt":  "not a key",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	keys, err := loadKeyDir(dir, "shared-secret", slog.New(slog.DiscardHandler))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(keys) != 2 {
+		t.Fatalf("expected 2 keys, got %d", len(keys))
+	}
+	if keys[0].name != "first.asc" || keys[0].passphrase != "first-secret" {
+		t.Errorf("expected first.asc with its own passphrase, got %s with %q", keys[0].name, keys[0].passphrase)
+	}
+	if keys[1].name != "second.asc" || keys[1].passphrase != "shared-secret" {
+		t.Errorf("expected second.asc with the shared passphrase, got %s with %q", keys[1].name, keys[1].passphrase)
+	}
+
+	if _, err := loadKeyDir(t.TempDir(), "", slog.New(slog.DiscardHandler)); err == nil {
+		t.Error("expected error for a directory without keys")
+	}
+}
+
+func TestRunKeyDir(t *testing.T) {
+	first := generateTestKeyArmored(t, "First", "first@test.com", "first-secret")
+	second := generateTestKeyArmored(t, "Second", "second@test.com", "shared-secret")
+
+	keyDir := t.TempDir()
+	for name, content := range map[string]string{
+		"first.asc":  first,
+		"first.pass": "first-secret",
+		"second.asc": second,
+	} {
+		if err := os.WriteFile(filepath.Join(keyDir, name), []byte(content), 0o600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	workDir := t.TempDir()
+	testFile := filepath.Join(workDir, "release.txt")
+	content := []byte("release content")
+	if err := os.WriteFile(testFile, content, 0o644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	args := Config{
+		KeyDir:     keyDir,
+		Passphrase: "shared-secret",
+		Backend:    string(BackendGoPGP),
+		Files:      "*.txt",
+		Armor:      true,
+		DetachSign: true,
+		WorkDir:    workDir,
+	}
+	if err := run(context.Background(), args, nil, nil, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, armoredKey := range []string{first, second} {
+		key := mustParseKey(t, armoredKey)
+		sigPath := testFile + "." + shortKeyID(key) + ".asc"
+		sig, err := os.ReadFile(sigPath)
+		if err != nil {
+			t.Fatalf("expected signature %s: %v", sigPath, err)
+		}
+
+		publicKey, err := key.ToPublic()
+		if err != nil {
+			t.Fatalf("failed to get public key: %v", err)
+		}
+		verifyHandle, err := crypto.PGP().Verify().VerificationKey(publicKey).New()
+		if err != nil {
+			t.Fatalf("failed to create verification handle: %v", err)
+		}
+		result, err := verifyHandle.VerifyDetached(content, sig, crypto.Armor)
+		if err != nil {
+			t.Fatalf("failed to verify %s: %v", sigPath, err)
+		}
+		if err := result.SignatureError(); err != nil {
+			t.Errorf("signature %s is invalid: %v", sigPath, err)
+		}
+	}
+}
+
+// mustParseKey parses an armored key or fails the test.
+func mustParseKey(t *testing.T, armored string) *crypto.Key {
+	t.Helper()
+
+	key, err := crypto.NewKeyFromArmored(armored)
+	if err != nil {
+		t.Fatalf("failed to parse key: %v", err)
+	}
+	return key
+}
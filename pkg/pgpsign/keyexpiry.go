@@ -0,0 +1,159 @@
+package pgpsign
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	openpgp "github.com/ProtonMail/go-crypto/openpgp/v2"
+)
+
+// KeyInfo describes a signing key for diagnostics and expiry checks.
+type KeyInfo struct {
+	UserIDs []string
+	Expires time.Time // Zero if the key does not expire
+}
+
+// expired reports whether the key has expired at now.
+func (k KeyInfo) expired(now time.Time) bool {
+	return !k.Expires.IsZero() && !now.Before(k.Expires)
+}
+
+// expiresString formats the expiration for log output.
+func (k KeyInfo) expiresString() string {
+	if k.Expires.IsZero() {
+		return "never"
+	}
+	return k.Expires.UTC().Format(time.RFC3339)
+}
+
+// KeyInfoProvider is implemented by signers that can describe their signing key.
+type KeyInfoProvider interface {
+	KeyInfo() (KeyInfo, error)
+}
+
+// signingKeyInfo describes the key of signer. It reports false for signers that do not
+// implement KeyInfoProvider.
+func signingKeyInfo(signer Signer) (KeyInfo, bool, error) {
+	provider, ok := signer.(KeyInfoProvider)
+	if !ok {
+		return KeyInfo{}, false, nil
+	}
+
+	info, err := provider.KeyInfo()
+	if err != nil {
+		return KeyInfo{}, true, fmt.Errorf("failed to inspect signing key: %w", err)
+	}
+	return info, true, nil
+}
+
+// checkKeyExpiry fails if the key of signer has expired at now and warns if it expires within window.
+// Signers that do not implement KeyInfoProvider are not checked. A zero window disables the warning.
+func checkKeyExpiry(signer Signer, window time.Duration, now time.Time, log *slog.Logger) error {
+	info, ok, err := signingKeyInfo(signer)
+	if !ok || err != nil {
+		return err
+	}
+
+	log.Debug("Signing key",
+		slog.Any("user_ids", info.UserIDs),
+		slog.String("expires", info.expiresString()),
+	)
+
+	if info.expired(now) {
+		return fmt.Errorf("signing key expired on %s", info.expiresString())
+	}
+	if window > 0 && !info.Expires.IsZero() && info.Expires.Sub(now) <= window {
+		log.Warn("Signing key expires soon; signatures made with it cannot be verified after that",
+			slog.String("expires", info.expiresString()),
+			slog.Int("days_left", int(info.Expires.Sub(now).Hours()/24)),
+		)
+	}
+
+	return nil
+}
+
+// entityKeyInfo returns the user IDs and the primary key expiration of entity.
+func entityKeyInfo(entity *openpgp.Entity) KeyInfo {
+	var info KeyInfo
+	for name := range entity.Identities {
+		info.UserIDs = append(info.UserIDs, name)
+	}
+	slices.Sort(info.UserIDs)
+
+	// A zero date selects the latest self-signature, even if the key has expired since.
+	sig, err := entity.PrimarySelfSignature(time.Time{}, &packet.Config{})
+	if err == nil && sig.KeyLifetimeSecs != nil && *sig.KeyLifetimeSecs != 0 {
+		lifetime := time.Duration(*sig.KeyLifetimeSecs) * time.Second
+		info.Expires = entity.PrimaryKey.CreationTime.Add(lifetime)
+	}
+
+	return info
+}
+
+// parseGPGKeyInfo extracts the user IDs and expiration of the first secret key in the
+// output of gpg --with-colons --list-secret-keys.
+func parseGPGKeyInfo(output string) (KeyInfo, error) {
+	var info KeyInfo
+	found := false
+
+	for line := range strings.Lines(output) {
+		fields := strings.Split(strings.TrimRight(line, "\r\n"), ":")
+		switch fields[0] {
+		case "sec":
+			if found {
+				return info, nil
+			}
+			found = true
+			if len(fields) > 6 && fields[6] != "" {
+				expires, err := parseGPGTime(fields[6])
+				if err != nil {
+					return KeyInfo{}, err
+				}
+				info.Expires = expires
+			}
+		case "uid":
+			if found && len(fields) > 9 {
+				info.UserIDs = append(info.UserIDs, unescapeGPGColons(fields[9]))
+			}
+		}
+	}
+
+	if !found {
+		return KeyInfo{}, fmt.Errorf("gpg did not list a secret key")
+	}
+	return info, nil
+}
+
+// parseGPGTime parses a timestamp from gpg's colon output, which is either
+// seconds since the epoch or an ISO 8601 basic format timestamp.
+func parseGPGTime(value string) (time.Time, error) {
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+	t, err := time.Parse("20060102T150405", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid key expiration %q in gpg output", value)
+	}
+	return t, nil
+}
+
+// unescapeGPGColons decodes the \xNN escapes gpg uses for special characters in colon output.
+func unescapeGPGColons(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' && i+3 < len(value) && value[i+1] == 'x' {
+			if c, err := strconv.ParseUint(value[i+2:i+4], 16, 8); err == nil {
+				b.WriteByte(byte(c))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(value[i])
+	}
+	return b.String()
+}
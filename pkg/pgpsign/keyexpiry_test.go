@@ -0,0 +1,178 @@
+package pgpsign
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+// keyInfoSigner is a MockSigner that describes its key.
+type keyInfoSigner struct {
+	MockSigner
+	info KeyInfo
+}
+
+func (s *keyInfoSigner) KeyInfo() (KeyInfo, error) {
+	return s.info, nil
+}
+
+func TestCheckKeyExpiry(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	window := 30 * 24 * time.Hour
+
+	tests := []struct {
+		name         string
+		signer       Signer
+		window       time.Duration
+		expectedErr  string
+		expectedWarn bool
+	}{
+		{
+			name:   "signer without key info",
+			signer: &MockSigner{},
+			window: window,
+		},
+		{
+			name:   "key without expiration",
+			signer: &keyInfoSigner{},
+			window: window,
+		},
+		{
+			name:   "key expiring after the window",
+			signer: &keyInfoSigner{info: KeyInfo{Expires: now.Add(60 * 24 * time.Hour)}},
+			window: window,
+		},
+		{
+			name:         "key expiring within the window",
+			signer:       &keyInfoSigner{info: KeyInfo{Expires: now.Add(10 * 24 * time.Hour)}},
+			window:       window,
+			expectedWarn: true,
+		},
+		{
+			name:   "warning disabled",
+			signer: &keyInfoSigner{info: KeyInfo{Expires: now.Add(10 * 24 * time.Hour)}},
+		},
+		{
+			name:        "expired key",
+			signer:      &keyInfoSigner{info: KeyInfo{Expires: now.Add(-time.Hour)}},
+			window:      window,
+			expectedErr: "signing key expired on 2025-12-31T23:00:00Z",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			log := slog.New(slog.NewTextHandler(&logs, nil))
+
+			err := checkKeyExpiry(tt.signer, tt.window, now, log)
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			warned := strings.Contains(logs.String(), "expires soon")
+			if warned != tt.expectedWarn {
+				t.Errorf("expected warning %v, got logs %q", tt.expectedWarn, logs.String())
+			}
+		})
+	}
+}
+
+func TestGoPGPSigner_KeyInfo(t *testing.T) {
+	created := time.Now().Add(-time.Hour).Truncate(time.Second)
+	signer, err := NewGoPGPSigner(generateTestKeyWithLifetime(t, created, 5*24*time.Hour), "", "")
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+
+	info, err := signer.KeyInfo()
+	if err != nil {
+		t.Fatalf("failed to get key info: %v", err)
+	}
+	if expected := created.Add(5 * 24 * time.Hour); !info.Expires.Equal(expected) {
+		t.Errorf("expected expiration %s, got %s", expected, info.Expires)
+	}
+	if len(info.UserIDs) != 1 || info.UserIDs[0] != "Expiring <expiring@test.com>" {
+		t.Errorf("unexpected user IDs %v", info.UserIDs)
+	}
+
+	var logs bytes.Buffer
+	if err := checkKeyExpiry(signer, 30*24*time.Hour, time.Now(), slog.New(slog.NewTextHandler(&logs, nil))); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(logs.String(), "expires soon") {
+		t.Errorf("expected expiry warning, got logs %q", logs.String())
+	}
+}
+
+func TestParseGPGKeyInfo(t *testing.T) {
+	tests := []struct {
+		name            string
+		output          string
+		expectedUserIDs []string
+		expectedExpires time.Time
+		expectedErr     string
+	}{
+		{
+			name: "expiring key with escaped user ID",
+			output: "sec:u:255:22:0123456789ABCDEF:1700000000:1800000000::u:::scESC:::+:::23::0:\n" +
+				"fpr:::::::::0123456789ABCDEF0123456789ABCDEF01234567:\n" +
+				"uid:u::::1700000000::HASH::Release Signing \\x3a CI <ci@example.com>::::::::::0:\n" +
+				"ssb:u:255:18:FEDCBA9876543210:1700000000::::::e:::+:::cv25519::\n",
+			expectedUserIDs: []string{"Release Signing : CI <ci@example.com>"},
+			expectedExpires: time.Unix(1800000000, 0),
+		},
+		{
+			name: "key without expiration lists only the first key",
+			output: "sec:u:255:22:0123456789ABCDEF:1700000000:::u:::scESC:::+:::23::0:\n" +
+				"uid:u::::1700000000::HASH::First <first@example.com>::::::::::0:\n" +
+				"sec:u:255:22:1111111111111111:1700000000:1800000000::u:::scESC:::+:::23::0:\n" +
+				"uid:u::::1700000000::HASH::Second <second@example.com>::::::::::0:\n",
+			expectedUserIDs: []string{"First <first@example.com>"},
+		},
+		{
+			name:            "ISO 8601 expiration",
+			output:          "sec:u:255:22:0123456789ABCDEF:20230101T000000:20270101T120000::u:::scESC:\n",
+			expectedExpires: time.Date(2027, 1, 1, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name:        "no secret key",
+			output:      "tru::1:1700000000:0:3:1:5\n",
+			expectedErr: "did not list a secret key",
+		},
+		{
+			name:        "invalid expiration",
+			output:      "sec:u:255:22:0123456789ABCDEF:1700000000:soon::u:::scESC:\n",
+			expectedErr: "invalid key expiration",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := parseGPGKeyInfo(tt.output)
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if strings.Join(info.UserIDs, "\n") != strings.Join(tt.expectedUserIDs, "\n") {
+				t.Errorf("expected user IDs %v, got %v", tt.expectedUserIDs, info.UserIDs)
+			}
+			if !info.Expires.Equal(tt.expectedExpires) {
+				t.Errorf("expected expiration %s, got %s", tt.expectedExpires, info.Expires)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package pgpsign
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// KeyEncoding defines how the private key input is encoded.
+type KeyEncoding string
+
+const (
+	KeyEncodingAuto   KeyEncoding = "auto"
+	KeyEncodingArmor  KeyEncoding = "armor"
+	KeyEncodingBase64 KeyEncoding = "base64"
+)
+
+const (
+	// armorHeader marks the beginning of an ASCII armored block.
+	armorHeader = "-----BEGIN"
+	// privateKeyHeader is the armor header line of a private key block.
+	privateKeyHeader = "BEGIN PGP PRIVATE KEY"
+)
+
+// Kinds of input recognized by classifyArmoredBlock.
+const (
+	armoredPrivateKey = "private key"
+	armoredPublicKey  = "public key"
+	armoredSignature  = "signature"
+	armoredMessage    = "message"
+	armoredSSHKey     = "ssh private key"
+	armoredUnknown    = "unknown"
+)
+
+// classifyArmoredBlock reports what kind of block the first armor header line in s
+// starts, so key errors can name the mistake instead of a generic parse failure.
+// Input without a recognized armor header is armoredUnknown.
+func classifyArmoredBlock(s string) string {
+	for _, line := range strings.Split(s, "\n") {
+		blockType, ok := strings.CutPrefix(strings.TrimSpace(line), armorHeader+" ")
+		if !ok {
+			continue
+		}
+		blockType = strings.TrimSuffix(blockType, "-----")
+		switch {
+		case strings.HasPrefix(blockType, "PGP PRIVATE KEY"):
+			return armoredPrivateKey
+		case strings.HasPrefix(blockType, "PGP PUBLIC KEY"):
+			return armoredPublicKey
+		case blockType == "PGP SIGNATURE", strings.HasPrefix(blockType, "PGP SIGNED MESSAGE"):
+			return armoredSignature
+		case strings.HasPrefix(blockType, "PGP MESSAGE"):
+			return armoredMessage
+		case blockType == "OPENSSH PRIVATE KEY":
+			return armoredSSHKey
+		}
+		return armoredUnknown
+	}
+	return armoredUnknown
+}
+
+// notPrivateKeyError explains why input, which failed to load as a PGP private key with
+// parseErr (nil if it parsed as a public key), cannot be used for signing.
+func notPrivateKeyError(input string, parseErr error) error {
+	switch classifyArmoredBlock(input) {
+	case armoredPublicKey:
+		return fmt.Errorf("provided key is a public key (BEGIN PGP PUBLIC KEY BLOCK), not a private key; " +
+			"export the secret key with gpg --armor --export-secret-keys <key-id> instead")
+	case armoredSignature:
+		return fmt.Errorf("provided key is a PGP signature block, not a private key")
+	case armoredMessage:
+		return fmt.Errorf("provided key is a PGP message block, not a private key")
+	case armoredSSHKey:
+		return fmt.Errorf("provided key is an ssh private key; set format to ssh to sign with it")
+	case armoredPrivateKey:
+		if parseErr != nil {
+			return fmt.Errorf("failed to parse private key: %w", parseErr)
+		}
+	}
+	if parseErr == nil {
+		return fmt.Errorf("provided key is not a private key")
+	}
+	return fmt.Errorf("failed to parse private key: input is not an armored PGP private key block: %w", parseErr)
+}
+
+// resolveKeyMaterial returns the armored key for the private-key input.
+// Armored keys are returned unchanged. An input of the form @path, or a single-line
+// input naming an existing file, is read from disk instead.
+func resolveKeyMaterial(input string) (string, error) {
+	if strings.Contains(input, armorHeader) {
+		return input, nil
+	}
+
+	trimmed := strings.TrimSpace(input)
+	if path, ok := strings.CutPrefix(trimmed, "@"); ok {
+		return readKeyFile(path)
+	}
+
+	if trimmed != "" && !strings.ContainsAny(trimmed, "\r\n") {
+		if info, err := os.Stat(trimmed); err == nil && info.Mode().IsRegular() {
+			return readKeyFile(trimmed)
+		}
+	}
+
+	return input, nil
+}
+
+// readKeyFile reads key material from path and rejects empty files.
+func readKeyFile(path string) (string, error) {
+	if path == "" {
+		return "", fmt.Errorf("private key file path is empty")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read private key file: %w", err)
+	}
+
+	if strings.TrimSpace(string(data)) == "" {
+		return "", fmt.Errorf("private key file %s is empty", path)
+	}
+
+	return string(data), nil
+}
+
+// decodeKeyMaterial returns the armored private key for input according to encoding.
+// In auto mode, input that is not armored but decodes cleanly as base64 is decoded.
+func decodeKeyMaterial(input string, encoding KeyEncoding) (string, error) {
+	switch encoding {
+	case KeyEncodingArmor:
+		return input, nil
+	case KeyEncodingBase64:
+		decoded, err := decodeBase64Key(input)
+		if err != nil {
+			return "", fmt.Errorf("failed to decode base64 private key: %w", err)
+		}
+		return decoded, nil
+	case KeyEncodingAuto, "":
+		if strings.Contains(input, armorHeader) {
+			return input, nil
+		}
+		decoded, err := decodeBase64Key(input)
+		if errors.Is(err, errNotBase64) {
+			return input, nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("private key looks base64 encoded: %w", err)
+		}
+		return decoded, nil
+	default:
+		return "", fmt.Errorf("unknown key encoding: %s", encoding)
+	}
+}
+
+// errNotBase64 is returned by decodeBase64Key for input that is not valid base64.
+var errNotBase64 = errors.New("input is not valid base64")
+
+// decodeBase64Key decodes base64 input, ignoring line breaks and surrounding whitespace,
+// and checks that the result is an armored private key.
+func decodeBase64Key(input string) (string, error) {
+	compact := strings.Join(strings.Fields(input), "")
+	if compact == "" {
+		return "", errNotBase64
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(compact)
+	if err != nil {
+		return "", errNotBase64
+	}
+
+	if !strings.Contains(string(decoded), privateKeyHeader) {
+		return "", fmt.Errorf("decoded value is not an armored PGP private key")
+	}
+
+	return string(decoded), nil
+}
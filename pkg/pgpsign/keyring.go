@@ -0,0 +1,93 @@
+package pgpsign
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ProtonMail/gopenpgp/v3/crypto"
+)
+
+// KeyringVerifier implements Verifier using a set of trusted public keys. A signature
+// verifies if it was made by any key in the ring, which catches a wrong signing key
+// injected into CI that self-verification would accept.
+type KeyringVerifier struct {
+	keyRing *crypto.KeyRing
+}
+
+// NewKeyringVerifier creates a KeyringVerifier from one or more concatenated armored public keys.
+func NewKeyringVerifier(armored string) (*KeyringVerifier, error) {
+	blocks := splitArmoredBlocks(armored)
+	if len(blocks) == 0 {
+		return nil, fmt.Errorf("verify keyring contains no armored public keys")
+	}
+
+	keyRing, err := crypto.NewKeyRing(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create key ring: %w", err)
+	}
+	for i, block := range blocks {
+		if kind := classifyArmoredBlock(block); kind != armoredPublicKey {
+			return nil, fmt.Errorf("verify keyring block %d is not a public key (found %s)", i+1, kind)
+		}
+		key, err := crypto.NewKeyFromArmored(block)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse verify keyring block %d: %w", i+1, err)
+		}
+		if err := keyRing.AddKey(key); err != nil {
+			return nil, fmt.Errorf("failed to add key %s to verify keyring: %w", key.GetFingerprint(), err)
+		}
+	}
+
+	return &KeyringVerifier{keyRing: keyRing}, nil
+}
+
+// loadVerifyKeyring reads the verify-keyring file at path.
+func loadVerifyKeyring(path string) (*KeyringVerifier, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read verify keyring: %w", err)
+	}
+	return NewKeyringVerifier(string(data))
+}
+
+// Fingerprints returns the fingerprints of the primary keys in the ring.
+func (v *KeyringVerifier) Fingerprints() []string {
+	keys := v.keyRing.GetKeys()
+	fingerprints := make([]string, len(keys))
+	for i, key := range keys {
+		fingerprints[i] = strings.ToUpper(key.GetFingerprint())
+	}
+	return fingerprints
+}
+
+// Verify checks that the signature at sigPath for filePath was made by a key in the ring.
+func (v *KeyringVerifier) Verify(filePath, sigPath string, opts SignOptions) error {
+	return verifyPGPSignature(v.keyRing, filePath, sigPath, opts)
+}
+
+// splitArmoredBlocks returns the armored blocks in s, each from its BEGIN to its END line.
+// Text around the blocks is ignored.
+func splitArmoredBlocks(s string) []string {
+	var blocks []string
+	var block strings.Builder
+	inBlock := false
+
+	for _, line := range strings.Split(s, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if !inBlock && strings.HasPrefix(trimmed, armorHeader+" ") {
+			inBlock = true
+			block.Reset()
+		}
+		if !inBlock {
+			continue
+		}
+		block.WriteString(trimmed + "\n")
+		if strings.HasPrefix(trimmed, "-----END ") {
+			blocks = append(blocks, block.String())
+			inBlock = false
+		}
+	}
+
+	return blocks
+}
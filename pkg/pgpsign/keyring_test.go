@@ -0,0 +1,177 @@
+package pgpsign
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// exportTestPublicKey returns the armored public key of an armored private key.
+func exportTestPublicKey(t *testing.T, privateKey string) string {
+	t.Helper()
+	signer, err := NewGoPGPSigner(privateKey, "", "")
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+	publicKey, err := signer.ExportPublicKey()
+	if err != nil {
+		t.Fatalf("failed to export public key: %v", err)
+	}
+	return publicKey
+}
+
+func TestNewKeyringVerifier(t *testing.T) {
+	privateKey := generateTestKeyArmored(t, "Release", "release@test.com", "")
+	publicKey := exportTestPublicKey(t, privateKey)
+	otherKey := exportTestPublicKey(t, generateTestKeyArmored(t, "Other", "other@test.com", ""))
+
+	tests := []struct {
+		name        string
+		keyring     string
+		keys        int
+		errContains string
+	}{
+		{name: "single key", keyring: publicKey, keys: 1},
+		{name: "concatenated keys with text between", keyring: "release key:\n" + publicKey + "\nbackup key:\n" + otherKey, keys: 2},
+		{name: "empty", keyring: "", errContains: "contains no armored public keys"},
+		{name: "private key", keyring: publicKey + "\n" + privateKey, errContains: "block 2 is not a public key (found private key)"},
+		{name: "truncated key", keyring: "-----BEGIN PGP PUBLIC KEY BLOCK-----\n\nxsA\n-----END PGP PUBLIC KEY BLOCK-----\n", errContains: "failed to parse verify keyring block 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier, err := NewKeyringVerifier(tt.keyring)
+			if tt.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Fatalf("expected error containing %q, got %v", tt.errContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := len(verifier.Fingerprints()); got != tt.keys {
+				t.Errorf("expected %d keys, got %d", tt.keys, got)
+			}
+		})
+	}
+}
+
+func TestKeyringVerifier_Verify(t *testing.T) {
+	privateKey := generateTestKeyArmored(t, "Release", "release@test.com", "")
+	signer, err := NewGoPGPSigner(privateKey, "", "")
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+	publicKey := exportTestPublicKey(t, privateKey)
+	otherKey := exportTestPublicKey(t, generateTestKeyArmored(t, "Other", "other@test.com", ""))
+
+	file := filepath.Join(t.TempDir(), "release.tar.gz")
+	if err := os.WriteFile(file, []byte("release content"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	for _, opts := range []SignOptions{
+		{Armor: true, DetachSign: true},
+		{DetachSign: true},
+		{ClearSign: true},
+		{Armor: true},
+	} {
+		result, err := signer.SignFile(file, opts)
+		if err != nil {
+			t.Fatalf("failed to sign with %+v: %v", opts, err)
+		}
+
+		tests := []struct {
+			name    string
+			keyring string
+			wantErr bool
+		}{
+			{name: "matching key", keyring: publicKey},
+			{name: "matching key among others", keyring: otherKey + "\n" + publicKey},
+			{name: "mismatched key", keyring: otherKey, wantErr: true},
+		}
+		for _, tt := range tests {
+			verifier, err := NewKeyringVerifier(tt.keyring)
+			if err != nil {
+				t.Fatalf("failed to create verifier: %v", err)
+			}
+			err = verifier.Verify(file, result.OutputPath, opts)
+			if tt.wantErr && err == nil {
+				t.Errorf("%s with %+v: expected verification to fail", tt.name, opts)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("%s with %+v: unexpected error: %v", tt.name, opts, err)
+			}
+		}
+	}
+}
+
+func TestRunVerifyKeyring(t *testing.T) {
+	privateKey := generateTestKeyArmored(t, "Release", "release@test.com", "")
+
+	tests := []struct {
+		name        string
+		keyring     string
+		errContains string
+	}{
+		{name: "signed by trusted key", keyring: exportTestPublicKey(t, privateKey)},
+		{
+			name:        "signed by wrong key",
+			keyring:     exportTestPublicKey(t, generateTestKeyArmored(t, "Other", "other@test.com", "")),
+			errContains: "signatures do not verify against verify-keyring",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workDir := t.TempDir()
+			writeTestFiles(t, workDir, "release.tar.gz")
+			if err := os.WriteFile(filepath.Join(workDir, "trusted.asc"), []byte(tt.keyring), 0o644); err != nil {
+				t.Fatalf("failed to write keyring: %v", err)
+			}
+
+			args := Config{
+				PrivateKey:    privateKey,
+				Backend:       string(BackendGoPGP),
+				Files:         "*.tar.gz",
+				Armor:         true,
+				DetachSign:    true,
+				WorkDir:       workDir,
+				VerifyKeyring: "trusted.asc",
+			}
+			err := run(context.Background(), args, nil, nil, nil)
+			if tt.errContains == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
+
+func TestRunVerifyKeyringMissingFile(t *testing.T) {
+	workDir := t.TempDir()
+	writeTestFiles(t, workDir, "release.tar.gz")
+	signer := &MockSigner{}
+
+	args := Config{
+		PrivateKey:    "unused",
+		Files:         "*.tar.gz",
+		DetachSign:    true,
+		WorkDir:       workDir,
+		VerifyKeyring: "missing.asc",
+	}
+	if err := run(context.Background(), args, signer, nil, nil); err == nil || !strings.Contains(err.Error(), "failed to read verify keyring") {
+		t.Fatalf("expected keyring read error, got %v", err)
+	}
+	if len(signer.SignedFiles) != 0 {
+		t.Errorf("expected nothing to be signed with a missing keyring, got %v", signer.SignedFiles)
+	}
+}
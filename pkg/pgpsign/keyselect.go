@@ -0,0 +1,108 @@
+package pgpsign
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	openpgp "github.com/ProtonMail/go-crypto/openpgp/v2"
+)
+
+// selectSigningKey returns the key ID of the primary key or subkey of entity matching keyID,
+// which may be a full fingerprint or a long (16 hex digit) key ID with an optional 0x prefix,
+// or an email address of one of the entity's user IDs. The selected key must be usable for signing.
+func selectSigningKey(entity *openpgp.Entity, keyID string) (uint64, error) {
+	want := normalizeKeyID(keyID)
+	if address, ok := emailSelector(want); ok {
+		return selectSigningKeyByEmail(entity, keyID, address)
+	}
+
+	keys := []*packet.PublicKey{entity.PrimaryKey}
+	for _, subkey := range entity.Subkeys {
+		keys = append(keys, subkey.PublicKey)
+	}
+
+	for _, key := range keys {
+		if want != formatKeyID(key.KeyId) && want != strings.ToUpper(hex.EncodeToString(key.Fingerprint)) {
+			continue
+		}
+		if _, ok := entity.SigningKeyById(time.Now(), key.KeyId, nil); !ok {
+			return 0, fmt.Errorf("key %s cannot be used for signing", formatKeyID(key.KeyId))
+		}
+		return key.KeyId, nil
+	}
+
+	available := make([]string, 0, len(keys))
+	for _, key := range keys {
+		available = append(available, formatKeyID(key.KeyId))
+	}
+	return 0, fmt.Errorf("no key matching %s; available key IDs: %s", keyID, strings.Join(available, ", "))
+}
+
+// requireSigningKey fails unless entity has a valid primary key or subkey that may sign.
+// Keys without any key flags pass, since skip-key-validation lets them sign.
+func requireSigningKey(entity *openpgp.Entity) error {
+	config := &packet.Config{InsecureAllowAllKeyFlagsWhenMissing: true}
+	if _, ok := entity.SigningKey(time.Now(), config); ok {
+		return nil
+	}
+	return fmt.Errorf("private key %s has no valid key or subkey that can sign: export the key with its signing subkey", formatKeyID(entity.PrimaryKey.KeyId))
+}
+
+// selectSigningKeyByEmail returns the key ID of the key gpg would sign with for the entity
+// if one of its user IDs has the email address, which must be lower case.
+func selectSigningKeyByEmail(entity *openpgp.Entity, keyID, address string) (uint64, error) {
+	for _, identity := range entity.Identities {
+		if identity.UserId == nil || strings.ToLower(identity.UserId.Email) != address {
+			continue
+		}
+		key, ok := entity.SigningKey(time.Now(), nil)
+		if !ok {
+			return 0, fmt.Errorf("key for %s cannot be used for signing", keyID)
+		}
+		return key.PublicKey.KeyId, nil
+	}
+	return 0, fmt.Errorf("no user ID with email address %s", keyID)
+}
+
+// normalizeKeyID canonicalizes a user supplied key selector, as accepted by gpg's --local-user.
+// Key IDs and fingerprints become upper-case hex without spaces and 0x prefix. Email addresses,
+// with or without angle brackets, become a lower-case address in angle brackets, which gpg
+// matches exactly.
+func normalizeKeyID(keyID string) string {
+	keyID = strings.TrimSpace(keyID)
+	address := strings.TrimSuffix(strings.TrimPrefix(keyID, "<"), ">")
+	if strings.Contains(address, "@") && !strings.ContainsAny(address, " <>") {
+		return "<" + strings.ToLower(address) + ">"
+	}
+
+	keyID = strings.Join(strings.Fields(keyID), "")
+	keyID = strings.TrimPrefix(strings.TrimPrefix(keyID, "0x"), "0X")
+	return strings.ToUpper(keyID)
+}
+
+// emailSelector returns the address of a key selector normalized by normalizeKeyID if it
+// names an email address.
+func emailSelector(normalized string) (string, bool) {
+	if !strings.HasPrefix(normalized, "<") {
+		return "", false
+	}
+	return strings.Trim(normalized, "<>"), true
+}
+
+// localUser returns the --local-user argument for a normalized key selector. Key IDs get a
+// trailing "!" so gpg uses exactly that key instead of picking a subkey; email addresses
+// let gpg pick the signing key of the matching certificate.
+func localUser(keyID string) string {
+	if _, ok := emailSelector(keyID); ok {
+		return keyID
+	}
+	return keyID + "!"
+}
+
+// formatKeyID formats a key ID as 16 upper-case hex digits.
+func formatKeyID(keyID uint64) string {
+	return fmt.Sprintf("%016X", keyID)
+}
@@ -0,0 +1,62 @@
+package pgpsign
+
+import (
+	"strings"
+	"testing"
+)
+
+// spacedFingerprint formats a hex fingerprint in groups of four, as gpg prints it.
+func spacedFingerprint(fingerprint string) string {
+	var groups []string
+	for i := 0; i < len(fingerprint); i += 4 {
+		groups = append(groups, fingerprint[i:min(i+4, len(fingerprint))])
+	}
+	return strings.Join(groups, " ")
+}
+
+func TestNormalizeKeyID(t *testing.T) {
+	tests := []struct {
+		name     string
+		keyID    string
+		expected string
+	}{
+		{name: "long key ID", keyID: "90479FD5373C5F7E", expected: "90479FD5373C5F7E"},
+		{name: "lower case", keyID: "90479fd5373c5f7e", expected: "90479FD5373C5F7E"},
+		{name: "0x prefix", keyID: "0xABCD", expected: "ABCD"},
+		{name: "upper case 0X prefix", keyID: "0X90479fd5373c5f7e", expected: "90479FD5373C5F7E"},
+		{
+			name:     "spaced fingerprint",
+			keyID:    "  8E5F 6E7A 1C27 8D5F 3A3B  C0F6 9047 9FD5 373C 5F7E ",
+			expected: "8E5F6E7A1C278D5F3A3BC0F690479FD5373C5F7E",
+		},
+		{name: "spaced fingerprint with 0x prefix", keyID: "0x 9047 9FD5 373C 5F7E", expected: "90479FD5373C5F7E"},
+		{name: "email", keyID: "name@example.com", expected: "<name@example.com>"},
+		{name: "email in angle brackets", keyID: " <Name@Example.com> ", expected: "<name@example.com>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeKeyID(tt.keyID); got != tt.expected {
+				t.Errorf("normalizeKeyID(%q) = %q, want %q", tt.keyID, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLocalUser(t *testing.T) {
+	tests := []struct {
+		keyID    string
+		expected string
+	}{
+		{keyID: "90479FD5373C5F7E", expected: "90479FD5373C5F7E!"},
+		{keyID: "<name@example.com>", expected: "<name@example.com>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.keyID, func(t *testing.T) {
+			if got := localUser(tt.keyID); got != tt.expected {
+				t.Errorf("localUser(%q) = %q, want %q", tt.keyID, got, tt.expected)
+			}
+		})
+	}
+}
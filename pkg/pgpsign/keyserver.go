@@ -0,0 +1,124 @@
+package pgpsign
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ProtonMail/gopenpgp/v3/crypto"
+)
+
+// hkpDefaultPort is the port of hkp:// keyservers without an explicit port.
+const hkpDefaultPort = "11371"
+
+// keyserverClient sends key submissions to the keyserver.
+var keyserverClient = &http.Client{Timeout: 30 * time.Second}
+
+// keyserverAddURL returns the HKP submission endpoint of the keyserver input. hkp:// is
+// submitted over HTTP on port 11371 and hkps:// over HTTPS; http:// and https:// URLs are used as is.
+func keyserverAddURL(keyserver string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(keyserver))
+	if err != nil || u.Host == "" {
+		return "", fmt.Errorf("invalid keyserver %q: expected a URL such as hkps://keys.openpgp.org", keyserver)
+	}
+
+	switch u.Scheme {
+	case "hkp":
+		u.Scheme = "http"
+		if u.Port() == "" {
+			u.Host = net.JoinHostPort(u.Hostname(), hkpDefaultPort)
+		}
+	case "hkps":
+		u.Scheme = "https"
+	case "http", "https":
+	default:
+		return "", fmt.Errorf("invalid keyserver %q: unsupported scheme %q, expected hkp, hkps, http or https", keyserver, u.Scheme)
+	}
+
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/pks/add"
+	return u.String(), nil
+}
+
+// publishPublicKeys uploads the public key of every signing key to the keyserver input.
+// Failures are logged as warnings unless keyserver-required is set. Cancelling ctx aborts
+// running uploads and stops retries.
+func publishPublicKeys(ctx context.Context, args Config, keys []signingKey, log *slog.Logger) error {
+	if args.Keyserver == "" {
+		return nil
+	}
+
+	addURL, err := keyserverAddURL(args.Keyserver)
+	if err != nil {
+		return err
+	}
+	retry := RetryPolicy{Retries: args.NetworkRetries, Backoff: args.NetworkBackoff}
+
+	publicKeys := uniquePublicKeys(keys)
+	if len(publicKeys) == 0 {
+		return keyserverFailure(args, log, fmt.Errorf("signer does not expose a public key to publish"))
+	}
+
+	for _, publicKey := range publicKeys {
+		fingerprint := strings.ToUpper(publicKey.GetFingerprint())
+		err := retry.Do(ctx, "keyserver upload", func() error {
+			return uploadPublicKey(ctx, addURL, publicKey)
+		})
+		if err != nil {
+			if err := keyserverFailure(args, log, fmt.Errorf("failed to publish key %s: %w", fingerprint, err)); err != nil {
+				return err
+			}
+			continue
+		}
+		log.Info("Public key published", slog.String("keyserver", args.Keyserver), slog.String("fingerprint", fingerprint))
+	}
+
+	return nil
+}
+
+// keyserverFailure returns err if keyserver uploads are required and only logs it otherwise.
+func keyserverFailure(args Config, log *slog.Logger, err error) error {
+	if args.KeyserverRequired {
+		return err
+	}
+	log.Warn("Keyserver upload failed; continuing as keyserver-required is not set", slog.String("error", err.Error()))
+	return nil
+}
+
+// uploadPublicKey submits the armored publicKey to the HKP endpoint addURL. Errors that a
+// retry cannot fix, such as a 4xx response other than 429, are wrapped by permanent.
+func uploadPublicKey(ctx context.Context, addURL string, publicKey *crypto.Key) error {
+	armored, err := publicKey.GetArmoredPublicKey()
+	if err != nil {
+		return permanent(fmt.Errorf("failed to armor public key: %w", err))
+	}
+
+	form := url.Values{"keytext": {armored}}.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, addURL, strings.NewReader(form))
+	if err != nil {
+		return permanent(fmt.Errorf("failed to create keyserver request: %w", err))
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := keyserverClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		err := fmt.Errorf("keyserver responded with %s: %s", resp.Status, strings.TrimSpace(string(body)))
+		// Rejected submissions fail the same way again; only rate limits and server errors are retried.
+		if resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+			return permanent(err)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,210 @@
+package pgpsign
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/gopenpgp/v3/crypto"
+)
+
+func TestKeyserverAddURL(t *testing.T) {
+	tests := []struct {
+		keyserver   string
+		expected    string
+		errContains string
+	}{
+		{keyserver: "hkps://keys.openpgp.org", expected: "https://keys.openpgp.org/pks/add"},
+		{keyserver: "hkp://keyserver.ubuntu.com", expected: "http://keyserver.ubuntu.com:11371/pks/add"},
+		{keyserver: "hkp://keyserver.example:80", expected: "http://keyserver.example:80/pks/add"},
+		{keyserver: "https://example.com/keys/", expected: "https://example.com/keys/pks/add"},
+		{keyserver: "ldap://keys.example", errContains: "unsupported scheme"},
+		{keyserver: "keys.openpgp.org", errContains: "expected a URL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.keyserver, func(t *testing.T) {
+			got, err := keyserverAddURL(tt.keyserver)
+			if tt.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Fatalf("expected error containing %q, got %v", tt.errContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRunPublishesPublicKey(t *testing.T) {
+	sleep = func(context.Context, time.Duration) error { return nil }
+	t.Cleanup(func() { sleep = sleepContext })
+
+	armoredKey := generateTestKeyArmored(t, "Test", "test@test.com", "")
+	signer, err := NewGoPGPSigner(armoredKey, "", "")
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+	fingerprint := signer.privateKey.GetFingerprint()
+
+	tests := []struct {
+		name        string
+		status      int
+		required    bool
+		expectError bool
+	}{
+		{name: "uploaded", status: http.StatusOK},
+		{name: "failure is best-effort", status: http.StatusInternalServerError},
+		{name: "failure is fatal when required", status: http.StatusInternalServerError, required: true, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				if r.Method != http.MethodPost || r.URL.Path != "/pks/add" {
+					t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+				}
+				if err := r.ParseForm(); err != nil {
+					t.Errorf("failed to parse form: %v", err)
+				}
+				keytext := r.PostForm.Get("keytext")
+				if strings.Contains(keytext, "PRIVATE KEY") {
+					t.Error("private key material was uploaded")
+				}
+				key, err := crypto.NewKeyFromArmored(keytext)
+				if err != nil {
+					t.Errorf("keytext is not an armored key: %v", err)
+				} else if key.GetFingerprint() != fingerprint || key.IsPrivate() {
+					t.Errorf("uploaded key %s does not match signing key %s", key.GetFingerprint(), fingerprint)
+				}
+				w.WriteHeader(tt.status)
+			}))
+			t.Cleanup(server.Close)
+
+			workDir := t.TempDir()
+			file := filepath.Join(workDir, "a.txt")
+			if err := os.WriteFile(file, []byte("content"), 0o644); err != nil {
+				t.Fatalf("failed to create test file: %v", err)
+			}
+
+			args := Config{
+				PrivateKey:        "key",
+				Files:             "*.txt",
+				DetachSign:        true,
+				WorkDir:           workDir,
+				Keyserver:         server.URL,
+				KeyserverRequired: tt.required,
+				NetworkRetries:    1,
+			}
+			err := run(context.Background(), args, signer, &MockFileFinder{Files: []string{file}}, slog.New(slog.DiscardHandler))
+			if tt.expectError != (err != nil) {
+				t.Fatalf("expected error %v, got %v", tt.expectError, err)
+			}
+
+			expectedRequests := int32(1)
+			if tt.status != http.StatusOK {
+				expectedRequests = 2
+			}
+			if requests.Load() != expectedRequests {
+				t.Errorf("expected %d requests, got %d", expectedRequests, requests.Load())
+			}
+		})
+	}
+}
+
+func TestPublishPublicKeysCancelled(t *testing.T) {
+	armoredKey := generateTestKeyArmored(t, "Test", "test@test.com", "")
+	signer, err := NewGoPGPSigner(armoredKey, "", "")
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		// The request context only ends with the connection once the body is read.
+		_, _ = io.Copy(io.Discard, r.Body)
+		<-r.Context().Done()
+	}))
+	t.Cleanup(server.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	args := Config{
+		Keyserver:         server.URL,
+		KeyserverRequired: true,
+		NetworkRetries:    3,
+		NetworkBackoff:    time.Hour,
+	}
+	start := time.Now()
+	err = publishPublicKeys(ctx, args, []signingKey{{signer: signer}}, slog.New(slog.DiscardHandler))
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the upload to be cancelled, got %v", err)
+	}
+	if requests.Load() != 1 {
+		t.Errorf("expected no retry after cancellation, got %d requests", requests.Load())
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected the upload to stop with the context, took %s", elapsed)
+	}
+}
+
+func TestPublishPublicKeysRetries(t *testing.T) {
+	sleep = func(context.Context, time.Duration) error { return nil }
+	t.Cleanup(func() { sleep = sleepContext })
+
+	armoredKey := generateTestKeyArmored(t, "Test", "test@test.com", "")
+	signer, err := NewGoPGPSigner(armoredKey, "", "")
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+
+	tests := []struct {
+		status   int
+		attempts int32
+	}{
+		{status: http.StatusBadRequest, attempts: 1},
+		{status: http.StatusNotFound, attempts: 1},
+		{status: http.StatusUnprocessableEntity, attempts: 1},
+		{status: http.StatusTooManyRequests, attempts: 4},
+		{status: http.StatusBadGateway, attempts: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				w.WriteHeader(tt.status)
+			}))
+			t.Cleanup(server.Close)
+
+			args := Config{Keyserver: server.URL, KeyserverRequired: true, NetworkRetries: 3}
+			err := publishPublicKeys(context.Background(), args, []signingKey{{signer: signer}}, slog.New(slog.DiscardHandler))
+			if err == nil || !strings.Contains(err.Error(), strconv.Itoa(tt.status)) {
+				t.Errorf("expected an error naming status %d, got %v", tt.status, err)
+			}
+			if requests.Load() != tt.attempts {
+				t.Errorf("expected %d attempts, got %d", tt.attempts, requests.Load())
+			}
+		})
+	}
+}
@@ -0,0 +1,243 @@
+package pgpsign_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v3/crypto"
+	"github.com/cbrgm/pgp-sign-artifact-action/pkg/pgpsign"
+)
+
+// These tests use only the exported API, as a program importing the package would.
+
+// newArmoredKey generates an unprotected private key for signing.
+func newArmoredKey(t *testing.T) string {
+	t.Helper()
+	key, err := crypto.PGP().KeyGeneration().AddUserId("Library", "library@test.com").New().GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	armored, err := key.Armor()
+	if err != nil {
+		t.Fatalf("failed to armor key: %v", err)
+	}
+	return armored
+}
+
+func TestSign(t *testing.T) {
+	workDir := t.TempDir()
+	for _, name := range []string{"app.tar.gz", "checksums.txt"} {
+		if err := os.WriteFile(filepath.Join(workDir, name), []byte(name), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	privateKey := newArmoredKey(t)
+	report, err := pgpsign.Sign(context.Background(), pgpsign.Config{
+		PrivateKey: privateKey,
+		Backend:    string(pgpsign.BackendGoPGP),
+		Files:      "*.tar.gz",
+		WorkDir:    workDir,
+		Armor:      true,
+		DetachSign: true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if report.Backend != "gopgp" || report.Signed != 1 || report.Failed != 0 || len(report.Results) != 1 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	result := report.Results[0]
+	if result.File != "app.tar.gz" || result.OutputPath != "app.tar.gz.asc" || result.Status != pgpsign.StatusSigned {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	signer, err := pgpsign.NewSigner(pgpsign.BackendGoPGP, privateKey, "", "", pgpsign.GnuPGOptions{})
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+	file := filepath.Join(workDir, "app.tar.gz")
+	if err := signer.Verify(file, file+".asc", pgpsign.SignOptions{Armor: true, DetachSign: true}); err != nil {
+		t.Errorf("signature does not verify: %v", err)
+	}
+}
+
+func TestSignWithoutGitHubActions(t *testing.T) {
+	outputFile := filepath.Join(t.TempDir(), "output")
+	summaryFile := filepath.Join(t.TempDir(), "summary")
+	t.Setenv("GITHUB_OUTPUT", outputFile)
+	t.Setenv("GITHUB_STEP_SUMMARY", summaryFile)
+	workDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(workDir, "app.bin"), []byte("app"), 0o644); err != nil {
+		t.Fatalf("failed to write app.bin: %v", err)
+	}
+
+	report, err := pgpsign.Sign(context.Background(), pgpsign.Config{
+		PrivateKey: newArmoredKey(t),
+		Backend:    string(pgpsign.BackendGoPGP),
+		Files:      "*.bin",
+		WorkDir:    workDir,
+		DetachSign: true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Signed != 1 {
+		t.Errorf("expected the file to be signed, got %+v", report)
+	}
+	for _, file := range []string{outputFile, summaryFile} {
+		if _, err := os.Stat(file); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("expected %s not to be written by a library run, got %v", file, err)
+		}
+	}
+}
+
+func TestSignDefaultConfig(t *testing.T) {
+	workDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(workDir, "app.bin"), []byte("app"), 0o644); err != nil {
+		t.Fatalf("failed to write app.bin: %v", err)
+	}
+
+	privateKey := newArmoredKey(t)
+	config := pgpsign.DefaultConfig()
+	config.PrivateKey = privateKey
+	config.Files = "*.bin"
+	config.WorkDir = workDir
+	config.DetachSign = true
+	report, err := pgpsign.Sign(context.Background(), config)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Armor defaults to true, so the signature is armored without setting it.
+	if report.Backend != "gopgp" || report.Signed != 1 || report.Results[0].OutputPath != "app.bin.asc" {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	signer, err := pgpsign.NewSigner("", privateKey, "", "", pgpsign.GnuPGOptions{})
+	if err != nil {
+		t.Fatalf("failed to create signer with the default backend: %v", err)
+	}
+	file := filepath.Join(workDir, "app.bin")
+	if err := signer.Verify(file, file+".asc", pgpsign.SignOptions{Armor: true, DetachSign: true}); err != nil {
+		t.Errorf("signature does not verify: %v", err)
+	}
+}
+
+func TestSignZeroConfig(t *testing.T) {
+	workDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(workDir, "app.bin"), []byte("app"), 0o644); err != nil {
+		t.Fatalf("failed to write app.bin: %v", err)
+	}
+
+	// Empty strings select the defaults; Armor stays off.
+	report, err := pgpsign.Sign(context.Background(), pgpsign.Config{
+		PrivateKey: newArmoredKey(t),
+		Files:      "*.bin",
+		WorkDir:    workDir,
+		DetachSign: true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Backend != "gopgp" || report.Signed != 1 || report.Results[0].OutputPath != "app.bin.sig" {
+		t.Errorf("unexpected report: %+v", report)
+	}
+}
+
+// listFinder is a FileFinder returning a fixed list of files.
+type listFinder []string
+
+func (f listFinder) FindFiles(string, []string, []string) ([]string, error) {
+	return f, nil
+}
+
+// refusingSigner is a Signer that fails every signature.
+type refusingSigner struct{}
+
+var errRefused = errors.New("refused")
+
+func (refusingSigner) SignFile(string, pgpsign.SignOptions) (pgpsign.SignResult, error) {
+	return pgpsign.SignResult{}, errRefused
+}
+
+func (refusingSigner) SignStream(io.Reader, io.Writer, pgpsign.SignOptions) error {
+	return errRefused
+}
+
+func (refusingSigner) SignBytes([]byte, pgpsign.SignOptions) ([]byte, error) {
+	return nil, errRefused
+}
+
+func (refusingSigner) Verify(string, string, pgpsign.SignOptions) error {
+	return errRefused
+}
+
+func TestSignWithFinderAndLogger(t *testing.T) {
+	workDir := t.TempDir()
+	files := listFinder{filepath.Join(workDir, "a.bin"), filepath.Join(workDir, "b.bin")}
+	for _, file := range files {
+		if err := os.WriteFile(file, []byte("data"), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", file, err)
+		}
+	}
+
+	var logs bytes.Buffer
+	logger, err := pgpsign.NewLogger(pgpsign.Config{LogLevel: "info"}, &logs, &logs)
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	report, err := pgpsign.Sign(context.Background(), pgpsign.Config{
+		Finder:     files,
+		Logger:     logger,
+		PrivateKey: newArmoredKey(t),
+		Backend:    string(pgpsign.BackendGoPGP),
+		Files:      "unused",
+		WorkDir:    workDir,
+		DetachSign: true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Signed != 2 {
+		t.Errorf("expected both files from the finder to be signed, got %+v", report)
+	}
+	if !strings.Contains(logs.String(), "Successfully signed all files") {
+		t.Errorf("expected the run to log to the configured logger, got %q", logs.String())
+	}
+}
+
+func TestSignWithSigner(t *testing.T) {
+	workDir := t.TempDir()
+	for _, name := range []string{"a.bin", "b.bin"} {
+		if err := os.WriteFile(filepath.Join(workDir, name), []byte(name), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	report, err := pgpsign.Sign(context.Background(), pgpsign.Config{
+		Signer:          refusingSigner{},
+		PrivateKey:      "unused",
+		Files:           "*.bin",
+		WorkDir:         workDir,
+		DetachSign:      true,
+		ContinueOnError: true,
+	})
+	if !errors.Is(err, errRefused) {
+		t.Fatalf("expected the signer's error, got %v", err)
+	}
+	if report.Signed != 0 || report.Failed != 2 {
+		t.Errorf("expected a report of two failed files, got %+v", report)
+	}
+	for _, result := range report.Results {
+		if result.Status != pgpsign.StatusFailed || !strings.HasSuffix(result.Error, "refused") {
+			t.Errorf("unexpected result: %+v", result)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package pgpsign
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+)
+
+// listOutput is where list-only prints the matched files. Tests replace it.
+var listOutput io.Writer = os.Stdout
+
+// validateListOnly rejects list-only together with inputs that do not sign files on disk
+// or that would report the same files in another way.
+func validateListOnly(args Config) error {
+	if !args.ListOnly {
+		return nil
+	}
+	if inputMode(args) != nil {
+		return fmt.Errorf("list-only cannot be combined with tar-members, image-digests or signing stdin")
+	}
+	if args.DryRun {
+		return fmt.Errorf("list-only cannot be combined with dry-run")
+	}
+	return nil
+}
+
+// listOnly prints the files that would be signed to listOutput, one per line, without
+// creating a signer. Files below the primary working directory are printed relative to
+// it and all others as absolute paths. Nothing is written to disk.
+func listOnly(ctx context.Context, args Config, finder FileFinder, workDirs []string, opts SignOptions, log *slog.Logger) error {
+	// Never create the archives of archive-dirs while listing.
+	args.DryRun = true
+
+	files, err := matchFiles(ctx, args, finder, workDirs, opts, log)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if rel, ok := relativeToRoot(workDirs[:1], file); ok {
+			file = rel
+		}
+		if _, err := fmt.Fprintln(listOutput, filepath.ToSlash(file)); err != nil {
+			return fmt.Errorf("failed to write file list: %w", err)
+		}
+	}
+	log.Debug("Listed matched files", slog.Int("count", len(files)))
+	return nil
+}
@@ -0,0 +1,70 @@
+package pgpsign
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestRunListOnly(t *testing.T) {
+	workDir := t.TempDir()
+	writeTestFiles(t, workDir, "app.tar.gz", "app.tar.gz.asc", "checksums.txt", "docs/guide.md", "docs/notes.txt", "lib/core.jar")
+
+	tests := []struct {
+		name     string
+		files    string
+		excludes string
+		expected string
+	}{
+		{
+			name:     "single pattern skips signatures",
+			files:    "*.tar.gz*",
+			expected: "app.tar.gz\n",
+		},
+		{
+			name:     "patterns in order",
+			files:    "*.txt\n**/*.jar",
+			expected: "checksums.txt\nlib/core.jar\n",
+		},
+		{
+			name:     "excludes",
+			files:    "**/*",
+			excludes: "docs/*\n*.asc",
+			expected: "app.tar.gz\nchecksums.txt\nlib/core.jar\n",
+		},
+		{
+			name:     "no matches",
+			files:    "*.zip",
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			prevOutput := listOutput
+			listOutput = &out
+			t.Cleanup(func() { listOutput = prevOutput })
+
+			// Without a signer and a private key, the run only succeeds if no key is loaded.
+			args := Config{
+				Files:    tt.files,
+				Excludes: tt.excludes,
+				WorkDir:  workDir,
+				ListOnly: true,
+			}
+			if err := run(context.Background(), args, nil, nil, nil); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if out.String() != tt.expected {
+				t.Errorf("expected listing %q, got %q", tt.expected, out.String())
+			}
+		})
+	}
+
+	args := Config{Files: "*", WorkDir: workDir, ListOnly: true, DryRun: true}
+	if err := run(context.Background(), args, nil, nil, nil); err == nil || !strings.Contains(err.Error(), "dry-run") {
+		t.Errorf("expected list-only and dry-run to be rejected, got %v", err)
+	}
+}
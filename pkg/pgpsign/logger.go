@@ -0,0 +1,158 @@
+package pgpsign
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Log output formats.
+const (
+	logFormatText = "text"
+	logFormatJSON = "json"
+)
+
+// Log output streams.
+const (
+	logOutputStdout = "stdout"
+	logOutputStderr = "stderr"
+)
+
+// Named log time formats; any other value is used as a Go time layout.
+const (
+	logTimeRFC3339     = "rfc3339"
+	logTimeRFC3339Nano = "rfc3339nano"
+	logTimeUnix        = "unix"
+	logTimeNone        = "none"
+)
+
+// ansiSequence matches ANSI escape sequences such as color codes.
+var ansiSequence = regexp.MustCompile(`\x1b(\[[0-9;?]*[ -/]*[@-~]|[@-Z\\-_])`)
+
+// NewLogger creates the logger described by the log inputs of args: the level and format,
+// quiet, which raises the level to errors only whatever the level says, the time format and
+// no-color. It writes to stdout or stderr as the log-output input selects. Neither handler
+// colors its output; no-color also strips escape sequences from relayed messages.
+func NewLogger(args Config, stdout, stderr io.Writer) (*slog.Logger, error) {
+	if err := validateLogInputs(args); err != nil {
+		return nil, err
+	}
+	w, err := logWriter(args, stdout, stderr)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &slog.HandlerOptions{
+		Level:       stringToLogLevel(args.LogLevel),
+		ReplaceAttr: logReplaceAttr(args.LogTimeFormat, args.NoColor),
+	}
+	if args.Quiet {
+		opts.Level = slog.LevelError
+	}
+
+	if strings.EqualFold(args.LogFormat, logFormatJSON) {
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	}
+	return slog.New(slog.NewTextHandler(w, opts)), nil
+}
+
+// validateLogInputs checks the log-format and log-output inputs.
+func validateLogInputs(args Config) error {
+	switch strings.ToLower(args.LogFormat) {
+	case "", logFormatText, logFormatJSON:
+	default:
+		return fmt.Errorf("unknown log-format %q, expected text or json", args.LogFormat)
+	}
+	switch strings.ToLower(args.LogOutput) {
+	case "", logOutputStdout, logOutputStderr:
+	default:
+		return fmt.Errorf("unknown log-output %q, expected stdout or stderr", args.LogOutput)
+	}
+	return nil
+}
+
+// logWriter returns the stream selected by the log-output input. Without it logs go to
+// stdout, unless the signature of stdin is written there and logs would corrupt it.
+func logWriter(args Config, stdout, stderr io.Writer) (io.Writer, error) {
+	switch strings.ToLower(args.LogOutput) {
+	case logOutputStderr:
+		return stderr, nil
+	case logOutputStdout:
+		if args.SignsToStdout() {
+			return nil, errors.New("log-output stdout cannot be used while the signature is written to stdout, set output or use stderr")
+		}
+		return stdout, nil
+	}
+	if args.SignsToStdout() {
+		return stderr, nil
+	}
+	return stdout, nil
+}
+
+// logReplaceAttr returns the slog ReplaceAttr function rendering the time as timeFormat
+// and, with noColor, stripping escape sequences from strings and errors. It returns nil
+// when the handler defaults apply.
+func logReplaceAttr(timeFormat string, noColor bool) func([]string, slog.Attr) slog.Attr {
+	if timeFormat == "" && !noColor {
+		return nil
+	}
+	return func(groups []string, a slog.Attr) slog.Attr {
+		if len(groups) == 0 && a.Key == slog.TimeKey && timeFormat != "" {
+			return formatLogTime(a, timeFormat)
+		}
+		if noColor {
+			return stripColor(a)
+		}
+		return a
+	}
+}
+
+// formatLogTime renders the time attribute a as timeFormat. The none format drops it.
+func formatLogTime(a slog.Attr, timeFormat string) slog.Attr {
+	t := a.Value.Time()
+	switch strings.ToLower(timeFormat) {
+	case logTimeNone:
+		return slog.Attr{}
+	case logTimeUnix:
+		return slog.Int64(a.Key, t.Unix())
+	case logTimeRFC3339:
+		return slog.String(a.Key, t.Format(time.RFC3339))
+	case logTimeRFC3339Nano:
+		return slog.String(a.Key, t.Format(time.RFC3339Nano))
+	default:
+		return slog.String(a.Key, t.Format(timeFormat))
+	}
+}
+
+// stripColor removes ANSI escape sequences from a string or error attribute.
+func stripColor(a slog.Attr) slog.Attr {
+	switch a.Value.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, ansiSequence.ReplaceAllString(a.Value.String(), ""))
+	case slog.KindAny:
+		if err, ok := a.Value.Any().(error); ok {
+			return slog.String(a.Key, ansiSequence.ReplaceAllString(err.Error(), ""))
+		}
+	}
+	return a
+}
+
+// stringToLogLevel converts a string log level to slog.Level.
+func stringToLogLevel(level string) slog.Level {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug
+	case "info":
+		return slog.LevelInfo
+	case "warn":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
@@ -0,0 +1,187 @@
+package pgpsign
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewLogger(t *testing.T) {
+	tests := []struct {
+		name      string
+		level     string
+		format    string
+		quiet     bool
+		wantInfo  bool
+		wantError bool
+		wantJSON  bool
+	}{
+		{name: "text", level: "info", format: "text", wantInfo: true, wantError: true},
+		{name: "default format", level: "info", wantInfo: true, wantError: true},
+		{name: "json", level: "info", format: "json", wantInfo: true, wantError: true, wantJSON: true},
+		{name: "json upper case", level: "info", format: "JSON", wantInfo: true, wantError: true, wantJSON: true},
+		{name: "quiet", level: "info", format: "text", quiet: true, wantError: true},
+		{name: "quiet overrides debug", level: "debug", format: "json", quiet: true, wantError: true, wantJSON: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf strings.Builder
+			log, err := NewLogger(Config{LogLevel: tt.level, LogFormat: tt.format, Quiet: tt.quiet}, &buf, &buf)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			log.Info("signed", slog.String("file", "release.tar.gz"), slog.Group("progress", slog.Int("done", 1)))
+			log.Error("failed", slog.String("file", "other.tar.gz"))
+
+			output := buf.String()
+			if got := strings.Contains(output, "signed"); got != tt.wantInfo {
+				t.Errorf("info message logged = %t, want %t:\n%s", got, tt.wantInfo, output)
+			}
+			if got := strings.Contains(output, "failed"); got != tt.wantError {
+				t.Errorf("error message logged = %t, want %t:\n%s", got, tt.wantError, output)
+			}
+
+			for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+				var entry map[string]any
+				err := json.Unmarshal([]byte(line), &entry)
+				if tt.wantJSON && err != nil {
+					t.Errorf("expected a JSON line, got %q: %v", line, err)
+				}
+				if !tt.wantJSON && err == nil {
+					t.Errorf("expected a text line, got JSON %q", line)
+				}
+				if tt.wantJSON && entry["msg"] == "signed" {
+					progress, ok := entry["progress"].(map[string]any)
+					if entry["file"] != "release.tar.gz" || !ok || progress["done"] != float64(1) {
+						t.Errorf("unexpected attributes in %q", line)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestNewLogger_Output(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       Config
+		wantStderr bool
+		wantErr    string
+	}{
+		{name: "default", args: Config{}},
+		{name: "stdout", args: Config{LogOutput: "stdout"}},
+		{name: "stderr", args: Config{LogOutput: "stderr"}, wantStderr: true},
+		{name: "upper case", args: Config{LogOutput: "STDERR"}, wantStderr: true},
+		{name: "signature on stdout", args: Config{Files: "-"}, wantStderr: true},
+		{name: "signature to a file", args: Config{Files: "-", Output: "data.sig", LogOutput: "stdout"}},
+		{name: "stdout taken by the signature", args: Config{Files: "-", LogOutput: "stdout"}, wantErr: "cannot be used while the signature is written to stdout"},
+		{name: "unknown", args: Config{LogOutput: "file"}, wantErr: "unknown log-output"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr strings.Builder
+			log, err := NewLogger(tt.args, &stdout, &stderr)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			log.Info("signed")
+			selected, other := &stdout, &stderr
+			if tt.wantStderr {
+				selected, other = &stderr, &stdout
+			}
+			if !strings.Contains(selected.String(), "signed") || other.Len() != 0 {
+				t.Errorf("expected the log on the selected stream only, got stdout %q and stderr %q", stdout.String(), stderr.String())
+			}
+		})
+	}
+}
+
+func TestNewLogger_TimeFormat(t *testing.T) {
+	tests := []struct {
+		timeFormat string
+		check      func(value any) bool
+	}{
+		{timeFormat: "", check: func(v any) bool {
+			s, ok := v.(string)
+			_, err := time.Parse(time.RFC3339Nano, s)
+			return ok && err == nil
+		}},
+		{timeFormat: "rfc3339", check: func(v any) bool {
+			s, ok := v.(string)
+			_, err := time.Parse(time.RFC3339, s)
+			return ok && err == nil && !strings.Contains(s, ".")
+		}},
+		{timeFormat: "unix", check: func(v any) bool {
+			n, ok := v.(float64)
+			return ok && time.Since(time.Unix(int64(n), 0)) < time.Hour
+		}},
+		{timeFormat: "15:04:05", check: func(v any) bool {
+			s, ok := v.(string)
+			_, err := time.Parse("15:04:05", s)
+			return ok && err == nil
+		}},
+		{timeFormat: "none", check: func(v any) bool { return v == nil }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.timeFormat, func(t *testing.T) {
+			var buf strings.Builder
+			log, err := NewLogger(Config{LogFormat: "json", LogTimeFormat: tt.timeFormat}, &buf, &buf)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			log.Info("signed", slog.Group("progress", slog.Time("time", time.Unix(0, 0))))
+
+			var entry map[string]any
+			if err := json.Unmarshal([]byte(buf.String()), &entry); err != nil {
+				t.Fatalf("expected a JSON line, got %q: %v", buf.String(), err)
+			}
+			if !tt.check(entry["time"]) {
+				t.Errorf("unexpected time %v for format %q", entry["time"], tt.timeFormat)
+			}
+			// Only the record's own time is reformatted.
+			if progress, _ := entry["progress"].(map[string]any); progress["time"] != "1970-01-01T00:00:00Z" {
+				t.Errorf("expected a nested time attribute to keep its format, got %v", entry["progress"])
+			}
+		})
+	}
+}
+
+func TestNewLogger_NoColor(t *testing.T) {
+	const colored = "\x1b[31mgpg: signing failed\x1b[0m"
+
+	for _, noColor := range []bool{false, true} {
+		var buf strings.Builder
+		log, err := NewLogger(Config{NoColor: noColor}, &buf, &buf)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		log.Info("plain", slog.String("file", "app.tar.gz"))
+		if strings.Contains(buf.String(), "\x1b") {
+			t.Errorf("expected no escape sequences from the handler itself, got %q", buf.String())
+		}
+
+		buf.Reset()
+		log.Error(colored, slog.String("output", colored), slog.Any("error", errors.New(colored)))
+		output := buf.String()
+		if got := strings.Count(output, "gpg: signing failed"); got != 3 {
+			t.Errorf("no-color=%t: expected the message, value and error to be logged, got %q", noColor, output)
+		}
+		// The text handler quotes values with control characters, so look for the sequence's parameters.
+		if got := strings.Contains(output, "[31m"); got == noColor {
+			t.Errorf("no-color=%t: escape sequences logged = %t: %q", noColor, got, output)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package pgpsign
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// checksumLine matches a line of a sha256sum-style manifest: a hex digest, a space,
+// and the file name, optionally prefixed with "*" for binary mode.
+var checksumLine = regexp.MustCompile(`^[0-9a-fA-F]{32,128} [ *](.+)$`)
+
+// readFilesFrom returns the files listed in the manifest at manifestPath. Entries are paths
+// relative to workDir, one per line; blank lines and lines starting with "#" are ignored,
+// and checksum lines like those of SHA256SUMS contribute their file name. Entries matching
+// excludes are dropped. Missing entries are reported as a warning, or as an error when strict.
+func readFilesFrom(manifestPath, workDir string, excludes []string, strict bool, log *slog.Logger) ([]string, error) {
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read files-from manifest: %w", err)
+	}
+
+	var files, missing []string
+	for _, entry := range parseMultilineInput(string(data)) {
+		if strings.HasPrefix(entry, "#") {
+			continue
+		}
+		if m := checksumLine.FindStringSubmatch(entry); m != nil {
+			entry = m[1]
+		}
+
+		file := resolvePath(workDir, filepath.FromSlash(entry))
+		info, err := os.Stat(file)
+		if err != nil || info.IsDir() {
+			missing = append(missing, entry)
+			continue
+		}
+		if shouldExclude(file, workDir, excludes) {
+			continue
+		}
+		files = append(files, file)
+	}
+
+	if len(missing) > 0 {
+		if strict {
+			return nil, fmt.Errorf("files-from manifest lists %d missing files: %s", len(missing), strings.Join(missing, ", "))
+		}
+		for _, entry := range missing {
+			log.Warn("File listed in files-from manifest does not exist, skipping", slog.String("file", entry))
+		}
+	}
+
+	return files, nil
+}
+
+// userExcludes returns the patterns of the excludes-from file, resolved against workDir,
+// followed by the inline excludes. Later patterns win, so inline "!" patterns can re-include
+// files excluded by a shared list.
+func userExcludes(args Config, workDir string) ([]string, error) {
+	excludes := parseMultilineInput(args.Excludes)
+	if args.ExcludesFrom == "" {
+		return excludes, nil
+	}
+
+	listed, err := readExcludesFrom(resolvePath(workDir, args.ExcludesFrom))
+	if err != nil {
+		return nil, err
+	}
+	return append(listed, excludes...), nil
+}
+
+// readExcludesFrom returns the exclude patterns listed in the file at path, one per line.
+// Blank lines and lines starting with "#" are ignored; patterns are used as written.
+func readExcludesFrom(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read excludes-from file: %w", err)
+	}
+
+	var excludes []string
+	for _, line := range parseMultilineInput(string(data)) {
+		if !strings.HasPrefix(line, "#") {
+			excludes = append(excludes, line)
+		}
+	}
+	return excludes, nil
+}
+
+// mergeFiles appends the files of extra that are not already in files.
+func mergeFiles(files, extra []string) []string {
+	seen := make(map[string]bool, len(files))
+	for _, file := range files {
+		seen[filepath.Clean(file)] = true
+	}
+
+	for _, file := range extra {
+		if !seen[filepath.Clean(file)] {
+			seen[filepath.Clean(file)] = true
+			files = append(files, file)
+		}
+	}
+	return files
+}
@@ -0,0 +1,204 @@
+package pgpsign
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestReadFilesFrom(t *testing.T) {
+	workDir := t.TempDir()
+	for _, name := range []string{"app.tar.gz", "bin/app", "notes.txt"} {
+		path := filepath.Join(workDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("content of "+name), 0o644); err != nil {
+			t.Fatalf("failed to create test file: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name        string
+		manifest    string
+		excludes    []string
+		strict      bool
+		expected    []string
+		expectedErr string
+	}{
+		{
+			name:     "comments and blank lines",
+			manifest: "# release files\n\napp.tar.gz\n  bin/app  \n\n# end\n",
+			expected: []string{"app.tar.gz", "bin/app"},
+		},
+		{
+			name: "checksum manifest",
+			manifest: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855  app.tar.gz\n" +
+				"d41d8cd98f00b204e9800998ecf8427ed41d8cd98f00b204e9800998ecf8427e *bin/app\n",
+			expected: []string{"app.tar.gz", "bin/app"},
+		},
+		{
+			name:     "excluded entries are dropped",
+			manifest: "app.tar.gz\nnotes.txt\n",
+			excludes: []string{"*.txt"},
+			expected: []string{"app.tar.gz"},
+		},
+		{
+			name:     "missing entries are skipped",
+			manifest: "app.tar.gz\nmissing.bin\nbin\n",
+			expected: []string{"app.tar.gz"},
+		},
+		{
+			name:        "missing entries fail in strict mode",
+			manifest:    "app.tar.gz\nmissing.bin\n",
+			strict:      true,
+			expectedErr: "lists 1 missing files: missing.bin",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manifestPath := filepath.Join(t.TempDir(), "MANIFEST")
+			if err := os.WriteFile(manifestPath, []byte(tt.manifest), 0o644); err != nil {
+				t.Fatalf("failed to write manifest: %v", err)
+			}
+
+			files, err := readFilesFrom(manifestPath, workDir, tt.excludes, tt.strict, slog.New(slog.DiscardHandler))
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var expected []string
+			for _, name := range tt.expected {
+				expected = append(expected, filepath.Join(workDir, filepath.FromSlash(name)))
+			}
+			if !slices.Equal(files, expected) {
+				t.Errorf("expected %v, got %v", expected, files)
+			}
+		})
+	}
+}
+
+func TestRunFilesFrom(t *testing.T) {
+	workDir := t.TempDir()
+	for _, name := range []string{"a.txt", "b.bin", "c.bin"} {
+		if err := os.WriteFile(filepath.Join(workDir, name), []byte("content of "+name), 0o644); err != nil {
+			t.Fatalf("failed to create test file: %v", err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(workDir, "SIGN"), []byte("a.txt\nb.bin\n"), 0o644); err != nil {
+		t.Fatalf("failed to write manifest: %v", err)
+	}
+
+	mockSigner := &MockSigner{}
+	args := Config{
+		PrivateKey: "key",
+		Files:      "*.txt",
+		FilesFrom:  "SIGN",
+		Armor:      true,
+		DetachSign: true,
+		WorkDir:    workDir,
+	}
+	if err := run(context.Background(), args, mockSigner, &DefaultFileFinder{}, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{filepath.Join(workDir, "a.txt"), filepath.Join(workDir, "b.bin")}
+	if !slices.Equal(mockSigner.SignedFiles, expected) {
+		t.Errorf("expected %v to be signed, got %v", expected, mockSigner.SignedFiles)
+	}
+}
+
+func TestUserExcludes(t *testing.T) {
+	workDir := t.TempDir()
+	excludesFile := "# generated files\n*.log\n\n  build/  \n# keep the changelog\n!CHANGELOG.log\n"
+	if err := os.WriteFile(filepath.Join(workDir, ".signignore"), []byte(excludesFile), 0o644); err != nil {
+		t.Fatalf("failed to write excludes file: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		args        Config
+		expected    []string
+		expectedErr string
+	}{
+		{
+			name:     "inline only",
+			args:     Config{Excludes: "*.tmp\n*.bak"},
+			expected: []string{"*.tmp", "*.bak"},
+		},
+		{
+			name:     "file only",
+			args:     Config{ExcludesFrom: ".signignore"},
+			expected: []string{"*.log", "build/", "!CHANGELOG.log"},
+		},
+		{
+			name:     "file before inline",
+			args:     Config{Excludes: "*.tmp", ExcludesFrom: ".signignore"},
+			expected: []string{"*.log", "build/", "!CHANGELOG.log", "*.tmp"},
+		},
+		{
+			name:        "missing file",
+			args:        Config{ExcludesFrom: "missing"},
+			expectedErr: "failed to read excludes-from file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			excludes, err := userExcludes(tt.args, workDir)
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(excludes, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, excludes)
+			}
+		})
+	}
+}
+
+func TestRunExcludesFrom(t *testing.T) {
+	workDir := t.TempDir()
+	writeTestFiles(t, workDir, "app.bin", "debug.log", "CHANGELOG.log", "notes.tmp", "build/out.bin")
+	excludesFile := "# generated files\n*.log\nbuild/\n\n# keep the changelog\n!CHANGELOG.log\n"
+	if err := os.WriteFile(filepath.Join(workDir, "excludes.txt"), []byte(excludesFile), 0o644); err != nil {
+		t.Fatalf("failed to write excludes file: %v", err)
+	}
+
+	mockSigner := &MockSigner{}
+	args := Config{
+		PrivateKey:   "key",
+		Files:        "**/*",
+		Excludes:     "*.tmp\nexcludes.txt",
+		ExcludesFrom: "excludes.txt",
+		Armor:        true,
+		DetachSign:   true,
+		WorkDir:      workDir,
+	}
+	if err := run(context.Background(), args, mockSigner, &DefaultFileFinder{}, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	signed := slices.Clone(mockSigner.SignedFiles)
+	slices.Sort(signed)
+	expected := []string{filepath.Join(workDir, "CHANGELOG.log"), filepath.Join(workDir, "app.bin")}
+	if !slices.Equal(signed, expected) {
+		t.Errorf("expected %v to be signed, got %v", expected, signed)
+	}
+}
@@ -0,0 +1,82 @@
+package pgpsign
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+)
+
+// minimalArmorWriter re-encodes armored output without armor headers: every Version,
+// Comment or other header line between a BEGIN line and the blank line ending the headers
+// is dropped, leaving only what OpenPGP requires. The Hash header of a clear-signed
+// message belongs to the signed text and is kept. Close writes a final unterminated line.
+type minimalArmorWriter struct {
+	w         io.Writer
+	line      []byte // Current line, written once it is complete
+	inHeaders bool   // The lines being written are armor headers
+}
+
+// Write filters complete lines of p and writes them to the underlying writer.
+func (m *minimalArmorWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	var out []byte
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			m.line = append(m.line, p...)
+			break
+		}
+		m.line = append(m.line, p[:i+1]...)
+		out = m.appendLine(out)
+		p = p[i+1:]
+	}
+	if _, err := m.w.Write(out); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// Close writes the last line if it was not terminated by a newline.
+func (m *minimalArmorWriter) Close() error {
+	if len(m.line) == 0 {
+		return nil
+	}
+	_, err := m.w.Write(m.appendLine(nil))
+	return err
+}
+
+// appendLine appends the current line to out unless it is an armor header, and resets it.
+func (m *minimalArmorWriter) appendLine(out []byte) []byte {
+	line := m.line
+	m.line = m.line[:0]
+
+	if m.inHeaders {
+		if len(bytes.TrimRight(line, "\r\n")) > 0 {
+			return out
+		}
+		m.inHeaders = false
+	} else if bytes.HasPrefix(line, []byte("-----BEGIN PGP ")) && !bytes.HasPrefix(line, []byte("-----BEGIN PGP SIGNED MESSAGE-----")) {
+		m.inHeaders = true
+	}
+	return append(out, line...)
+}
+
+// stripArmorHeadersFile rewrites the armored file at path without armor headers,
+// for signatures a tool such as gpg wrote to disk itself.
+func stripArmorHeadersFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read signature: %w", err)
+	}
+
+	var buf bytes.Buffer
+	m := &minimalArmorWriter{w: &buf}
+	_, _ = m.Write(data)
+	_ = m.Close()
+
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("failed to write signature: %w", err)
+	}
+	return nil
+}
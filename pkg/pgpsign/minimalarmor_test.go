@@ -0,0 +1,80 @@
+package pgpsign
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMinimalArmorWriter(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "detached signature",
+			input:    "-----BEGIN PGP SIGNATURE-----\nVersion: GnuPG v2\nComment: release\n\nwsBcBAAB\n=abcd\n-----END PGP SIGNATURE-----\n",
+			expected: "-----BEGIN PGP SIGNATURE-----\n\nwsBcBAAB\n=abcd\n-----END PGP SIGNATURE-----\n",
+		},
+		{
+			name: "clear-signed message keeps the hash header",
+			input: "-----BEGIN PGP SIGNED MESSAGE-----\nHash: SHA256\n\nComment: part of the text\n" +
+				"-----BEGIN PGP SIGNATURE-----\r\nComment: release\r\n\r\nwsBcBAAB\r\n-----END PGP SIGNATURE-----\r\n",
+			expected: "-----BEGIN PGP SIGNED MESSAGE-----\nHash: SHA256\n\nComment: part of the text\n" +
+				"-----BEGIN PGP SIGNATURE-----\r\n\r\nwsBcBAAB\r\n-----END PGP SIGNATURE-----\r\n",
+		},
+		{
+			name:     "without headers",
+			input:    "-----BEGIN PGP MESSAGE-----\n\nowGbwMvM\n-----END PGP MESSAGE-----\n",
+			expected: "-----BEGIN PGP MESSAGE-----\n\nowGbwMvM\n-----END PGP MESSAGE-----\n",
+		},
+		{
+			name:     "unterminated last line",
+			input:    "-----BEGIN PGP SIGNATURE-----\nVersion: GnuPG v2\n\nwsBcBAAB\n-----END PGP SIGNATURE-----",
+			expected: "-----BEGIN PGP SIGNATURE-----\n\nwsBcBAAB\n-----END PGP SIGNATURE-----",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Lines split across writes must come out the same as whole ones.
+			for _, chunk := range []int{len(tt.input), 7, 1} {
+				var buf bytes.Buffer
+				m := &minimalArmorWriter{w: &buf}
+				for data := []byte(tt.input); len(data) > 0; {
+					n := min(chunk, len(data))
+					if written, err := m.Write(data[:n]); err != nil || written != n {
+						t.Fatalf("write returned %d, %v", written, err)
+					}
+					data = data[n:]
+				}
+				if err := m.Close(); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if buf.String() != tt.expected {
+					t.Errorf("chunks of %d: expected:\n%q\ngot:\n%q", chunk, tt.expected, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestStripArmorHeadersFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file.asc")
+	if err := os.WriteFile(path, []byte("-----BEGIN PGP SIGNATURE-----\nVersion: GnuPG v2\n\nwsBcBAAB\n-----END PGP SIGNATURE-----\n"), 0o644); err != nil {
+		t.Fatalf("failed to write signature: %v", err)
+	}
+	if err := stripArmorHeadersFile(path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read signature: %v", err)
+	}
+	if expected := "-----BEGIN PGP SIGNATURE-----\n\nwsBcBAAB\n-----END PGP SIGNATURE-----\n"; string(data) != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, data)
+	}
+}
@@ -0,0 +1,278 @@
+package pgpsign
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Layout of minisign keys and signatures.
+const (
+	minisignExtension = ".minisig"
+
+	minisignAlgEd25519   = "Ed" // Signature over the file itself; only read when verifying
+	minisignAlgPrehashed = "ED" // Signature over the BLAKE2b-512 hash of the file
+	minisignKDFScrypt    = "Sc"
+	minisignKDFNone      = "\x00\x00"
+	minisignChecksumAlg  = "B2"
+
+	minisignKeyIDSize     = 8
+	minisignSaltSize      = 32
+	minisignChecksumSize  = 32
+	minisignKeyNumSize    = minisignKeyIDSize + ed25519.PrivateKeySize + minisignChecksumSize
+	minisignSecretKeySize = 2 + 2 + 2 + minisignSaltSize + 8 + 8 + minisignKeyNumSize
+	minisignSignatureSize = 2 + minisignKeyIDSize + ed25519.SignatureSize
+
+	minisignUntrustedComment = "untrusted comment: "
+	minisignTrustedComment   = "trusted comment: "
+)
+
+// MinisignSigner implements Signer using an Ed25519 minisign secret key.
+// Minisign signatures are always detached and written as .minisig files.
+type MinisignSigner struct {
+	keyID      [minisignKeyIDSize]byte
+	privateKey ed25519.PrivateKey
+}
+
+// NewMinisignSigner creates a new MinisignSigner from a minisign secret key file,
+// decrypting the key with passphrase if it is encrypted.
+func NewMinisignSigner(secretKey, passphrase string) (*MinisignSigner, error) {
+	data, err := decodeMinisignBlock(secretKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse minisign secret key: %w", err)
+	}
+	if len(data) != minisignSecretKeySize {
+		return nil, fmt.Errorf("failed to parse minisign secret key: unexpected length %d", len(data))
+	}
+
+	sigAlg, kdfAlg, checksumAlg := string(data[0:2]), string(data[2:4]), string(data[4:6])
+	salt := data[6 : 6+minisignSaltSize]
+	opsLimit := binary.LittleEndian.Uint64(data[38:46])
+	memLimit := binary.LittleEndian.Uint64(data[46:54])
+	keyNum := bytes.Clone(data[54:])
+
+	if sigAlg != minisignAlgEd25519 {
+		return nil, fmt.Errorf("unsupported minisign signature algorithm %q", sigAlg)
+	}
+	if checksumAlg != minisignChecksumAlg {
+		return nil, fmt.Errorf("unsupported minisign checksum algorithm %q", checksumAlg)
+	}
+
+	switch kdfAlg {
+	case minisignKDFNone:
+	case minisignKDFScrypt:
+		if passphrase == "" {
+			return nil, fmt.Errorf("minisign secret key is encrypted but no passphrase was provided")
+		}
+		stream, err := minisignKeyStream(passphrase, salt, opsLimit, memLimit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive minisign key: %w", err)
+		}
+		subtle.XORBytes(keyNum, keyNum, stream)
+	default:
+		return nil, fmt.Errorf("unsupported minisign key derivation %q", kdfAlg)
+	}
+
+	keyID := keyNum[:minisignKeyIDSize]
+	privateKey := ed25519.PrivateKey(keyNum[minisignKeyIDSize : minisignKeyIDSize+ed25519.PrivateKeySize])
+	checksum := keyNum[minisignKeyIDSize+ed25519.PrivateKeySize:]
+
+	expected := blake2b.Sum256(bytes.Join([][]byte{[]byte(sigAlg), keyID, privateKey}, nil))
+	if subtle.ConstantTimeCompare(expected[:], checksum) != 1 {
+		return nil, fmt.Errorf("failed to unlock minisign secret key: wrong passphrase or corrupted key")
+	}
+
+	signer := &MinisignSigner{privateKey: bytes.Clone(privateKey)}
+	copy(signer.keyID[:], keyID)
+	return signer, nil
+}
+
+// minisignKeyStream derives the stream that encrypts a minisign secret key from passphrase.
+func minisignKeyStream(passphrase string, salt []byte, opsLimit, memLimit uint64) ([]byte, error) {
+	n, r, p := minisignScryptParams(opsLimit, memLimit)
+	return scrypt.Key([]byte(passphrase), salt, n, r, p, minisignKeyNumSize)
+}
+
+// minisignScryptParams converts the libsodium opslimit and memlimit stored in a minisign
+// key to the scrypt parameters N, r and p, as libsodium's crypto_pwhash_scryptsalsa208sha256 does.
+func minisignScryptParams(opsLimit, memLimit uint64) (n, r, p int) {
+	const blockSize = 8
+	opsLimit = max(opsLimit, 32768)
+
+	maxN := memLimit / (blockSize * 128)
+	if opsLimit < memLimit/32 {
+		maxN = opsLimit / (blockSize * 4)
+	}
+
+	nLog2 := uint(1)
+	for ; nLog2 < 63; nLog2++ {
+		if uint64(1)<<nLog2 > maxN/2 {
+			break
+		}
+	}
+
+	if opsLimit < memLimit/32 {
+		return 1 << nLog2, blockSize, 1
+	}
+	maxRP := min((opsLimit/4)/(uint64(1)<<nLog2), 0x3fffffff)
+	return 1 << nLog2, blockSize, int(maxRP / blockSize)
+}
+
+// decodeMinisignBlock decodes the base64 line of a minisign key, skipping its untrusted comment.
+func decodeMinisignBlock(input string) ([]byte, error) {
+	for _, line := range strings.Split(input, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, minisignUntrustedComment) {
+			continue
+		}
+		return base64.StdEncoding.DecodeString(line)
+	}
+	return nil, fmt.Errorf("no key data found")
+}
+
+// KeyID returns the minisign key ID as shown by minisign.
+func (s *MinisignSigner) KeyID() string {
+	return fmt.Sprintf("%016X", binary.LittleEndian.Uint64(s.keyID[:]))
+}
+
+// SignFile signs a file and writes the minisign signature next to it.
+func (s *MinisignSigner) SignFile(filePath string, opts SignOptions) (SignResult, error) {
+	outputPath := getOutputPath(filePath, opts)
+	if err := checkOverwrite(outputPath, opts); err != nil {
+		return SignResult{}, err
+	}
+
+	in, err := os.Open(filePath)
+	if err != nil {
+		return SignResult{}, fmt.Errorf("failed to read file: %w", err)
+	}
+	defer in.Close()
+
+	out, err := createOutputFile(outputPath, opts.outputMode())
+	if err != nil {
+		return SignResult{}, fmt.Errorf("failed to create signature file: %w", err)
+	}
+
+	if err := s.writeSignature(in, out, filepath.Base(filePath), opts); err != nil {
+		out.Abort()
+		return SignResult{}, err
+	}
+
+	if err := out.Commit(); err != nil {
+		return SignResult{}, fmt.Errorf("failed to write signature: %w", err)
+	}
+
+	result := newSignResult(filePath, outputPath, nil, "blake2b-512")
+	result.KeyID = s.KeyID()
+	return result, nil
+}
+
+// SignStream signs data read from r and writes the minisign signature to w.
+func (s *MinisignSigner) SignStream(r io.Reader, w io.Writer, opts SignOptions) error {
+	return s.writeSignature(r, w, "", opts)
+}
+
+// SignBytes signs data held in memory and returns the minisign signature.
+func (s *MinisignSigner) SignBytes(data []byte, opts SignOptions) ([]byte, error) {
+	return signBytesWithStream(s.SignStream, data, opts)
+}
+
+// writeSignature writes a prehashed minisign signature of r to w. The trusted comment
+// records the signature time and, if fileName is set, the name of the signed file.
+func (s *MinisignSigner) writeSignature(r io.Reader, w io.Writer, fileName string, opts SignOptions) error {
+	hash, err := blake2b.New512(nil)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(hash, r); err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+
+	signature := ed25519.Sign(s.privateKey, hash.Sum(nil))
+
+	signedAt := opts.SignatureTime
+	if signedAt.IsZero() {
+		signedAt = time.Now()
+	}
+	trustedComment := fmt.Sprintf("timestamp:%d", signedAt.Unix())
+	if fileName != "" {
+		trustedComment += "\tfile:" + fileName
+	}
+	trustedComment += "\thashed"
+
+	globalSignature := ed25519.Sign(s.privateKey, append(bytes.Clone(signature), trustedComment...))
+
+	blob := bytes.Join([][]byte{[]byte(minisignAlgPrehashed), s.keyID[:], signature}, nil)
+	_, err = fmt.Fprintf(w, "%ssignature from minisign secret key\n%s\n%s%s\n%s\n",
+		minisignUntrustedComment,
+		base64.StdEncoding.EncodeToString(blob),
+		minisignTrustedComment, trustedComment,
+		base64.StdEncoding.EncodeToString(globalSignature),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to write signature: %w", err)
+	}
+	return nil
+}
+
+// Verify checks the minisign signature at sigPath for filePath, including its trusted comment.
+func (s *MinisignSigner) Verify(filePath, sigPath string, _ SignOptions) error {
+	content, err := os.ReadFile(sigPath)
+	if err != nil {
+		return fmt.Errorf("failed to read signature: %w", err)
+	}
+
+	lines := strings.Split(strings.TrimRight(string(content), "\n"), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[0], minisignUntrustedComment) || !strings.HasPrefix(lines[2], minisignTrustedComment) {
+		return fmt.Errorf("malformed minisign signature")
+	}
+
+	blob, err := base64.StdEncoding.DecodeString(lines[1])
+	if err != nil || len(blob) != minisignSignatureSize {
+		return fmt.Errorf("malformed minisign signature")
+	}
+	globalSignature, err := base64.StdEncoding.DecodeString(lines[3])
+	if err != nil || len(globalSignature) != ed25519.SignatureSize {
+		return fmt.Errorf("malformed minisign global signature")
+	}
+
+	algorithm, keyID, signature := string(blob[:2]), blob[2:2+minisignKeyIDSize], blob[2+minisignKeyIDSize:]
+	if !bytes.Equal(keyID, s.keyID[:]) {
+		return fmt.Errorf("signature was made by key %016X, not %s", binary.LittleEndian.Uint64(keyID), s.KeyID())
+	}
+
+	message, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	switch algorithm {
+	case minisignAlgPrehashed:
+		digest := blake2b.Sum512(message)
+		message = digest[:]
+	case minisignAlgEd25519:
+	default:
+		return fmt.Errorf("unsupported minisign signature algorithm %q", algorithm)
+	}
+
+	publicKey := s.privateKey.Public().(ed25519.PublicKey)
+	if !ed25519.Verify(publicKey, message, signature) {
+		return fmt.Errorf("signature is invalid")
+	}
+	trustedComment := strings.TrimPrefix(lines[2], minisignTrustedComment)
+	if !ed25519.Verify(publicKey, append(bytes.Clone(signature), trustedComment...), globalSignature) {
+		return fmt.Errorf("trusted comment signature is invalid")
+	}
+
+	return nil
+}
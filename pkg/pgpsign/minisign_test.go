@@ -0,0 +1,257 @@
+package pgpsign
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/scrypt"
+)
+
+// generateMinisignKey returns a new minisign secret key file and its public key. A non-empty
+// passphrase encrypts the key with the cheapest scrypt parameters minisign accepts.
+func generateMinisignKey(t *testing.T, passphrase string) (string, ed25519.PublicKey) {
+	t.Helper()
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	keyID := make([]byte, minisignKeyIDSize)
+	salt := make([]byte, minisignSaltSize)
+	rand.Read(keyID)
+	rand.Read(salt)
+
+	checksum := blake2b.Sum256(bytes.Join([][]byte{[]byte("Ed"), keyID, privateKey}, nil))
+	keyNum := bytes.Join([][]byte{keyID, privateKey, checksum[:]}, nil)
+
+	kdf := []byte{0, 0}
+	// opslimit 32768 and memlimit 1 MiB select N=1024, r=8, p=1.
+	opsLimit, memLimit := uint64(32768), uint64(1<<20)
+	if passphrase != "" {
+		kdf = []byte("Sc")
+		stream, err := scrypt.Key([]byte(passphrase), salt, 1024, 8, 1, len(keyNum))
+		if err != nil {
+			t.Fatalf("failed to derive key: %v", err)
+		}
+		for i := range keyNum {
+			keyNum[i] ^= stream[i]
+		}
+	}
+
+	limits := make([]byte, 16)
+	binary.LittleEndian.PutUint64(limits[:8], opsLimit)
+	binary.LittleEndian.PutUint64(limits[8:], memLimit)
+
+	data := bytes.Join([][]byte{[]byte("Ed"), kdf, []byte("B2"), salt, limits, keyNum}, nil)
+	return "untrusted comment: minisign encrypted secret key\n" + base64.StdEncoding.EncodeToString(data) + "\n", publicKey
+}
+
+// verifyMinisign checks a .minisig the way minisign -V does and returns its trusted comment.
+func verifyMinisign(publicKey ed25519.PublicKey, message, minisig []byte) (string, error) {
+	lines := strings.Split(strings.TrimSuffix(string(minisig), "\n"), "\n")
+	if len(lines) != 4 {
+		return "", fmt.Errorf("expected 4 lines, got %d", len(lines))
+	}
+	if !strings.HasPrefix(lines[0], "untrusted comment: ") {
+		return "", fmt.Errorf("missing untrusted comment")
+	}
+	trustedComment, ok := strings.CutPrefix(lines[2], "trusted comment: ")
+	if !ok {
+		return "", fmt.Errorf("missing trusted comment")
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(lines[1])
+	if err != nil || len(sig) != 74 {
+		return "", fmt.Errorf("invalid signature line")
+	}
+	if string(sig[:2]) != "ED" {
+		return "", fmt.Errorf("expected prehashed signature, got %q", sig[:2])
+	}
+	digest := blake2b.Sum512(message)
+	if !ed25519.Verify(publicKey, digest[:], sig[10:]) {
+		return "", fmt.Errorf("signature verification failed")
+	}
+
+	global, err := base64.StdEncoding.DecodeString(lines[3])
+	if err != nil {
+		return "", fmt.Errorf("invalid global signature line")
+	}
+	if !ed25519.Verify(publicKey, append(sig[10:], trustedComment...), global) {
+		return "", fmt.Errorf("trusted comment verification failed")
+	}
+
+	return trustedComment, nil
+}
+
+func TestNewMinisignSigner(t *testing.T) {
+	plain, _ := generateMinisignKey(t, "")
+	encrypted, _ := generateMinisignKey(t, "secret")
+
+	tests := []struct {
+		name        string
+		key         string
+		passphrase  string
+		errContains string
+	}{
+		{name: "unencrypted", key: plain},
+		{name: "encrypted", key: encrypted, passphrase: "secret"},
+		{name: "without comment", key: strings.SplitN(encrypted, "\n", 2)[1], passphrase: "secret"},
+		{name: "wrong passphrase", key: encrypted, passphrase: "wrong", errContains: "wrong passphrase"},
+		{name: "missing passphrase", key: encrypted, errContains: "no passphrase"},
+		{name: "not base64", key: "untrusted comment: x\n!!!", errContains: "failed to parse"},
+		{name: "wrong length", key: base64.StdEncoding.EncodeToString([]byte("EdB2")), errContains: "unexpected length"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewMinisignSigner(tt.key, tt.passphrase)
+			if tt.errContains == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
+
+func TestMinisignScryptParams(t *testing.T) {
+	tests := []struct {
+		name               string
+		opsLimit, memLimit uint64
+		n, r, p            int
+	}{
+		{name: "minisign default", opsLimit: 33554432, memLimit: 1073741824, n: 1 << 20, r: 8, p: 1},
+		{name: "minimum", opsLimit: 32768, memLimit: 1 << 20, n: 1024, r: 8, p: 1},
+		{name: "ops bound", opsLimit: 1 << 20, memLimit: 1 << 30, n: 1 << 15, r: 8, p: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, r, p := minisignScryptParams(tt.opsLimit, tt.memLimit)
+			if n != tt.n || r != tt.r || p != tt.p {
+				t.Errorf("got N=%d r=%d p=%d, want N=%d r=%d p=%d", n, r, p, tt.n, tt.r, tt.p)
+			}
+		})
+	}
+}
+
+func TestMinisignSigner_SignFile(t *testing.T) {
+	secretKey, publicKey := generateMinisignKey(t, "secret")
+	signer, err := NewMinisignSigner(secretKey, "secret")
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "release.tar.gz")
+	content := []byte("release content")
+	if err := os.WriteFile(file, content, 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	opts := SignOptions{DetachSign: true, Format: FormatMinisign, SignatureTime: time.Unix(1700000000, 0)}
+	result, err := signer.SignFile(file, opts)
+	if err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+	if result.OutputPath != file+".minisig" {
+		t.Errorf("expected signature %s.minisig, got %s", file, result.OutputPath)
+	}
+	if result.KeyID != signer.KeyID() {
+		t.Errorf("expected key ID %s, got %s", signer.KeyID(), result.KeyID)
+	}
+
+	minisig, err := os.ReadFile(result.OutputPath)
+	if err != nil {
+		t.Fatalf("failed to read signature: %v", err)
+	}
+	trustedComment, err := verifyMinisign(publicKey, content, minisig)
+	if err != nil {
+		t.Fatalf("reference verification failed: %v", err)
+	}
+	if want := "timestamp:1700000000\tfile:release.tar.gz\thashed"; trustedComment != want {
+		t.Errorf("expected trusted comment %q, got %q", want, trustedComment)
+	}
+
+	if err := signer.Verify(file, result.OutputPath, opts); err != nil {
+		t.Errorf("Verify failed: %v", err)
+	}
+	if err := os.WriteFile(file, []byte("tampered"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if err := signer.Verify(file, result.OutputPath, opts); err == nil {
+		t.Error("expected verification of a modified file to fail")
+	}
+}
+
+func TestMinisignSigner_SignStream(t *testing.T) {
+	secretKey, publicKey := generateMinisignKey(t, "")
+	signer, err := NewMinisignSigner(secretKey, "")
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+
+	var out bytes.Buffer
+	content := []byte("streamed content")
+	if err := signer.SignStream(bytes.NewReader(content), &out, SignOptions{Format: FormatMinisign}); err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+
+	trustedComment, err := verifyMinisign(publicKey, content, out.Bytes())
+	if err != nil {
+		t.Fatalf("reference verification failed: %v", err)
+	}
+	if strings.Contains(trustedComment, "file:") {
+		t.Errorf("expected no file name in the trusted comment of a stream, got %q", trustedComment)
+	}
+}
+
+func TestRunMinisign(t *testing.T) {
+	secretKey, publicKey := generateMinisignKey(t, "")
+
+	workDir := t.TempDir()
+	testFile := filepath.Join(workDir, "release.txt")
+	content := []byte("release content")
+	if err := os.WriteFile(testFile, content, 0o644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	args := Config{
+		PrivateKey:      secretKey,
+		Backend:         string(BackendGoPGP),
+		Format:          string(FormatMinisign),
+		Files:           "*.txt",
+		Armor:           true,
+		VerifyAfterSign: true,
+		WorkDir:         workDir,
+	}
+	if err := run(context.Background(), args, nil, nil, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	minisig, err := os.ReadFile(testFile + ".minisig")
+	if err != nil {
+		t.Fatalf("expected minisign signature: %v", err)
+	}
+	if _, err := verifyMinisign(publicKey, content, minisig); err != nil {
+		t.Errorf("reference verification failed: %v", err)
+	}
+	if _, err := os.Stat(testFile + ".asc"); err == nil {
+		t.Error("expected no PGP signature in minisign format")
+	}
+}
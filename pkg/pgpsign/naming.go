@@ -0,0 +1,70 @@
+package pgpsign
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// signatureNamePlaceholder matches the placeholders of a signature name template.
+var signatureNamePlaceholder = regexp.MustCompile(`\{[^{}]*\}`)
+
+// validateSignatureNaming checks the signature suffix and name template inputs.
+// Templates may only use the {name} and {ext} placeholders and must describe a
+// file name, so signatures always land in the directory chosen for them.
+func validateSignatureNaming(suffix, template string) error {
+	if suffix != "" && template != "" {
+		return fmt.Errorf("signature-suffix and signature-name cannot be used together")
+	}
+	if strings.ContainsAny(suffix, `/\`) {
+		return fmt.Errorf("invalid signature suffix %q: must not contain path separators", suffix)
+	}
+	if template == "" {
+		return nil
+	}
+
+	if strings.ContainsAny(template, `/\`) {
+		return fmt.Errorf("invalid signature name %q: must not contain path separators", template)
+	}
+	for _, placeholder := range signatureNamePlaceholder.FindAllString(template, -1) {
+		if placeholder != "{name}" && placeholder != "{ext}" {
+			return fmt.Errorf("invalid signature name %q: unknown placeholder %s (supported: {name}, {ext})", template, placeholder)
+		}
+	}
+
+	return nil
+}
+
+// normalizeSignatureSuffix adds the leading dot to a signature suffix given without one.
+func normalizeSignatureSuffix(suffix string) string {
+	if suffix == "" || strings.HasPrefix(suffix, ".") {
+		return suffix
+	}
+	return "." + suffix
+}
+
+// renderSignatureName renders the signature name template for the file named base.
+// {name} is base without its last extension and {ext} is that extension without the dot.
+// The key suffix is inserted before the last extension of the rendered name.
+func renderSignatureName(base string, opts SignOptions) (string, error) {
+	ext := filepath.Ext(base)
+	name := strings.NewReplacer(
+		"{name}", strings.TrimSuffix(base, ext),
+		"{ext}", strings.TrimPrefix(ext, "."),
+	).Replace(opts.SignatureName)
+
+	if opts.KeySuffix != "" {
+		last := filepath.Ext(name)
+		name = strings.TrimSuffix(name, last) + opts.KeySuffix + last
+	}
+
+	switch {
+	case name == "" || name == "." || name == "..":
+		return "", fmt.Errorf("signature name %q renders to %q for %s", opts.SignatureName, name, base)
+	case name == base:
+		return "", fmt.Errorf("signature name %q would overwrite %s", opts.SignatureName, base)
+	}
+
+	return name, nil
+}
@@ -0,0 +1,72 @@
+package pgpsign
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateSignatureNaming(t *testing.T) {
+	tests := []struct {
+		name        string
+		suffix      string
+		template    string
+		expectedErr string
+	}{
+		{name: "defaults"},
+		{name: "suffix", suffix: ".minisig"},
+		{name: "template", template: "{name}.{ext}.sig"},
+		{name: "suffix and template", suffix: ".sig", template: "{name}.sig", expectedErr: "cannot be used together"},
+		{name: "suffix with separator", suffix: "/x.sig", expectedErr: "path separators"},
+		{name: "template with directory", template: "../{name}.{ext}.sig", expectedErr: "path separators"},
+		{name: "unknown placeholder", template: "{base}.sig", expectedErr: "unknown placeholder {base}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSignatureNaming(tt.suffix, tt.template)
+			if tt.expectedErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestRenderSignatureName(t *testing.T) {
+	tests := []struct {
+		name        string
+		base        string
+		template    string
+		expected    string
+		expectedErr string
+	}{
+		{name: "name and extension", base: "app.tar.gz", template: "{name}.{ext}.sig", expected: "app.tar.gz.sig"},
+		{name: "file without extension", base: "app", template: "{name}.sig", expected: "app.sig"},
+		{name: "empty result", base: "app", template: "{ext}", expectedErr: "renders to"},
+		{name: "parent directory", base: "..app", template: "{name}", expectedErr: "renders to"},
+		{name: "overwrites input", base: "app.bin", template: "{name}.{ext}", expectedErr: "would overwrite"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := renderSignatureName(tt.base, SignOptions{SignatureName: tt.template})
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package pgpsign
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+)
+
+// Notation is a human-readable name/value pair embedded in every signature.
+type Notation struct {
+	Name  string
+	Value string
+}
+
+// parseNotations parses the notation input, one name=value pair per line.
+// Names must be user notation names of the form name@domain, as required by RFC 4880.
+func parseNotations(input string) ([]Notation, error) {
+	var notations []Notation
+	for _, line := range parseMultilineInput(input) {
+		name, value, ok := strings.Cut(line, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid notation %q: expected name@domain=value", line)
+		}
+		if strings.Count(name, "@") != 1 || strings.HasPrefix(name, "@") || strings.HasSuffix(name, "@") {
+			return nil, fmt.Errorf("invalid notation name %q: expected name@domain", name)
+		}
+		if strings.ContainsFunc(name, unicode.IsSpace) {
+			return nil, fmt.Errorf("invalid notation name %q: must not contain whitespace", name)
+		}
+		notations = append(notations, Notation{Name: name, Value: strings.TrimSpace(value)})
+	}
+	return notations, nil
+}
+
+// packetNotations converts notations to the signature subpackets added by go-crypto.
+func packetNotations(notations []Notation) []*packet.Notation {
+	result := make([]*packet.Notation, 0, len(notations))
+	for _, notation := range notations {
+		result = append(result, &packet.Notation{
+			Name:            notation.Name,
+			Value:           []byte(notation.Value),
+			IsHumanReadable: true,
+		})
+	}
+	return result
+}
@@ -0,0 +1,76 @@
+package pgpsign
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseNotations(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expected    []Notation
+		expectedErr string
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name:  "several pairs",
+			input: "build-id@ci.example.com=1234\n\n  commit@ci.example.com = abc=def  \n",
+			expected: []Notation{
+				{Name: "build-id@ci.example.com", Value: "1234"},
+				{Name: "commit@ci.example.com", Value: "abc=def"},
+			},
+		},
+		{
+			name:     "empty value",
+			input:    "flag@ci.example.com=",
+			expected: []Notation{{Name: "flag@ci.example.com", Value: ""}},
+		},
+		{
+			name:        "missing equals sign",
+			input:       "build-id@ci.example.com",
+			expectedErr: `invalid notation "build-id@ci.example.com"`,
+		},
+		{
+			name:        "missing name",
+			input:       "=1234",
+			expectedErr: "expected name@domain=value",
+		},
+		{
+			name:        "name without domain",
+			input:       "build-id=1234",
+			expectedErr: `invalid notation name "build-id"`,
+		},
+		{
+			name:        "name with two at signs",
+			input:       "a@b@c=1",
+			expectedErr: `invalid notation name "a@b@c"`,
+		},
+		{
+			name:        "name with whitespace",
+			input:       "build id@ci=1",
+			expectedErr: "must not contain whitespace",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notations, err := parseNotations(tt.input)
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(notations, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, notations)
+			}
+		})
+	}
+}
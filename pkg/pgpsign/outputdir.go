@@ -0,0 +1,129 @@
+package pgpsign
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// checkOutputPaths makes sure every file gets its own signature path before anything is signed.
+// It rejects files that cannot be mirrored below the output directory, files sharing a base
+// name in a flat output directory, name templates that render to an invalid name, and
+// signatures that would overwrite each other or one of the files being signed. Default names
+// next to their file need no check.
+func checkOutputPaths(files []string, keys []signingKey) error {
+	inputs := make(map[string]bool, len(files))
+	for _, file := range files {
+		inputs[filepath.Clean(file)] = true
+	}
+
+	outputs := make(map[string]string)
+	for _, file := range files {
+		for _, key := range keys {
+			if key.opts.OutputDir == "" && key.opts.SignatureName == "" {
+				continue
+			}
+			if err := checkOutputTarget(file, key.opts); err != nil {
+				return err
+			}
+
+			outputPath := filepath.Clean(getOutputPath(file, key.opts))
+			if other, ok := outputs[outputPath]; ok {
+				if key.opts.FlatOutput && filepath.Base(other) == filepath.Base(file) {
+					return fmt.Errorf("files %s and %s share the base name %s and cannot both be signed with signature-layout flat", other, file, filepath.Base(file))
+				}
+				return fmt.Errorf("signatures for %s and %s would both be written to %s", other, file, outputPath)
+			}
+			if inputs[outputPath] {
+				return fmt.Errorf("signature for %s would overwrite the signed file %s", file, outputPath)
+			}
+			outputs[outputPath] = file
+		}
+	}
+
+	return nil
+}
+
+// checkOutputTarget reports whether a signature path can be derived for file.
+func checkOutputTarget(file string, opts SignOptions) error {
+	if opts.OutputDir != "" && !opts.FlatOutput {
+		if _, ok := relativeToRoot(opts.BaseDirs, file); !ok {
+			return fmt.Errorf("file %s is outside the working directory and cannot be mirrored into output-dir", file)
+		}
+	}
+	if opts.SignatureName != "" {
+		if _, err := renderSignatureName(filepath.Base(file), opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SignatureLayout selects where signatures are written relative to the signed files.
+type SignatureLayout string
+
+const (
+	// LayoutInPlace writes every signature next to its file.
+	LayoutInPlace SignatureLayout = "inplace"
+	// LayoutMirror mirrors the paths of the signed files below the output directory.
+	LayoutMirror SignatureLayout = "mirror"
+	// LayoutFlat writes all signatures directly into the output directory.
+	LayoutFlat SignatureLayout = "flat"
+)
+
+// parseSignatureLayout parses the signature-layout input. An empty value selects
+// LayoutMirror if an output directory is set and LayoutInPlace otherwise.
+func parseSignatureLayout(value, outputDir string) (SignatureLayout, error) {
+	layout := SignatureLayout(strings.ToLower(strings.TrimSpace(value)))
+	switch layout {
+	case "":
+		if outputDir != "" {
+			return LayoutMirror, nil
+		}
+		return LayoutInPlace, nil
+	case LayoutInPlace:
+		if outputDir != "" {
+			return "", fmt.Errorf("signature-layout inplace cannot be combined with output-dir")
+		}
+		return layout, nil
+	case LayoutMirror, LayoutFlat:
+		if outputDir == "" {
+			return "", fmt.Errorf("signature-layout %s requires output-dir", layout)
+		}
+		return layout, nil
+	default:
+		return "", fmt.Errorf("unknown signature-layout %q, expected inplace, mirror or flat", value)
+	}
+}
+
+// applySignatureLayout sets the output directory of opts from the output-dir and
+// signature-layout inputs.
+func applySignatureLayout(opts *SignOptions, args Config, workDirs []string) error {
+	layout, err := parseSignatureLayout(args.SignatureLayout, args.OutputDir)
+	if err != nil {
+		return err
+	}
+	if layout == LayoutInPlace {
+		return nil
+	}
+
+	opts.OutputDir = resolvePath(workDirs[0], args.OutputDir)
+	opts.BaseDirs = workDirs
+	opts.FlatOutput = layout == LayoutFlat
+	return nil
+}
+
+// prepareOutputDir creates the directory the signature for filePath is written to.
+func prepareOutputDir(filePath string, opts SignOptions) error {
+	if opts.OutputDir == "" {
+		return nil
+	}
+
+	dir := filepath.Dir(getOutputPath(filePath, opts))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create output directory %s: %w", dir, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,224 @@
+package pgpsign
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunOutputDir(t *testing.T) {
+	privateKey := generateTestKeyArmored(t, "Test User", "test@example.com", "")
+	workDir := t.TempDir()
+
+	// Two inputs share a base name from different subdirectories.
+	inputs := []string{"app.tar.gz", "linux/app.bin", "darwin/app.bin"}
+	for _, name := range inputs {
+		path := filepath.Join(workDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("content of "+name), 0o644); err != nil {
+			t.Fatalf("failed to create test file: %v", err)
+		}
+	}
+
+	args := Config{
+		PrivateKey:   privateKey,
+		Files:        "*.tar.gz\n*/*.bin",
+		Armor:        true,
+		DetachSign:   true,
+		WorkDir:      workDir,
+		OutputDir:    "dist/signatures",
+		Backend:      "gopgp",
+		BundleFormat: string(BundleSigstorePGP),
+	}
+
+	if err := run(context.Background(), args, nil, nil, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	outputDir := filepath.Join(workDir, "dist", "signatures")
+	for _, name := range inputs {
+		base := filepath.Join(outputDir, filepath.FromSlash(name))
+		for _, ext := range []string{".asc", ".sigstore.json"} {
+			if _, err := os.Stat(base + ext); err != nil {
+				t.Errorf("expected %s in output directory: %v", name+ext, err)
+			}
+		}
+		if _, err := os.Stat(filepath.Join(workDir, filepath.FromSlash(name)) + ".asc"); !os.IsNotExist(err) {
+			t.Errorf("expected no signature next to %s", name)
+		}
+	}
+}
+
+func TestCheckOutputPaths(t *testing.T) {
+	workDir := t.TempDir()
+	opts := SignOptions{
+		Armor:      true,
+		DetachSign: true,
+		OutputDir:  filepath.Join(workDir, "sigs"),
+		BaseDirs:   []string{workDir},
+	}
+
+	tests := []struct {
+		name        string
+		files       []string
+		opts        SignOptions
+		expectedErr string
+	}{
+		{
+			name:  "files below the working directory",
+			files: []string{filepath.Join(workDir, "a", "app"), filepath.Join(workDir, "b", "app")},
+			opts:  opts,
+		},
+		{
+			name:        "file outside the working directory",
+			files:       []string{filepath.Join(filepath.Dir(workDir), "outside.txt")},
+			opts:        opts,
+			expectedErr: "outside the working directory",
+		},
+		{
+			name:        "signature overwrites a signed file",
+			files:       []string{filepath.Join(workDir, "app"), filepath.Join(workDir, "sigs", "app.asc")},
+			opts:        opts,
+			expectedErr: "would overwrite the signed file",
+		},
+		{
+			name:  "in-place signatures are not checked",
+			files: []string{filepath.Join(filepath.Dir(workDir), "outside.txt")},
+			opts:  SignOptions{Armor: true, DetachSign: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkOutputPaths(tt.files, []signingKey{{opts: tt.opts}})
+			if tt.expectedErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestParseSignatureLayout(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		outputDir   string
+		expected    SignatureLayout
+		expectedErr string
+	}{
+		{name: "default without output-dir", expected: LayoutInPlace},
+		{name: "default with output-dir", outputDir: "sigs", expected: LayoutMirror},
+		{name: "inplace", value: "inplace", expected: LayoutInPlace},
+		{name: "mirror", value: "Mirror", outputDir: "sigs", expected: LayoutMirror},
+		{name: "flat", value: " flat ", outputDir: "sigs", expected: LayoutFlat},
+		{name: "inplace with output-dir", value: "inplace", outputDir: "sigs", expectedErr: "cannot be combined with output-dir"},
+		{name: "flat without output-dir", value: "flat", expectedErr: "signature-layout flat requires output-dir"},
+		{name: "unknown", value: "tree", outputDir: "sigs", expectedErr: "unknown signature-layout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			layout, err := parseSignatureLayout(tt.value, tt.outputDir)
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if layout != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, layout)
+			}
+		})
+	}
+}
+
+func TestRunSignatureLayout(t *testing.T) {
+	privateKey := generateTestKeyArmored(t, "Test User", "test@example.com", "")
+
+	tests := []struct {
+		name        string
+		layout      string
+		outputDir   string
+		files       []string
+		expected    []string
+		expectedErr string
+	}{
+		{
+			name:     "inplace",
+			layout:   "inplace",
+			files:    []string{"app.tar.gz", "bin/linux/app", "docs/README.md"},
+			expected: []string{"app.tar.gz.asc", "bin/linux/app.asc", "docs/README.md.asc"},
+		},
+		{
+			name:      "mirror",
+			layout:    "mirror",
+			outputDir: "signatures",
+			files:     []string{"app.tar.gz", "bin/linux/app", "docs/README.md"},
+			expected:  []string{"signatures/app.tar.gz.asc", "signatures/bin/linux/app.asc", "signatures/docs/README.md.asc"},
+		},
+		{
+			name:      "flat",
+			layout:    "flat",
+			outputDir: "signatures",
+			files:     []string{"app.tar.gz", "bin/linux/app", "docs/README.md"},
+			expected:  []string{"signatures/app.tar.gz.asc", "signatures/app.asc", "signatures/README.md.asc"},
+		},
+		{
+			name:        "flat with base name collision",
+			layout:      "flat",
+			outputDir:   "signatures",
+			files:       []string{"bin/linux/app", "bin/darwin/app"},
+			expectedErr: "share the base name app",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workDir := t.TempDir()
+			writeTestFiles(t, workDir, tt.files...)
+
+			args := Config{
+				PrivateKey:      privateKey,
+				Files:           "**/*",
+				Armor:           true,
+				DetachSign:      true,
+				WorkDir:         workDir,
+				OutputDir:       tt.outputDir,
+				SignatureLayout: tt.layout,
+				Backend:         string(BackendGoPGP),
+			}
+			err := run(context.Background(), args, nil, nil, nil)
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectedErr, err)
+				}
+				if _, statErr := os.Stat(filepath.Join(workDir, tt.outputDir)); !os.IsNotExist(statErr) {
+					t.Errorf("expected nothing to be signed before the collision is reported")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			for _, name := range tt.expected {
+				if _, err := os.Stat(filepath.Join(workDir, filepath.FromSlash(name))); err != nil {
+					t.Errorf("expected signature %s: %v", name, err)
+				}
+			}
+		})
+	}
+}
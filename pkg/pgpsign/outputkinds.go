@@ -0,0 +1,98 @@
+package pgpsign
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+)
+
+// OutputKind is a kind of signature written for every signed file.
+type OutputKind string
+
+const (
+	OutputDetached OutputKind = "detached"
+	OutputClear    OutputKind = "clear"
+	OutputInline   OutputKind = "inline"
+)
+
+// resolveOutputKinds parses the sign-modes input. It returns nil if the input is empty,
+// in which case detach-sign and clear-sign select the single kind of signature.
+func resolveOutputKinds(args Config) ([]OutputKind, error) {
+	var kinds []OutputKind
+	for _, field := range strings.FieldsFunc(args.SignModes, func(r rune) bool { return r == ',' || r == '\n' }) {
+		kind := OutputKind(strings.ToLower(strings.TrimSpace(field)))
+		switch kind {
+		case "":
+			continue
+		case OutputDetached, OutputClear, OutputInline:
+		default:
+			return nil, fmt.Errorf("unknown sign mode %q: expected detached, clear or inline", field)
+		}
+		if !slices.Contains(kinds, kind) {
+			kinds = append(kinds, kind)
+		}
+	}
+	if len(kinds) == 0 {
+		return nil, nil
+	}
+
+	if args.DetachSign || args.ClearSign {
+		return nil, fmt.Errorf("sign-modes cannot be combined with detach-sign or clear-sign")
+	}
+	if args.TarMembers {
+		return nil, fmt.Errorf("tar-members mode does not support sign-modes")
+	}
+	if len(kinds) > 1 && args.SignatureSuffix != "" {
+		return nil, fmt.Errorf("signature-suffix cannot be combined with several sign modes, as all signatures of a file would share one name")
+	}
+
+	return kinds, nil
+}
+
+// apply returns opts configured to create this kind of signature.
+// With several kinds, outputs are named by kind instead of by armor so they do not collide.
+func (k OutputKind) apply(opts SignOptions, several bool) SignOptions {
+	opts.DetachSign = k == OutputDetached
+	opts.ClearSign = k == OutputClear
+	opts.SidecarHeader = opts.SidecarHeader && opts.DetachSign
+	opts.NameByKind = several
+	return opts
+}
+
+// expandOutputKinds returns one signing key per key and kind, so every file gets each kind of signature.
+func expandOutputKinds(keys []signingKey, kinds []OutputKind) []signingKey {
+	if len(kinds) == 0 {
+		return keys
+	}
+
+	expanded := make([]signingKey, 0, len(keys)*len(kinds))
+	for _, key := range keys {
+		for _, kind := range kinds {
+			kindKey := key
+			kindKey.opts = kind.apply(key.opts, len(kinds) > 1)
+			expanded = append(expanded, kindKey)
+		}
+	}
+	return expanded
+}
+
+// bundleFormatFor returns the bundle format for signatures made with opts.
+// When several kinds include detached signatures, only those are bundled.
+func bundleFormatFor(format BundleFormat, kinds []OutputKind, opts SignOptions) BundleFormat {
+	if len(kinds) > 1 && !opts.DetachSign && slices.Contains(kinds, OutputDetached) {
+		return BundleNone
+	}
+	return format
+}
+
+// logSignModes logs the signing mode of every kind of signature that is created.
+func logSignModes(opts SignOptions, kinds []OutputKind, log *slog.Logger) {
+	if len(kinds) == 0 {
+		logSignMode(opts, log)
+		return
+	}
+	for _, kind := range kinds {
+		logSignMode(kind.apply(opts, len(kinds) > 1), log)
+	}
+}
@@ -0,0 +1,144 @@
+package pgpsign
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestResolveOutputKinds(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        Config
+		expected    []OutputKind
+		expectedErr string
+	}{
+		{
+			name: "empty",
+			args: Config{},
+		},
+		{
+			name:     "comma separated",
+			args:     Config{SignModes: "clear, detached"},
+			expected: []OutputKind{OutputClear, OutputDetached},
+		},
+		{
+			name:     "newline separated with duplicates",
+			args:     Config{SignModes: "Detached\ninline\ndetached\n"},
+			expected: []OutputKind{OutputDetached, OutputInline},
+		},
+		{
+			name:        "unknown mode",
+			args:        Config{SignModes: "clear,encrypted"},
+			expectedErr: `unknown sign mode "encrypted"`,
+		},
+		{
+			name:        "combined with detach-sign",
+			args:        Config{SignModes: "clear", DetachSign: true},
+			expectedErr: "cannot be combined with detach-sign or clear-sign",
+		},
+		{
+			name:        "several modes with signature suffix",
+			args:        Config{SignModes: "clear,detached", SignatureSuffix: ".sign"},
+			expectedErr: "signature-suffix cannot be combined",
+		},
+		{
+			name:     "single mode with signature suffix",
+			args:     Config{SignModes: "detached", SignatureSuffix: ".sign"},
+			expected: []OutputKind{OutputDetached},
+		},
+		{
+			name:        "tar members",
+			args:        Config{SignModes: "detached", TarMembers: true},
+			expectedErr: "does not support sign-modes",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kinds, err := resolveOutputKinds(tt.args)
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(kinds, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, kinds)
+			}
+		})
+	}
+}
+
+func TestRunSignModes(t *testing.T) {
+	workDir := t.TempDir()
+	testFile := filepath.Join(workDir, "release.txt")
+	if err := os.WriteFile(testFile, []byte("release notes\n"), 0o644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	args := Config{
+		PrivateKey:    generateTestKeyArmored(t, "Test User", "test@example.com", ""),
+		Files:         "*.txt",
+		Armor:         false,
+		SignModes:     "clear,detached",
+		SignAndVerify: true,
+		WorkDir:       workDir,
+		Backend:       string(BackendGoPGP),
+	}
+	if err := run(context.Background(), args, nil, nil, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	clearSigned, err := os.ReadFile(testFile + ".asc")
+	if err != nil {
+		t.Fatalf("expected clear-signed file: %v", err)
+	}
+	if !strings.HasPrefix(string(clearSigned), "-----BEGIN PGP SIGNED MESSAGE-----") {
+		t.Errorf("expected a clear-signed message in release.txt.asc, got %q", clearSigned)
+	}
+
+	detached, err := os.ReadFile(testFile + ".sig")
+	if err != nil {
+		t.Fatalf("expected detached signature: %v", err)
+	}
+	if strings.Contains(string(detached), "-----BEGIN") {
+		t.Error("expected a binary detached signature in release.txt.sig")
+	}
+}
+
+func TestRunSignModesArmoredBundle(t *testing.T) {
+	workDir := t.TempDir()
+	testFile := filepath.Join(workDir, "release.txt")
+	if err := os.WriteFile(testFile, []byte("release notes\n"), 0o644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	args := Config{
+		PrivateKey:   generateTestKeyArmored(t, "Test User", "test@example.com", ""),
+		Files:        "*.txt",
+		Armor:        true,
+		SignModes:    "inline,detached",
+		BundleFormat: string(BundleSigstorePGP),
+		WorkDir:      workDir,
+		Backend:      string(BackendGoPGP),
+	}
+	if err := run(context.Background(), args, nil, nil, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, name := range []string{"release.txt.gpg", "release.txt.sig", "release.txt.sigstore.json"} {
+		if _, err := os.Stat(filepath.Join(workDir, name)); err != nil {
+			t.Errorf("expected %s: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(workDir, "release.txt.asc")); !os.IsNotExist(err) {
+		t.Errorf("expected no release.txt.asc, got %v", err)
+	}
+}
@@ -0,0 +1,36 @@
+package pgpsign
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// defaultOutputMode is the permission of written signatures unless output-mode is set.
+const defaultOutputMode os.FileMode = 0o644
+
+// parseOutputMode parses the output-mode input, octal permission bits such as 0600.
+// An empty value selects defaultOutputMode.
+func parseOutputMode(value string) (os.FileMode, error) {
+	if value == "" {
+		return defaultOutputMode, nil
+	}
+
+	mode, err := strconv.ParseUint(strings.TrimPrefix(value, "0o"), 8, 32)
+	if err != nil || mode > 0o777 {
+		return 0, fmt.Errorf("invalid output-mode %q: expected octal permission bits such as 0644", value)
+	}
+	if mode&0o400 == 0 {
+		return 0, fmt.Errorf("invalid output-mode %q: signatures must be readable by their owner", value)
+	}
+	return os.FileMode(mode), nil
+}
+
+// outputMode returns the permission bits of files written with opts.
+func (o SignOptions) outputMode() os.FileMode {
+	if o.OutputMode == 0 {
+		return defaultOutputMode
+	}
+	return o.OutputMode
+}
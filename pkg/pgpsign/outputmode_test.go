@@ -0,0 +1,102 @@
+package pgpsign
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestParseOutputMode(t *testing.T) {
+	tests := []struct {
+		value       string
+		expected    os.FileMode
+		errContains string
+	}{
+		{value: "", expected: 0o644},
+		{value: "0644", expected: 0o644},
+		{value: "600", expected: 0o600},
+		{value: "0o660", expected: 0o660},
+		{value: "0755", expected: 0o755},
+		{value: "0988", errContains: "expected octal permission bits"},
+		{value: "rw-r--r--", errContains: "expected octal permission bits"},
+		{value: "01777", errContains: "expected octal permission bits"},
+		{value: "0044", errContains: "readable by their owner"},
+		{value: "0", errContains: "readable by their owner"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			mode, err := parseOutputMode(tt.value)
+			if tt.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Fatalf("expected error containing %q, got %v", tt.errContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if mode != tt.expected {
+				t.Errorf("expected mode %o, got %o", tt.expected, mode)
+			}
+		})
+	}
+}
+
+func TestRunOutputMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permission bits are not meaningful on windows")
+	}
+
+	armoredKey := generateTestKeyArmored(t, "Test", "test@test.com", "")
+	signer, err := NewGoPGPSigner(armoredKey, "", "")
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		outputMode string
+		expected   os.FileMode
+	}{
+		{name: "default", expected: 0o644},
+		{name: "owner only", outputMode: "0600", expected: 0o600},
+		{name: "group writable", outputMode: "0664", expected: 0o664},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workDir := t.TempDir()
+			file := filepath.Join(workDir, "a.txt")
+			if err := os.WriteFile(file, []byte("content"), 0o644); err != nil {
+				t.Fatalf("failed to create test file: %v", err)
+			}
+
+			args := Config{
+				PrivateKey:   "key",
+				Files:        "*.txt",
+				Armor:        true,
+				DetachSign:   true,
+				WorkDir:      workDir,
+				OutputMode:   tt.outputMode,
+				BundleFormat: string(BundleSigstorePGP),
+			}
+			if err := run(context.Background(), args, signer, &MockFileFinder{Files: []string{file}}, nil); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			for _, output := range []string{file + ".asc", file + sigstoreBundleExtension} {
+				info, err := os.Stat(output)
+				if err != nil {
+					t.Fatalf("expected %s to be written: %v", output, err)
+				}
+				if info.Mode().Perm() != tt.expected {
+					t.Errorf("expected %s to have mode %o, got %o", filepath.Base(output), tt.expected, info.Mode().Perm())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package pgpsign
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// stdinPassphrase is the passphrase-file value that reads the passphrase from stdin.
+const stdinPassphrase = "-"
+
+// passphraseStdin is the reader stdin passphrases are read from; tests replace it.
+var passphraseStdin io.Reader = os.Stdin
+
+// resolvePassphrase returns the passphrase from the passphrase, passphrase-file or
+// passphrase-fd input. At most one of them may be set. Trailing line breaks are
+// removed from passphrases read from a file, file descriptor or stdin.
+func resolvePassphrase(args Config) (string, error) {
+	sources := 0
+	for _, set := range []bool{args.Passphrase != "", args.PassphraseFile != "", args.PassphraseFD != nil} {
+		if set {
+			sources++
+		}
+	}
+	if sources > 1 {
+		return "", fmt.Errorf("only one of passphrase, passphrase-file and passphrase-fd may be set")
+	}
+
+	switch {
+	case args.PassphraseFile == stdinPassphrase:
+		return readPassphrase(passphraseStdin, "stdin")
+	case args.PassphraseFile != "":
+		f, err := os.Open(args.PassphraseFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to open passphrase file: %w", err)
+		}
+		defer f.Close()
+		return readPassphrase(f, "passphrase file "+args.PassphraseFile)
+	case args.PassphraseFD != nil:
+		return readPassphraseFD(*args.PassphraseFD)
+	default:
+		return args.Passphrase, nil
+	}
+}
+
+// readPassphraseFD reads the passphrase from the file descriptor fd, where 0 is stdin.
+func readPassphraseFD(fd int) (string, error) {
+	if fd < 0 {
+		return "", fmt.Errorf("passphrase-fd must not be negative, got %d", fd)
+	}
+	if fd == 0 {
+		return readPassphrase(passphraseStdin, "stdin")
+	}
+
+	f := os.NewFile(uintptr(fd), "passphrase-fd")
+	if f == nil {
+		return "", fmt.Errorf("passphrase-fd %d is not a valid file descriptor", fd)
+	}
+	defer f.Close()
+	return readPassphrase(f, fmt.Sprintf("file descriptor %d", fd))
+}
+
+// readPassphrase reads a passphrase from r and rejects empty input.
+// source names where the passphrase comes from in errors; the passphrase itself never appears in them.
+func readPassphrase(r io.Reader, source string) (string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("failed to read passphrase from %s: %w", source, err)
+	}
+
+	passphrase := strings.TrimRight(string(data), "\r\n")
+	if passphrase == "" {
+		return "", fmt.Errorf("passphrase from %s is empty", source)
+	}
+	return passphrase, nil
+}
+
+// gpgPassphraseFile returns the absolute passphrase-file path the gnupg backend hands
+// to gpg, or "" if the passphrase does not come from a regular file.
+func gpgPassphraseFile(args Config) (string, error) {
+	if args.PassphraseFile == "" || args.PassphraseFile == stdinPassphrase {
+		return "", nil
+	}
+	path, err := filepath.Abs(args.PassphraseFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve passphrase file: %w", err)
+	}
+	return path, nil
+}
+
+// hasPassphrase reports whether any passphrase input is set.
+func hasPassphrase(args Config) bool {
+	return args.Passphrase != "" || args.PassphraseFile != "" || args.PassphraseFD != nil
+}
@@ -0,0 +1,148 @@
+package pgpsign
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolvePassphrase(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		return path
+	}
+	passphraseFile := writeFile("passphrase", "file secret\n")
+	crlfFile := writeFile("crlf", "crlf secret\r\n")
+	emptyFile := writeFile("empty", "\n")
+	stdinFD := 0
+	negativeFD := -1
+
+	tests := []struct {
+		name        string
+		args        Config
+		stdin       string
+		expected    string
+		expectedErr string
+	}{
+		{
+			name:     "no passphrase",
+			args:     Config{},
+			expected: "",
+		},
+		{
+			name:     "inline passphrase",
+			args:     Config{Passphrase: "inline secret\n"},
+			expected: "inline secret\n",
+		},
+		{
+			name:     "passphrase file",
+			args:     Config{PassphraseFile: passphraseFile},
+			expected: "file secret",
+		},
+		{
+			name:     "passphrase file with CRLF",
+			args:     Config{PassphraseFile: crlfFile},
+			expected: "crlf secret",
+		},
+		{
+			name:     "passphrase file from stdin",
+			args:     Config{PassphraseFile: "-"},
+			stdin:    "stdin secret\n",
+			expected: "stdin secret",
+		},
+		{
+			name:     "passphrase fd 0 reads stdin",
+			args:     Config{PassphraseFD: &stdinFD},
+			stdin:    "fd secret",
+			expected: "fd secret",
+		},
+		{
+			name:        "empty passphrase file",
+			args:        Config{PassphraseFile: emptyFile},
+			expectedErr: "is empty",
+		},
+		{
+			name:        "empty stdin",
+			args:        Config{PassphraseFile: "-"},
+			expectedErr: "passphrase from stdin is empty",
+		},
+		{
+			name:        "missing passphrase file",
+			args:        Config{PassphraseFile: filepath.Join(dir, "missing")},
+			expectedErr: "failed to open passphrase file",
+		},
+		{
+			name:        "negative fd",
+			args:        Config{PassphraseFD: &negativeFD},
+			expectedErr: "must not be negative",
+		},
+		{
+			name:        "multiple sources",
+			args:        Config{Passphrase: "inline", PassphraseFile: passphraseFile},
+			expectedErr: "only one of passphrase, passphrase-file and passphrase-fd",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := passphraseStdin
+			passphraseStdin = strings.NewReader(tt.stdin)
+			t.Cleanup(func() { passphraseStdin = original })
+
+			passphrase, err := resolvePassphrase(tt.args)
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if passphrase != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, passphrase)
+			}
+		})
+	}
+}
+
+func TestGPGPassphraseFile(t *testing.T) {
+	if path, _ := gpgPassphraseFile(Config{PassphraseFile: "-"}); path != "" {
+		t.Errorf("expected no gpg passphrase file for stdin, got %q", path)
+	}
+
+	path, err := gpgPassphraseFile(Config{PassphraseFile: "passphrase.txt"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !filepath.IsAbs(path) || filepath.Base(path) != "passphrase.txt" {
+		t.Errorf("expected absolute path to passphrase.txt, got %q", path)
+	}
+}
+
+func TestNewSigningKeysFromInputs_PassphraseFile(t *testing.T) {
+	passphraseFile := filepath.Join(t.TempDir(), "passphrase")
+	if err := os.WriteFile(passphraseFile, []byte("secret\n"), 0o600); err != nil {
+		t.Fatalf("failed to write passphrase file: %v", err)
+	}
+
+	args := Config{
+		PrivateKey:     generateTestKeyArmored(t, "Test", "test@test.com", "secret"),
+		PassphraseFile: passphraseFile,
+		Backend:        string(BackendGoPGP),
+	}
+	keys, err := newSigningKeysFromInputs(context.Background(), args, SignOptions{}, slog.New(slog.DiscardHandler))
+	if err != nil {
+		t.Fatalf("failed to unlock key with passphrase file: %v", err)
+	}
+	if len(keys) != 1 {
+		t.Errorf("expected 1 signing key, got %d", len(keys))
+	}
+}
@@ -0,0 +1,46 @@
+package pgpsign
+
+import (
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+// progressTracker logs how many files have been processed and estimates the remaining time.
+// It is safe for concurrent use, and its methods do nothing on a nil tracker.
+type progressTracker struct {
+	total int
+	start time.Time
+	done  atomic.Int64
+	log   *slog.Logger
+}
+
+// newProgressTracker returns a tracker for total files that starts timing now.
+func newProgressTracker(total int, log *slog.Logger) *progressTracker {
+	return &progressTracker{total: total, start: time.Now(), log: log}
+}
+
+// fileDone records that file has been processed, successfully or not, and logs the progress
+// as a [done/total] counter with the attributes grouped under "progress".
+func (p *progressTracker) fileDone(file string) {
+	if p == nil {
+		return
+	}
+
+	done := int(p.done.Add(1))
+	elapsed := time.Since(p.start)
+	rate := float64(done) / max(elapsed.Seconds(), 1e-9)
+	eta := time.Duration(float64(elapsed) / float64(done) * float64(p.total-done))
+
+	p.log.Info(fmt.Sprintf("[%d/%d] Processed file", done, p.total),
+		slog.String("file", file),
+		slog.Group("progress",
+			slog.Int("done", done),
+			slog.Int("total", p.total),
+			slog.String("rate", fmt.Sprintf("%.1f files/s", rate)),
+			slog.Duration("elapsed", elapsed.Round(time.Millisecond)),
+			slog.Duration("eta", eta.Round(time.Second)),
+		),
+	)
+}
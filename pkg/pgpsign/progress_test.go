@@ -0,0 +1,71 @@
+package pgpsign
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunProgress(t *testing.T) {
+	workDir := t.TempDir()
+	const total = 6
+	for i := range total {
+		if err := os.WriteFile(filepath.Join(workDir, fmt.Sprintf("file%d.bin", i)), []byte("data"), 0o644); err != nil {
+			t.Fatalf("failed to create file: %v", err)
+		}
+	}
+
+	for _, concurrency := range []int{1, 3} {
+		t.Run(fmt.Sprintf("concurrency %d", concurrency), func(t *testing.T) {
+			var buf bytes.Buffer
+			log := slog.New(slog.NewTextHandler(&buf, nil))
+
+			args := Config{
+				PrivateKey:  "unused",
+				Files:       "*.bin",
+				DetachSign:  true,
+				WorkDir:     workDir,
+				Concurrency: concurrency,
+				Progress:    true,
+			}
+			if err := run(context.Background(), args, &MockSigner{}, nil, log); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			output := buf.String()
+			for done := 1; done <= total; done++ {
+				counter := fmt.Sprintf("[%d/%d]", done, total)
+				if n := strings.Count(output, counter); n != 1 {
+					t.Errorf("expected %s once, found it %d times", counter, n)
+				}
+				if !strings.Contains(output, fmt.Sprintf("progress.done=%d progress.total=%d", done, total)) {
+					t.Errorf("expected progress attributes for file %d in:\n%s", done, output)
+				}
+			}
+			if !strings.Contains(output, "progress.eta=0s") {
+				t.Errorf("expected an ETA of zero after the last file in:\n%s", output)
+			}
+		})
+	}
+}
+
+func TestRunWithoutProgress(t *testing.T) {
+	workDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(workDir, "file.bin"), []byte("data"), 0o644); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+
+	var buf bytes.Buffer
+	args := Config{PrivateKey: "unused", Files: "*.bin", DetachSign: true, WorkDir: workDir}
+	if err := run(context.Background(), args, &MockSigner{}, nil, slog.New(slog.NewTextHandler(&buf, nil))); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(buf.String(), "progress.") {
+		t.Errorf("expected no progress output by default, got:\n%s", buf.String())
+	}
+}
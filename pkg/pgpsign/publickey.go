@@ -0,0 +1,58 @@
+package pgpsign
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/ProtonMail/gopenpgp/v3/crypto"
+)
+
+// PublicKeyExporter is implemented by signers that can export their public key in armored form.
+type PublicKeyExporter interface {
+	ExportPublicKey() (string, error)
+}
+
+// exportPublicKeys writes the armored public key of every signing key to the export-public-key
+// path and sets the public-key-fingerprint output. Nothing is written in a dry run.
+func exportPublicKeys(args Config, keys []signingKey, workDir string, log *slog.Logger) error {
+	if args.ExportPublicKey == "" || args.DryRun {
+		return nil
+	}
+
+	var armored, fingerprints []string
+	seen := make(map[Signer]bool)
+	for _, key := range keys {
+		// Keys expanded for several sign modes share their signer.
+		if seen[key.signer] {
+			continue
+		}
+		seen[key.signer] = true
+
+		exporter, ok := key.signer.(PublicKeyExporter)
+		if !ok {
+			return fmt.Errorf("signer does not support exporting its public key")
+		}
+		publicKey, err := exporter.ExportPublicKey()
+		if err != nil {
+			return fmt.Errorf("failed to export public key: %w", err)
+		}
+
+		// The fingerprint is read back from the export, so it always describes the written key.
+		exported, err := crypto.NewKeyFromArmored(publicKey)
+		if err != nil {
+			return fmt.Errorf("failed to parse exported public key: %w", err)
+		}
+		armored = append(armored, strings.TrimRight(publicKey, "\n")+"\n")
+		fingerprints = append(fingerprints, strings.ToUpper(exported.GetFingerprint()))
+	}
+
+	path := resolvePath(workDir, args.ExportPublicKey)
+	if err := writeOutputFile(path, []byte(strings.Join(armored, "")), keys[0].opts.outputMode()); err != nil {
+		return fmt.Errorf("failed to write public key: %w", err)
+	}
+	log.Info("Public key exported", slog.String("path", path), slog.Any("fingerprints", fingerprints))
+
+	args.setActionOutput("public-key-fingerprint", strings.Join(fingerprints, "\n"))
+	return nil
+}
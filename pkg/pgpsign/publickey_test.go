@@ -0,0 +1,103 @@
+package pgpsign
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v3/crypto"
+)
+
+func TestRunExportsPublicKey(t *testing.T) {
+	armoredKey := generateTestKeyArmored(t, "Test", "test@test.com", "")
+	signer, err := NewGoPGPSigner(armoredKey, "", "")
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+	fingerprint := strings.ToUpper(signer.privateKey.GetFingerprint())
+
+	workDir := t.TempDir()
+	outputFile := filepath.Join(t.TempDir(), "output")
+	t.Setenv("GITHUB_OUTPUT", outputFile)
+
+	file := filepath.Join(workDir, "a.txt")
+	if err := os.WriteFile(file, []byte("content"), 0o644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	args := Config{
+		PrivateKey:      "key",
+		Files:           "*.txt",
+		WorkDir:         workDir,
+		SignModes:       "detached,clear",
+		ExportPublicKey: "signing-key.asc",
+	}
+	if err := run(context.Background(), args, signer, &MockFileFinder{Files: []string{file}}, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(workDir, "signing-key.asc"))
+	if err != nil {
+		t.Fatalf("expected public key to be exported: %v", err)
+	}
+	if strings.Count(string(data), "BEGIN PGP PUBLIC KEY BLOCK") != 1 || strings.Contains(string(data), "PRIVATE KEY") {
+		t.Fatalf("expected a single public key block, got:\n%s", data)
+	}
+	exported, err := crypto.NewKeyFromArmored(string(data))
+	if err != nil {
+		t.Fatalf("exported file is not a valid key: %v", err)
+	}
+	if exported.IsPrivate() || strings.ToUpper(exported.GetFingerprint()) != fingerprint {
+		t.Errorf("exported key %s does not match the signing key %s", exported.GetFingerprint(), fingerprint)
+	}
+
+	outputs, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("failed to read outputs: %v", err)
+	}
+	if !strings.Contains(string(outputs), "public-key-fingerprint") || !strings.Contains(string(outputs), fingerprint) {
+		t.Errorf("expected public-key-fingerprint output %s, got:\n%s", fingerprint, outputs)
+	}
+}
+
+func TestGnuPGSigner_ExportPublicKey(t *testing.T) {
+	key, err := crypto.NewKeyFromArmored(generateTestKeyArmored(t, "Test", "test@test.com", ""))
+	if err != nil {
+		t.Fatalf("failed to parse key: %v", err)
+	}
+	publicKey, err := key.ToPublic()
+	if err != nil {
+		t.Fatalf("failed to derive public key: %v", err)
+	}
+	armored, err := publicKey.Armor()
+	if err != nil {
+		t.Fatalf("failed to armor public key: %v", err)
+	}
+
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "key.asc")
+	argsFile := filepath.Join(dir, "args")
+	if err := os.WriteFile(keyFile, []byte(armored), 0o644); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+	installFakeGPG(t, `echo "$@" > '`+argsFile+`'; cat '`+keyFile+`'`)
+
+	signer := &GnuPGSigner{publicKey: publicKey}
+	exported, err := signer.ExportPublicKey()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if exported != armored {
+		t.Errorf("expected the key printed by gpg, got:\n%s", exported)
+	}
+
+	args, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatalf("failed to read gpg arguments: %v", err)
+	}
+	if want := "--armor --export " + publicKey.GetFingerprint(); !strings.Contains(string(args), want) {
+		t.Errorf("expected gpg arguments to contain %q, got %q", want, args)
+	}
+}
@@ -0,0 +1,90 @@
+package pgpsign
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// reportStdout is the report-file value that writes the report to stdout.
+const reportStdout = "-"
+
+// RunReport is the JSON document written to report-file. It describes the signing
+// configuration and the outcome for every file and key, but never key material or passphrases.
+type RunReport struct {
+	Backend         string       `json:"backend"`
+	DigestAlgorithm string       `json:"digest_algorithm"`
+	Signed          int          `json:"signed"`
+	Failed          int          `json:"failed"`
+	Results         []SignResult `json:"results"`
+}
+
+// newRunReport builds the report for results. Paths below a working directory are made relative to it.
+func newRunReport(args Config, opts SignOptions, workDirs []string, results []SignResult) RunReport {
+	report := RunReport{
+		Backend:         args.Backend,
+		DigestAlgorithm: string(opts.DigestAlgorithm),
+		Results:         make([]SignResult, 0, len(results)),
+	}
+	if report.DigestAlgorithm == "" {
+		report.DigestAlgorithm = "default"
+	}
+	if report.Backend == "" {
+		report.Backend = string(BackendGoPGP)
+	}
+
+	for _, result := range results {
+		result.File = relativeTo(workDirs, result.File)
+		if result.OutputPath != "" {
+			result.OutputPath = relativeTo(workDirs, result.OutputPath)
+		}
+		switch result.Status {
+		case StatusSigned:
+			report.Signed++
+		case StatusFailed:
+			report.Failed++
+		}
+		report.Results = append(report.Results, result)
+	}
+
+	return report
+}
+
+// writeRunReport writes report as indented JSON to path, or to stdout if path is "-".
+func writeRunReport(path string, report RunReport) error {
+	if path == reportStdout {
+		return encodeRunReport(os.Stdout, report)
+	}
+
+	f, err := createOutputFile(path, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to create report file: %w", err)
+	}
+	if err := encodeRunReport(f, report); err != nil {
+		f.Abort()
+		return err
+	}
+	if err := f.Commit(); err != nil {
+		return fmt.Errorf("failed to write report file: %w", err)
+	}
+	return nil
+}
+
+// encodeRunReport writes report as indented JSON to w.
+func encodeRunReport(w io.Writer, report RunReport) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
+
+// resolveReportPath resolves the report-file input against workDir, keeping "-" for stdout.
+func resolveReportPath(workDir, path string) string {
+	if path == reportStdout {
+		return path
+	}
+	return resolvePath(workDir, path)
+}
@@ -0,0 +1,76 @@
+package pgpsign
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunWritesReport(t *testing.T) {
+	const passphrase = "hunter2"
+	armoredKey := generateTestKeyArmored(t, "Test", "test@test.com", passphrase)
+
+	workDir := t.TempDir()
+	files := []string{filepath.Join(workDir, "a.txt"), filepath.Join(workDir, "missing.txt")}
+	if err := os.WriteFile(files[0], []byte("content"), 0o644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	args := Config{
+		PrivateKey:      armoredKey,
+		Passphrase:      passphrase,
+		Files:           "*.txt",
+		Armor:           true,
+		DetachSign:      true,
+		Backend:         "gopgp",
+		DigestAlgo:      "sha256",
+		WorkDir:         workDir,
+		ContinueOnError: true,
+		ReportFile:      "report.json",
+	}
+	if err := run(context.Background(), args, nil, &MockFileFinder{Files: files}, nil); err == nil {
+		t.Fatal("expected error for missing file")
+	}
+
+	data, err := os.ReadFile(filepath.Join(workDir, "report.json"))
+	if err != nil {
+		t.Fatalf("expected report to be written: %v", err)
+	}
+	if strings.Contains(string(data), passphrase) || strings.Contains(string(data), "PGP PRIVATE KEY") {
+		t.Fatalf("report must not contain secrets, got:\n%s", data)
+	}
+
+	var report RunReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("failed to parse report: %v", err)
+	}
+	if report.Backend != "gopgp" || report.DigestAlgorithm != "sha256" {
+		t.Errorf("unexpected backend or digest: %q, %q", report.Backend, report.DigestAlgorithm)
+	}
+	if report.Signed != 1 || report.Failed != 1 || len(report.Results) != 2 {
+		t.Fatalf("expected one signed and one failed result, got:\n%s", data)
+	}
+
+	signed := report.Results[0]
+	if signed.File != "a.txt" || signed.OutputPath != "a.txt.asc" || signed.Status != StatusSigned {
+		t.Errorf("unexpected result for a.txt: %+v", signed)
+	}
+	if signed.Bytes != int64(len("content")) || len(signed.Fingerprint) != 40 || signed.DigestAlgo != "sha256" || signed.Error != "" {
+		t.Errorf("unexpected details for a.txt: %+v", signed)
+	}
+
+	if !strings.Contains(string(data), `"duration_ms"`) {
+		t.Errorf("expected signing durations in the report, got:\n%s", data)
+	}
+
+	failed := report.Results[1]
+	if failed.File != "missing.txt" || failed.Status != StatusFailed || failed.OutputPath != "" {
+		t.Errorf("unexpected result for missing.txt: %+v", failed)
+	}
+	if !strings.Contains(failed.Error, "missing.txt") {
+		t.Errorf("expected error for missing.txt, got %q", failed.Error)
+	}
+}
@@ -0,0 +1,103 @@
+package pgpsign
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// validateSignatureTime checks that signature-time and its alias signing-time do not
+// disagree.
+func validateSignatureTime(args Config) error {
+	signatureTime, signingTime := strings.TrimSpace(args.SignatureTime), strings.TrimSpace(args.SigningTime)
+	if signatureTime != "" && signingTime != "" && signatureTime != signingTime {
+		return fmt.Errorf("signature-time %q and its alias signing-time %q must not be set to different values", args.SignatureTime, args.SigningTime)
+	}
+	return nil
+}
+
+// signatureTimeInput returns the signature-time input, or signing-time if only the alias
+// is set.
+func (args Config) signatureTimeInput() string {
+	if strings.TrimSpace(args.SignatureTime) == "" {
+		return args.SigningTime
+	}
+	return args.SignatureTime
+}
+
+// resolveSignatureTime determines the fixed signature creation time.
+// An explicit value takes precedence over SOURCE_DATE_EPOCH; the zero time means "now".
+func resolveSignatureTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		epoch := strings.TrimSpace(os.Getenv("SOURCE_DATE_EPOCH"))
+		if epoch == "" {
+			return time.Time{}, nil
+		}
+		seconds, err := strconv.ParseInt(epoch, 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid SOURCE_DATE_EPOCH %q: %w", epoch, err)
+		}
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+
+	return parseSignatureTime(value)
+}
+
+// parseSignatureTime parses a signature time given as RFC3339 or as Unix epoch seconds.
+func parseSignatureTime(value string) (time.Time, error) {
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid signature time %q: expected RFC3339 or Unix epoch seconds", value)
+	}
+	return t.UTC(), nil
+}
+
+// assertReproducible signs filePath a second time and compares the result with the
+// signature already on disk, failing if the two differ.
+func assertReproducible(signer Signer, filePath string, opts SignOptions) error {
+	sigPath := getOutputPath(filePath, opts)
+
+	first, err := os.ReadFile(sigPath)
+	if err != nil {
+		return fmt.Errorf("failed to read signature: %w", err)
+	}
+
+	// The second signature replaces the one just written for comparison.
+	opts.NoOverwrite = false
+	if _, err := signer.SignFile(filePath, opts); err != nil {
+		return fmt.Errorf("failed to sign file again: %w", err)
+	}
+
+	second, err := os.ReadFile(sigPath)
+	if err != nil {
+		return fmt.Errorf("failed to read signature: %w", err)
+	}
+
+	if offset := firstDifference(first, second); offset >= 0 {
+		return fmt.Errorf("signature is not reproducible: outputs differ at byte offset %d", offset)
+	}
+
+	return nil
+}
+
+// firstDifference returns the offset of the first differing byte, or -1 if a and b are equal.
+func firstDifference(a, b []byte) int {
+	if bytes.Equal(a, b) {
+		return -1
+	}
+	n := min(len(a), len(b))
+	for i := range n {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	return n
+}
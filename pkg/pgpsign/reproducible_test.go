@@ -0,0 +1,229 @@
+package pgpsign
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResolveSignatureTime(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		epoch       string
+		expected    time.Time
+		expectError bool
+	}{
+		{
+			name:     "unset",
+			expected: time.Time{},
+		},
+		{
+			name:     "unix epoch",
+			value:    "1700000000",
+			expected: time.Unix(1700000000, 0).UTC(),
+		},
+		{
+			name:     "rfc3339",
+			value:    "2024-01-02T03:04:05Z",
+			expected: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		},
+		{
+			name:     "rfc3339 with offset",
+			value:    "2024-01-02T05:04:05+02:00",
+			expected: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		},
+		{
+			name:     "source date epoch",
+			epoch:    "1600000000",
+			expected: time.Unix(1600000000, 0).UTC(),
+		},
+		{
+			name:     "explicit value wins over source date epoch",
+			value:    "1700000000",
+			epoch:    "1600000000",
+			expected: time.Unix(1700000000, 0).UTC(),
+		},
+		{
+			name:        "invalid value",
+			value:       "yesterday",
+			expectError: true,
+		},
+		{
+			name:        "invalid source date epoch",
+			epoch:       "not-a-number",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("SOURCE_DATE_EPOCH", tt.epoch)
+
+			result, err := resolveSignatureTime(tt.value)
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !result.Equal(tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestSigningTimeAlias(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        Config
+		expected    string
+		errContains string
+	}{
+		{name: "unset"},
+		{name: "signature-time", args: Config{SignatureTime: "1700000000"}, expected: "1700000000"},
+		{name: "signing-time", args: Config{SigningTime: "1700000000"}, expected: "1700000000"},
+		{name: "both equal", args: Config{SignatureTime: "1700000000", SigningTime: "1700000000"}, expected: "1700000000"},
+		{name: "both different", args: Config{SignatureTime: "1700000000", SigningTime: "1600000000"}, errContains: "different values"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSignatureTime(tt.args)
+			if tt.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Errorf("expected error containing %q, got %v", tt.errContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := tt.args.signatureTimeInput(); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestFirstDifference(t *testing.T) {
+	tests := []struct {
+		name     string
+		a        []byte
+		b        []byte
+		expected int
+	}{
+		{name: "equal", a: []byte("abc"), b: []byte("abc"), expected: -1},
+		{name: "both empty", a: nil, b: []byte{}, expected: -1},
+		{name: "differ at start", a: []byte("abc"), b: []byte("xbc"), expected: 0},
+		{name: "differ in middle", a: []byte("abc"), b: []byte("abx"), expected: 2},
+		{name: "prefix", a: []byte("ab"), b: []byte("abc"), expected: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := firstDifference(tt.a, tt.b); result != tt.expected {
+				t.Errorf("expected %d, got %d", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestAssertReproducible(t *testing.T) {
+	armoredKey := generateTestKeyArmored(t, "Test", "test@test.com", "")
+	signer, err := NewGoPGPSigner(armoredKey, "", "")
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+
+	signatureTime := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	tests := []struct {
+		name        string
+		opts        SignOptions
+		expectError bool
+	}{
+		{
+			name:        "salted signatures differ",
+			opts:        SignOptions{Armor: true, DetachSign: true, SignatureTime: signatureTime},
+			expectError: true,
+		},
+		{
+			name: "deterministic detached signature",
+			opts: SignOptions{Armor: true, DetachSign: true, SignatureTime: signatureTime, Deterministic: true},
+		},
+		{
+			name: "deterministic clear signature",
+			opts: SignOptions{ClearSign: true, SignatureTime: signatureTime, Deterministic: true},
+		},
+		{
+			name: "deterministic inline signature",
+			opts: SignOptions{SignatureTime: signatureTime, Deterministic: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testFile := filepath.Join(t.TempDir(), "test.txt")
+			if err := os.WriteFile(testFile, []byte("Hello, World!"), 0o644); err != nil {
+				t.Fatalf("failed to create test file: %v", err)
+			}
+
+			if _, err := signer.SignFile(testFile, tt.opts); err != nil {
+				t.Fatalf("failed to sign file: %v", err)
+			}
+
+			err := assertReproducible(signer, testFile, tt.opts)
+			if tt.expectError && err == nil {
+				t.Error("expected error but got nil")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestFixedSignatureTimeCreationTime(t *testing.T) {
+	signer, err := NewGoPGPSigner(generateTestKeyArmored(t, "Test", "test@test.com", ""), "", "")
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+
+	// The signature time must not predate the key, or go-crypto finds no valid signing key.
+	signatureTime := time.Now().Add(time.Hour).Truncate(time.Second)
+	opts := SignOptions{DetachSign: true, SignatureTime: signatureTime}
+
+	testFile := filepath.Join(t.TempDir(), "test.txt")
+	if err := os.WriteFile(testFile, []byte("Hello, World!"), 0o644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	// Salted signatures differ byte for byte, but both runs must record the same creation time.
+	var signatures [][]byte
+	for run := range 2 {
+		if _, err := signer.SignFile(testFile, opts); err != nil {
+			t.Fatalf("run %d: failed to sign file: %v", run+1, err)
+		}
+		sig := readSignaturePacket(t, getOutputPath(testFile, opts))
+		if !sig.CreationTime.Equal(signatureTime) {
+			t.Errorf("run %d: expected creation time %s, got %s", run+1, signatureTime, sig.CreationTime)
+		}
+		data, err := os.ReadFile(getOutputPath(testFile, opts))
+		if err != nil {
+			t.Fatalf("run %d: failed to read signature: %v", run+1, err)
+		}
+		signatures = append(signatures, data)
+	}
+
+	if bytes.Equal(signatures[0], signatures[1]) {
+		t.Error("expected salted signatures to differ despite the fixed creation time")
+	}
+}
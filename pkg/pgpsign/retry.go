@@ -0,0 +1,108 @@
+package pgpsign
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+// Defaults for retrying outbound network operations.
+const (
+	DefaultNetworkRetries = 3
+	DefaultNetworkBackoff = time.Second
+)
+
+// Limits keeping a misconfigured policy from retrying for hours.
+const (
+	maxNetworkRetries = 10          // Most retries of one operation
+	maxRetryDelay     = time.Minute // Cap of the exponential delay between two attempts
+)
+
+// sleep pauses between retry attempts; tests replace it to avoid real delays.
+var sleep = sleepContext
+
+// sleepContext waits for d to pass and returns ctx's error if ctx is done first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// RetryPolicy configures retries for outbound network operations.
+type RetryPolicy struct {
+	Retries int           // Number of retries after the first attempt
+	Backoff time.Duration // Base delay, doubled after every failed attempt up to maxRetryDelay
+}
+
+// Validate checks that the policy values are usable.
+func (p RetryPolicy) Validate() error {
+	if p.Retries < 0 {
+		return fmt.Errorf("network retries must not be negative: %d", p.Retries)
+	}
+	if p.Retries > maxNetworkRetries {
+		return fmt.Errorf("network retries must not exceed %d: %d", maxNetworkRetries, p.Retries)
+	}
+	if p.Backoff < 0 {
+		return fmt.Errorf("network backoff must not be negative: %s", p.Backoff)
+	}
+	return nil
+}
+
+// permanentError marks an error that retrying cannot fix, such as a rejected request.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// permanent wraps err so RetryPolicy.Do returns it without retrying.
+func permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// Do calls fn until it succeeds, fails with an error wrapped by permanent, or all retries are
+// used up. Attempts are separated by an exponential backoff with random jitter. Once ctx is
+// done no further attempt is made; fn should use ctx to stop a running attempt as well.
+func (p RetryPolicy) Do(ctx context.Context, operation string, fn func() error) error {
+	var err error
+	for attempt := 0; attempt <= p.Retries; attempt++ {
+		if attempt > 0 {
+			if sleepErr := sleep(ctx, p.delay(attempt)); sleepErr != nil {
+				return fmt.Errorf("%s cancelled after %d attempts: %w (last error: %v)", operation, attempt, sleepErr, err)
+			}
+		}
+		if err = fn(); err == nil {
+			return nil
+		}
+		var perm *permanentError
+		if errors.As(err, &perm) {
+			return fmt.Errorf("%s failed: %w", operation, perm.err)
+		}
+	}
+	return fmt.Errorf("%s failed after %d attempts: %w", operation, p.Retries+1, err)
+}
+
+// delay returns the jittered backoff before the given retry attempt.
+// The result lies between half and the full exponential delay, which is capped at
+// maxRetryDelay.
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	if p.Backoff <= 0 {
+		return 0
+	}
+	backoff := p.Backoff
+	// Doubling stops at the cap, so large attempt counts cannot overflow.
+	for i := 1; i < attempt && backoff < maxRetryDelay; i++ {
+		backoff *= 2
+	}
+	backoff = min(backoff, maxRetryDelay)
+	half := backoff / 2
+	return half + rand.N(backoff-half+1)
+}
@@ -0,0 +1,158 @@
+package pgpsign
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy_Do(t *testing.T) {
+	tests := []struct {
+		name          string
+		retries       int
+		failures      int
+		expectError   bool
+		expectedCalls int
+	}{
+		{
+			name:          "succeeds first attempt",
+			retries:       3,
+			failures:      0,
+			expectedCalls: 1,
+		},
+		{
+			name:          "succeeds after retries",
+			retries:       3,
+			failures:      2,
+			expectedCalls: 3,
+		},
+		{
+			name:          "exhausts retries",
+			retries:       2,
+			failures:      10,
+			expectError:   true,
+			expectedCalls: 3,
+		},
+		{
+			name:          "no retries",
+			retries:       0,
+			failures:      1,
+			expectError:   true,
+			expectedCalls: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var delays []time.Duration
+			sleep = func(_ context.Context, d time.Duration) error {
+				delays = append(delays, d)
+				return nil
+			}
+			t.Cleanup(func() { sleep = sleepContext })
+
+			policy := RetryPolicy{Retries: tt.retries, Backoff: 100 * time.Millisecond}
+
+			calls := 0
+			err := policy.Do(context.Background(), "test operation", func() error {
+				calls++
+				if calls <= tt.failures {
+					return errors.New("temporary failure")
+				}
+				return nil
+			})
+
+			if tt.expectError && err == nil {
+				t.Error("expected error but got nil")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if calls != tt.expectedCalls {
+				t.Errorf("expected %d calls, got %d", tt.expectedCalls, calls)
+			}
+			if len(delays) != calls-1 {
+				t.Errorf("expected %d delays, got %d", calls-1, len(delays))
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	policy := RetryPolicy{Retries: 5, Backoff: 100 * time.Millisecond}
+
+	for attempt := 1; attempt <= 5; attempt++ {
+		upper := policy.Backoff << (attempt - 1)
+		lower := upper / 2
+		for range 20 {
+			d := policy.delay(attempt)
+			if d < lower || d > upper {
+				t.Errorf("attempt %d: delay %s outside [%s, %s]", attempt, d, lower, upper)
+			}
+		}
+	}
+
+	for _, attempt := range []int{20, 64, 1000} {
+		if d := policy.delay(attempt); d < maxRetryDelay/2 || d > maxRetryDelay {
+			t.Errorf("attempt %d: delay %s outside [%s, %s]", attempt, d, maxRetryDelay/2, maxRetryDelay)
+		}
+	}
+	if d := (RetryPolicy{Backoff: time.Hour}).delay(1); d > maxRetryDelay {
+		t.Errorf("expected a large base delay to be capped at %s, got %s", maxRetryDelay, d)
+	}
+
+	if d := (RetryPolicy{}).delay(1); d != 0 {
+		t.Errorf("expected zero delay without backoff, got %s", d)
+	}
+}
+
+func TestRetryPolicy_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      RetryPolicy
+		expectError bool
+	}{
+		{name: "defaults", policy: RetryPolicy{Retries: DefaultNetworkRetries, Backoff: DefaultNetworkBackoff}},
+		{name: "zero values", policy: RetryPolicy{}},
+		{name: "negative retries", policy: RetryPolicy{Retries: -1}, expectError: true},
+		{name: "maximum retries", policy: RetryPolicy{Retries: maxNetworkRetries}},
+		{name: "too many retries", policy: RetryPolicy{Retries: maxNetworkRetries + 1}, expectError: true},
+		{name: "negative backoff", policy: RetryPolicy{Backoff: -time.Second}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate()
+			if tt.expectError && err == nil {
+				t.Error("expected error but got nil")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_DoCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	policy := RetryPolicy{Retries: 3, Backoff: time.Hour}
+
+	calls := 0
+	start := time.Now()
+	err := policy.Do(ctx, "test operation", func() error {
+		calls++
+		cancel()
+		return errors.New("temporary failure")
+	})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected a cancellation error, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected no retry after cancellation, got %d calls", calls)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected the backoff to be interrupted, waited %s", elapsed)
+	}
+}
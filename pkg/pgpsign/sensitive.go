@@ -0,0 +1,77 @@
+package pgpsign
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// SensitivePolicy selects what happens when a matched file looks like a private key or secret.
+type SensitivePolicy string
+
+const (
+	SensitiveOff   SensitivePolicy = "off"   // Do not check file names
+	SensitiveWarn  SensitivePolicy = "warn"  // Log a warning for each file and sign it anyway
+	SensitiveError SensitivePolicy = "error" // Fail before anything is signed
+)
+
+var (
+	// sensitiveNames are file names of private keys and credential stores.
+	sensitiveNames = []string{
+		"id_rsa", "id_dsa", "id_ecdsa", "id_ecdsa_sk", "id_ed25519", "id_ed25519_sk",
+		"secring.gpg", "secring.kbx", "trustdb.gpg",
+		".env", ".netrc", ".pgpass", ".htpasswd", ".npmrc", ".pypirc",
+		"credentials", "credentials.json", "client_secret.json",
+	}
+	// sensitiveExtensions are extensions of private keys, keystores and password databases.
+	sensitiveExtensions = []string{
+		".key", ".pem", ".p8", ".p12", ".pfx", ".ppk", ".jks", ".keystore", ".kdbx",
+	}
+)
+
+// parseSensitivePolicy parses the warn-sensitive input. An empty value selects SensitiveOff.
+func parseSensitivePolicy(value string) (SensitivePolicy, error) {
+	switch policy := SensitivePolicy(strings.ToLower(strings.TrimSpace(value))); policy {
+	case "":
+		return SensitiveOff, nil
+	case SensitiveOff, SensitiveWarn, SensitiveError:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown warn-sensitive policy %q, expected %s, %s or %s", value, SensitiveOff, SensitiveWarn, SensitiveError)
+	}
+}
+
+// looksSensitive reports whether the base name of name suggests a private key or secret,
+// such as id_rsa, server.pem or .env.production. The check is a heuristic on the name only
+// and never reads the file.
+func looksSensitive(name string) bool {
+	base := strings.ToLower(filepath.Base(name))
+	if slices.Contains(sensitiveNames, base) || strings.HasPrefix(base, ".env.") {
+		return true
+	}
+	return slices.Contains(sensitiveExtensions, filepath.Ext(base))
+}
+
+// checkSensitiveFiles applies the warn-sensitive input to files, so secrets that a broad
+// pattern matched by accident are noticed before their signatures are published.
+func checkSensitiveFiles(args Config, files []string, log *slog.Logger) error {
+	policy, err := parseSensitivePolicy(args.WarnSensitive)
+	if err != nil || policy == SensitiveOff {
+		return err
+	}
+
+	var sensitive []string
+	for _, file := range files {
+		if looksSensitive(file) {
+			log.Warn("Matched file looks like a private key or secret", slog.String("file", file))
+			sensitive = append(sensitive, file)
+		}
+	}
+
+	if policy == SensitiveError && len(sensitive) > 0 {
+		return fmt.Errorf("%d matched files look like private keys or secrets: %s; exclude them or set warn-sensitive to warn", len(sensitive), strings.Join(sensitive, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package pgpsign
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLooksSensitive(t *testing.T) {
+	sensitive := []string{
+		"private.pem",
+		"dist/server.KEY",
+		"id_rsa",
+		"/home/runner/.ssh/id_ed25519",
+		"release.p12",
+		"signing.pfx",
+		"putty.ppk",
+		"android.jks",
+		"release.keystore",
+		"passwords.kdbx",
+		"secring.gpg",
+		".env",
+		".env.production",
+		".netrc",
+		"credentials.json",
+		"client_secret.json",
+	}
+	benign := []string{
+		"app.tar.gz",
+		"id_rsa.pub",
+		"app.tar.gz.asc",
+		"keyboard-layout.txt",
+		"monkey.png",
+		"README.md",
+		"pubring.kbx",
+		"environment.yaml",
+		"SHA256SUMS",
+		"keys/",
+	}
+
+	for _, name := range sensitive {
+		if !looksSensitive(name) {
+			t.Errorf("expected %q to look sensitive", name)
+		}
+	}
+	for _, name := range benign {
+		if looksSensitive(name) {
+			t.Errorf("expected %q not to look sensitive", name)
+		}
+	}
+}
+
+func TestCheckSensitiveFiles(t *testing.T) {
+	files := []string{filepath.Join("dist", "app.tar.gz"), filepath.Join("dist", "private.pem")}
+
+	tests := []struct {
+		name        string
+		policy      string
+		warnings    int
+		errContains string
+	}{
+		{name: "off by default", policy: ""},
+		{name: "off", policy: "off"},
+		{name: "warn", policy: "warn", warnings: 1},
+		{name: "error", policy: "Error", warnings: 1, errContains: "1 matched files look like private keys or secrets: " + files[1]},
+		{name: "unknown policy", policy: "fail", errContains: "unknown warn-sensitive policy"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			log := slog.New(slog.NewTextHandler(&logs, nil))
+
+			err := checkSensitiveFiles(Config{WarnSensitive: tt.policy}, files, log)
+			if tt.errContains == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Fatalf("expected error containing %q, got %v", tt.errContains, err)
+			}
+			if got := strings.Count(logs.String(), "looks like a private key or secret"); got != tt.warnings {
+				t.Errorf("expected %d warnings, got %d: %s", tt.warnings, got, logs.String())
+			}
+		})
+	}
+}
+
+func TestRunWarnSensitive(t *testing.T) {
+	workDir := t.TempDir()
+	writeTestFiles(t, workDir, "app.tar.gz", "private.pem")
+
+	mockSigner := &MockSigner{}
+	args := Config{
+		PrivateKey:    "key",
+		Files:         "*",
+		Armor:         true,
+		DetachSign:    true,
+		WorkDir:       workDir,
+		WarnSensitive: string(SensitiveError),
+	}
+	err := run(context.Background(), args, mockSigner, &DefaultFileFinder{}, nil)
+	if err == nil || !strings.Contains(err.Error(), "private.pem") {
+		t.Fatalf("expected error naming private.pem, got %v", err)
+	}
+	if len(mockSigner.SignedFiles) != 0 {
+		t.Errorf("expected nothing to be signed, got %v", mockSigner.SignedFiles)
+	}
+}
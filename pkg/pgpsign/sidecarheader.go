@@ -0,0 +1,119 @@
+package pgpsign
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// sidecarMetaExtension is appended to the path of a binary signature to name the file
+// holding its sidecar header.
+const sidecarMetaExtension = ".meta"
+
+// sidecarCommentPrefix starts every sidecar header line prepended to an armored signature.
+// OpenPGP readers skip any text before the BEGIN line, so the signature still verifies.
+const sidecarCommentPrefix = "# "
+
+// validateSidecarHeader checks that sidecar-header is only set when detached signatures of
+// files on disk are written.
+func validateSidecarHeader(args Config) error {
+	if !args.SidecarHeader {
+		return nil
+	}
+	if args.TarMembers || isStdinMode(args) || args.ImageDigests != "" {
+		return fmt.Errorf("sidecar-header is only supported when signing files, not with tar-members, stdin or image-digests")
+	}
+	kinds, _ := resolveOutputKinds(args) // Invalid sign modes are reported once the options are built
+	if !args.DetachSign && !slices.Contains(kinds, OutputDetached) {
+		return fmt.Errorf("sidecar-header requires detached signatures: set detach-sign or include detached in sign-modes")
+	}
+	return nil
+}
+
+// sidecarHeaderLines returns the sidecar header describing filePath: the digest algorithm,
+// the hex digest and the size of the file and its base name, one "key: value" per line.
+// The digest uses the signature's digest algorithm, or SHA-256 if it is the backend default.
+func sidecarHeaderLines(filePath string, opts SignOptions) ([]string, error) {
+	algorithm := opts.DigestAlgorithm
+	if algorithm == DigestDefault {
+		algorithm = DigestSHA256
+	}
+
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+
+	h := digestHashes[algorithm].New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash file: %w", err)
+	}
+
+	return []string{
+		"algorithm: " + string(algorithm),
+		"digest: " + hex.EncodeToString(h.Sum(nil)),
+		fmt.Sprintf("size: %d", size),
+		"file: " + filepath.Base(filePath),
+	}, nil
+}
+
+// writeSidecarHeader adds the sidecar header of filePath to its detached signature at
+// sigPath. Armored signatures get the header prepended as comment lines; binary signatures
+// cannot carry text, so the header is written to sigPath with sidecarMetaExtension appended.
+// It returns the path the header was written to.
+func writeSidecarHeader(filePath, sigPath string, opts SignOptions) (string, error) {
+	lines, err := sidecarHeaderLines(filePath, opts)
+	if err != nil {
+		return "", fmt.Errorf("failed to write sidecar header: %w", err)
+	}
+
+	var buf bytes.Buffer
+	path := sigPath + sidecarMetaExtension
+	prefix := ""
+	if opts.Armor {
+		path, prefix = sigPath, sidecarCommentPrefix
+	}
+	for _, line := range lines {
+		buf.WriteString(prefix + line + "\n")
+	}
+	if opts.Armor {
+		signature, err := os.ReadFile(sigPath)
+		if err != nil {
+			return "", fmt.Errorf("failed to read signature: %w", err)
+		}
+		buf.Write(signature)
+	}
+
+	if err := writeOutputFile(path, buf.Bytes(), opts.outputMode()); err != nil {
+		return "", fmt.Errorf("failed to write sidecar header: %w", err)
+	}
+	return path, nil
+}
+
+// skipSidecarHeader returns data without the sidecar header lines writeSidecarHeader
+// prepends to armored signatures.
+func skipSidecarHeader(data []byte) []byte {
+	for bytes.HasPrefix(data, []byte(sidecarCommentPrefix)) {
+		end := bytes.IndexByte(data, '\n')
+		if end < 0 {
+			return nil
+		}
+		data = data[end+1:]
+	}
+	return data
+}
+
+// sidecarMetaPath returns the path of the sidecar header file written for the signature at
+// sigPath, or "" if the header is part of the signature or not written at all.
+func sidecarMetaPath(sigPath string, opts SignOptions) string {
+	if !opts.SidecarHeader || opts.Armor {
+		return ""
+	}
+	return sigPath + sidecarMetaExtension
+}
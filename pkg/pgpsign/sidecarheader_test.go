@@ -0,0 +1,261 @@
+package pgpsign
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// parseSidecarHeader reads the "key: value" lines of a sidecar header, stripping prefix from
+// each line and stopping at the first line without it.
+func parseSidecarHeader(t *testing.T, data []byte, prefix string) map[string]string {
+	t.Helper()
+	fields := make(map[string]string)
+	for _, line := range strings.Split(string(data), "\n") {
+		line, ok := strings.CutPrefix(line, prefix)
+		if !ok || line == "" {
+			break
+		}
+		key, value, ok := strings.Cut(line, ": ")
+		if !ok {
+			t.Fatalf("malformed sidecar header line %q", line)
+		}
+		fields[key] = value
+	}
+	return fields
+}
+
+// checkSidecarFields fails unless fields describe the file at path hashed with SHA-256.
+func checkSidecarFields(t *testing.T, fields map[string]string, path string) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", path, err)
+	}
+	want := map[string]string{
+		"algorithm": "sha256",
+		"digest":    sha256Hex(string(data)),
+		"size":      strconv.Itoa(len(data)),
+		"file":      filepath.Base(path),
+	}
+	for key, value := range want {
+		if fields[key] != value {
+			t.Errorf("expected %s %q, got %q", key, value, fields[key])
+		}
+	}
+	if len(fields) != len(want) {
+		t.Errorf("expected %d fields, got %v", len(want), fields)
+	}
+}
+
+func TestWriteSidecarHeader(t *testing.T) {
+	signer, err := NewGoPGPSigner(generateTestKeyArmored(t, "Test", "test@test.com", ""), "", "")
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		armor bool
+	}{
+		{name: "armored", armor: true},
+		{name: "binary"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeTestFiles(t, dir, "app.tar.gz")
+			file := filepath.Join(dir, "app.tar.gz")
+			opts := SignOptions{Armor: tt.armor, DetachSign: true, SidecarHeader: true}
+
+			result, err := signer.SignFile(file, opts)
+			if err != nil {
+				t.Fatalf("failed to sign: %v", err)
+			}
+			signature, err := os.ReadFile(result.OutputPath)
+			if err != nil {
+				t.Fatalf("failed to read signature: %v", err)
+			}
+
+			headerPath, err := writeSidecarHeader(file, result.OutputPath, opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			header, err := os.ReadFile(headerPath)
+			if err != nil {
+				t.Fatalf("failed to read sidecar header: %v", err)
+			}
+
+			if tt.armor {
+				if headerPath != result.OutputPath {
+					t.Errorf("expected the header in the signature %s, got %s", result.OutputPath, headerPath)
+				}
+				checkSidecarFields(t, parseSidecarHeader(t, header, sidecarCommentPrefix), file)
+				if !bytes.HasSuffix(header, signature) {
+					t.Errorf("expected the signature to follow the header unchanged, got:\n%s", header)
+				}
+			} else {
+				if want := result.OutputPath + ".meta"; headerPath != want || sidecarMetaPath(result.OutputPath, opts) != want {
+					t.Errorf("expected the header in %s, got %s", want, headerPath)
+				}
+				checkSidecarFields(t, parseSidecarHeader(t, header, ""), file)
+				if written, _ := os.ReadFile(result.OutputPath); !bytes.Equal(written, signature) {
+					t.Error("expected the binary signature to be left unchanged")
+				}
+			}
+
+			if err := signer.Verify(file, result.OutputPath, opts); err != nil {
+				t.Errorf("signature with sidecar header does not verify: %v", err)
+			}
+		})
+	}
+}
+
+func TestSidecarHeaderLines_DigestAlgorithm(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, "app.bin")
+
+	lines, err := sidecarHeaderLines(filepath.Join(dir, "app.bin"), SignOptions{DigestAlgorithm: DigestSHA512})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sum := sha512.Sum512([]byte("app.bin"))
+	if lines[0] != "algorithm: sha512" || lines[1] != "digest: "+hex.EncodeToString(sum[:]) {
+		t.Errorf("expected a SHA-512 digest matching digest-algo, got %v", lines)
+	}
+}
+
+func TestValidateSidecarHeader(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    Config
+		wantErr string
+	}{
+		{name: "unset", args: Config{}},
+		{name: "detached", args: Config{SidecarHeader: true, DetachSign: true}},
+		{name: "detached sign mode", args: Config{SidecarHeader: true, SignModes: "clear,detached"}},
+		{name: "clear-signed", args: Config{SidecarHeader: true, ClearSign: true}, wantErr: "requires detached signatures"},
+		{name: "inline", args: Config{SidecarHeader: true}, wantErr: "requires detached signatures"},
+		{name: "clear sign mode", args: Config{SidecarHeader: true, SignModes: "clear"}, wantErr: "requires detached signatures"},
+		{name: "stdin", args: Config{SidecarHeader: true, DetachSign: true, Files: "-"}, wantErr: "only supported when signing files"},
+		{name: "tar members", args: Config{SidecarHeader: true, DetachSign: true, TarMembers: true}, wantErr: "only supported when signing files"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSidecarHeader(tt.args)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestRunSidecarHeader(t *testing.T) {
+	signer, err := NewGoPGPSigner(generateTestKeyArmored(t, "Test", "test@test.com", ""), "", "")
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		armor      bool
+		signModes  string
+		wantHeader string // File expected to hold the header of app.bin
+		wantPrefix string
+		wantUpload []string
+	}{
+		{name: "armored", armor: true, wantHeader: "app.bin.asc", wantPrefix: sidecarCommentPrefix, wantUpload: []string{"app.bin.asc"}},
+		{name: "binary", wantHeader: "app.bin.sig.meta", wantUpload: []string{"app.bin.sig", "app.bin.sig.meta"}},
+		{name: "sign modes", armor: true, signModes: "clear,detached", wantHeader: "app.bin.sig", wantPrefix: sidecarCommentPrefix, wantUpload: []string{"app.bin.asc", "app.bin.sig"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workDir := t.TempDir()
+			writeTestFiles(t, workDir, "app.bin")
+
+			args := Config{
+				PrivateKey:    "key",
+				Files:         "*.bin",
+				Armor:         tt.armor,
+				DetachSign:    tt.signModes == "",
+				SignModes:     tt.signModes,
+				WorkDir:       workDir,
+				SidecarHeader: true,
+				SignAndVerify: true,
+				UploadList:    "upload.txt",
+			}
+			if err := run(context.Background(), args, signer, nil, nil); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			header, err := os.ReadFile(filepath.Join(workDir, tt.wantHeader))
+			if err != nil {
+				t.Fatalf("expected sidecar header: %v", err)
+			}
+			checkSidecarFields(t, parseSidecarHeader(t, header, tt.wantPrefix), filepath.Join(workDir, "app.bin"))
+
+			upload, err := os.ReadFile(filepath.Join(workDir, "upload.txt"))
+			if err != nil {
+				t.Fatalf("expected upload list: %v", err)
+			}
+			var want strings.Builder
+			for _, name := range tt.wantUpload {
+				want.WriteString(filepath.Join(workDir, name) + "\n")
+			}
+			if string(upload) != want.String() {
+				t.Errorf("expected upload list:\n%s\ngot:\n%s", want.String(), upload)
+			}
+		})
+	}
+}
+
+func TestVerifySidecarHeader(t *testing.T) {
+	privateKey := generateTestKeyArmored(t, "Test", "test@test.com", "")
+
+	for _, armor := range []bool{true, false} {
+		t.Run(fmt.Sprintf("armor %v", armor), func(t *testing.T) {
+			workDir := t.TempDir()
+			writeTestFiles(t, workDir, "app.bin")
+
+			sign := Config{
+				PrivateKey:    privateKey,
+				Backend:       string(BackendGoPGP),
+				Files:         "*.bin",
+				Armor:         armor,
+				DetachSign:    true,
+				WorkDir:       workDir,
+				SidecarHeader: true,
+			}
+			if err := run(context.Background(), sign, nil, nil, nil); err != nil {
+				t.Fatalf("failed to sign: %v", err)
+			}
+
+			verify := Config{
+				PrivateKey: privateKey,
+				Backend:    string(BackendGoPGP),
+				Mode:       string(RunModeVerify),
+				Files:      "*.bin",
+				WorkDir:    workDir,
+			}
+			if err := run(context.Background(), verify, nil, &DefaultFileFinder{}, nil); err != nil {
+				t.Errorf("expected the signature with a sidecar header to verify, got %v", err)
+			}
+		})
+	}
+}
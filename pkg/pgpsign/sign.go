@@ -0,0 +1,1150 @@
+package pgpsign
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// startTime is when the process started; file ages and the run duration are measured from it.
+var startTime = time.Now()
+
+// SignerBackend defines the available signer backends.
+type SignerBackend string
+
+const (
+	BackendGoPGP    SignerBackend = "gopgp"
+	BackendGnuPG    SignerBackend = "gnupg"
+	BackendMinisign SignerBackend = "minisign"
+	BackendSSH      SignerBackend = "ssh"
+	DefaultBackend                = BackendGoPGP
+)
+
+// Config holds the settings of a signing run. Its fields mirror the action inputs and the
+// command line flags; the defaults in the tags are applied by the CLI when it parses them,
+// so library callers set every field they rely on.
+type Config struct {
+	PrivateKey string `arg:"--private-key,env:PRIVATE_KEY" help:"Private GPG key used for signing (armored, or @path to a key file); required unless --skip-import is set"`
+	Passphrase string `arg:"--passphrase,env:PASSPHRASE" help:"Passphrase for the GPG key"`
+	Armor      bool   `arg:"--armor,env:ARMOR" default:"true" help:"Create ASCII armored output"`
+	DetachSign bool   `arg:"--detach-sign,env:DETACH_SIGN" default:"false" help:"Make a detached signature"`
+	ClearSign  bool   `arg:"--clear-sign,env:CLEAR_SIGN" default:"false" help:"Make a clear text signature"`
+	Files      string `arg:"--files,env:FILES" help:"List of files to sign (glob patterns, newline separated; pattern => exclude1,exclude2 scopes excludes to one pattern)"`
+	Excludes   string `arg:"--excludes,env:EXCLUDES" help:"List of files to exclude (glob patterns, newline separated)"`
+	WorkDir    string `arg:"--workdir,env:WORKDIR" help:"Working directories for file operations (newline separated); the first one resolves other relative paths"`
+	Backend    string `arg:"--backend,env:BACKEND" default:"gopgp" help:"Signer backend: gopgp (pure Go, default), gnupg (system GPG) or ssh (SSH key, SSHSIG signatures)"`
+	LogLevel   string `arg:"--log-level,env:LOG_LEVEL" default:"info" help:"Log level: debug, info, warn, error"`
+
+	DryRun          bool `arg:"--dry-run,env:DRY_RUN" default:"false" help:"Only report which files would be signed and where signatures would be written"`
+	ContinueOnError bool `arg:"--continue-on-error,env:CONTINUE_ON_ERROR" default:"false" help:"Keep signing the remaining files when one fails and report all failures at the end"`
+
+	ListOnly bool `arg:"--list-only,env:LIST_ONLY" default:"false" help:"Print the files that would be signed to stdout, one per line, without loading a key"`
+
+	Mode      string `arg:"--mode,env:MODE" default:"sign" help:"sign the matched files, or verify their existing detached signatures"`
+	PublicKey string `arg:"--public-key,env:PUBLIC_KEY" help:"Armored public keys trusted by --mode verify; defaults to the public part of --private-key"`
+
+	NoOverwrite  bool `arg:"--no-overwrite,env:NO_OVERWRITE" default:"false" help:"Fail instead of replacing signature files that already exist"`
+	SkipExisting bool `arg:"--skip-existing,env:SKIP_EXISTING" default:"false" help:"Skip files whose signature file already exists"`
+
+	MaxFileSize string `arg:"--max-file-size,env:MAX_FILE_SIZE" help:"Largest file to sign, such as 500MB or 2GiB; larger files are handled by --on-oversize"`
+	OnOversize  string `arg:"--on-oversize,env:ON_OVERSIZE" default:"error" help:"What to do with files larger than --max-file-size: error, skip or warn"`
+	OnEmptyFile string `arg:"--on-empty-file,env:ON_EMPTY_FILE" default:"sign" help:"What to do with matched files of zero bytes: sign, skip or error"`
+
+	WarnSensitive string `arg:"--warn-sensitive,env:WARN_SENSITIVE" default:"off" help:"What to do with matched files named like private keys or secrets (*.key, *.pem, id_rsa, ...): off, warn or error"`
+
+	FilesFrom       string `arg:"--files-from,env:FILES_FROM" help:"File listing paths to sign, one per line, in addition to --files"`
+	FilesFromStrict bool   `arg:"--files-from-strict,env:FILES_FROM_STRICT" default:"false" help:"Fail instead of warning when --files-from lists a missing file"`
+
+	FilesCommand string `arg:"--files-command,env:FILES_COMMAND" help:"Shell command run in the working directory whose output, one path per line, replaces --files as the files to sign"`
+
+	ExcludesFrom string `arg:"--excludes-from,env:EXCLUDES_FROM" help:"File listing exclude patterns, one per line, applied before --excludes"`
+
+	PassphraseFile string `arg:"--passphrase-file,env:PASSPHRASE_FILE" help:"File containing the passphrase for the GPG key, or - to read it from stdin"`
+	PassphraseFD   *int   `arg:"--passphrase-fd,env:PASSPHRASE_FD" help:"File descriptor to read the passphrase from (0 for stdin)"`
+
+	Notation string `arg:"--notation,env:NOTATION" help:"Notation data added to every signature as name@domain=value pairs (newline separated)"`
+
+	EmbedFilename bool `arg:"--embed-filename,env:EMBED_FILENAME" default:"false" help:"Record the signed file's base name in the signature: as a notation for detached and clear-text signatures, as the literal data filename for inline ones"`
+
+	ReportFile string `arg:"--report-file,env:REPORT_FILE" help:"Write a JSON report of the run to this file, or - for stdout"`
+
+	SignModes string `arg:"--sign-modes,env:SIGN_MODES" help:"Signature kinds to create in one pass, comma or newline separated: detached, clear, inline"`
+
+	ExpiryWarnWindow time.Duration `arg:"--expiry-warn-window,env:EXPIRY_WARN_WINDOW" default:"720h" help:"Warn if the signing key expires within this duration (0 disables the warning)"`
+	ExpiryGuard      string        `arg:"--expiry-guard,env:EXPIRY_GUARD" default:"off" help:"What to do if a signing key expires before the run is projected to finish: off, warn or abort"`
+
+	Dereference bool `arg:"--dereference,env:DEREFERENCE" default:"true" help:"Sign the content of matched symlinks' targets, writing the signature next to the link; when false, symlinks are skipped"`
+
+	FollowSymlinks bool `arg:"--follow-symlinks,env:FOLLOW_SYMLINKS" default:"false" help:"Descend into symlinked directories when expanding ** patterns"`
+
+	ArchiveDirs bool `arg:"--archive-dirs,env:ARCHIVE_DIRS" default:"false" help:"Pack matched directories into dir.tar next to them and sign the archive"`
+
+	CaseInsensitive bool `arg:"--case-insensitive,env:CASE_INSENSITIVE" default:"false" help:"Match files and excludes patterns regardless of case"`
+
+	Recursive bool `arg:"--recursive,env:RECURSIVE" default:"false" help:"Match files patterns without a directory part at any depth, as if prefixed with **/"`
+
+	ExcludeOlderThan  time.Duration `arg:"--exclude-older-than,env:EXCLUDE_OLDER_THAN" default:"0" help:"Skip matched files last modified longer than this duration before the run started (0 disables the limit)"`
+	ExcludeLargerThan string        `arg:"--exclude-larger-than,env:EXCLUDE_LARGER_THAN" help:"Skip matched files larger than this size, such as 500MB or 2GiB"`
+
+	SignSignatures bool `arg:"--sign-signatures,env:SIGN_SIGNATURES" default:"false" help:"Also sign matched files that look like signatures (.asc, .sig, .gpg, .sigstore.json)"`
+
+	KeyDir string `arg:"--key-dir,env:KEY_DIR" help:"Directory of armored private keys (*.asc) to sign with all at once; name.pass files hold per-key passphrases"`
+
+	KeyEncoding string `arg:"--key-encoding,env:KEY_ENCODING" default:"auto" help:"Encoding of the private key: auto, armor, or base64"`
+	KeyID       string `arg:"--key-id,env:KEY_ID" help:"Fingerprint, long key ID or email address of the key or subkey to sign with"`
+
+	SkipKeyValidation bool `arg:"--skip-key-validation,env:SKIP_KEY_VALIDATION" default:"false" help:"Accept keys without key flags (gopgp) and trust every key (gnupg), for self-signed test keys"`
+
+	SignatureType string `arg:"--signature-type,env:SIGNATURE_TYPE" default:"binary" help:"OpenPGP signature type of detached and inline signatures: binary (0x00) or text (0x01); clear-signed files always use text"`
+
+	TextMode          bool   `arg:"--text-mode,env:TEXT_MODE" default:"false" help:"Canonicalize line endings of clear-signed text (to LF) and inline signed text (to CRLF, as an OpenPGP text message)"`
+	CleartextEncoding string `arg:"--cleartext-encoding,env:CLEARTEXT_ENCODING" default:"strict" help:"Handling of BOM/UTF-16 input when clear-signing: strict (error) or convert (transcode to UTF-8)"`
+	SignAndVerify     bool   `arg:"--sign-and-verify,env:SIGN_AND_VERIFY" default:"false" help:"Verify all written signatures in a separate pass after signing"`
+	VerifyAfterSign   bool   `arg:"--verify-after-sign,env:VERIFY_AFTER_SIGN" default:"false" help:"Verify each signature right after it is written and stop at the first failure"`
+
+	VerifyKeyring string `arg:"--verify-keyring,env:VERIFY_KEYRING" help:"File with trusted armored public keys; every signature must verify against one of them"`
+
+	SignatureTime      string `arg:"--signature-time,env:SIGNATURE_TIME" help:"Fixed signature creation time (RFC3339 or Unix epoch); defaults to SOURCE_DATE_EPOCH if set"`
+	SigningTime        string `arg:"--signing-time,env:SIGNING_TIME" help:"Alias of --signature-time"`
+	AssertReproducible bool   `arg:"--assert-reproducible,env:ASSERT_REPRODUCIBLE" default:"false" help:"Sign every file twice and fail if the signatures differ (requires a fixed signature time)"`
+
+	DigestAlgo string `arg:"--digest-algo,env:DIGEST_ALGO" help:"Hash algorithm for signatures: sha256, sha384 or sha512 (defaults to the backend's choice)"`
+
+	GnuPGCompat bool `arg:"--gnupg-compat,env:GNUPG_COMPAT" default:"false" help:"Create SHA-256 v4 signatures without extra notations so older GnuPG verifies them cleanly (gopgp backend)"`
+
+	BundleFormat string `arg:"--bundle-format,env:BUNDLE_FORMAT" help:"Additionally wrap each detached signature in a bundle: sigstore-pgp"`
+
+	SidecarHeader bool `arg:"--sidecar-header,env:SIDECAR_HEADER" default:"false" help:"Record the digest algorithm, digest and size of each signed file in a comment block before armored detached signatures, or in a .meta file next to binary ones"`
+
+	UploadList               string `arg:"--upload-list,env:UPLOAD_LIST" help:"Write the paths of all written signatures to this file, one per line"`
+	UploadListIncludeSources bool   `arg:"--upload-list-include-sources,env:UPLOAD_LIST_INCLUDE_SOURCES" default:"false" help:"Also list the signed source files in --upload-list"`
+
+	BundleOut string `arg:"--bundle-out,env:BUNDLE_OUT" help:"Also collect all written signatures into this zip archive, mirroring the layout of the signed files"`
+
+	ManifestOut  string `arg:"--manifest-out,env:MANIFEST_OUT" help:"Write a manifest listing the SHA-256, path and signature of every signed file to this file"`
+	SignManifest bool   `arg:"--sign-manifest,env:SIGN_MANIFEST" default:"false" help:"Also sign the --manifest-out file with every signing key"`
+
+	TarMembers bool   `arg:"--tar-members,env:TAR_MEMBERS" default:"false" help:"Sign members of --archive matching --files instead of files on disk"`
+	Archive    string `arg:"--archive,env:ARCHIVE" help:"Tar or tar.gz archive whose members are signed in tar-members mode"`
+	OutputDir  string `arg:"--output-dir,env:OUTPUT_DIR" help:"Directory for signatures, mirroring the paths of the signed files below the working directory"`
+
+	SignatureLayout string `arg:"--signature-layout,env:SIGNATURE_LAYOUT" help:"Where signatures are written: inplace, mirror (subdirectories below --output-dir) or flat (directly in --output-dir); defaults to mirror with --output-dir and inplace without"`
+
+	Output string `arg:"--output,env:OUTPUT" help:"Signature file when signing stdin with --files -; defaults to stdout"`
+
+	ImageDigests string `arg:"--image-digests,env:IMAGE_DIGESTS" help:"Container image references pinned to a digest (image@sha256:<hex>, newline separated) whose digests are signed instead of files"`
+
+	ArmorComment      string `arg:"--armor-comment,env:ARMOR_COMMENT" help:"Comment header added to armored signatures"`
+	SignerComment     string `arg:"--signer-comment,env:SIGNER_COMMENT" help:"Comment header of clear-signed output instead of armor-comment; {signer} is replaced by the signer's user ID"`
+	StripArmorVersion bool   `arg:"--strip-armor-version,env:STRIP_ARMOR_VERSION" default:"false" help:"Never write a Version header into armored signatures"`
+	MinimalArmor      bool   `arg:"--minimal-armor,env:MINIMAL_ARMOR" default:"false" help:"Write armored signatures with only the BEGIN and END lines and no Version, Comment or other headers"`
+
+	Format string `arg:"--format,env:FORMAT" default:"pgp" help:"Signature format: pgp (default), minisign for detached .minisig files signed with a minisign secret key, or ssh for SSHSIG signatures"`
+
+	OutputMode string `arg:"--output-mode,env:OUTPUT_MODE" default:"0644" help:"Octal permission bits of written signature files"`
+
+	OnFileChange string `arg:"--on-file-change,env:ON_FILE_CHANGE" default:"error" help:"Handling of files whose size or modification time changes while they are signed (gopgp backend): error or retry"`
+
+	SignatureSuffix string `arg:"--signature-suffix,env:SIGNATURE_SUFFIX" help:"Extension for signature files instead of .asc, .sig or .gpg"`
+	SignatureName   string `arg:"--signature-name,env:SIGNATURE_NAME" help:"Template for signature file names using {name} and {ext}, e.g. {name}.{ext}.sig"`
+
+	Concurrency   int `arg:"--concurrency,env:CONCURRENCY" default:"1" help:"Maximum number of files signed in parallel"`
+	MaxCPUPercent int `arg:"--max-cpu-percent,env:MAX_CPU_PERCENT" default:"0" help:"Cap parallel signing to this percentage of available CPUs (0 disables the cap)"`
+
+	Progress bool `arg:"--progress,env:PROGRESS" default:"false" help:"Log a [done/total] counter with rate and estimated time remaining after each file"`
+
+	FailOnNoMatch bool `arg:"--fail-on-no-match,env:FAIL_ON_NO_MATCH" default:"false" help:"Fail instead of warning when no files match --files"`
+
+	GPGTimeout time.Duration `arg:"--gpg-timeout,env:GPG_TIMEOUT" default:"10m" help:"Maximum duration of a single gpg invocation with the gnupg backend (0 disables the limit)"`
+
+	GnuPGHome string `arg:"--gnupg-home,env:GNUPG_HOME" help:"GNUPGHOME for gpg with the gnupg backend; by default a temporary home is created for each run and removed afterwards"`
+
+	TempDir string `arg:"--temp-dir,env:TEMP_DIR" help:"Directory for temporary files, such as the gpg home of the gnupg backend; defaults to the system temp directory"`
+
+	GlobBase string `arg:"--glob-base,env:GLOB_BASE" help:"Directory the --files patterns and excludes are matched in instead of the working directories; signature paths and outputs stay relative to --workdir"`
+
+	ChangedSince string `arg:"--changed-since,env:CHANGED_SINCE" help:"Only sign matched files that changed since this git ref, such as origin/main, in the repository of the working directory"`
+
+	OnlyExtensions string `arg:"--only-extensions,env:ONLY_EXTENSIONS" help:"Only sign matched files whose name ends with one of these extensions, such as tar.gz,zip,deb (comma or newline separated)"`
+
+	SkipImport bool `arg:"--skip-import,env:SKIP_IMPORT" default:"false" help:"Sign with the key selected by --key-id that is already in gpg's keyring instead of importing --private-key (gnupg backend)"`
+
+	ExportPublicKey string `arg:"--export-public-key,env:EXPORT_PUBLIC_KEY" help:"Write the armored public key of the signing key to this file"`
+
+	Keyserver         string `arg:"--keyserver,env:KEYSERVER" help:"HKP keyserver to publish the signing key to after signing, e.g. hkps://keys.openpgp.org"`
+	KeyserverRequired bool   `arg:"--keyserver-required,env:KEYSERVER_REQUIRED" default:"false" help:"Fail the run if the keyserver upload fails instead of only warning"`
+
+	LogFormat     string `arg:"--log-format,env:LOG_FORMAT" default:"text" help:"Log output format: text or json"`
+	Quiet         bool   `arg:"--quiet,env:QUIET" default:"false" help:"Only log errors regardless of --log-level, and skip progress and the job summary"`
+	LogOutput     string `arg:"--log-output,env:LOG_OUTPUT" help:"Stream the log is written to: stdout or stderr; defaults to stdout, or stderr when the signature of stdin is written to stdout"`
+	LogTimeFormat string `arg:"--log-time-format,env:LOG_TIME_FORMAT" help:"Rendering of log timestamps: rfc3339, rfc3339nano, unix, none, or a Go time layout such as 15:04:05"`
+	NoColor       bool   `arg:"--no-color" default:"false" help:"Strip ANSI color and control sequences, such as those in relayed gpg output, from log messages and values; also enabled by a non-empty NO_COLOR"`
+
+	NetworkRetries int           `arg:"--network-retries,env:NETWORK_RETRIES" default:"3" help:"Number of retries for the keyserver upload (at most 10)"`
+	NetworkBackoff time.Duration `arg:"--network-backoff,env:NETWORK_BACKOFF" default:"1s" help:"Base delay between network retries, doubled on each attempt up to 1m with jitter"`
+
+	// Signer signs the files in place of the keys loaded from the key inputs, if set.
+	Signer Signer `arg:"-"`
+	// Finder matches the files in place of the default file finder, if set.
+	Finder FileFinder `arg:"-"`
+	// Logger receives the log output of the run; nil discards it. NewLogger creates the
+	// logger the log inputs describe.
+	Logger *slog.Logger `arg:"-"`
+	// GitHubActions integrates the run with the GitHub Actions runner: step outputs are
+	// written to GITHUB_OUTPUT, or as workflow commands to stdout, the summary is appended
+	// to GITHUB_STEP_SUMMARY, and the working directory defaults to GITHUB_WORKSPACE. The
+	// CLI sets it; without it a run only reports through its RunReport and the logger.
+	GitHubActions bool `arg:"-"`
+}
+
+// Sign signs the files matched by args and returns the outcome for every file and key.
+// Cancelling ctx stops the signing of further files, kills running gpg invocations and
+// aborts keyserver uploads and their retries.
+// The report is empty when no files on disk were signed, such as in dry runs or when
+// signing stdin, and when the run failed before signing started. Step outputs and the job
+// summary are only written with args.GitHubActions.
+func Sign(ctx context.Context, args Config) (RunReport, error) {
+	log := args.Logger
+	if log == nil {
+		log = slog.New(slog.DiscardHandler)
+	}
+
+	log.Debug("Starting PGP Sign Artifact Action",
+		slog.String("backend", args.Backend),
+		slog.Bool("armor", args.Armor),
+		slog.Bool("detach_sign", args.DetachSign),
+		slog.Bool("clear_sign", args.ClearSign),
+		slog.Bool("has_passphrase", hasPassphrase(args)),
+		slog.Bool("sign_and_verify", args.SignAndVerify),
+		slog.Bool("verify_after_sign", args.VerifyAfterSign),
+		slog.Bool("dry_run", args.DryRun),
+	)
+
+	if err := validateInputs(args); err != nil {
+		return RunReport{}, err
+	}
+
+	finder := args.Finder
+	if finder == nil {
+		fileFinder, err := newFileFinder(ctx, args, log)
+		if err != nil {
+			return RunReport{}, err
+		}
+		finder = fileFinder
+	}
+
+	workDirs, err := resolveWorkDirs(args.WorkDir, args.GitHubActions)
+	if err != nil {
+		return RunReport{}, err
+	}
+	workDir := workDirs[0]
+	log.Debug("Working directories resolved", slog.Any("workdirs", workDirs))
+
+	opts, err := buildSignOptions(args, workDirs, log)
+	if err != nil {
+		return RunReport{}, err
+	}
+	if err := opts.Validate(); err != nil {
+		return RunReport{}, err
+	}
+	if readMode := readOnlyMode(args); readMode != nil {
+		return RunReport{}, readMode(ctx, args, finder, workDirs, opts, log)
+	}
+
+	kinds, err := resolveOutputKinds(args)
+	if err != nil {
+		return RunReport{}, err
+	}
+	logSignModes(opts, kinds, log)
+
+	cleanupHome, err := prepareGnuPGHome(&args, log)
+	if err != nil {
+		return RunReport{}, err
+	}
+	defer cleanupHome()
+
+	keys, err := loadSigningKeys(ctx, args, args.Signer, opts, kinds, log)
+	if err != nil {
+		return RunReport{}, err
+	}
+	if err := exportPublicKeys(args, keys, workDir, log); err != nil {
+		return RunReport{}, err
+	}
+
+	if runMode := inputMode(args); runMode != nil {
+		return RunReport{}, runMode(ctx, args, keys, workDir, log)
+	}
+
+	files, err := matchFiles(ctx, args, finder, workDirs, opts, log)
+	if err != nil {
+		return RunReport{}, err
+	}
+
+	if len(files) == 0 {
+		log.Warn("No files matched the specified patterns")
+		args.setSignatureOutputs(nil)
+		return newRunReport(args, opts, workDirs, nil), nil
+	}
+
+	if err := checkOutputPaths(files, keys); err != nil {
+		return RunReport{}, err
+	}
+
+	if args.DryRun {
+		reportDryRun(args, files, keys, log)
+		return RunReport{}, nil
+	}
+
+	fs := &fileSigner{
+		keys:               keys,
+		assertReproducible: args.AssertReproducible,
+		verifyAfterSign:    args.VerifyAfterSign,
+		skipExisting:       args.SkipExisting,
+		log:                log,
+	}
+	report, err := signFiles(ctx, args, fs, workDirs, files)
+	if err != nil {
+		return report, err
+	}
+
+	return report, publishPublicKeys(ctx, args, keys, log)
+}
+
+// inputMode returns the function that signs the input if it is not files on disk,
+// such as archive members, stdin or image digests, and nil otherwise.
+func inputMode(args Config) func(context.Context, Config, []signingKey, string, *slog.Logger) error {
+	switch {
+	case args.TarMembers:
+		return runTarMembers
+	case isStdinMode(args):
+		return runStdin
+	case args.ImageDigests != "":
+		return runImageDigests
+	}
+	return nil
+}
+
+// readOnlyMode returns the function that handles the matched files without signing them,
+// or nil if they are signed.
+func readOnlyMode(args Config) func(context.Context, Config, FileFinder, []string, SignOptions, *slog.Logger) error {
+	switch {
+	case args.ListOnly:
+		return listOnly
+	case isVerifyMode(args):
+		return runVerify
+	}
+	return nil
+}
+
+// signFiles signs the matched files and runs the steps that follow a successful signing pass,
+// and returns the report of the outcome for every file and key, also when signing failed.
+// Once ctx is cancelled no further files are started and a cancellation error is returned.
+func signFiles(ctx context.Context, args Config, fs *fileSigner, workDirs, files []string) (report RunReport, err error) {
+	// Load the trusted keys before signing, so a broken keyring fails the run early.
+	var keyring *KeyringVerifier
+	if args.VerifyKeyring != "" {
+		if keyring, err = loadVerifyKeyring(resolvePath(workDirs[0], args.VerifyKeyring)); err != nil {
+			return report, err
+		}
+	}
+
+	workers := resolveWorkers(args.Concurrency, args.MaxCPUPercent, availableCPUs())
+	fs.log.Info("Starting to sign files", slog.Int("count", len(files)), slog.Int("workers", workers))
+
+	// The report is built once signing stopped, whichever way it did.
+	defer func() {
+		if args.GitHubActions && !args.Quiet {
+			appendStepSummary(workDirs, fs.results(files), fs.log)
+		}
+		report = newRunReport(args, fs.keys[0].opts, workDirs, fs.allResults(files))
+		if args.ReportFile != "" {
+			err = errors.Join(err, writeRunReport(resolveReportPath(workDirs[0], args.ReportFile), report))
+		}
+	}()
+
+	var progress *progressTracker
+	if args.Progress && !args.Quiet {
+		progress = newProgressTracker(len(files), fs.log)
+	}
+	guard, err := newExpiryGuard(args.ExpiryGuard, fs.keys, len(files), fs.log)
+	if err != nil {
+		return report, err
+	}
+
+	var failed, signed atomic.Int64
+	signingStart := time.Now()
+	err = forEachFile(ctx, files, workers, args.ContinueOnError, func(file string) error {
+		if err := guard.check(); err != nil {
+			return err
+		}
+		err := fs.sign(file)
+		progress.fileDone(file)
+		guard.fileDone()
+		if err == nil {
+			signed.Add(1)
+		} else if args.ContinueOnError && ctx.Err() == nil {
+			failed.Add(1)
+			fs.log.Error("Failed to sign file, continuing", slog.String("file", file), slog.Any("error", err))
+		}
+		return err
+	})
+	fs.logSigningTime(files, time.Since(signingStart))
+	args.setSignatureOutputs(fs.signatures(files))
+	if ctx.Err() != nil {
+		return report, fmt.Errorf("signing cancelled with %d of %d files signed: %w", signed.Load(), len(files), ctx.Err())
+	}
+	if err != nil {
+		if args.ContinueOnError {
+			return report, fmt.Errorf("failed to sign %d of %d files: %w", failed.Load(), len(files), err)
+		}
+		return report, err
+	}
+
+	fs.log.Info("Successfully signed all files", slog.Int("count", len(files)))
+	return report, afterSigning(args, fs, workDirs, files, keyring)
+}
+
+// afterSigning writes the signatures manifest, the upload list and the signatures zip and
+// verifies the signatures as configured, once all files are signed.
+func afterSigning(args Config, fs *fileSigner, workDirs, files []string, keyring *KeyringVerifier) error {
+	if args.ManifestOut != "" {
+		if err := writeManifestFiles(args, fs, workDirs, files); err != nil {
+			return err
+		}
+	}
+
+	if args.UploadList != "" {
+		listPath := resolvePath(workDirs[0], args.UploadList)
+		if err := writeUploadList(listPath, files, fs.keys, args.UploadListIncludeSources); err != nil {
+			return err
+		}
+		fs.log.Info("Upload list written", slog.String("path", listPath))
+	}
+
+	if args.BundleOut != "" {
+		zipPath := resolvePath(workDirs[0], args.BundleOut)
+		if err := writeSignatureZip(zipPath, workDirs, files, fs.keys); err != nil {
+			return err
+		}
+		fs.log.Info("Signatures zip written", slog.String("path", zipPath))
+	}
+
+	if args.SignAndVerify {
+		if err := fs.verify(files, nil); err != nil {
+			return err
+		}
+		fs.log.Info("Successfully verified all signatures", slog.Int("count", len(files)*len(fs.keys)))
+	}
+
+	if keyring != nil {
+		fs.log.Info("Verifying signatures against trusted keyring", slog.Any("fingerprints", keyring.Fingerprints()))
+		if err := fs.verify(files, keyring); err != nil {
+			return fmt.Errorf("signatures do not verify against verify-keyring: %w", err)
+		}
+		fs.log.Info("Successfully verified all signatures against trusted keyring", slog.Int("count", len(files)*len(fs.keys)))
+	}
+
+	return nil
+}
+
+// verify checks the signatures of files made with every key, using verifier or, if it is
+// nil, the key's own signer, and records the outcome.
+func (fs *fileSigner) verify(files []string, verifier Verifier) error {
+	var errs []error
+	for _, key := range fs.keys {
+		keyVerifier := verifier
+		if keyVerifier == nil {
+			keyVerifier = key.signer
+		}
+		failedFiles, err := verifySignatures(keyVerifier, files, key.opts, fs.log)
+		fs.recordVerification(key, files, failedFiles)
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// recordResults remembers the outcome of signing file with every key.
+func (fs *fileSigner) recordResults(file string, results []SignResult) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if fs.outcomes == nil {
+		fs.outcomes = make(map[string][]SignResult)
+	}
+	fs.outcomes[file] = results
+}
+
+// recordVerification stores the outcome of the verification pass for key's signatures of files.
+func (fs *fileSigner) recordVerification(key signingKey, files, failed []string) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	// A set keeps this linear when most of a large release fails to verify.
+	failedSet := make(map[string]bool, len(failed))
+	for _, file := range failed {
+		failedSet[file] = true
+	}
+	for _, file := range files {
+		status := VerificationPassed
+		if failedSet[file] {
+			status = VerificationFailed
+		}
+		signature := getOutputPath(file, key.opts)
+		for i := range fs.outcomes[file] {
+			if fs.outcomes[file][i].OutputPath == signature && fs.outcomes[file][i].Status == StatusSigned {
+				fs.outcomes[file][i].Verification = status
+			}
+		}
+	}
+}
+
+// results returns the written signatures in the order of files.
+func (fs *fileSigner) results(files []string) []SignResult {
+	var results []SignResult
+	for _, result := range fs.allResults(files) {
+		if result.Status == StatusSigned {
+			results = append(results, result)
+		}
+	}
+	return results
+}
+
+// allResults returns the outcome of every file and key in the order of files.
+// Files that were not reached because an earlier file failed are reported as not attempted.
+func (fs *fileSigner) allResults(files []string) []SignResult {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	var results []SignResult
+	for _, file := range files {
+		if outcomes, ok := fs.outcomes[file]; ok {
+			results = append(results, outcomes...)
+			continue
+		}
+		results = append(results, SignResult{File: file, Status: StatusNotAttempted})
+	}
+	return results
+}
+
+// signatures returns the written signature paths in the order of files.
+func (fs *fileSigner) signatures(files []string) []string {
+	var signatures []string
+	for _, result := range fs.results(files) {
+		signatures = append(signatures, result.OutputPath)
+	}
+	return signatures
+}
+
+// validateInputs checks inputs that are independent of the signer and the matched files.
+func validateInputs(args Config) error {
+	retryPolicy := RetryPolicy{
+		Retries: args.NetworkRetries,
+		Backoff: args.NetworkBackoff,
+	}
+	if err := retryPolicy.Validate(); err != nil {
+		return err
+	}
+	if args.Keyserver != "" {
+		if _, err := keyserverAddURL(args.Keyserver); err != nil {
+			return err
+		}
+	}
+	if err := validateKeyInputs(args); err != nil {
+		return err
+	}
+	if args.Files == "" && args.FilesFrom == "" && args.FilesCommand == "" && args.ImageDigests == "" {
+		return fmt.Errorf("either files, files-from, files-command or image-digests must be set")
+	}
+	if args.GPGTimeout < 0 {
+		return fmt.Errorf("gpg-timeout must not be negative, got %s", args.GPGTimeout)
+	}
+	if args.ExpiryWarnWindow < 0 {
+		return fmt.Errorf("expiry-warn-window must not be negative, got %s", args.ExpiryWarnWindow)
+	}
+	if strings.ContainsAny(args.ArmorComment, "\r\n") {
+		return fmt.Errorf("armor-comment must be a single line")
+	}
+	if strings.ContainsAny(args.SignerComment, "\r\n") {
+		return fmt.Errorf("signer-comment must be a single line")
+	}
+	if err := validateFileFilterInputs(args); err != nil {
+		return err
+	}
+	if err := validateSignatureTime(args); err != nil {
+		return err
+	}
+	if _, err := parseExpiryGuardPolicy(args.ExpiryGuard); err != nil {
+		return err
+	}
+	if err := validateLogInputs(args); err != nil {
+		return err
+	}
+
+	if err := validateModeInputs(args); err != nil {
+		return err
+	}
+	if err := validateOutputInputs(args); err != nil {
+		return err
+	}
+
+	return validateWorkerInputs(args.Concurrency, args.MaxCPUPercent)
+}
+
+// validateOutputInputs checks the inputs naming where temporary files, the signatures
+// manifest and the signatures zip go, and what is written next to the signatures.
+func validateOutputInputs(args Config) error {
+	if err := checkTempDir(args.TempDir); err != nil {
+		return err
+	}
+	if args.SignManifest && args.ManifestOut == "" {
+		return fmt.Errorf("sign-manifest requires manifest-out")
+	}
+	if err := validateBundleOut(args); err != nil {
+		return err
+	}
+	return validateSidecarHeader(args)
+}
+
+// validateModeInputs checks the inputs of the modes that do not sign files on disk.
+func validateModeInputs(args Config) error {
+	for _, validate := range []func(Config) error{validateStdinMode, validateImageDigestMode, validateListOnly, validateVerifyMode} {
+		if err := validate(args); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateFileFilterInputs checks the inputs that filter matched files by size, age,
+// changes and extension.
+func validateFileFilterInputs(args Config) error {
+	if _, err := parseFileSize("max-file-size", args.MaxFileSize); err != nil {
+		return err
+	}
+	if _, err := parseOversizePolicy(args.OnOversize); err != nil {
+		return err
+	}
+	if _, err := parseEmptyFilePolicy(args.OnEmptyFile); err != nil {
+		return err
+	}
+	if _, err := parseFileSize("exclude-larger-than", args.ExcludeLargerThan); err != nil {
+		return err
+	}
+	if args.ExcludeOlderThan < 0 {
+		return fmt.Errorf("exclude-older-than must not be negative, got %s", args.ExcludeOlderThan)
+	}
+	if _, err := parseSensitivePolicy(args.WarnSensitive); err != nil {
+		return err
+	}
+	// The ref is resolved with git rev-parse, where a leading dash would be read as an option.
+	if strings.HasPrefix(args.ChangedSince, "-") {
+		return fmt.Errorf("changed-since must be a git ref, got %q", args.ChangedSince)
+	}
+	if err := validateExtensionInputs(args); err != nil {
+		return err
+	}
+	if err := validateFilesCommand(args); err != nil {
+		return err
+	}
+	return checkGlobBase(args.GlobBase)
+}
+
+// matchFiles returns the files to sign: those matching the patterns or listed in the
+// files-from manifest that pass the excludes, the changed-since and only-extensions
+// filters, the size limit, the on-empty-file policy and the warn-sensitive check.
+func matchFiles(ctx context.Context, args Config, finder FileFinder, workDirs []string, opts SignOptions, log *slog.Logger) ([]string, error) {
+	patterns, excludes, err := inputPatterns(args, workDirs[0], opts, log)
+	if err != nil {
+		return nil, err
+	}
+	files, err := findInputFiles(args, finder, workDirs, patterns, excludes, log)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireMatches(args, finder, workDirs, patterns, excludes, files); err != nil {
+		return nil, err
+	}
+	if files, err = filterChangedFiles(ctx, args, files, workDirs[0], log); err != nil {
+		return nil, err
+	}
+	if files, err = filterExtensions(args, files, log); err != nil {
+		return nil, err
+	}
+	if files, err = limitFileSizes(args, files, log); err != nil {
+		return nil, err
+	}
+	if files, err = checkEmptyFiles(args, files, log); err != nil {
+		return nil, err
+	}
+	if err := checkSensitiveFiles(args, files, log); err != nil {
+		return nil, err
+	}
+
+	log.Debug("Files matched", slog.Int("count", len(files)))
+	return files, nil
+}
+
+// inputPatterns returns the files patterns and excludes to match, rewritten for the
+// recursive and case-insensitive inputs.
+func inputPatterns(args Config, workDir string, opts SignOptions, log *slog.Logger) ([]string, []string, error) {
+	patterns := parseMultilineInput(args.Files)
+	if args.Recursive {
+		patterns = recursivePatterns(patterns)
+	}
+	excludes, err := buildExcludes(args, workDir, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	log.Debug("File patterns configured",
+		slog.Any("patterns", patterns),
+		slog.Any("excludes", excludes),
+		slog.Bool("recursive", args.Recursive),
+		slog.Bool("case_insensitive", args.CaseInsensitive),
+	)
+	if args.CaseInsensitive {
+		patterns, excludes = foldCasePatterns(patterns), foldCasePatterns(excludes)
+	}
+	return patterns, excludes, nil
+}
+
+// findInputFiles returns the files matching the patterns in any of the working directories,
+// followed by those listed in the files-from manifest that were not matched already.
+// The manifest is resolved against the primary working directory.
+func findInputFiles(args Config, finder FileFinder, workDirs, patterns, excludes []string, log *slog.Logger) ([]string, error) {
+	files, err := findFilesInRoots(finder, globRoots(args, workDirs), patterns, excludes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find files: %w", err)
+	}
+	if args.ArchiveDirs {
+		// SOURCE_DATE_EPOCH pins the modification times in the archives.
+		mtime, err := resolveSignatureTime("")
+		if err != nil {
+			return nil, err
+		}
+		if files, err = archiveDirectories(files, mtime, args.DryRun, log); err != nil {
+			return nil, err
+		}
+	}
+	if args.FilesFrom == "" {
+		return files, nil
+	}
+
+	manifestPath := resolvePath(workDirs[0], args.FilesFrom)
+	listed, err := readFilesFrom(manifestPath, workDirs[0], excludes, args.FilesFromStrict, log)
+	if err != nil {
+		return nil, err
+	}
+	log.Debug("Files read from manifest", slog.String("manifest", manifestPath), slog.Int("count", len(listed)))
+
+	return mergeFiles(files, listed), nil
+}
+
+// requireMatches returns an error for an empty files list if fail-on-no-match is set. To point
+// at the broken input, it tells patterns that match nothing apart from matches that were all excluded.
+// A CommandFileFinder answers the search without excludes from the output it already collected.
+func requireMatches(args Config, finder FileFinder, workDirs, patterns, excludes, files []string) error {
+	if len(files) > 0 || !args.FailOnNoMatch {
+		return nil
+	}
+
+	filePatterns, err := parseFilePatterns(patterns)
+	if err != nil {
+		return err
+	}
+	includes := make([]string, 0, len(filePatterns))
+	for _, filePattern := range filePatterns {
+		includes = append(includes, filePattern.Include)
+	}
+
+	roots := globRoots(args, workDirs)
+	if unfiltered, err := findFilesInRoots(finder, roots, includes, nil); err == nil && len(unfiltered) > 0 {
+		return fmt.Errorf("all %d files matching %s were excluded by %s", len(unfiltered), quoteList(patterns), quoteList(excludes))
+	}
+	if args.FilesFrom != "" {
+		return fmt.Errorf("no files matched %s in %s or were listed in %s", quoteList(patterns), strings.Join(roots, ", "), args.FilesFrom)
+	}
+	return fmt.Errorf("no files matched %s in %s", quoteList(patterns), strings.Join(roots, ", "))
+}
+
+// quoteList formats patterns as a quoted, comma separated list for error messages.
+func quoteList(patterns []string) string {
+	if len(patterns) == 0 {
+		return "(none)"
+	}
+	quoted := make([]string, len(patterns))
+	for i, pattern := range patterns {
+		quoted[i] = strconv.Quote(pattern)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// buildExcludes returns the exclude patterns for the finder. Unless sign-signatures is set,
+// signature files are excluded first, so "!" patterns in the user's excludes can re-include them.
+func buildExcludes(args Config, workDir string, opts SignOptions) ([]string, error) {
+	excludes, err := userExcludes(args, workDir)
+	if err != nil {
+		return nil, err
+	}
+	if args.SignSignatures {
+		return excludes, nil
+	}
+	return append(signatureExcludes(opts), excludes...), nil
+}
+
+// resolveWorkDir returns the default working directory: GITHUB_WORKSPACE in GitHub Actions
+// if it is set, and the current directory otherwise.
+func resolveWorkDir(githubActions bool) (string, error) {
+	var workDir string
+	if githubActions {
+		workDir = os.Getenv("GITHUB_WORKSPACE")
+	}
+	if workDir == "" {
+		var err error
+		workDir, err = os.Getwd()
+		if err != nil {
+			return "", fmt.Errorf("failed to get working directory: %w", err)
+		}
+	}
+	return workDir, nil
+}
+
+// buildSignOptions derives the signing options from the action inputs.
+func buildSignOptions(args Config, workDirs []string, log *slog.Logger) (SignOptions, error) {
+	signatureTime, err := resolveSignatureTime(args.signatureTimeInput())
+	if err != nil {
+		return SignOptions{}, err
+	}
+	if err := validateSignatureNaming(args.SignatureSuffix, args.SignatureName); err != nil {
+		return SignOptions{}, err
+	}
+	if args.AssertReproducible && signatureTime.IsZero() {
+		return SignOptions{}, fmt.Errorf("assert-reproducible requires a fixed signature time (set signature-time or SOURCE_DATE_EPOCH)")
+	}
+	if !signatureTime.IsZero() {
+		log.Debug("Using fixed signature time", slog.Time("signature_time", signatureTime))
+	}
+
+	digestAlgorithm, err := parseDigestAlgorithm(args.DigestAlgo)
+	if err != nil {
+		return SignOptions{}, err
+	}
+	if args.GnuPGCompat && digestAlgorithm != DigestDefault && digestAlgorithm != DigestSHA256 {
+		return SignOptions{}, fmt.Errorf("gnupg-compat always signs with sha256 and cannot be combined with digest-algo %s", digestAlgorithm)
+	}
+
+	if digestAlgorithm == DigestDefault {
+		log.Debug("Using the backend's default digest algorithm")
+	} else {
+		log.Debug("Using digest algorithm", slog.String("digest_algo", string(digestAlgorithm)))
+	}
+
+	notations, err := parseNotations(args.Notation)
+	if err != nil {
+		return SignOptions{}, err
+	}
+
+	outputMode, err := parseOutputMode(args.OutputMode)
+	if err != nil {
+		return SignOptions{}, err
+	}
+
+	onFileChange, err := parseFileChangePolicy(args.OnFileChange)
+	if err != nil {
+		return SignOptions{}, err
+	}
+
+	signatureType, err := parseSignatureType(args.SignatureType)
+	if err != nil {
+		return SignOptions{}, err
+	}
+
+	format, err := resolveSignatureFormat(args)
+	if err != nil {
+		return SignOptions{}, err
+	}
+
+	opts := SignOptions{
+		Armor:      args.Armor,
+		DetachSign: args.DetachSign || format != FormatPGP || args.ImageDigests != "", // Minisign, SSH and image digest signatures are always detached
+		ClearSign:  args.ClearSign,
+		Format:     format,
+
+		ArmorComment:      args.ArmorComment,
+		SignerComment:     args.SignerComment,
+		StripArmorVersion: args.StripArmorVersion,
+		MinimalArmor:      args.MinimalArmor,
+		SidecarHeader:     args.SidecarHeader,
+
+		CleartextEncoding: CleartextEncoding(args.CleartextEncoding),
+		TextMode:          args.TextMode,
+		SignatureType:     signatureType,
+		SignatureTime:     signatureTime,
+		Deterministic:     args.AssertReproducible,
+		GnuPGCompat:       args.GnuPGCompat,
+		DigestAlgorithm:   digestAlgorithm,
+		Notations:         notations,
+		EmbedFilename:     args.EmbedFilename,
+		SkipKeyValidation: args.SkipKeyValidation,
+		NoOverwrite:       args.NoOverwrite,
+		OutputMode:        outputMode,
+		OnFileChange:      onFileChange,
+
+		SignatureSuffix: normalizeSignatureSuffix(args.SignatureSuffix),
+		SignatureName:   args.SignatureName,
+	}
+	if err := applySignatureLayout(&opts, args, workDirs); err != nil {
+		return SignOptions{}, err
+	}
+
+	return opts, nil
+}
+
+// fileSigner signs individual files with every signing key and runs the configured
+// per-file checks and bundling.
+type fileSigner struct {
+	keys               []signingKey
+	assertReproducible bool
+	verifyAfterSign    bool
+	skipExisting       bool
+	log                *slog.Logger
+
+	mu       sync.Mutex
+	outcomes map[string][]SignResult
+}
+
+// sign signs a single file with every key.
+func (fs *fileSigner) sign(file string) error {
+	fs.log.Info("Signing file", slog.String("file", file))
+
+	var results []SignResult
+	defer func() { fs.recordResults(file, results) }()
+
+	for _, key := range fs.keys {
+		outputPath := getOutputPath(file, key.opts)
+		if fs.skipExisting && fileExists(outputPath) {
+			fs.log.Info("Signature already exists, skipping", slog.String("file", file), slog.String("signature", outputPath))
+			result := unsignedResult(file, key)
+			result.OutputPath = outputPath
+			result.Status = StatusSkipped
+			results = append(results, result)
+			continue
+		}
+
+		result, err := fs.signWithKey(file, key)
+		if err != nil {
+			result = unsignedResult(file, key)
+			result.Status = StatusFailed
+			result.Error = err.Error()
+			results = append(results, result)
+			return err
+		}
+
+		result.Status = StatusSigned
+		if fs.verifyAfterSign {
+			result.Verification = VerificationPassed
+		}
+		results = append(results, result)
+	}
+
+	return nil
+}
+
+// unsignedResult describes file for key when no signature was written.
+func unsignedResult(file string, key signingKey) SignResult {
+	result := SignResult{File: file, KeyID: key.keyID, Fingerprint: key.fingerprint}
+	if info, err := os.Stat(file); err == nil {
+		result.Bytes = info.Size()
+	}
+	return result
+}
+
+// signWithKey signs a single file with key and runs the per-file steps on the written signature.
+func (fs *fileSigner) signWithKey(file string, key signingKey) (SignResult, error) {
+	key.opts = key.opts.forFile(file)
+	if err := prepareOutputDir(file, key.opts); err != nil {
+		return SignResult{}, err
+	}
+	start := time.Now()
+	result, err := key.signer.SignFile(file, key.opts)
+	if err != nil {
+		return SignResult{}, fmt.Errorf("failed to sign file %s: %w", file, err)
+	}
+	elapsed := time.Since(start)
+	result.DurationMs = elapsed.Milliseconds()
+	// Signers that cannot identify their key leave it to the key loaded for them.
+	if result.KeyID == "" {
+		result.KeyID, result.Fingerprint = key.keyID, key.fingerprint
+	}
+	fs.log.Debug("File signed successfully",
+		slog.String("file", file),
+		slog.String("signature", result.OutputPath),
+		slog.String("digest_algo", result.DigestAlgo),
+		slog.Duration("duration", elapsed),
+		slog.Float64("bytes_per_second", throughput(result.Bytes, elapsed)),
+	)
+
+	if fs.verifyAfterSign {
+		if err := key.signer.Verify(file, result.OutputPath, key.opts); err != nil {
+			return SignResult{}, fmt.Errorf("signature verification failed for %s: %w", file, err)
+		}
+		fs.log.Debug("Signature verified", slog.String("file", file))
+	}
+
+	if fs.assertReproducible {
+		if err := assertReproducible(key.signer, file, key.opts); err != nil {
+			return SignResult{}, fmt.Errorf("reproducibility check failed for %s: %w", file, err)
+		}
+		fs.log.Debug("Signature is reproducible", slog.String("file", file))
+	}
+
+	if key.bundles != nil {
+		bundlePath, err := key.bundles.Write(file, result.OutputPath, key.opts)
+		if err != nil {
+			return SignResult{}, fmt.Errorf("failed to write bundle for %s: %w", file, err)
+		}
+		fs.log.Debug("Bundle written", slog.String("file", file), slog.String("bundle", bundlePath))
+	}
+
+	// The header is added last, so the checks and bundles above see the plain signature.
+	if key.opts.SidecarHeader {
+		headerPath, err := writeSidecarHeader(file, result.OutputPath, key.opts)
+		if err != nil {
+			return SignResult{}, fmt.Errorf("failed to write sidecar header for %s: %w", file, err)
+		}
+		fs.log.Debug("Sidecar header written", slog.String("file", file), slog.String("path", headerPath))
+	}
+
+	return result, nil
+}
+
+// fileExists reports whether a file or link exists at path.
+func fileExists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
+
+// parseMultilineInput splits a multiline string into a slice of trimmed, non-empty strings.
+func parseMultilineInput(input string) []string {
+	var result []string
+	lines := strings.Split(input, "\n")
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			result = append(result, line)
+		}
+	}
+	return result
+}
+
+// reportDryRun logs the files that would be signed and sets the outputs without signing anything.
+func reportDryRun(args Config, files []string, keys []signingKey, log *slog.Logger) {
+	for _, file := range files {
+		for _, key := range keys {
+			log.Info("Would sign file",
+				slog.String("file", file),
+				slog.String("signature", getOutputPath(file, key.opts)),
+			)
+		}
+	}
+	log.Info("Dry run complete, no files were signed", slog.Int("count", len(files)))
+
+	args.setSignatureOutputs(nil)
+	args.setActionOutput("would-sign-count", strconv.Itoa(len(files)))
+}
+
+// setActionOutput writes an output value for GitHub Actions. It does nothing unless
+// args.GitHubActions is set.
+func (args Config) setActionOutput(name, value string) {
+	if !args.GitHubActions {
+		return
+	}
+	outputFile := os.Getenv("GITHUB_OUTPUT")
+	if outputFile == "" {
+		fmt.Printf("::set-output name=%s::%s\n", name, escapeCommandValue(value))
+		return
+	}
+
+	f, err := os.OpenFile(outputFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to open GITHUB_OUTPUT file: %v\n", err)
+		fmt.Printf("::set-output name=%s::%s\n", name, escapeCommandValue(value))
+		return
+	}
+
+	if _, err := io.WriteString(f, formatActionOutput(name, value)); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to write to GITHUB_OUTPUT file: %v\n", err)
+		fmt.Printf("::set-output name=%s::%s\n", name, escapeCommandValue(value))
+	}
+
+	if err := f.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to close GITHUB_OUTPUT file: %v\n", err)
+	}
+}
+
+// formatActionOutput formats an entry for the GITHUB_OUTPUT file.
+// Multiline values use the heredoc syntax with a random delimiter.
+func formatActionOutput(name, value string) string {
+	if !strings.ContainsAny(value, "\r\n") {
+		return fmt.Sprintf("%s=%s\n", name, value)
+	}
+	delimiter := "ghadelimiter_" + rand.Text()
+	return fmt.Sprintf("%s<<%s\n%s\n%s\n", name, delimiter, value, delimiter)
+}
+
+// escapeCommandValue escapes a value for use in a workflow command.
+func escapeCommandValue(value string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(value)
+}
+
+// setSignatureOutputs publishes the written signature paths as action outputs.
+func (args Config) setSignatureOutputs(signatures []string) {
+	args.setActionOutput("signatures", strings.Join(relativizeToWorkspace(signatures), "\n"))
+	args.setActionOutput("signed-count", strconv.Itoa(len(signatures)))
+}
+
+// relativizeToWorkspace makes paths below GITHUB_WORKSPACE relative to it, so later steps
+// can pass them to upload globs as they are. Other paths, and all paths outside GitHub
+// Actions, are made absolute.
+func relativizeToWorkspace(paths []string) []string {
+	workspace := os.Getenv("GITHUB_WORKSPACE")
+	if workspace != "" {
+		if abs, err := filepath.Abs(workspace); err == nil {
+			workspace = abs
+		}
+	}
+
+	normalized := make([]string, len(paths))
+	for i, p := range paths {
+		if abs, err := filepath.Abs(p); err == nil {
+			p = abs
+		}
+		if workspace != "" {
+			if rel, err := filepath.Rel(workspace, p); err == nil && filepath.IsLocal(rel) {
+				p = filepath.ToSlash(rel)
+			}
+		}
+		normalized[i] = p
+	}
+	return normalized
+}
+
+// logSignMode logs the resolved signature type and output extension.
+func logSignMode(opts SignOptions, log *slog.Logger) {
+	mode := signMode(opts)
+	log.Info("Signing mode resolved",
+		slog.String("mode", mode),
+		slog.Bool("armor", opts.Armor || mode == "clear-sign"),
+		slog.String("extension", getOutputExtension(opts)),
+	)
+}
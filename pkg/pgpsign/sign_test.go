@@ -0,0 +1,1087 @@
+package pgpsign
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseMultilineInput(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{
+			name:     "empty input",
+			input:    "",
+			expected: nil,
+		},
+		{
+			name:     "single line",
+			input:    "file.txt",
+			expected: []string{"file.txt"},
+		},
+		{
+			name:     "multiple lines",
+			input:    "file1.txt\nfile2.txt\nfile3.txt",
+			expected: []string{"file1.txt", "file2.txt", "file3.txt"},
+		},
+		{
+			name:     "with empty lines",
+			input:    "file1.txt\n\nfile2.txt\n\n",
+			expected: []string{"file1.txt", "file2.txt"},
+		},
+		{
+			name:     "with whitespace",
+			input:    "  file1.txt  \n  file2.txt  ",
+			expected: []string{"file1.txt", "file2.txt"},
+		},
+		{
+			name:     "glob patterns",
+			input:    "dist/*\n*.tar.gz\n**/*.bin",
+			expected: []string{"dist/*", "*.tar.gz", "**/*.bin"},
+		},
+		{
+			name:     "only whitespace lines",
+			input:    "   \n\t\n  \n",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := parseMultilineInput(tt.input)
+			if len(result) != len(tt.expected) {
+				t.Errorf("expected %d items, got %d", len(tt.expected), len(result))
+				return
+			}
+			for i := range result {
+				if result[i] != tt.expected[i] {
+					t.Errorf("item %d: expected %q, got %q", i, tt.expected[i], result[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        Config
+		mockSigner  *MockSigner
+		mockFinder  *MockFileFinder
+		expectError bool
+	}{
+		{
+			name: "successful signing single file",
+			args: Config{
+				PrivateKey: "test-key",
+				Files:      "*.txt",
+				Armor:      true,
+				DetachSign: true,
+			},
+			mockSigner: &MockSigner{},
+			mockFinder: &MockFileFinder{
+				Files: []string{"/tmp/test/file.txt"},
+			},
+			expectError: false,
+		},
+		{
+			name: "successful signing multiple files",
+			args: Config{
+				PrivateKey: "test-key",
+				Files:      "*.txt\n*.bin",
+				Armor:      true,
+			},
+			mockSigner: &MockSigner{},
+			mockFinder: &MockFileFinder{
+				Files: []string{"/tmp/test/file.txt", "/tmp/test/file.bin"},
+			},
+			expectError: false,
+		},
+		{
+			name: "no files matched",
+			args: Config{
+				PrivateKey: "test-key",
+				Files:      "*.nonexistent",
+			},
+			mockSigner: &MockSigner{},
+			mockFinder: &MockFileFinder{
+				Files: []string{},
+			},
+			expectError: false,
+		},
+		{
+			name: "signer error",
+			args: Config{
+				PrivateKey: "test-key",
+				Files:      "*.txt",
+			},
+			mockSigner: &MockSigner{
+				Err: os.ErrPermission,
+			},
+			mockFinder: &MockFileFinder{
+				Files: []string{"/tmp/test/file.txt"},
+			},
+			expectError: true,
+		},
+		{
+			name: "finder error",
+			args: Config{
+				PrivateKey: "test-key",
+				Files:      "*.txt",
+			},
+			mockSigner: &MockSigner{},
+			mockFinder: &MockFileFinder{
+				Err: os.ErrNotExist,
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := run(context.Background(), tt.args, tt.mockSigner, tt.mockFinder, nil)
+			if tt.expectError && err == nil {
+				t.Error("expected error but got nil")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestRunSignOptionsPassthrough(t *testing.T) {
+	tests := []struct {
+		name         string
+		args         Config
+		expectedOpts SignOptions
+	}{
+		{
+			name: "armor only",
+			args: Config{
+				PrivateKey: "key",
+				Files:      "file.txt",
+				Armor:      true,
+			},
+			expectedOpts: SignOptions{
+				Armor:      true,
+				DetachSign: false,
+				ClearSign:  false,
+			},
+		},
+		{
+			name: "detach sign with armor",
+			args: Config{
+				PrivateKey: "key",
+				Files:      "file.txt",
+				Armor:      true,
+				DetachSign: true,
+			},
+			expectedOpts: SignOptions{
+				Armor:      true,
+				DetachSign: true,
+				ClearSign:  false,
+			},
+		},
+		{
+			name: "clear sign",
+			args: Config{
+				PrivateKey: "key",
+				Files:      "file.txt",
+				Armor:      true,
+				ClearSign:  true,
+			},
+			expectedOpts: SignOptions{
+				Armor:      true,
+				DetachSign: false,
+				ClearSign:  true,
+			},
+		},
+		{
+			name: "no armor binary output",
+			args: Config{
+				PrivateKey: "key",
+				Files:      "file.txt",
+				Armor:      false,
+				DetachSign: true,
+			},
+			expectedOpts: SignOptions{
+				Armor:      false,
+				DetachSign: true,
+				ClearSign:  false,
+			},
+		},
+		{
+			name: "digest algorithm",
+			args: Config{
+				PrivateKey: "key",
+				Files:      "file.txt",
+				DetachSign: true,
+				DigestAlgo: " SHA512 ",
+			},
+			expectedOpts: SignOptions{
+				DetachSign:      true,
+				DigestAlgorithm: DigestSHA512,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSigner := &MockSigner{}
+			mockFinder := &MockFileFinder{
+				Files: []string{"/tmp/file.txt"},
+			}
+
+			_ = run(context.Background(), tt.args, mockSigner, mockFinder, nil)
+
+			if len(mockSigner.SignedFiles) != 1 {
+				t.Fatalf("expected 1 signed file, got %d", len(mockSigner.SignedFiles))
+			}
+
+			opts := mockSigner.SignedOpts[0]
+			if opts.Armor != tt.expectedOpts.Armor {
+				t.Errorf("Armor: expected %v, got %v", tt.expectedOpts.Armor, opts.Armor)
+			}
+			if opts.DetachSign != tt.expectedOpts.DetachSign {
+				t.Errorf("DetachSign: expected %v, got %v", tt.expectedOpts.DetachSign, opts.DetachSign)
+			}
+			if opts.ClearSign != tt.expectedOpts.ClearSign {
+				t.Errorf("ClearSign: expected %v, got %v", tt.expectedOpts.ClearSign, opts.ClearSign)
+			}
+			if opts.DigestAlgorithm != tt.expectedOpts.DigestAlgorithm {
+				t.Errorf("DigestAlgorithm: expected %q, got %q", tt.expectedOpts.DigestAlgorithm, opts.DigestAlgorithm)
+			}
+		})
+	}
+}
+
+func TestRunRejectsInvalidDigestAlgo(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        Config
+		expectedErr string
+	}{
+		{
+			name:        "unknown algorithm",
+			args:        Config{PrivateKey: "key", Files: "file.txt", DigestAlgo: "md5"},
+			expectedErr: "unknown digest algorithm",
+		},
+		{
+			name:        "gnupg-compat with sha512",
+			args:        Config{PrivateKey: "key", Files: "file.txt", DigestAlgo: "sha512", GnuPGCompat: true},
+			expectedErr: "cannot be combined with digest-algo sha512",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSigner := &MockSigner{}
+			mockFinder := &MockFileFinder{Files: []string{"/tmp/file.txt"}}
+
+			err := run(context.Background(), tt.args, mockSigner, mockFinder, nil)
+			if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.expectedErr, err)
+			}
+			if len(mockSigner.SignedFiles) != 0 {
+				t.Errorf("expected no files to be signed, got %v", mockSigner.SignedFiles)
+			}
+		})
+	}
+}
+
+func TestRunRejectsContradictorySignModes(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        Config
+		expectedErr string
+	}{
+		{
+			name:        "clear-sign with detach-sign",
+			args:        Config{PrivateKey: "key", Files: "file.txt", Armor: true, DetachSign: true, ClearSign: true},
+			expectedErr: "clear-sign cannot be combined with detach-sign",
+		},
+		{
+			name:        "clear-sign with armor=false",
+			args:        Config{PrivateKey: "key", Files: "file.txt", ClearSign: true},
+			expectedErr: "clear-sign cannot be combined with armor=false",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSigner := &MockSigner{}
+			mockFinder := &MockFileFinder{Files: []string{"/tmp/file.txt"}}
+
+			err := run(context.Background(), tt.args, mockSigner, mockFinder, nil)
+			if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.expectedErr, err)
+			}
+			if len(mockSigner.SignedFiles) != 0 {
+				t.Errorf("expected no files to be signed, got %v", mockSigner.SignedFiles)
+			}
+		})
+	}
+}
+
+func TestRunSignAndVerify(t *testing.T) {
+	tests := []struct {
+		name             string
+		signAndVerify    bool
+		verifyErr        error
+		expectError      bool
+		expectedVerified int
+	}{
+		{
+			name:             "disabled",
+			signAndVerify:    false,
+			expectedVerified: 0,
+		},
+		{
+			name:             "all signatures verify",
+			signAndVerify:    true,
+			expectedVerified: 2,
+		},
+		{
+			name:          "verification failure",
+			signAndVerify: true,
+			verifyErr:     os.ErrInvalid,
+			expectError:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSigner := &MockSigner{VerifyErr: tt.verifyErr}
+			mockFinder := &MockFileFinder{
+				Files: []string{"/tmp/file1.txt", "/tmp/file2.txt"},
+			}
+			args := Config{
+				PrivateKey:    "key",
+				Files:         "*.txt",
+				SignAndVerify: tt.signAndVerify,
+			}
+
+			err := run(context.Background(), args, mockSigner, mockFinder, nil)
+			if tt.expectError && err == nil {
+				t.Error("expected error but got nil")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if len(mockSigner.VerifiedFiles) != tt.expectedVerified {
+				t.Errorf("expected %d verified files, got %d", tt.expectedVerified, len(mockSigner.VerifiedFiles))
+			}
+		})
+	}
+}
+
+func TestRunAssertReproducible(t *testing.T) {
+	t.Setenv("SOURCE_DATE_EPOCH", "")
+
+	tests := []struct {
+		name          string
+		signatureTime string
+		expectError   bool
+	}{
+		{
+			name:        "requires fixed signature time",
+			expectError: true,
+		},
+		{
+			name:          "with fixed signature time",
+			signatureTime: "1700000000",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testFile := filepath.Join(t.TempDir(), "file.txt")
+			if err := os.WriteFile(testFile, []byte("content"), 0o644); err != nil {
+				t.Fatalf("failed to create test file: %v", err)
+			}
+
+			mockSigner := &MockSigner{}
+			mockFinder := &MockFileFinder{Files: []string{testFile}}
+			if err := os.WriteFile(testFile+".asc", []byte("signature"), 0o644); err != nil {
+				t.Fatalf("failed to create signature file: %v", err)
+			}
+
+			args := Config{
+				PrivateKey:         "key",
+				Files:              "*.txt",
+				Armor:              true,
+				SignatureTime:      tt.signatureTime,
+				AssertReproducible: true,
+			}
+
+			err := run(context.Background(), args, mockSigner, mockFinder, nil)
+			if tt.expectError && err == nil {
+				t.Error("expected error but got nil")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !tt.expectError {
+				if len(mockSigner.SignedFiles) != 2 {
+					t.Errorf("expected file to be signed twice, got %d", len(mockSigner.SignedFiles))
+				}
+				if !mockSigner.SignedOpts[0].Deterministic {
+					t.Error("expected deterministic signing to be enabled")
+				}
+			}
+		})
+	}
+}
+
+func TestRunConcurrency(t *testing.T) {
+	files := []string{"/tmp/a.txt", "/tmp/b.txt", "/tmp/c.txt", "/tmp/d.txt", "/tmp/e.txt"}
+
+	mockSigner := &MockSigner{}
+	mockFinder := &MockFileFinder{Files: files}
+	args := Config{
+		PrivateKey:    "key",
+		Files:         "*.txt",
+		Concurrency:   4,
+		MaxCPUPercent: 50,
+	}
+
+	if err := run(context.Background(), args, mockSigner, mockFinder, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	signed := slices.Clone(mockSigner.SignedFiles)
+	slices.Sort(signed)
+	if !slices.Equal(signed, files) {
+		t.Errorf("expected every file signed once, got %v", mockSigner.SignedFiles)
+	}
+
+	args.MaxCPUPercent = 150
+	if err := run(context.Background(), args, &MockSigner{}, mockFinder, nil); err == nil {
+		t.Error("expected error for max-cpu-percent above 100")
+	}
+}
+
+// tamperingSigner corrupts every signature right after the wrapped signer writes it.
+type tamperingSigner struct {
+	Signer
+}
+
+func (s *tamperingSigner) SignFile(filePath string, opts SignOptions) (SignResult, error) {
+	result, err := s.Signer.SignFile(filePath, opts)
+	if err != nil {
+		return SignResult{}, err
+	}
+	data, err := os.ReadFile(result.OutputPath)
+	if err != nil {
+		return SignResult{}, err
+	}
+	tampered := strings.Replace(string(data), "\n\n", "\n\nAAAA", 1)
+	return result, os.WriteFile(result.OutputPath, []byte(tampered), 0o644)
+}
+
+func TestRunVerifyAfterSign(t *testing.T) {
+	armoredKey := generateTestKeyArmored(t, "Test", "test@test.com", "")
+	signer, err := NewGoPGPSigner(armoredKey, "", "")
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		signer      Signer
+		expectError bool
+	}{
+		{name: "valid signature", signer: signer},
+		{name: "tampered signature", signer: &tamperingSigner{Signer: signer}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testFile := filepath.Join(t.TempDir(), "release.txt")
+			if err := os.WriteFile(testFile, []byte("release content"), 0o644); err != nil {
+				t.Fatalf("failed to create test file: %v", err)
+			}
+
+			args := Config{
+				PrivateKey:      "key",
+				Files:           "*.txt",
+				Armor:           true,
+				DetachSign:      true,
+				VerifyAfterSign: true,
+			}
+
+			err := run(context.Background(), args, tt.signer, &MockFileFinder{Files: []string{testFile}}, nil)
+			if tt.expectError {
+				if err == nil {
+					t.Fatal("expected verification to catch the tampered signature")
+				}
+				if !strings.Contains(err.Error(), testFile) {
+					t.Errorf("expected error to name %s, got: %v", testFile, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestRunUploadList(t *testing.T) {
+	workDir := t.TempDir()
+	mockFinder := &MockFileFinder{
+		Files: []string{filepath.Join(workDir, "file1.txt"), filepath.Join(workDir, "file2.txt")},
+	}
+	args := Config{
+		PrivateKey: "key",
+		Files:      "*.txt",
+		Armor:      true,
+		DetachSign: true,
+		WorkDir:    workDir,
+		UploadList: "upload.txt",
+	}
+
+	if err := run(context.Background(), args, &MockSigner{}, mockFinder, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(workDir, "upload.txt"))
+	if err != nil {
+		t.Fatalf("expected upload list in working directory: %v", err)
+	}
+
+	expected := mockFinder.Files[0] + ".asc\n" + mockFinder.Files[1] + ".asc\n"
+	if string(data) != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, data)
+	}
+}
+
+func TestRunSetsSignatureOutputs(t *testing.T) {
+	outputFile := filepath.Join(t.TempDir(), "github_output")
+	t.Setenv("GITHUB_OUTPUT", outputFile)
+	t.Setenv("GITHUB_WORKSPACE", "")
+
+	mockFinder := &MockFileFinder{
+		Files: []string{"/tmp/file1.txt", "/tmp/file2.bin"},
+	}
+	args := Config{
+		PrivateKey: "key",
+		Files:      "*",
+		Armor:      true,
+		DetachSign: true,
+	}
+
+	if err := run(context.Background(), args, &MockSigner{}, mockFinder, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("failed to read GITHUB_OUTPUT: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("expected 5 output lines, got %d:\n%s", len(lines), data)
+	}
+
+	delimiter, ok := strings.CutPrefix(lines[0], "signatures<<")
+	if !ok {
+		t.Fatalf("expected multiline signatures output, got %q", lines[0])
+	}
+	if lines[1] != "/tmp/file1.txt.asc" || lines[2] != "/tmp/file2.bin.asc" {
+		t.Errorf("unexpected signature paths: %q, %q", lines[1], lines[2])
+	}
+	if lines[3] != delimiter {
+		t.Errorf("expected closing delimiter %q, got %q", delimiter, lines[3])
+	}
+	if lines[4] != "signed-count=2" {
+		t.Errorf("expected signed-count=2, got %q", lines[4])
+	}
+}
+
+func TestRelativizeToWorkspace(t *testing.T) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get working directory: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		workspace string
+		paths     []string
+		want      []string
+	}{
+		{
+			name:      "inside workspace",
+			workspace: "/home/runner/work/repo",
+			paths:     []string{"/home/runner/work/repo/dist/app.tar.gz.asc", "/home/runner/work/repo/app.sig"},
+			want:      []string{"dist/app.tar.gz.asc", "app.sig"},
+		},
+		{
+			name:      "outside workspace",
+			workspace: "/home/runner/work/repo",
+			paths:     []string{"/tmp/build/app.asc", "/home/runner/work/repo-other/app.asc"},
+			want:      []string{"/tmp/build/app.asc", "/home/runner/work/repo-other/app.asc"},
+		},
+		{
+			name:      "workspace with trailing slash",
+			workspace: "/home/runner/work/repo/",
+			paths:     []string{"/home/runner/work/repo/app.asc"},
+			want:      []string{"app.asc"},
+		},
+		{
+			name:      "relative path below workspace",
+			workspace: cwd,
+			paths:     []string{"dist/app.asc"},
+			want:      []string{"dist/app.asc"},
+		},
+		{
+			name:  "no workspace",
+			paths: []string{"/tmp/build/app.asc", "dist/app.asc"},
+			want:  []string{"/tmp/build/app.asc", filepath.Join(cwd, "dist", "app.asc")},
+		},
+		{
+			name:      "empty",
+			workspace: "/home/runner/work/repo",
+			paths:     nil,
+			want:      []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GITHUB_WORKSPACE", tt.workspace)
+			if got := relativizeToWorkspace(tt.paths); !slices.Equal(got, tt.want) {
+				t.Errorf("relativizeToWorkspace() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatActionOutput(t *testing.T) {
+	if got := formatActionOutput("signed-count", "3"); got != "signed-count=3\n" {
+		t.Errorf("unexpected single-line output: %q", got)
+	}
+
+	got := formatActionOutput("signatures", "a.asc\nb.asc")
+	lines := strings.Split(got, "\n")
+	delimiter, ok := strings.CutPrefix(lines[0], "signatures<<")
+	if !ok || delimiter == "" {
+		t.Fatalf("expected heredoc syntax, got %q", got)
+	}
+	if got != "signatures<<"+delimiter+"\na.asc\nb.asc\n"+delimiter+"\n" {
+		t.Errorf("unexpected multiline output: %q", got)
+	}
+}
+
+func TestRunDryRun(t *testing.T) {
+	outputFile := filepath.Join(t.TempDir(), "github_output")
+	t.Setenv("GITHUB_OUTPUT", outputFile)
+
+	workDir := t.TempDir()
+	for _, name := range []string{"app.tar.gz", "app.zip"} {
+		if err := os.WriteFile(filepath.Join(workDir, name), []byte("artifact"), 0o644); err != nil {
+			t.Fatalf("failed to create test file: %v", err)
+		}
+	}
+
+	args := Config{
+		PrivateKey: generateTestKeyArmored(t, "Test", "test@test.com", ""),
+		Files:      "app.*",
+		Backend:    string(BackendGoPGP),
+		Armor:      true,
+		DetachSign: true,
+		WorkDir:    workDir,
+		DryRun:     true,
+	}
+
+	if err := run(context.Background(), args, nil, nil, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, pattern := range []string{"*.asc", "*.sig"} {
+		matches, err := filepath.Glob(filepath.Join(workDir, pattern))
+		if err != nil {
+			t.Fatalf("failed to glob: %v", err)
+		}
+		if len(matches) != 0 {
+			t.Errorf("dry run must not write signatures, found %v", matches)
+		}
+	}
+
+	data, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("failed to read GITHUB_OUTPUT: %v", err)
+	}
+	for _, expected := range []string{"signatures=\n", "signed-count=0\n", "would-sign-count=2\n"} {
+		if !strings.Contains(string(data), expected) {
+			t.Errorf("expected output %q, got:\n%s", expected, data)
+		}
+	}
+
+	args.PrivateKey = "invalid-key"
+	if err := run(context.Background(), args, nil, nil, nil); err == nil {
+		t.Error("expected dry run to fail for an invalid key")
+	}
+}
+
+func TestRunContinueOnError(t *testing.T) {
+	armoredKey := generateTestKeyArmored(t, "Test", "test@test.com", "")
+	signer, err := NewGoPGPSigner(armoredKey, "", "")
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+
+	tests := []struct {
+		name            string
+		continueOnError bool
+		expectedSigned  []string
+	}{
+		{name: "stop at first failure", continueOnError: false, expectedSigned: []string{"a.txt"}},
+		{name: "continue on error", continueOnError: true, expectedSigned: []string{"a.txt", "c.txt"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workDir := t.TempDir()
+			files := []string{
+				filepath.Join(workDir, "a.txt"),
+				filepath.Join(workDir, "missing.txt"),
+				filepath.Join(workDir, "c.txt"),
+			}
+			for _, file := range []string{files[0], files[2]} {
+				if err := os.WriteFile(file, []byte("content"), 0o644); err != nil {
+					t.Fatalf("failed to create test file: %v", err)
+				}
+			}
+
+			args := Config{
+				PrivateKey:      "key",
+				Files:           "*.txt",
+				Armor:           true,
+				DetachSign:      true,
+				ContinueOnError: tt.continueOnError,
+			}
+
+			err := run(context.Background(), args, signer, &MockFileFinder{Files: files}, nil)
+			if err == nil {
+				t.Fatal("expected error for unreadable file")
+			}
+			if !strings.Contains(err.Error(), "missing.txt") {
+				t.Errorf("expected error to name the failed file, got: %v", err)
+			}
+
+			for _, name := range []string{"a.txt", "c.txt"} {
+				_, statErr := os.Stat(filepath.Join(workDir, name+".asc"))
+				signed := statErr == nil
+				if signed != slices.Contains(tt.expectedSigned, name) {
+					t.Errorf("unexpected signing state for %s: signed=%v", name, signed)
+				}
+			}
+		})
+	}
+}
+
+// cancellingSigner cancels the run's context once it has signed a file, like a job
+// cancelled while its files are being signed.
+type cancellingSigner struct {
+	*GoPGPSigner
+	cancel context.CancelFunc
+}
+
+func (s *cancellingSigner) SignFile(filePath string, opts SignOptions) (SignResult, error) {
+	defer s.cancel()
+	return s.GoPGPSigner.SignFile(filePath, opts)
+}
+
+func TestRunCancelled(t *testing.T) {
+	signer, err := NewGoPGPSigner(generateTestKeyArmored(t, "Test", "test@test.com", ""), "", "")
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+
+	for _, continueOnError := range []bool{false, true} {
+		t.Run(fmt.Sprintf("continue-on-error=%v", continueOnError), func(t *testing.T) {
+			workDir := t.TempDir()
+			writeTestFiles(t, workDir, "a.txt", "b.txt", "c.txt", "d.txt")
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			args := Config{
+				PrivateKey:      "key",
+				Files:           "*.txt",
+				Armor:           true,
+				DetachSign:      true,
+				WorkDir:         workDir,
+				ContinueOnError: continueOnError,
+			}
+
+			err := run(ctx, args, &cancellingSigner{GoPGPSigner: signer, cancel: cancel}, nil, nil)
+			if !errors.Is(err, context.Canceled) || !strings.Contains(err.Error(), "1 of 4 files signed") {
+				t.Fatalf("expected cancellation error after the first file, got %v", err)
+			}
+
+			for _, name := range []string{"a.txt", "b.txt", "c.txt", "d.txt"} {
+				_, statErr := os.Stat(filepath.Join(workDir, name+".asc"))
+				if signed := statErr == nil; signed != (name == "a.txt") {
+					t.Errorf("unexpected signing state for %s after cancelling: signed=%v", name, signed)
+				}
+			}
+		})
+	}
+}
+
+func TestRunSkipsSignatureFiles(t *testing.T) {
+	tests := []struct {
+		name           string
+		excludes       string
+		signSignatures bool
+		expected       []string
+	}{
+		{
+			name:     "signatures from a previous run are skipped",
+			expected: []string{"release.tar.gz"},
+		},
+		{
+			name:     "negated exclude re-includes a signature file",
+			excludes: "!KEYS.asc",
+			expected: []string{"release.tar.gz", "KEYS.asc"},
+		},
+		{
+			name:           "sign-signatures signs everything",
+			signSignatures: true,
+			expected:       []string{"release.tar.gz", "release.tar.gz.asc", "release.tar.gz.sig", "KEYS.asc"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workDir := t.TempDir()
+			for _, name := range []string{"release.tar.gz", "release.tar.gz.asc", "release.tar.gz.sig", "KEYS.asc"} {
+				if err := os.WriteFile(filepath.Join(workDir, name), []byte("content of "+name), 0o644); err != nil {
+					t.Fatalf("failed to create test file: %v", err)
+				}
+			}
+
+			mockSigner := &MockSigner{}
+			args := Config{
+				PrivateKey:     "key",
+				Files:          "*",
+				Excludes:       tt.excludes,
+				Armor:          true,
+				DetachSign:     true,
+				WorkDir:        workDir,
+				SignSignatures: tt.signSignatures,
+			}
+			if err := run(context.Background(), args, mockSigner, &DefaultFileFinder{}, nil); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var signed []string
+			for _, file := range mockSigner.SignedFiles {
+				signed = append(signed, filepath.Base(file))
+			}
+			slices.Sort(signed)
+			expected := slices.Sorted(slices.Values(tt.expected))
+			if !slices.Equal(signed, expected) {
+				t.Errorf("expected %v to be signed, got %v", expected, signed)
+			}
+		})
+	}
+}
+
+func TestRunExistingSignatures(t *testing.T) {
+	privateKey := generateTestKeyArmored(t, "Test User", "test@example.com", "")
+	const existing = "existing signature"
+
+	tests := []struct {
+		name         string
+		noOverwrite  bool
+		skipExisting bool
+		expectErr    bool
+		expectKept   bool
+	}{
+		{name: "overwrite by default"},
+		{name: "no-overwrite fails", noOverwrite: true, expectErr: true, expectKept: true},
+		{name: "skip-existing skips", skipExisting: true, expectKept: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workDir := t.TempDir()
+			for _, name := range []string{"a.txt", "b.txt"} {
+				if err := os.WriteFile(filepath.Join(workDir, name), []byte("content of "+name), 0o644); err != nil {
+					t.Fatalf("failed to create test file: %v", err)
+				}
+			}
+			existingSig := filepath.Join(workDir, "a.txt.asc")
+			if err := os.WriteFile(existingSig, []byte(existing), 0o644); err != nil {
+				t.Fatalf("failed to create existing signature: %v", err)
+			}
+
+			args := Config{
+				PrivateKey:   privateKey,
+				Files:        "*.txt",
+				Armor:        true,
+				DetachSign:   true,
+				WorkDir:      workDir,
+				Backend:      "gopgp",
+				NoOverwrite:  tt.noOverwrite,
+				SkipExisting: tt.skipExisting,
+			}
+
+			err := run(context.Background(), args, nil, &DefaultFileFinder{}, nil)
+			if tt.expectErr {
+				if !errors.Is(err, errSignatureExists) {
+					t.Fatalf("expected errSignatureExists, got %v", err)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			data, err := os.ReadFile(existingSig)
+			if err != nil {
+				t.Fatalf("failed to read signature: %v", err)
+			}
+			if kept := string(data) == existing; kept != tt.expectKept {
+				t.Errorf("expected existing signature kept=%v, got %v", tt.expectKept, kept)
+			}
+
+			if !tt.expectErr {
+				if _, err := os.Stat(filepath.Join(workDir, "b.txt.asc")); err != nil {
+					t.Errorf("expected b.txt to be signed: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestRunFailOnNoMatch(t *testing.T) {
+	workDir := t.TempDir()
+	writeTestFiles(t, workDir, "release.tar.gz", "notes.txt")
+
+	tests := []struct {
+		name        string
+		files       string
+		excludes    string
+		errContains string
+	}{
+		{name: "files matched", files: "*.tar.gz"},
+		{name: "no files matched", files: "*.zip\ndist/*", errContains: `no files matched "*.zip", "dist/*" in ` + workDir},
+		{name: "all matches excluded", files: "*.tar.gz\n*.txt", excludes: "*.txt\n*.gz", errContains: `all 2 files matching "*.tar.gz", "*.txt" were excluded by`},
+		{name: "all matches excluded by scoped excludes", files: "* => *.txt,*.gz", errContains: "all 2 files matching"},
+	}
+
+	for _, tt := range tests {
+		for _, failOnNoMatch := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/fail-on-no-match=%t", tt.name, failOnNoMatch), func(t *testing.T) {
+				signer := &MockSigner{}
+				args := Config{
+					PrivateKey:    "test-key",
+					Files:         tt.files,
+					Excludes:      tt.excludes,
+					DetachSign:    true,
+					WorkDir:       workDir,
+					FailOnNoMatch: failOnNoMatch,
+				}
+				err := run(context.Background(), args, signer, nil, nil)
+
+				if tt.errContains == "" {
+					if err != nil {
+						t.Fatalf("unexpected error: %v", err)
+					}
+					if len(signer.SignedFiles) != 1 {
+						t.Errorf("expected 1 signed file, got %v", signer.SignedFiles)
+					}
+					return
+				}
+				if !failOnNoMatch {
+					if err != nil {
+						t.Errorf("expected only a warning without fail-on-no-match, got %v", err)
+					}
+					return
+				}
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Errorf("expected error containing %q, got %v", tt.errContains, err)
+				}
+			})
+		}
+	}
+}
+
+func TestRunQuiet(t *testing.T) {
+	workDir := t.TempDir()
+	writeTestFiles(t, workDir, "release.tar.gz")
+	summaryFile := filepath.Join(t.TempDir(), "summary.md")
+	t.Setenv("GITHUB_STEP_SUMMARY", summaryFile)
+
+	var buf strings.Builder
+	args := Config{
+		PrivateKey: "unused",
+		Files:      "*.tar.gz",
+		DetachSign: true,
+		WorkDir:    workDir,
+		Progress:   true,
+		Quiet:      true,
+		LogLevel:   "debug",
+	}
+	log, err := NewLogger(args, &buf, &buf)
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	if err := run(context.Background(), args, &MockSigner{}, nil, log); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if buf.Len() != 0 {
+		t.Errorf("expected no log output in quiet mode, got:\n%s", buf.String())
+	}
+	if _, err := os.Stat(summaryFile); !os.IsNotExist(err) {
+		t.Errorf("expected no job summary in quiet mode, got %v", err)
+	}
+}
+
+func TestRunRejectsUnknownLogFormat(t *testing.T) {
+	args := Config{PrivateKey: "unused", Files: "*", LogFormat: "yaml"}
+	if err := run(context.Background(), args, &MockSigner{}, &MockFileFinder{}, nil); err == nil || !strings.Contains(err.Error(), "unknown log-format") {
+		t.Errorf("expected unknown log-format error, got %v", err)
+	}
+}
+
+func BenchmarkRun_10kFiles(b *testing.B) {
+	workDir := b.TempDir()
+	files := writeBenchmarkTree(b, workDir, 10000)
+	b.Setenv("GITHUB_OUTPUT", filepath.Join(b.TempDir(), "output"))
+	args := Config{
+		PrivateKey:    "key",
+		Files:         "**/*.bin",
+		Armor:         true,
+		DetachSign:    true,
+		WorkDir:       workDir,
+		OutputDir:     filepath.Join(workDir, "signatures"),
+		SignAndVerify: true,
+	}
+	errVerify := errors.New("bad signature")
+
+	for b.Loop() {
+		// Every signature failing verification is the worst case for recording the outcomes.
+		signer := &MockSigner{VerifyErr: errVerify}
+		err := run(context.Background(), args, signer, &MockFileFinder{Files: files}, nil)
+		if !errors.Is(err, errVerify) {
+			b.Fatalf("expected verification to fail, got %v", err)
+		}
+		if len(signer.SignedFiles) != len(files) {
+			b.Fatalf("expected %d signed files, got %d", len(files), len(signer.SignedFiles))
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package pgpsign
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"slices"
+)
+
+// manifestEntry is a line of the signatures manifest.
+type manifestEntry struct {
+	sha256    string
+	file      string
+	signature string
+}
+
+// writeManifestFiles writes the signatures manifest of the run to the manifest-out input
+// and, with sign-manifest, signs it with every key like the other files.
+func writeManifestFiles(args Config, fs *fileSigner, workDirs, files []string) error {
+	manifestPath := resolvePath(workDirs[0], args.ManifestOut)
+	if err := writeSignatureManifest(manifestPath, workDirs, fs.allResults(files)); err != nil {
+		return err
+	}
+	fs.log.Info("Signatures manifest written", slog.String("path", manifestPath))
+
+	if !args.SignManifest {
+		return nil
+	}
+	for _, key := range fs.keys {
+		result, err := fs.signWithKey(manifestPath, key)
+		if err != nil {
+			return fmt.Errorf("failed to sign manifest: %w", err)
+		}
+		fs.log.Info("Signatures manifest signed", slog.String("path", manifestPath), slog.String("signature", result.OutputPath))
+	}
+	return nil
+}
+
+// writeSignatureManifest writes one line per signature on disk to path: the SHA-256 of the
+// signed file, the file and its signature, separated by two spaces. Paths below a working
+// directory are relative to it. Lines are sorted by file, then signature, so the manifest
+// does not depend on the order files were signed in.
+func writeSignatureManifest(path string, workDirs []string, results []SignResult) error {
+	digests := make(map[string]string)
+	var entries []manifestEntry
+	for _, result := range results {
+		// Skipped files keep the signature found on disk, so they are listed as well.
+		if result.OutputPath == "" || (result.Status != StatusSigned && result.Status != StatusSkipped) {
+			continue
+		}
+		digest, ok := digests[result.File]
+		if !ok {
+			sum, err := sha256File(result.File)
+			if err != nil {
+				return fmt.Errorf("failed to write manifest: %s: %w", result.File, err)
+			}
+			digest = hex.EncodeToString(sum)
+			digests[result.File] = digest
+		}
+		entries = append(entries, manifestEntry{
+			sha256:    digest,
+			file:      relativeTo(workDirs, result.File),
+			signature: relativeTo(workDirs, result.OutputPath),
+		})
+	}
+
+	slices.SortFunc(entries, func(a, b manifestEntry) int {
+		return cmp.Or(cmp.Compare(a.file, b.file), cmp.Compare(a.signature, b.signature))
+	})
+
+	var buf bytes.Buffer
+	for _, entry := range entries {
+		fmt.Fprintf(&buf, "%s  %s  %s\n", entry.sha256, entry.file, entry.signature)
+	}
+	if err := writeOutputFile(path, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
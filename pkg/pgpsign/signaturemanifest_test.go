@@ -0,0 +1,121 @@
+package pgpsign
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// sha256Hex returns the hex SHA-256 of data.
+func sha256Hex(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestWriteSignatureManifest(t *testing.T) {
+	workDir := t.TempDir()
+	writeTestFiles(t, workDir, "dist/b.bin", "a.bin", "c.bin")
+	file := func(name string) string { return filepath.Join(workDir, filepath.FromSlash(name)) }
+
+	results := []SignResult{
+		{File: file("dist/b.bin"), OutputPath: file("dist/b.bin.asc"), Status: StatusSigned},
+		{File: file("a.bin"), OutputPath: file("a.bin.sig"), Status: StatusSigned},
+		{File: file("a.bin"), OutputPath: file("a.bin.asc"), Status: StatusSkipped},
+		{File: file("c.bin"), Status: StatusFailed},
+		{File: file("d.bin"), Status: StatusNotAttempted},
+	}
+
+	manifestPath := filepath.Join(t.TempDir(), "signatures.txt")
+	if err := writeSignatureManifest(manifestPath, []string{workDir}, results); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		t.Fatalf("failed to read manifest: %v", err)
+	}
+	expected := sha256Hex("a.bin") + "  a.bin  a.bin.asc\n" +
+		sha256Hex("a.bin") + "  a.bin  a.bin.sig\n" +
+		sha256Hex("dist/b.bin") + "  dist/b.bin  dist/b.bin.asc\n"
+	if string(data) != expected {
+		t.Errorf("expected manifest:\n%s\ngot:\n%s", expected, data)
+	}
+}
+
+func TestRunSignManifest(t *testing.T) {
+	signer, err := NewGoPGPSigner(generateTestKeyArmored(t, "Test", "test@test.com", ""), "", "")
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		signManifest  bool
+		wantSignature bool
+	}{
+		{name: "manifest only"},
+		{name: "signed manifest", signManifest: true, wantSignature: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workDir := t.TempDir()
+			writeTestFiles(t, workDir, "app.tar.gz", "lib/core.bin")
+
+			args := Config{
+				PrivateKey:   "key",
+				Files:        "*.tar.gz\nlib/*.bin",
+				Armor:        true,
+				DetachSign:   true,
+				WorkDir:      workDir,
+				ManifestOut:  "signatures.txt",
+				SignManifest: tt.signManifest,
+			}
+			if err := run(context.Background(), args, signer, nil, nil); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			manifestPath := filepath.Join(workDir, "signatures.txt")
+			data, err := os.ReadFile(manifestPath)
+			if err != nil {
+				t.Fatalf("expected manifest: %v", err)
+			}
+			expected := sha256Hex("app.tar.gz") + "  app.tar.gz  app.tar.gz.asc\n" +
+				sha256Hex("lib/core.bin") + "  lib/core.bin  lib/core.bin.asc\n"
+			if string(data) != expected {
+				t.Errorf("expected manifest:\n%s\ngot:\n%s", expected, data)
+			}
+
+			sigPath := manifestPath + ".asc"
+			if _, err := os.Stat(sigPath); (err == nil) != tt.wantSignature {
+				t.Fatalf("expected manifest signature %v, got %v", tt.wantSignature, err)
+			}
+			if !tt.wantSignature {
+				return
+			}
+			opts := SignOptions{Armor: true, DetachSign: true}
+			if err := signer.Verify(manifestPath, sigPath, opts); err != nil {
+				t.Errorf("manifest signature does not verify: %v", err)
+			}
+			if err := os.WriteFile(manifestPath, []byte(strings.Replace(string(data), "app", "evil", 1)), 0o644); err != nil {
+				t.Fatalf("failed to tamper with manifest: %v", err)
+			}
+			if err := signer.Verify(manifestPath, sigPath, opts); err == nil {
+				t.Error("expected the signature of a tampered manifest to fail verification")
+			}
+		})
+	}
+}
+
+func TestValidateOutputInputs(t *testing.T) {
+	if err := validateOutputInputs(Config{SignManifest: true}); err == nil || !strings.Contains(err.Error(), "requires manifest-out") {
+		t.Errorf("expected sign-manifest without manifest-out to be rejected, got %v", err)
+	}
+	if err := validateOutputInputs(Config{ManifestOut: "signatures.txt", SignManifest: true}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,36 @@
+package pgpsign
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SignatureType is the OpenPGP signature type of detached and inline signatures.
+type SignatureType string
+
+const (
+	SignatureTypeBinary SignatureType = "binary" // Signature over the exact bytes (type 0x00)
+	SignatureTypeText   SignatureType = "text"   // Signature over the text with canonical CRLF line endings (type 0x01)
+)
+
+// parseSignatureType parses the signature-type input. An empty value selects SignatureTypeBinary.
+func parseSignatureType(value string) (SignatureType, error) {
+	switch signatureType := SignatureType(strings.ToLower(strings.TrimSpace(value))); signatureType {
+	case "":
+		return SignatureTypeBinary, nil
+	case SignatureTypeBinary, SignatureTypeText:
+		return signatureType, nil
+	default:
+		return "", fmt.Errorf("unknown signature-type %q, expected %s or %s", value, SignatureTypeBinary, SignatureTypeText)
+	}
+}
+
+// textSignature reports whether a detached or inline signature made with opts is a text
+// signature. Inline signatures in text mode always are, as they sign a text message.
+// Clear-signed files always carry text signatures and do not consult this.
+func (opts SignOptions) textSignature() bool {
+	if opts.SignatureType == SignatureTypeText {
+		return true
+	}
+	return opts.TextMode && !opts.DetachSign && !opts.ClearSign
+}
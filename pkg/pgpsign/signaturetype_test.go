@@ -0,0 +1,115 @@
+package pgpsign
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp/clearsign"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+)
+
+func TestParseSignatureType(t *testing.T) {
+	for input, expected := range map[string]SignatureType{"": SignatureTypeBinary, "binary": SignatureTypeBinary, "TEXT": SignatureTypeText} {
+		signatureType, err := parseSignatureType(input)
+		if err != nil || signatureType != expected {
+			t.Errorf("parseSignatureType(%q) = %q, %v; expected %q", input, signatureType, err, expected)
+		}
+	}
+	if _, err := parseSignatureType("canonical"); err == nil {
+		t.Error("expected error for unknown signature type")
+	}
+}
+
+func TestGoPGPSigner_SignatureType(t *testing.T) {
+	signer, err := NewGoPGPSigner(generateTestKeyArmored(t, "Test", "test@test.com", ""), "", "")
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		opts     SignOptions
+		expected packet.SignatureType
+	}{
+		{name: "detached binary", opts: SignOptions{DetachSign: true, SignatureType: SignatureTypeBinary}, expected: packet.SigTypeBinary},
+		{name: "detached text", opts: SignOptions{DetachSign: true, SignatureType: SignatureTypeText}, expected: packet.SigTypeText},
+		{name: "inline binary", opts: SignOptions{SignatureType: SignatureTypeBinary}, expected: packet.SigTypeBinary},
+		{name: "inline text", opts: SignOptions{SignatureType: SignatureTypeText}, expected: packet.SigTypeText},
+		{name: "clear-sign binary", opts: SignOptions{ClearSign: true, SignatureType: SignatureTypeBinary}, expected: packet.SigTypeText},
+		{name: "clear-sign text", opts: SignOptions{ClearSign: true, SignatureType: SignatureTypeText}, expected: packet.SigTypeText},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testFile := filepath.Join(t.TempDir(), "notes.txt")
+			if err := os.WriteFile(testFile, []byte("line one\nline two\n"), 0o644); err != nil {
+				t.Fatalf("failed to create test file: %v", err)
+			}
+
+			if _, err := signer.SignFile(testFile, tt.opts); err != nil {
+				t.Fatalf("failed to sign file: %v", err)
+			}
+			outputPath := getOutputPath(testFile, tt.opts)
+
+			var sig *packet.Signature
+			if tt.opts.ClearSign {
+				sig = readClearSignaturePacket(t, outputPath)
+			} else {
+				sig = readSignaturePacket(t, outputPath)
+			}
+			if sig.SigType != tt.expected {
+				t.Errorf("expected signature type 0x%02x, got 0x%02x", tt.expected, sig.SigType)
+			}
+
+			if err := signer.Verify(testFile, outputPath, tt.opts); err != nil {
+				t.Errorf("failed to verify signature: %v", err)
+			}
+		})
+	}
+}
+
+func TestGnuPGSigner_BuildArgsSignatureType(t *testing.T) {
+	signer := &GnuPGSigner{}
+
+	tests := []struct {
+		opts     SignOptions
+		textmode bool
+	}{
+		{opts: SignOptions{DetachSign: true}},
+		{opts: SignOptions{DetachSign: true, SignatureType: SignatureTypeText}, textmode: true},
+		{opts: SignOptions{SignatureType: SignatureTypeText}, textmode: true},
+		{opts: SignOptions{ClearSign: true, SignatureType: SignatureTypeText}},
+	}
+
+	for _, tt := range tests {
+		args := strings.Join(signer.buildArgs(tt.opts, 0), " ")
+		if strings.Contains(args, "--textmode") != tt.textmode {
+			t.Errorf("expected --textmode %v for %+v, got %s", tt.textmode, tt.opts, args)
+		}
+	}
+}
+
+// readClearSignaturePacket returns the signature packet of a clear-signed file.
+func readClearSignaturePacket(t *testing.T, path string) *packet.Signature {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read clear-signed file: %v", err)
+	}
+	block, _ := clearsign.Decode(data)
+	if block == nil {
+		t.Fatal("failed to decode clear-signed file")
+	}
+	p, err := packet.Read(block.ArmoredSignature.Body)
+	if err != nil {
+		t.Fatalf("failed to read signature packet: %v", err)
+	}
+	sig, ok := p.(*packet.Signature)
+	if !ok {
+		t.Fatalf("expected a signature packet, got %T", p)
+	}
+	return sig
+}
@@ -0,0 +1,115 @@
+package pgpsign
+
+import (
+	"archive/zip"
+	"cmp"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// zipEntry is a file added to the signatures zip under name.
+type zipEntry struct {
+	name string
+	path string
+}
+
+// validateBundleOut checks that bundle-out is only set when files on disk are signed.
+func validateBundleOut(args Config) error {
+	if args.BundleOut != "" && (args.TarMembers || isStdinMode(args) || args.ImageDigests != "") {
+		return fmt.Errorf("bundle-out collects the signatures of files on disk and cannot be combined with tar-members, stdin or image-digests")
+	}
+	return nil
+}
+
+// writeSignatureZip collects every file written for files with keys, as listed by
+// signatureFiles, into the zip archive at zipPath, in addition to the files themselves.
+// Entry names mirror the layout of the signed files below their working directory, so
+// dist/app.tar.gz gets dist/app.tar.gz.asc wherever output-dir put the signature.
+// Entries are sorted by name, and with a fixed signature time, such as one taken from
+// SOURCE_DATE_EPOCH, their timestamps are zeroed so the archive is reproducible.
+func writeSignatureZip(zipPath string, workDirs, files []string, keys []signingKey) error {
+	entries, err := signatureZipEntries(workDirs, files, keys)
+	if err != nil {
+		return err
+	}
+	reproducible := !keys[0].opts.SignatureTime.IsZero()
+
+	f, err := createOutputFile(zipPath, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to create bundle-out archive: %w", err)
+	}
+	zw := zip.NewWriter(f)
+	for _, entry := range entries {
+		if err := addZipEntry(zw, entry, reproducible); err != nil {
+			f.Abort()
+			return fmt.Errorf("failed to write bundle-out archive: %w", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		f.Abort()
+		return fmt.Errorf("failed to write bundle-out archive: %w", err)
+	}
+	if err := f.Commit(); err != nil {
+		return fmt.Errorf("failed to write bundle-out archive: %w", err)
+	}
+	return nil
+}
+
+// signatureZipEntries returns the entries of the signatures zip sorted by name. Files
+// outside all working directories are added under their base name. Two files that would
+// share an entry name are rejected.
+func signatureZipEntries(workDirs, files []string, keys []signingKey) ([]zipEntry, error) {
+	var entries []zipEntry
+	seen := make(map[string]string)
+	for _, file := range files {
+		dir := "."
+		if rel, ok := relativeToRoot(workDirs, file); ok {
+			dir = path.Dir(filepath.ToSlash(rel))
+		}
+		for _, p := range signatureFiles(file, keys) {
+			name := path.Join(dir, filepath.Base(p))
+			if other, ok := seen[name]; ok {
+				if other == p {
+					continue
+				}
+				return nil, fmt.Errorf("bundle-out cannot hold both %s and %s as %s", other, p, name)
+			}
+			seen[name] = p
+			entries = append(entries, zipEntry{name: name, path: p})
+		}
+	}
+	slices.SortFunc(entries, func(a, b zipEntry) int { return cmp.Compare(a.name, b.name) })
+	return entries, nil
+}
+
+// addZipEntry copies the file of entry into zw. Without reproducible the entry keeps the
+// modification time of the file.
+func addZipEntry(zw *zip.Writer, entry zipEntry, reproducible bool) error {
+	src, err := os.Open(entry.path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	header := &zip.FileHeader{Name: entry.name, Method: zip.Deflate}
+	header.SetMode(0o644)
+	if !reproducible {
+		info, err := src.Stat()
+		if err != nil {
+			return err
+		}
+		header.Modified = info.ModTime().UTC().Truncate(time.Second)
+	}
+
+	w, err := zw.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, src)
+	return err
+}
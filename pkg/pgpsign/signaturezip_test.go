@@ -0,0 +1,166 @@
+package pgpsign
+
+import (
+	"archive/zip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// readTestZip returns the names and contents of the entries of the zip at path in
+// archive order, and fails unless every entry has the zeroed timestamp when zeroTime is set.
+func readTestZip(t *testing.T, path string, zeroTime bool) ([]string, map[string][]byte) {
+	t.Helper()
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatalf("failed to open zip: %v", err)
+	}
+	defer zr.Close()
+
+	var names []string
+	contents := make(map[string][]byte)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("failed to open entry %s: %v", f.Name, err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("failed to read entry %s: %v", f.Name, err)
+		}
+		names = append(names, f.Name)
+		contents[f.Name] = data
+		if zeroTime && (f.ModifiedDate != 0 || f.ModifiedTime != 0) {
+			t.Errorf("expected a zeroed timestamp for %s, got %v", f.Name, f.Modified)
+		}
+	}
+	return names, contents
+}
+
+func TestRunBundleOut(t *testing.T) {
+	signer, err := NewGoPGPSigner(generateTestKeyArmored(t, "Test", "test@test.com", ""), "", "")
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		outputDir string
+		epoch     string
+		wantNames []string
+	}{
+		{
+			name:      "next to files",
+			wantNames: []string{"app.tar.gz.asc", "dist/lib/core.bin.asc", "dist/tool.bin.asc"},
+		},
+		{
+			name:      "output-dir mirrors the input layout",
+			outputDir: "signatures",
+			wantNames: []string{"app.tar.gz.asc", "dist/lib/core.bin.asc", "dist/tool.bin.asc"},
+		},
+		{
+			name:      "reproducible",
+			epoch:     strconv.FormatInt(time.Now().Unix(), 10), // Not before the key was created
+			wantNames: []string{"app.tar.gz.asc", "dist/lib/core.bin.asc", "dist/tool.bin.asc"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("SOURCE_DATE_EPOCH", tt.epoch)
+			workDir := t.TempDir()
+			writeTestFiles(t, workDir, "app.tar.gz", "dist/tool.bin", "dist/lib/core.bin")
+
+			args := Config{
+				PrivateKey: "key",
+				Files:      "*.tar.gz\ndist/**/*.bin",
+				Armor:      true,
+				DetachSign: true,
+				WorkDir:    workDir,
+				OutputDir:  tt.outputDir,
+				BundleOut:  "signatures.zip",
+			}
+			if err := run(context.Background(), args, signer, nil, nil); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			names, contents := readTestZip(t, filepath.Join(workDir, "signatures.zip"), tt.epoch != "")
+			if !slices.Equal(names, tt.wantNames) {
+				t.Fatalf("expected entries %v, got %v", tt.wantNames, names)
+			}
+
+			opts := SignOptions{Armor: true, DetachSign: true}
+			for _, name := range names {
+				sigPath := filepath.Join(t.TempDir(), "entry.asc")
+				if err := os.WriteFile(sigPath, contents[name], 0o644); err != nil {
+					t.Fatalf("failed to write signature: %v", err)
+				}
+				file := filepath.Join(workDir, filepath.FromSlash(strings.TrimSuffix(name, ".asc")))
+				if err := signer.Verify(file, sigPath, opts); err != nil {
+					t.Errorf("signature %s in zip does not verify: %v", name, err)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteSignatureZip_Reproducible(t *testing.T) {
+	workDir := t.TempDir()
+	writeTestFiles(t, workDir, "b.bin", "a.bin", "b.bin.sig", "a.bin.sig")
+	files := []string{filepath.Join(workDir, "b.bin"), filepath.Join(workDir, "a.bin")}
+	keys := []signingKey{{opts: SignOptions{DetachSign: true, SignatureTime: time.Unix(1700000000, 0)}}}
+
+	var archives [][]byte
+	for range 2 {
+		zipPath := filepath.Join(t.TempDir(), "signatures.zip")
+		if err := writeSignatureZip(zipPath, []string{workDir}, files, keys); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		data, err := os.ReadFile(zipPath)
+		if err != nil {
+			t.Fatalf("failed to read zip: %v", err)
+		}
+		archives = append(archives, data)
+
+		names, _ := readTestZip(t, zipPath, true)
+		if want := []string{"a.bin.sig", "b.bin.sig"}; !slices.Equal(names, want) {
+			t.Errorf("expected entries sorted by name %v, got %v", want, names)
+		}
+
+		// A later modification time must not change the archive.
+		later := time.Now().Add(time.Hour)
+		if err := os.Chtimes(filepath.Join(workDir, "a.bin.sig"), later, later); err != nil {
+			t.Fatalf("failed to touch signature: %v", err)
+		}
+	}
+	if string(archives[0]) != string(archives[1]) {
+		t.Error("expected identical archives with a fixed signature time")
+	}
+}
+
+func TestSignatureZipEntries_Collision(t *testing.T) {
+	workDir := t.TempDir()
+	other := t.TempDir()
+	files := []string{filepath.Join(workDir, "app.bin"), filepath.Join(other, "app.bin")}
+	keys := []signingKey{{opts: SignOptions{DetachSign: true}}}
+
+	if _, err := signatureZipEntries([]string{workDir, other}, files, keys); err == nil || !strings.Contains(err.Error(), "cannot hold both") {
+		t.Errorf("expected files sharing an entry name to be rejected, got %v", err)
+	}
+}
+
+func TestValidateBundleOut(t *testing.T) {
+	if err := validateBundleOut(Config{BundleOut: "signatures.zip", Files: "*"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := validateBundleOut(Config{BundleOut: "signatures.zip", Files: "-"}); err == nil || !strings.Contains(err.Error(), "files on disk") {
+		t.Errorf("expected bundle-out to be rejected for stdin, got %v", err)
+	}
+}
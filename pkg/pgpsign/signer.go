@@ -0,0 +1,304 @@
+package pgpsign
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ProtonMail/gopenpgp/v3/crypto"
+)
+
+// SignOptions contains the options for signing a file.
+type SignOptions struct {
+	Armor      bool // Create ASCII armored output
+	DetachSign bool // Make a detached signature
+	ClearSign  bool // Make a clear text signature
+
+	Format SignatureFormat // Kind of signature; empty means FormatPGP
+
+	ArmorComment      string // Comment header of armored output; empty writes none
+	SignerComment     string // Comment header of clear-signed output, replacing ArmorComment there
+	StripArmorVersion bool   // Never write a Version header into armored output
+	MinimalArmor      bool   // Write armored output without any armor headers
+	SidecarHeader     bool   // Describe the signed file's digest and size before or next to detached signatures
+
+	CleartextEncoding CleartextEncoding // Handling of BOM/UTF-16 input when clear-signing
+	TextMode          bool              // Canonicalize line endings of clear-signed and inline signed text
+	SignatureType     SignatureType     // Binary or text signatures for detached and inline modes; empty means binary
+	SignatureTime     time.Time         // Fixed signature creation time; zero uses the current time
+	Deterministic     bool              // Omit the random salt notation so signatures are reproducible
+	GnuPGCompat       bool              // Restrict signatures to SHA-256 v4 without extra notations
+	DigestAlgorithm   DigestAlgorithm   // Hash used for signatures; empty keeps the backend default
+	Notations         []Notation        // Human-readable notation data added to every signature
+	EmbedFilename     bool              // Record the signed file's base name in the signature
+	Filename          string            // Base name recorded with EmbedFilename; set per file by forFile
+	KeySuffix         string            // Inserted before the extension to keep signatures of several keys apart
+	SkipKeyValidation bool              // Accept keys without key flags or trust, such as freshly generated test keys
+
+	OutputDir  string   // Directory mirroring BaseDirs that receives the signatures; empty writes them next to the file
+	BaseDirs   []string // Working directories the paths of signed files are made relative to under OutputDir
+	FlatOutput bool     // Write signatures directly into OutputDir instead of mirroring BaseDirs
+
+	SignatureSuffix string // Replaces the extension chosen from the signature type
+	SignatureName   string // Template for the signature file name using {name} and {ext}
+	NameByKind      bool   // Choose the extension by signature kind only, ignoring Armor
+
+	NoOverwrite bool        // Fail instead of replacing a signature file that already exists
+	OutputMode  os.FileMode // Permission bits of written signatures; zero means defaultOutputMode
+
+	OnFileChange FileChangePolicy // Handling of files that change while they are read; empty means FileChangeError
+}
+
+// SignResult describes the signature of a file. Signers fill in the signature details;
+// the outcome fields are recorded for the run's summary and report.
+type SignResult struct {
+	File         string `json:"file"`                       // Signed file
+	OutputPath   string `json:"signature,omitempty"`        // Written signature file
+	Bytes        int64  `json:"size"`                       // Size of the signed file in bytes
+	KeyID        string `json:"key_id,omitempty"`           // Long ID of the signing key; empty if unknown
+	Fingerprint  string `json:"fingerprint,omitempty"`      // Fingerprint of the signing key; empty if unknown
+	DigestAlgo   string `json:"digest_algorithm,omitempty"` // Hash of the signature; empty if the backend default is unknown
+	Verification string `json:"verification,omitempty"`     // VerificationPassed or VerificationFailed; empty if not verified
+	Status       string `json:"status"`                     // StatusSigned, StatusSkipped, StatusFailed or StatusNotAttempted
+	Error        string `json:"error,omitempty"`            // Why signing failed; empty unless Status is StatusFailed
+	DurationMs   int64  `json:"duration_ms"`                // Milliseconds the signer took to write the signature
+}
+
+// newSignResult describes the signature of filePath written to outputPath with digest.
+// publicKey identifies the signing key and may be nil if it is unknown.
+func newSignResult(filePath, outputPath string, publicKey *crypto.Key, digest DigestAlgorithm) SignResult {
+	result := SignResult{File: filePath, OutputPath: outputPath, DigestAlgo: string(digest)}
+	if info, err := os.Stat(filePath); err == nil {
+		result.Bytes = info.Size()
+	}
+	if publicKey != nil {
+		result.KeyID = formatKeyID(publicKey.GetKeyID())
+		result.Fingerprint = strings.ToUpper(publicKey.GetFingerprint())
+	}
+	return result
+}
+
+// errSignatureExists is returned by SignFile when the signature file exists and NoOverwrite is set.
+var errSignatureExists = errors.New("signature file already exists")
+
+// Signer defines the interface for GPG signing operations.
+type Signer interface {
+	// SignFile signs a file and writes the signature.
+	// For detached signatures, creates a .sig or .asc file.
+	// For clear signatures, creates a .asc file with the clear-signed content.
+	// For normal signatures, creates a .gpg or .asc file.
+	// The result describes the written signature.
+	SignFile(filePath string, opts SignOptions) (SignResult, error)
+
+	// SignStream signs data read from r and writes the signature to w.
+	SignStream(r io.Reader, w io.Writer, opts SignOptions) error
+
+	// SignBytes signs data held in memory and returns the signature SignStream would write.
+	SignBytes(data []byte, opts SignOptions) ([]byte, error)
+
+	// Verify checks the signature at sigPath for filePath.
+	// For clear and inline signatures sigPath holds the signed message itself.
+	Verify(filePath, sigPath string, opts SignOptions) error
+}
+
+// signBytesWithStream implements SignBytes by passing data through signStream.
+func signBytesWithStream(signStream func(io.Reader, io.Writer, SignOptions) error, data []byte, opts SignOptions) ([]byte, error) {
+	var signature bytes.Buffer
+	if err := signStream(bytes.NewReader(data), &signature, opts); err != nil {
+		return nil, err
+	}
+	return signature.Bytes(), nil
+}
+
+// Verifier checks signatures written by a Signer, as Signer.Verify does.
+type Verifier interface {
+	Verify(filePath, sigPath string, opts SignOptions) error
+}
+
+// Fingerprinter is implemented by signers that know the fingerprint of their signing key.
+// An empty fingerprint means it could not be determined.
+type Fingerprinter interface {
+	Fingerprint() string
+}
+
+// NewSigner creates a new Signer based on the specified backend.
+// An empty backend selects BackendGoPGP and an empty keyID lets the backend pick the signing
+// key. gpg is only used by the gnupg backend.
+func NewSigner(backend SignerBackend, privateKey, passphrase, keyID string, gpg GnuPGOptions) (Signer, error) {
+	switch backend {
+	case BackendGoPGP, "":
+		return NewGoPGPSigner(privateKey, passphrase, keyID)
+	case BackendGnuPG:
+		return NewGnuPGSigner(privateKey, passphrase, keyID, gpg)
+	case BackendMinisign:
+		if keyID != "" {
+			return nil, fmt.Errorf("key-id is not supported by the %s backend", BackendMinisign)
+		}
+		return NewMinisignSigner(privateKey, passphrase)
+	case BackendSSH:
+		if keyID != "" {
+			return nil, fmt.Errorf("key-id is not supported by the %s backend", BackendSSH)
+		}
+		return NewSSHSigner(privateKey, passphrase)
+	default:
+		return nil, fmt.Errorf("unknown signer backend: %s", backend)
+	}
+}
+
+// checkOverwrite fails with errSignatureExists if opts forbid replacing an existing file at outputPath.
+func checkOverwrite(outputPath string, opts SignOptions) error {
+	if !opts.NoOverwrite {
+		return nil
+	}
+
+	_, err := os.Lstat(outputPath)
+	switch {
+	case err == nil:
+		return fmt.Errorf("%w: %s", errSignatureExists, outputPath)
+	case !errors.Is(err, fs.ErrNotExist):
+		return fmt.Errorf("failed to check for existing signature: %w", err)
+	}
+
+	return nil
+}
+
+// Extensions of the signature files written by default.
+const (
+	armoredExtension  = ".asc"
+	detachedExtension = ".sig"
+	inlineExtension   = ".gpg"
+)
+
+// getOutputExtension returns the appropriate file extension for the signature.
+func getOutputExtension(opts SignOptions) string {
+	if opts.SignatureSuffix != "" {
+		return opts.SignatureSuffix
+	}
+
+	switch opts.Format {
+	case FormatMinisign:
+		return minisignExtension
+	case FormatSSH:
+		// SSH signatures are armored text but named like git and ssh-keygen name them.
+		return detachedExtension
+	}
+
+	// Clear sign always produces armored output
+	if opts.ClearSign {
+		return armoredExtension
+	}
+
+	// Several kinds of signature for one file would all be .asc when armored.
+	if opts.NameByKind {
+		if opts.DetachSign {
+			return detachedExtension
+		}
+		return inlineExtension
+	}
+
+	if opts.Armor {
+		return armoredExtension
+	}
+
+	if opts.DetachSign {
+		return detachedExtension
+	}
+
+	return inlineExtension
+}
+
+// signatureExcludes returns exclude patterns for files that look like output of earlier runs,
+// so re-running the action over the same directory does not sign its own signatures.
+func signatureExcludes(opts SignOptions) []string {
+	extensions := []string{armoredExtension, detachedExtension, inlineExtension, sigstoreBundleExtension, minisignExtension}
+	if opts.SignatureSuffix != "" {
+		extensions = append(extensions, opts.SignatureSuffix)
+	}
+
+	excludes := make([]string, len(extensions))
+	for i, ext := range extensions {
+		excludes[i] = "*" + ext
+	}
+	return excludes
+}
+
+// signMode returns the signature type the backends create for the given options.
+// Detached signing takes precedence over clear-signing, matching both backends.
+func signMode(opts SignOptions) string {
+	if opts.DetachSign {
+		return "detached"
+	}
+	if opts.ClearSign {
+		return "clear-sign"
+	}
+	return "inline"
+}
+
+// Validate rejects option combinations that ask for contradictory signature types.
+// A clear-signed file is always an armored message that embeds the signed text, so
+// it can neither be detached nor binary. Minimal armor needs armor and leaves no room
+// for a comment header.
+func (opts SignOptions) Validate() error {
+	if opts.MinimalArmor {
+		if !opts.Armor {
+			return errors.New("minimal-armor requires armor: binary signatures have no armor headers to strip")
+		}
+		if opts.commentHeader() != "" {
+			return errors.New("minimal-armor cannot be combined with armor-comment or signer-comment: it writes no headers")
+		}
+	}
+	if !opts.ClearSign {
+		return nil
+	}
+	if opts.DetachSign {
+		return errors.New("clear-sign cannot be combined with detach-sign: a clear-signed file embeds the signed text, set only one of them")
+	}
+	if !opts.Armor {
+		return errors.New("clear-sign cannot be combined with armor=false: clear-signed files are always armored, leave armor enabled")
+	}
+	return nil
+}
+
+// getOutputPath determines the output file path based on signing options.
+// A name template that is invalid for filePath falls back to the default name;
+// checkOutputPaths rejects such files before anything is signed.
+func getOutputPath(filePath string, opts SignOptions) string {
+	target := outputTarget(filePath, opts)
+	if opts.SignatureName != "" {
+		if name, err := renderSignatureName(filepath.Base(target), opts); err == nil {
+			return filepath.Join(filepath.Dir(target), name)
+		}
+	}
+	return target + opts.KeySuffix + getOutputExtension(opts)
+}
+
+// outputBase returns the path of the files written for filePath without their extension.
+func outputBase(filePath string, opts SignOptions) string {
+	return outputTarget(filePath, opts) + opts.KeySuffix
+}
+
+// outputTarget returns the path the files written for filePath are named after.
+// With an output directory the path of filePath relative to its own working directory in
+// BaseDirs is mirrored below it, or only its base name is used with FlatOutput; files
+// outside all of them, or sharing a base name, are rejected by checkOutputPaths before
+// anything is signed.
+func outputTarget(filePath string, opts SignOptions) string {
+	if opts.OutputDir == "" {
+		return filePath
+	}
+	if opts.FlatOutput {
+		return filepath.Join(opts.OutputDir, filepath.Base(filePath))
+	}
+
+	rel, ok := relativeToRoot(opts.BaseDirs, filePath)
+	if !ok {
+		rel = filepath.Base(filePath)
+	}
+	return filepath.Join(opts.OutputDir, rel)
+}
@@ -0,0 +1,570 @@
+package pgpsign
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ProtonMail/gopenpgp/v3/crypto"
+)
+
+const (
+	// gpgWaitDelay bounds how long a killed gpg invocation may keep its output pipes open.
+	// Helpers spawned by gpg, such as a stalled gpg-agent, can otherwise block the wait forever.
+	gpgWaitDelay = 5 * time.Second
+
+	// stderrErrorLines is the number of trailing stderr lines included in the error of a
+	// failed command, such as gpg or git.
+	stderrErrorLines = 5
+)
+
+// GnuPGOptions configures how the gnupg backend runs gpg.
+type GnuPGOptions struct {
+	Context context.Context // Kills running gpg invocations once cancelled; nil never cancels
+	Timeout time.Duration   // Kills a gpg invocation after this long; zero disables the limit
+	Log     *slog.Logger    // Receives the stderr of every gpg invocation at debug level
+	Home    string          // GNUPGHOME of every gpg invocation; empty keeps the environment's
+	TempDir string          // TMPDIR of every gpg invocation; empty keeps the environment's
+
+	// PassphraseFile is handed to gpg with --passphrase-file instead of the passphrase itself.
+	PassphraseFile string
+
+	// SkipImport signs with a secret key already in gpg's keyring, such as a key on a
+	// smartcard, instead of importing the private key. The key is selected by key ID.
+	SkipImport bool
+}
+
+// logger returns the configured logger, or one that discards everything.
+func (o GnuPGOptions) logger() *slog.Logger {
+	if o.Log == nil {
+		return slog.New(slog.DiscardHandler)
+	}
+	return o.Log
+}
+
+// GnuPGSigner implements Signer using the system's GnuPG installation.
+type GnuPGSigner struct {
+	passphrase  string
+	keyID       string
+	fingerprint string // Primary key fingerprint as listed by gpg; empty if unknown
+	publicKey   *crypto.Key
+	gpg         GnuPGOptions
+}
+
+// NewGnuPGSigner creates a new GnuPGSigner and imports the private key.
+// A non-empty keyID is passed to gpg as the exact key to sign with.
+// With gpg.SkipImport, keyID must name a secret key already in the keyring.
+func NewGnuPGSigner(armoredKey, passphrase, keyID string, gpg GnuPGOptions) (*GnuPGSigner, error) {
+	if gpg.SkipImport {
+		return newPreloadedGnuPGSigner(armoredKey, passphrase, keyID, gpg)
+	}
+
+	publicKey := parsePublicKey(armoredKey)
+	if keyID != "" && publicKey != nil {
+		if _, err := selectSigningKey(publicKey.GetEntity(), keyID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := importGPGKey(armoredKey, gpg); err != nil {
+		return nil, fmt.Errorf("failed to import GPG key: %w", err)
+	}
+
+	signer := &GnuPGSigner{
+		passphrase: passphrase,
+		keyID:      normalizeKeyID(keyID),
+		publicKey:  publicKey,
+		gpg:        gpg,
+	}
+	// Without a public key or key ID the imported key cannot be looked up, and gpg
+	// reports a missing signing key when the first file is signed.
+	if ref, err := signer.keyRef(); err == nil {
+		if _, err := checkSecretKey(ref, gpg); err != nil {
+			return nil, err
+		}
+	}
+	return signer, nil
+}
+
+// newPreloadedGnuPGSigner creates a GnuPGSigner for the secret key keyID already in gpg's
+// keyring. The public key is taken from armoredKey if given, and exported from gpg otherwise.
+func newPreloadedGnuPGSigner(armoredKey, passphrase, keyID string, gpg GnuPGOptions) (*GnuPGSigner, error) {
+	if keyID == "" {
+		return nil, fmt.Errorf("skip-import requires key-id to select the key in the gpg keyring")
+	}
+	keyID = normalizeKeyID(keyID)
+	fingerprint, err := checkSecretKey(keyID, gpg)
+	if err != nil {
+		return nil, err
+	}
+
+	signer := &GnuPGSigner{passphrase: passphrase, keyID: keyID, fingerprint: fingerprint, gpg: gpg}
+	signer.publicKey = parsePublicKey(armoredKey)
+	if signer.publicKey == nil {
+		// Only optional features need the public key, so a failed export is not an error.
+		if exported, err := signer.ExportPublicKey(); err == nil {
+			signer.publicKey = parsePublicKey(exported)
+		}
+	}
+	return signer, nil
+}
+
+// checkSecretKey fails unless gpg's keyring holds the secret key keyID with a key or subkey
+// that can sign, and returns the fingerprint of its primary key as gpg lists it.
+func checkSecretKey(keyID string, gpg GnuPGOptions) (string, error) {
+	ctx, cancel := gpgContext(gpg)
+	defer cancel()
+
+	var stdout bytes.Buffer
+	cmd := gpgCommand(ctx, gpg, "--batch", "--with-colons", "--list-secret-keys", keyID)
+	cmd.Stdout = &stdout
+
+	if err := runGPG(ctx, cmd, "gpg key lookup", gpg); err != nil {
+		return "", fmt.Errorf("secret key %s not found in the gpg keyring: %w", keyID, err)
+	}
+	if !gpgKeyCanSign(stdout.String()) {
+		return "", fmt.Errorf("secret key %s has no valid key or subkey that can sign: export the key with its signing subkey", keyID)
+	}
+	return parseSecretKeyFingerprint(stdout.String()), nil
+}
+
+// gpgKeyCanSign reports whether the first secret key in the output of gpg --list-secret-keys
+// --with-colons may sign. gpg lists the usable capabilities of the whole key in upper case in
+// field 12 of the sec record, so an S there means the primary key or a subkey can sign.
+// Output without a sec record or capabilities is not rejected, leaving the check to gpg.
+func gpgKeyCanSign(colons string) bool {
+	for _, line := range strings.Split(colons, "\n") {
+		fields := strings.Split(strings.TrimSpace(line), ":")
+		if fields[0] != "sec" {
+			continue
+		}
+		return len(fields) <= 11 || fields[11] == "" || strings.Contains(fields[11], "S")
+	}
+	return true
+}
+
+// parseSecretKeyFingerprint returns the fingerprint of the first secret key in the output
+// of gpg --list-secret-keys --with-colons: the fpr record that follows its sec record.
+func parseSecretKeyFingerprint(colons string) string {
+	inSecretKey := false
+	for _, line := range strings.Split(colons, "\n") {
+		fields := strings.Split(strings.TrimSpace(line), ":")
+		switch {
+		case fields[0] == "sec":
+			inSecretKey = true
+		case fields[0] == "fpr" && inSecretKey && len(fields) > 9:
+			return strings.ToUpper(fields[9])
+		case fields[0] == "ssb":
+			inSecretKey = false
+		}
+	}
+	return ""
+}
+
+// parsePublicKey extracts the public half of an armored key, or returns nil if it can't be parsed.
+// Public key material is only needed for optional features, so gpg stays the authority on key validity.
+func parsePublicKey(armoredKey string) *crypto.Key {
+	key, err := crypto.NewKeyFromArmored(armoredKey)
+	if err != nil {
+		return nil
+	}
+	publicKey, err := key.ToPublic()
+	if err != nil {
+		return nil
+	}
+	return publicKey
+}
+
+// Fingerprint returns the fingerprint of the primary signing key: as listed by gpg for a
+// key already in the keyring, and from the private key for an imported key.
+func (s *GnuPGSigner) Fingerprint() string {
+	if s.fingerprint == "" && s.publicKey != nil {
+		return strings.ToUpper(s.publicKey.GetFingerprint())
+	}
+	return s.fingerprint
+}
+
+// PublicKey returns the public half of the imported signing key.
+func (s *GnuPGSigner) PublicKey() (*crypto.Key, error) {
+	if s.publicKey == nil {
+		return nil, fmt.Errorf("public key is not available for the gnupg backend")
+	}
+	return s.publicKey, nil
+}
+
+// importGPGKey imports a GPG key using the gpg command.
+func importGPGKey(armoredKey string, gpg GnuPGOptions) error {
+	ctx, cancel := gpgContext(gpg)
+	defer cancel()
+
+	cmd := gpgCommand(ctx, gpg, "--batch", "--import", "-")
+	cmd.Stdin = strings.NewReader(armoredKey)
+
+	return runGPG(ctx, cmd, "gpg import", gpg)
+}
+
+// KeyInfo returns the user IDs and expiration gpg reports for the imported secret key.
+func (s *GnuPGSigner) KeyInfo() (KeyInfo, error) {
+	key, err := s.keyRef()
+	if err != nil {
+		return KeyInfo{}, err
+	}
+
+	ctx, cancel := gpgContext(s.gpg)
+	defer cancel()
+
+	var stdout bytes.Buffer
+	cmd := gpgCommand(ctx, s.gpg, "--batch", "--with-colons", "--fixed-list-mode", "--list-secret-keys", key)
+	cmd.Stdout = &stdout
+
+	if err := runGPG(ctx, cmd, "gpg key listing", s.gpg); err != nil {
+		return KeyInfo{}, err
+	}
+	return parseGPGKeyInfo(stdout.String())
+}
+
+// ExportPublicKey returns the armored public key exported by gpg from its keyring.
+func (s *GnuPGSigner) ExportPublicKey() (string, error) {
+	key, err := s.keyRef()
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := gpgContext(s.gpg)
+	defer cancel()
+
+	var stdout bytes.Buffer
+	cmd := gpgCommand(ctx, s.gpg, "--batch", "--armor", "--export", key)
+	cmd.Stdout = &stdout
+
+	if err := runGPG(ctx, cmd, "gpg key export", s.gpg); err != nil {
+		return "", err
+	}
+	if stdout.Len() == 0 {
+		return "", fmt.Errorf("gpg exported no public key for %s", key)
+	}
+	return stdout.String(), nil
+}
+
+// keyRef returns the identifier gpg commands use to refer to the signing key.
+func (s *GnuPGSigner) keyRef() (string, error) {
+	if s.publicKey != nil {
+		return s.publicKey.GetFingerprint(), nil
+	}
+	if s.keyID == "" {
+		return "", fmt.Errorf("cannot identify the imported key")
+	}
+	return s.keyID, nil
+}
+
+// SignFile signs a file using the system's GnuPG. gpg cannot write the signatures of several
+// files to separate outputs in one invocation, so every file costs one gpg process; the
+// concurrency input runs them in parallel for large releases.
+func (s *GnuPGSigner) SignFile(filePath string, opts SignOptions) (SignResult, error) {
+	outputPath := getOutputPath(filePath, opts)
+	if err := checkOverwrite(outputPath, opts); err != nil {
+		return SignResult{}, err
+	}
+
+	if opts.ClearSign {
+		if err := checkCleartextFile(filePath, opts); err != nil {
+			return SignResult{}, err
+		}
+	}
+
+	// gpg writes to a temporary file that only replaces outputPath once it is complete.
+	err := writeOutputFileWith(outputPath, opts.outputMode(), func(tempPath string) error {
+		// gpg picks its own file name otherwise, which ignores the key suffix and the output directory.
+		args := s.buildArgs(opts, 0)
+		args = append(args, "--output", tempPath, filePath)
+
+		ctx, cancel := gpgContext(s.gpg)
+		defer cancel()
+
+		cmd := gpgCommand(ctx, s.gpg, args...)
+
+		if s.passphrase != "" && s.gpg.PassphraseFile == "" {
+			cmd.Stdin = strings.NewReader(s.passphrase)
+		}
+
+		if err := runGPG(ctx, cmd, "gpg command", s.gpg); err != nil {
+			return err
+		}
+		// Headers asked for by gpg.conf are stripped from what gpg wrote.
+		if opts.MinimalArmor {
+			return stripArmorHeadersFile(tempPath)
+		}
+		return nil
+	})
+	if err != nil {
+		return SignResult{}, err
+	}
+
+	// gpg picks its default digest unless one is configured, so it is only reported if set.
+	return newSignResult(filePath, outputPath, s.publicKey, opts.DigestAlgorithm), nil
+}
+
+// SignStream signs data read from r and writes the signature produced by gpg to w.
+// The input occupies stdin, so the passphrase is handed to gpg on file descriptor 3.
+func (s *GnuPGSigner) SignStream(r io.Reader, w io.Writer, opts SignOptions) error {
+	if opts.ClearSign {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return fmt.Errorf("failed to read input: %w", err)
+		}
+		data, err = clearSignText(data, opts)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(data)
+	}
+
+	args := s.buildArgs(opts, 3)
+	args = append(args, "--output", "-")
+
+	ctx, cancel := gpgContext(s.gpg)
+	defer cancel()
+
+	cmd := gpgCommand(ctx, s.gpg, args...)
+	cmd.Stdin = r
+	cmd.Stdout = w
+	var minimal *minimalArmorWriter
+	if opts.MinimalArmor {
+		minimal = &minimalArmorWriter{w: w}
+		cmd.Stdout = minimal
+	}
+
+	if s.passphrase != "" && s.gpg.PassphraseFile == "" {
+		passphraseReader, passphraseWriter, err := os.Pipe()
+		if err != nil {
+			return fmt.Errorf("failed to create passphrase pipe: %w", err)
+		}
+		defer passphraseReader.Close()
+
+		_, err = io.WriteString(passphraseWriter, s.passphrase)
+		passphraseWriter.Close()
+		if err != nil {
+			return fmt.Errorf("failed to pass passphrase to gpg: %w", err)
+		}
+		cmd.ExtraFiles = []*os.File{passphraseReader}
+	}
+
+	if err := runGPG(ctx, cmd, "gpg command", s.gpg); err != nil {
+		return err
+	}
+	if minimal != nil {
+		return minimal.Close()
+	}
+	return nil
+}
+
+// SignBytes signs data held in memory by piping it to gpg on stdin and returns the signature.
+func (s *GnuPGSigner) SignBytes(data []byte, opts SignOptions) ([]byte, error) {
+	return signBytesWithStream(s.SignStream, data, opts)
+}
+
+// Verify checks a signature using the system's GnuPG. For clear-signed and inline
+// signatures, the message gpg extracts from the signature must also match filePath.
+func (s *GnuPGSigner) Verify(filePath, sigPath string, opts SignOptions) error {
+	args := []string{"--batch"}
+	if opts.SkipKeyValidation {
+		args = append(args, "--trust-model", "always")
+	}
+	if opts.DetachSign {
+		args = append(args, "--verify", sigPath, filePath)
+	} else {
+		args = append(args, "--output", "-", "--verify", sigPath)
+	}
+
+	ctx, cancel := gpgContext(s.gpg)
+	defer cancel()
+
+	cmd := gpgCommand(ctx, s.gpg, args...)
+	var cleartext bytes.Buffer
+	message := newMessageDigest(opts)
+	switch {
+	case opts.ClearSign:
+		cmd.Stdout = &cleartext
+	case !opts.DetachSign:
+		cmd.Stdout = message
+	}
+	if err := runGPG(ctx, cmd, "gpg verification", s.gpg); err != nil {
+		return err
+	}
+
+	switch {
+	case opts.ClearSign:
+		return checkClearSignedFile(cleartext.Bytes(), filePath, opts)
+	case !opts.DetachSign:
+		return message.checkFile(filePath, opts)
+	}
+	return nil
+}
+
+// gpgContext returns the context bounding a single gpg invocation, derived from gpg.Context
+// so cancelling the run kills it. A zero timeout leaves the invocation unbounded otherwise.
+func gpgContext(gpg GnuPGOptions) (context.Context, context.CancelFunc) {
+	parent := gpg.Context
+	if parent == nil {
+		parent = context.Background()
+	}
+	if gpg.Timeout <= 0 {
+		return context.WithCancel(parent)
+	}
+	return context.WithTimeout(parent, gpg.Timeout)
+}
+
+// gpgCommand creates a gpg invocation that is killed once ctx is done and that uses
+// gpg.Home as its home directory and gpg.TempDir for temporary files if they are set.
+func gpgCommand(ctx context.Context, gpg GnuPGOptions, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "gpg", args...)
+	cmd.WaitDelay = gpgWaitDelay
+	var env []string
+	if gpg.Home != "" {
+		env = append(env, "GNUPGHOME="+gpg.Home)
+	}
+	if gpg.TempDir != "" {
+		env = append(env, "TMPDIR="+gpg.TempDir)
+	}
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	return cmd
+}
+
+// runGPG runs a gpg invocation and captures its stderr, and its stdout unless the caller
+// reads it. The output is logged at debug level, and the last lines of stderr are added to
+// the error when gpg fails so the cause shows up in CI logs.
+func runGPG(ctx context.Context, cmd *exec.Cmd, operation string, gpg GnuPGOptions) error {
+	var stdout, stderr bytes.Buffer
+	if cmd.Stdout == nil {
+		cmd.Stdout = &stdout
+	}
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	if output := strings.TrimSpace(stdout.String()); output != "" {
+		gpg.logger().Debug("gpg output", slog.String("operation", operation), slog.String("stdout", output))
+	}
+	output := strings.TrimSpace(stderr.String())
+	if output != "" {
+		gpg.logger().Debug("gpg output", slog.String("operation", operation), slog.String("stderr", output))
+	}
+	if err == nil {
+		return nil
+	}
+
+	// A timeout or cancellation kills gpg, so the exit error itself carries no useful information.
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		err = fmt.Errorf("%s timed out after %s (gpg-timeout) and was killed", operation, gpg.Timeout)
+	case errors.Is(ctx.Err(), context.Canceled):
+		err = fmt.Errorf("%s was cancelled and gpg was killed: %w", operation, context.Canceled)
+	default:
+		err = fmt.Errorf("%s failed: %w", operation, err)
+	}
+	if output != "" {
+		return fmt.Errorf("%w: %s", err, lastLines(output, stderrErrorLines))
+	}
+	return err
+}
+
+// lastLines returns up to n trailing lines of s joined into a single line.
+func lastLines(s string, n int) string {
+	lines := strings.Split(s, "\n")
+	lines = lines[max(0, len(lines)-n):]
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	return strings.Join(lines, "; ")
+}
+
+// checkCleartextFile validates that a file can be clear-signed by gpg as-is.
+// gpg reads the file directly, so inputs that would need transcoding or, with TextMode,
+// converting line endings are rejected.
+func checkCleartextFile(filePath string, opts SignOptions) error {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+
+	normalized, err := clearSignText(data, opts)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(normalized, data) {
+		return fmt.Errorf("converting cleartext encoding or line endings is not supported by the gnupg backend")
+	}
+
+	return nil
+}
+
+// buildArgs constructs the GPG command arguments based on sign options.
+// passphraseFD is the file descriptor gpg reads the passphrase from unless a passphrase file is configured.
+func (s *GnuPGSigner) buildArgs(opts SignOptions, passphraseFD int) []string {
+	args := []string{"--batch", "--yes"}
+
+	switch {
+	case s.gpg.PassphraseFile != "":
+		args = append(args, "--pinentry-mode", "loopback", "--passphrase-file", s.gpg.PassphraseFile)
+	case s.passphrase != "":
+		args = append(args, "--pinentry-mode", "loopback", "--passphrase-fd", strconv.Itoa(passphraseFD))
+	}
+
+	if s.keyID != "" {
+		args = append(args, "--local-user", localUser(s.keyID))
+	}
+
+	if opts.SkipKeyValidation {
+		args = append(args, "--trust-model", "always")
+	}
+
+	if opts.DigestAlgorithm != DigestDefault {
+		args = append(args, "--digest-algo", opts.DigestAlgorithm.gnupgName())
+	}
+
+	for _, notation := range opts.signatureNotations() {
+		args = append(args, "--set-notation", notation.Name+"="+notation.Value)
+	}
+	if opts.Filename != "" && !opts.DetachSign && !opts.ClearSign {
+		args = append(args, "--set-filename", opts.Filename)
+	}
+
+	if !opts.SignatureTime.IsZero() {
+		args = append(args, "--faked-system-time", fmt.Sprintf("%d!", opts.SignatureTime.Unix()))
+	}
+
+	if opts.Armor {
+		args = append(args, "--armor")
+	}
+	// These override the emit-version and comment settings of gpg.conf.
+	if comment := opts.commentHeader(); comment != "" {
+		args = append(args, "--comment", comment)
+	}
+	if opts.StripArmorVersion {
+		args = append(args, "--no-emit-version")
+	}
+
+	// Clear-signed files always carry text signatures, so only the other modes need this.
+	if opts.textSignature() && !opts.ClearSign {
+		args = append(args, "--textmode")
+	}
+
+	if opts.DetachSign {
+		args = append(args, "--detach-sign")
+	} else if opts.ClearSign {
+		args = append(args, "--clear-sign")
+	} else {
+		args = append(args, "--sign")
+	}
+
+	return args
+}
@@ -0,0 +1,604 @@
+package pgpsign
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/gopenpgp/v3/crypto"
+)
+
+// installFakeGPG puts a gpg shell script with the given body on PATH.
+func installFakeGPG(t *testing.T, body string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake gpg script requires a POSIX shell")
+	}
+
+	binDir := t.TempDir()
+	script := "#!/bin/sh\n" + body + "\n"
+	if err := os.WriteFile(filepath.Join(binDir, "gpg"), []byte(script), 0o755); err != nil {
+		t.Fatalf("failed to write fake gpg: %v", err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestGnuPGSigner_Timeout(t *testing.T) {
+	installFakeGPG(t, "exec sleep 30")
+
+	testFile := filepath.Join(t.TempDir(), "test.txt")
+	if err := os.WriteFile(testFile, []byte("test content"), 0o644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	timeout := 100 * time.Millisecond
+	signer := &GnuPGSigner{gpg: GnuPGOptions{Timeout: timeout}}
+
+	tests := []struct {
+		name string
+		run  func() error
+	}{
+		{
+			name: "import",
+			run:  func() error { return importGPGKey("key", GnuPGOptions{Timeout: timeout}) },
+		},
+		{
+			name: "sign file",
+			run: func() error {
+				_, err := signer.SignFile(testFile, SignOptions{Armor: true, DetachSign: true})
+				return err
+			},
+		},
+		{
+			name: "sign stream",
+			run: func() error {
+				var out strings.Builder
+				return signer.SignStream(strings.NewReader("data"), &out, SignOptions{DetachSign: true})
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			err := tt.run()
+			if err == nil || !strings.Contains(err.Error(), "timed out after 100ms") {
+				t.Fatalf("expected timeout error, got %v", err)
+			}
+			if elapsed := time.Since(start); elapsed > 10*time.Second {
+				t.Errorf("expected gpg to be killed promptly, took %s", elapsed)
+			}
+		})
+	}
+}
+
+func TestGnuPGSigner_Cancelled(t *testing.T) {
+	installFakeGPG(t, "exec sleep 30")
+
+	testFile := filepath.Join(t.TempDir(), "test.txt")
+	if err := os.WriteFile(testFile, []byte("test content"), 0o644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	signer := &GnuPGSigner{gpg: GnuPGOptions{Context: ctx}}
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := signer.SignFile(testFile, SignOptions{Armor: true, DetachSign: true})
+	if !errors.Is(err, context.Canceled) || !strings.Contains(err.Error(), "was cancelled") {
+		t.Fatalf("expected cancellation error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("expected gpg to be killed promptly, took %s", elapsed)
+	}
+	if _, err := os.Stat(testFile + ".asc"); !os.IsNotExist(err) {
+		t.Errorf("expected no signature after cancellation, got %v", err)
+	}
+}
+
+func TestGnuPGSigner_StderrInError(t *testing.T) {
+	installFakeGPG(t, `echo "gpg: using pgp trust model" >&2
+echo "gpg: signing failed: No secret key" >&2
+exit 2`)
+
+	testFile := filepath.Join(t.TempDir(), "test.txt")
+	if err := os.WriteFile(testFile, []byte("test content"), 0o644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	var logs bytes.Buffer
+	log := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	signer := &GnuPGSigner{gpg: GnuPGOptions{Log: log}}
+
+	_, err := signer.SignFile(testFile, SignOptions{Armor: true, DetachSign: true})
+	if err == nil {
+		t.Fatal("expected error from failing gpg")
+	}
+	expected := "gpg command failed: exit status 2: gpg: using pgp trust model; gpg: signing failed: No secret key"
+	if err.Error() != expected {
+		t.Errorf("expected error %q, got %q", expected, err.Error())
+	}
+	if !strings.Contains(logs.String(), "No secret key") {
+		t.Errorf("expected gpg stderr in debug log, got %q", logs.String())
+	}
+}
+
+func TestLastLines(t *testing.T) {
+	output := "line 1\nline 2\nline 3\n  line 4  "
+	if got := lastLines(output, 2); got != "line 3; line 4" {
+		t.Errorf("expected last two lines, got %q", got)
+	}
+	if got := lastLines(output, 10); got != "line 1; line 2; line 3; line 4" {
+		t.Errorf("expected all lines, got %q", got)
+	}
+}
+
+func TestGnuPGSigner_NoOverwrite(t *testing.T) {
+	installFakeGPG(t, "exit 0")
+
+	testFile := filepath.Join(t.TempDir(), "test.txt")
+	if err := os.WriteFile(testFile, []byte("test content"), 0o644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+	opts := SignOptions{Armor: true, DetachSign: true, NoOverwrite: true}
+	if err := os.WriteFile(getOutputPath(testFile, opts), []byte("existing"), 0o644); err != nil {
+		t.Fatalf("failed to create existing signature: %v", err)
+	}
+
+	_, err := (&GnuPGSigner{}).SignFile(testFile, opts)
+	if !errors.Is(err, errSignatureExists) {
+		t.Fatalf("expected errSignatureExists, got %v", err)
+	}
+}
+
+func TestGnuPGSigner_BuildArgsDigestAlgorithm(t *testing.T) {
+	signer := &GnuPGSigner{}
+
+	args := signer.buildArgs(SignOptions{DetachSign: true, DigestAlgorithm: DigestSHA512}, 0)
+	if !slices.Contains(args, "--digest-algo") || !slices.Contains(args, "SHA512") {
+		t.Errorf("expected --digest-algo SHA512 in %v", args)
+	}
+
+	args = signer.buildArgs(SignOptions{DetachSign: true}, 0)
+	if slices.Contains(args, "--digest-algo") {
+		t.Errorf("expected no --digest-algo by default, got %v", args)
+	}
+}
+
+func TestGnuPGSigner_BuildArgsArmorHeaders(t *testing.T) {
+	signer := &GnuPGSigner{}
+
+	args := signer.buildArgs(SignOptions{Armor: true, DetachSign: true, ArmorComment: "release", StripArmorVersion: true}, 0)
+	if i := slices.Index(args, "--comment"); i < 0 || i+1 >= len(args) || args[i+1] != "release" {
+		t.Errorf("expected --comment release in %v", args)
+	}
+	if !slices.Contains(args, "--no-emit-version") {
+		t.Errorf("expected --no-emit-version in %v", args)
+	}
+
+	args = signer.buildArgs(SignOptions{Armor: true, DetachSign: true}, 0)
+	if slices.Contains(args, "--comment") || slices.Contains(args, "--no-emit-version") {
+		t.Errorf("expected gpg's own armor headers by default, got %v", args)
+	}
+}
+
+func TestGnuPGSigner_MinimalArmor(t *testing.T) {
+	// A fake gpg that writes the headers a gpg.conf with emit-version and comment asks for.
+	const armored = "-----BEGIN PGP SIGNATURE-----\nVersion: GnuPG v2\nComment: from gpg.conf\n\nwsBcBAAB\n-----END PGP SIGNATURE-----\n"
+	installFakeGPG(t, `cat > /dev/null
+out=-; while [ $# -gt 0 ]; do [ "$1" = --output ] && out="$2"; shift; done
+sig='`+strings.ReplaceAll(armored, "\n", `\n`)+`'
+if [ "$out" = - ]; then printf '%b' "$sig"; else printf '%b' "$sig" > "$out"; fi`)
+
+	signer := &GnuPGSigner{keyID: "0123456789ABCDEF"}
+	opts := SignOptions{Armor: true, DetachSign: true, MinimalArmor: true}
+	const expected = "-----BEGIN PGP SIGNATURE-----\n\nwsBcBAAB\n-----END PGP SIGNATURE-----\n"
+
+	testFile := filepath.Join(t.TempDir(), "test.txt")
+	if err := os.WriteFile(testFile, []byte("test content"), 0o644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+	result, err := signer.SignFile(testFile, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if data, err := os.ReadFile(result.OutputPath); err != nil || string(data) != expected {
+		t.Errorf("expected signature file without headers:\n%s\ngot:\n%s (%v)", expected, data, err)
+	}
+
+	var buf bytes.Buffer
+	if err := signer.SignStream(strings.NewReader("test content"), &buf, opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != expected {
+		t.Errorf("expected streamed signature without headers:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+func TestGnuPGSigner_PassphraseFile(t *testing.T) {
+	dir := t.TempDir()
+	argsFile := filepath.Join(dir, "args")
+	stdinFile := filepath.Join(dir, "stdin")
+	installFakeGPG(t, `echo "$@" > '`+argsFile+`'; cat > '`+stdinFile+`'
+while [ $# -gt 0 ]; do [ "$1" = --output ] && : > "$2"; shift; done`)
+
+	testFile := filepath.Join(dir, "test.txt")
+	if err := os.WriteFile(testFile, []byte("test content"), 0o644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		passphraseFile string
+		expectedArg    string
+		expectedStdin  string
+	}{
+		{
+			name:           "passphrase file is handed to gpg",
+			passphraseFile: "/run/secrets/passphrase",
+			expectedArg:    "--passphrase-file /run/secrets/passphrase",
+		},
+		{
+			name:          "passphrase without file goes to stdin",
+			expectedArg:   "--passphrase-fd 0",
+			expectedStdin: "hunter2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer := &GnuPGSigner{passphrase: "hunter2", gpg: GnuPGOptions{PassphraseFile: tt.passphraseFile}}
+			result, err := signer.SignFile(testFile, SignOptions{Armor: true, DetachSign: true})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.OutputPath != testFile+".asc" || result.Bytes != int64(len("test content")) {
+				t.Errorf("unexpected result: %+v", result)
+			}
+
+			args, err := os.ReadFile(argsFile)
+			if err != nil {
+				t.Fatalf("failed to read gpg arguments: %v", err)
+			}
+			if !strings.Contains(string(args), tt.expectedArg) {
+				t.Errorf("expected %q in gpg arguments %q", tt.expectedArg, args)
+			}
+			if strings.Contains(string(args), "hunter2") {
+				t.Errorf("passphrase leaked into gpg arguments %q", args)
+			}
+
+			stdin, err := os.ReadFile(stdinFile)
+			if err != nil {
+				t.Fatalf("failed to read gpg stdin: %v", err)
+			}
+			if string(stdin) != tt.expectedStdin {
+				t.Errorf("expected stdin %q, got %q", tt.expectedStdin, stdin)
+			}
+		})
+	}
+}
+
+func TestGnuPGSigner_BuildArgsNotations(t *testing.T) {
+	signer := &GnuPGSigner{}
+
+	args := signer.buildArgs(SignOptions{
+		DetachSign: true,
+		Notations: []Notation{
+			{Name: "build-id@ci.example.com", Value: "1234"},
+			{Name: "commit@ci.example.com", Value: "abc"},
+		},
+	}, 0)
+
+	joined := strings.Join(args, " ")
+	for _, expected := range []string{"--set-notation build-id@ci.example.com=1234", "--set-notation commit@ci.example.com=abc"} {
+		if !strings.Contains(joined, expected) {
+			t.Errorf("expected %q in %v", expected, args)
+		}
+	}
+}
+
+func TestGnuPGSigner_BuildArgsSignatureTime(t *testing.T) {
+	signer := &GnuPGSigner{}
+
+	args := signer.buildArgs(SignOptions{DetachSign: true, SignatureTime: time.Unix(1700000000, 0)}, 0)
+	if !strings.Contains(strings.Join(args, " "), "--faked-system-time 1700000000!") {
+		t.Errorf("expected --faked-system-time 1700000000! in %v", args)
+	}
+
+	args = signer.buildArgs(SignOptions{DetachSign: true}, 0)
+	if slices.Contains(args, "--faked-system-time") {
+		t.Errorf("expected no --faked-system-time without a fixed time, got %v", args)
+	}
+}
+
+func TestNewGnuPGSigner_SkipImport(t *testing.T) {
+	const keyID = "90479FD5373C5F7E"
+
+	tests := []struct {
+		name        string
+		skipImport  bool
+		keyPresent  bool
+		errContains string
+		expectCalls []string
+	}{
+		{name: "imports by default", keyPresent: true, expectCalls: []string{"--batch --import -", "--batch --with-colons --list-secret-keys " + keyID}},
+		{name: "uses the key in the keyring", skipImport: true, keyPresent: true, expectCalls: []string{"--batch --with-colons --list-secret-keys " + keyID, "--batch --armor --export " + keyID}},
+		{name: "fails early for a missing key", skipImport: true, errContains: "secret key " + keyID + " not found in the gpg keyring", expectCalls: []string{"--batch --with-colons --list-secret-keys " + keyID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			callsFile := filepath.Join(t.TempDir(), "calls")
+			status := "2"
+			if tt.keyPresent {
+				status = "0"
+			}
+			installFakeGPG(t, `echo "$@" >> '`+callsFile+`'; cat > /dev/null; case "$*" in *--list-secret-keys*) exit `+status+`;; esac`)
+
+			_, err := NewGnuPGSigner("", "", keyID, GnuPGOptions{SkipImport: tt.skipImport})
+			if tt.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Fatalf("expected error containing %q, got %v", tt.errContains, err)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			data, err := os.ReadFile(callsFile)
+			if err != nil {
+				t.Fatalf("failed to read gpg calls: %v", err)
+			}
+			calls := strings.Split(strings.TrimSpace(string(data)), "\n")
+			if !slices.Equal(calls, tt.expectCalls) {
+				t.Errorf("expected gpg calls %q, got %q", tt.expectCalls, calls)
+			}
+			if tt.skipImport && strings.Contains(string(data), "--import") {
+				t.Error("expected no --import in skip-import mode")
+			}
+		})
+	}
+}
+
+func TestGnuPGSigner_SignBytes(t *testing.T) {
+	argsFile := filepath.Join(t.TempDir(), "args")
+	installFakeGPG(t, `echo "$@" > '`+argsFile+`'; printf 'signed:'; cat`)
+
+	tests := []struct {
+		name         string
+		opts         SignOptions
+		expectedMode string
+	}{
+		{name: "detached", opts: SignOptions{Armor: true, DetachSign: true}, expectedMode: "--armor --detach-sign"},
+		{name: "clear", opts: SignOptions{ClearSign: true}, expectedMode: "--clear-sign"},
+		{name: "inline", opts: SignOptions{}, expectedMode: "--sign"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer := &GnuPGSigner{}
+			signature, err := signer.SignBytes([]byte("release content"), tt.opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(signature) != "signed:release content" {
+				t.Errorf("expected the data to be piped through gpg, got %q", signature)
+			}
+
+			args, err := os.ReadFile(argsFile)
+			if err != nil {
+				t.Fatalf("failed to read gpg arguments: %v", err)
+			}
+			if !strings.Contains(string(args), tt.expectedMode+" --output -") {
+				t.Errorf("expected %q writing to stdout in gpg arguments %q", tt.expectedMode, args)
+			}
+		})
+	}
+}
+
+func TestGnuPGSigner_SkipKeyValidation(t *testing.T) {
+	signer := &GnuPGSigner{}
+
+	args := signer.buildArgs(SignOptions{DetachSign: true, SkipKeyValidation: true}, 0)
+	if i := slices.Index(args, "--trust-model"); i < 0 || i+1 >= len(args) || args[i+1] != "always" {
+		t.Errorf("expected --trust-model always in %v", args)
+	}
+
+	args = signer.buildArgs(SignOptions{DetachSign: true}, 0)
+	if slices.Contains(args, "--trust-model") {
+		t.Errorf("expected gpg's own trust model by default, got %v", args)
+	}
+
+	argsFile := filepath.Join(t.TempDir(), "args")
+	installFakeGPG(t, `echo "$@" > '`+argsFile+`'`)
+	if err := signer.Verify("file.txt", "file.txt.sig", SignOptions{DetachSign: true, SkipKeyValidation: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	verifyArgs, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatalf("failed to read gpg arguments: %v", err)
+	}
+	if !strings.Contains(string(verifyArgs), "--trust-model always --verify file.txt.sig file.txt") {
+		t.Errorf("expected --trust-model always before --verify, got %q", verifyArgs)
+	}
+}
+
+func TestGnuPGSigner_BuildArgsSignerComment(t *testing.T) {
+	signer := &GnuPGSigner{}
+	base := SignOptions{Armor: true, ArmorComment: "release", SignerComment: "Signed by Release Bot"}
+
+	clear := base
+	clear.ClearSign = true
+	args := signer.buildArgs(clear, 0)
+	if i := slices.Index(args, "--comment"); i < 0 || i+1 >= len(args) || args[i+1] != "Signed by Release Bot" {
+		t.Errorf("expected the signer comment for clear-signing in %v", args)
+	}
+
+	detached := base
+	detached.DetachSign = true
+	args = signer.buildArgs(detached, 0)
+	if i := slices.Index(args, "--comment"); i < 0 || i+1 >= len(args) || args[i+1] != "release" {
+		t.Errorf("expected the armor comment for detached signatures in %v", args)
+	}
+}
+
+func TestParseSecretKeyFingerprint(t *testing.T) {
+	tests := []struct {
+		name     string
+		colons   string
+		expected string
+	}{
+		{
+			name: "primary key with subkey",
+			colons: "sec:u:255:22:90479FD5373C5F7E:1700000000::::::scESC:::+:::23::0:\n" +
+				"fpr:::::::::8e5f6e7a1c278d5f3a3bc0f690479fd5373c5f7e:\n" +
+				"grp:::::::::0123456789ABCDEF0123456789ABCDEF01234567:\n" +
+				"uid:u::::1700000000::HASH::Test <test@test.com>::::::::::0:\n" +
+				"ssb:u:255:18:1111222233334444:1700000000::::::e:::+:::cv25519::\n" +
+				"fpr:::::::::AAAABBBBCCCCDDDDEEEEFFFF1111222233334444:\n",
+			expected: "8E5F6E7A1C278D5F3A3BC0F690479FD5373C5F7E",
+		},
+		{name: "no fingerprint record", colons: "sec:u:255:22:0123456789ABCDEF:1700000000::::::scESC:\n"},
+		{name: "subkey fingerprint only", colons: "ssb:u:255:18:1111222233334444:1700000000::::::e:\nfpr:::::::::AAAABBBBCCCCDDDDEEEEFFFF1111222233334444:\n"},
+		{name: "empty", colons: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseSecretKeyFingerprint(tt.colons); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestGPGKeyCanSign(t *testing.T) {
+	tests := []struct {
+		name     string
+		colons   string
+		expected bool
+	}{
+		{
+			name: "signing subkey",
+			colons: "sec:u:255:22:90479FD5373C5F7E:1700000000::::::cSC:::+:::23::0:\n" +
+				"ssb:u:255:22:1111222233334444:1700000000::::::s:::+:::ed25519::\n",
+			expected: true,
+		},
+		{name: "signing primary key", colons: "sec:u:255:22:90479FD5373C5F7E:1700000000::::::scESC:\n", expected: true},
+		{
+			name: "encryption subkey only",
+			colons: "sec:u:255:22:90479FD5373C5F7E:1700000000::::::cEC:::+:::23::0:\n" +
+				"ssb:u:255:18:1111222233334444:1700000000::::::e:::+:::cv25519::\n",
+		},
+		{name: "expired signing key", colons: "sec:e:255:22:90479FD5373C5F7E:1700000000:1710000000:::::sc:\n"},
+		{name: "no capabilities listed", colons: "sec:u:255:22:90479FD5373C5F7E:1700000000:\n", expected: true},
+		{name: "empty", colons: "", expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := gpgKeyCanSign(tt.colons); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestNewGnuPGSigner_NoSigningKey(t *testing.T) {
+	const keyID = "90479FD5373C5F7E"
+	installFakeGPG(t, `cat > /dev/null; case "$*" in *--list-secret-keys*)
+echo "sec:u:255:22:`+keyID+`:1700000000::::::cEC:"
+echo "ssb:u:255:18:1111222233334444:1700000000::::::e:"
+exit 0;; esac`)
+
+	for _, skipImport := range []bool{false, true} {
+		_, err := NewGnuPGSigner("", "", keyID, GnuPGOptions{SkipImport: skipImport})
+		if err == nil || !strings.Contains(err.Error(), "no valid key or subkey that can sign") {
+			t.Errorf("expected a key without signing subkey to be rejected (skip-import %v), got %v", skipImport, err)
+		}
+	}
+}
+
+func TestGnuPGSigner_Fingerprint(t *testing.T) {
+	const fingerprint = "8E5F6E7A1C278D5F3A3BC0F690479FD5373C5F7E"
+	installFakeGPG(t, `cat > /dev/null; case "$*" in *--list-secret-keys*)
+echo "sec:u:255:22:90479FD5373C5F7E:1700000000::::::scESC:"
+echo "fpr:::::::::`+strings.ToLower(fingerprint)+`:"
+exit 0;; esac`)
+
+	preloaded, err := NewGnuPGSigner("", "", "90479FD5373C5F7E", GnuPGOptions{SkipImport: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := preloaded.Fingerprint(); got != fingerprint {
+		t.Errorf("expected fingerprint %s from the gpg key listing, got %q", fingerprint, got)
+	}
+
+	armoredKey := generateTestKeyArmored(t, "Test", "test@test.com", "")
+	key, err := crypto.NewKeyFromArmored(armoredKey)
+	if err != nil {
+		t.Fatalf("failed to parse test key: %v", err)
+	}
+	imported, err := NewGnuPGSigner(armoredKey, "", "", GnuPGOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := imported.Fingerprint(), strings.ToUpper(key.GetFingerprint()); got != want {
+		t.Errorf("expected fingerprint %s of the imported key, got %q", want, got)
+	}
+}
+
+func TestGnuPGSigner_VerifyChecksSignedContent(t *testing.T) {
+	if _, err := exec.LookPath("gpg"); err != nil {
+		t.Skip("gpg not available")
+	}
+
+	signer, err := NewGnuPGSigner(generateTestKeyArmored(t, "Test", "test@test.com", ""), "", "", GnuPGOptions{Home: t.TempDir()})
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		opts SignOptions
+	}{
+		{name: "clear sign", opts: SignOptions{ClearSign: true}},
+		{name: "inline armor", opts: SignOptions{Armor: true}},
+		{name: "inline binary", opts: SignOptions{}},
+		{name: "inline text mode", opts: SignOptions{Armor: true, TextMode: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testFile := filepath.Join(t.TempDir(), "test.txt")
+			if err := os.WriteFile(testFile, []byte("line one  \nline two\n"), 0o644); err != nil {
+				t.Fatalf("failed to create test file: %v", err)
+			}
+			if _, err := signer.SignFile(testFile, tt.opts); err != nil {
+				t.Fatalf("failed to sign file: %v", err)
+			}
+
+			sigFile := getOutputPath(testFile, tt.opts)
+			if err := signer.Verify(testFile, sigFile, tt.opts); err != nil {
+				t.Fatalf("expected signature to verify, got: %v", err)
+			}
+
+			if err := os.WriteFile(testFile, []byte("line one  \nline 2\n"), 0o644); err != nil {
+				t.Fatalf("failed to modify test file: %v", err)
+			}
+			err := signer.Verify(testFile, sigFile, tt.opts)
+			if err == nil || !strings.Contains(err.Error(), "does not match file content") {
+				t.Errorf("expected verification to fail for the modified file, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,557 @@
+package pgpsign
+
+import (
+	"bytes"
+	stdcrypto "crypto"
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/ProtonMail/go-crypto/openpgp/clearsign"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	openpgp "github.com/ProtonMail/go-crypto/openpgp/v2"
+	"github.com/ProtonMail/gopenpgp/v3/constants"
+	"github.com/ProtonMail/gopenpgp/v3/crypto"
+	"github.com/ProtonMail/gopenpgp/v3/profile"
+)
+
+// GoPGPSigner implements Signer using the gopenpgp library (pure Go).
+type GoPGPSigner struct {
+	privateKey   *crypto.Key
+	signingKeyID uint64 // Selected primary key or subkey; zero lets go-crypto choose
+}
+
+// NewGoPGPSigner creates a new GoPGPSigner with the provided private key and passphrase.
+// A non-empty keyID selects the primary key or subkey used for signing by fingerprint or long key ID.
+func NewGoPGPSigner(armoredKey, passphrase, keyID string) (*GoPGPSigner, error) {
+	key, err := crypto.NewKeyFromArmored(armoredKey)
+	if err != nil {
+		return nil, notPrivateKeyError(armoredKey, err)
+	}
+
+	if !key.IsPrivate() {
+		return nil, notPrivateKeyError(armoredKey, nil)
+	}
+
+	// Unlock the key if passphrase is provided
+	if passphrase != "" {
+		key, err = key.Unlock([]byte(passphrase))
+		if err != nil {
+			return nil, fmt.Errorf("failed to unlock private key: %w", err)
+		}
+	}
+
+	// Check if key is locked (needs passphrase but none provided)
+	locked, err := key.IsLocked()
+	if err != nil {
+		return nil, fmt.Errorf("failed to check key lock status: %w", err)
+	}
+	if locked {
+		return nil, fmt.Errorf("private key is locked but no passphrase provided")
+	}
+
+	// Signatures from an expired key fail verification, so refuse to make them at all.
+	if info := entityKeyInfo(key.GetEntity()); info.expired(time.Now()) {
+		return nil, fmt.Errorf("private key expired on %s", info.expiresString())
+	}
+
+	// Keys exported with only an encryption subkey would otherwise fail on the first file.
+	if err := requireSigningKey(key.GetEntity()); err != nil {
+		return nil, err
+	}
+
+	signer := &GoPGPSigner{
+		privateKey: key,
+	}
+
+	if keyID != "" {
+		signer.signingKeyID, err = selectSigningKey(key.GetEntity(), keyID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return signer, nil
+}
+
+// PublicKey returns the public half of the signing key.
+func (s *GoPGPSigner) PublicKey() (*crypto.Key, error) {
+	return s.privateKey.ToPublic()
+}
+
+// Fingerprint returns the upper-case hex fingerprint of the primary key.
+func (s *GoPGPSigner) Fingerprint() string {
+	return strings.ToUpper(s.privateKey.GetFingerprint())
+}
+
+// ExportPublicKey returns the armored public half of the signing key.
+func (s *GoPGPSigner) ExportPublicKey() (string, error) {
+	return s.privateKey.GetArmoredPublicKey()
+}
+
+// KeyInfo returns the user IDs and expiration of the signing key.
+func (s *GoPGPSigner) KeyInfo() (KeyInfo, error) {
+	return entityKeyInfo(s.privateKey.GetEntity()), nil
+}
+
+// SignFile signs a file using gopenpgp.
+// The file is streamed into the signature file, so memory use does not grow with the file size
+// for detached and inline signatures. If the file's size or modification time changes while
+// it is read, no signature is written and the file is signed again or fails per OnFileChange.
+func (s *GoPGPSigner) SignFile(filePath string, opts SignOptions) (SignResult, error) {
+	outputPath := getOutputPath(filePath, opts)
+	if err := checkOverwrite(outputPath, opts); err != nil {
+		return SignResult{}, err
+	}
+
+	err := retryOnFileChange(opts.OnFileChange, func() error {
+		return s.signFileTo(filePath, outputPath, opts)
+	})
+	if err != nil {
+		return SignResult{}, err
+	}
+
+	return newSignResult(filePath, outputPath, s.privateKey, s.digestAlgorithm(opts)), nil
+}
+
+// signFileTo streams filePath into a signature written to outputPath. The signature is
+// discarded if the file changed while it was read.
+func (s *GoPGPSigner) signFileTo(filePath, outputPath string, opts SignOptions) error {
+	in, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	defer in.Close()
+
+	before, err := in.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	out, err := createOutputFile(outputPath, opts.outputMode())
+	if err != nil {
+		return fmt.Errorf("failed to create signature file: %w", err)
+	}
+
+	if err := s.SignStream(in, out, opts); err != nil {
+		out.Abort()
+		return err
+	}
+	if err := checkFileUnchanged(filePath, before); err != nil {
+		out.Abort()
+		return err
+	}
+
+	if err := out.Commit(); err != nil {
+		return fmt.Errorf("failed to write signature: %w", err)
+	}
+	return nil
+}
+
+// SignStream signs data read from r and writes the signature to w.
+// Detached and inline signatures are computed while streaming; clear-signing buffers the input.
+func (s *GoPGPSigner) SignStream(r io.Reader, w io.Writer, opts SignOptions) error {
+	if opts.MinimalArmor {
+		m := &minimalArmorWriter{w: w}
+		opts.MinimalArmor = false
+		if err := s.SignStream(r, m, opts); err != nil {
+			return err
+		}
+		return m.Close()
+	}
+
+	config, err := s.signConfig(opts)
+	if err != nil {
+		return err
+	}
+
+	headers := armorHeaders(opts)
+	if opts.DetachSign {
+		return s.writeDetachedSignature(r, w, opts, headers, config)
+	} else if opts.ClearSign {
+		return s.writeClearSignature(r, w, opts, headers, config)
+	}
+	return s.writeInlineSignature(r, w, opts, headers, config)
+}
+
+// SignBytes signs data held in memory and returns the signature.
+func (s *GoPGPSigner) SignBytes(data []byte, opts SignOptions) ([]byte, error) {
+	return signBytesWithStream(s.SignStream, data, opts)
+}
+
+// pgpProfile returns the gopenpgp default profile. With SkipKeyValidation, keys without key
+// flags are treated as if all flags were set, as GnuPG does.
+func pgpProfile(opts SignOptions) *profile.Custom {
+	p := profile.Default()
+	p.InsecureAllowAllKeyFlagsWhenMissing = opts.SkipKeyValidation
+	return p
+}
+
+// signConfig builds the OpenPGP signing configuration for the given options.
+// It starts from the gopenpgp default profile so unset options keep the library defaults.
+func (s *GoPGPSigner) signConfig(opts SignOptions) (*packet.Config, error) {
+	config := pgpProfile(opts).SignConfig()
+	config.SigningKeyId = s.signingKeyID
+
+	if !opts.SignatureTime.IsZero() {
+		signatureTime := opts.SignatureTime
+		config.Time = func() time.Time { return signatureTime }
+	}
+
+	// Older GnuPG releases warn about or reject the salt notation, so the compat
+	// profile omits it along with any hash other than SHA-256.
+	if opts.Deterministic || opts.GnuPGCompat {
+		salted := false
+		config.NonDeterministicSignaturesViaNotation = &salted
+	}
+
+	if opts.GnuPGCompat {
+		if version := s.privateKey.GetVersion(); version != 4 {
+			return nil, fmt.Errorf("gnupg-compat requires a v4 signing key, got v%d", version)
+		}
+		config.DefaultHash = stdcrypto.SHA256
+	}
+
+	if notations := opts.signatureNotations(); len(notations) > 0 {
+		config.SignatureNotations = packetNotations(notations)
+	}
+
+	if opts.DigestAlgorithm != DigestDefault {
+		hash := digestHashes[opts.DigestAlgorithm]
+		if err := checkDigestPreference(s.privateKey.GetEntity(), hash, config); err != nil {
+			return nil, err
+		}
+		config.DefaultHash = hash
+	}
+
+	return config, nil
+}
+
+// digestAlgorithm returns the digest go-crypto signs with for opts,
+// or DigestDefault if it falls back to a hash preferred by the key.
+func (s *GoPGPSigner) digestAlgorithm(opts SignOptions) DigestAlgorithm {
+	if opts.DigestAlgorithm != DigestDefault {
+		return opts.DigestAlgorithm
+	}
+	config, err := s.signConfig(opts)
+	if err != nil {
+		return DigestDefault
+	}
+	for algo, hash := range digestHashes {
+		if hash == config.Hash() && checkDigestPreference(s.privateKey.GetEntity(), hash, config) == nil {
+			return algo
+		}
+	}
+	return DigestDefault
+}
+
+// signers returns the entities used for signing.
+func (s *GoPGPSigner) signers() []*openpgp.Entity {
+	return []*openpgp.Entity{s.privateKey.GetEntity()}
+}
+
+// writeDetachedSignature streams r through the signer and writes the detached signature to w.
+func (s *GoPGPSigner) writeDetachedSignature(r io.Reader, w io.Writer, opts SignOptions, headers map[string]string, config *packet.Config) error {
+	params := &openpgp.SignParams{TextSig: opts.textSignature(), Config: config}
+	if !opts.Armor {
+		if err := openpgp.DetachSignWithParams(w, s.signers(), r, params); err != nil {
+			return fmt.Errorf("failed to create detached signature: %w", err)
+		}
+		return nil
+	}
+
+	armorWriter, err := s.armorWriter(w, constants.PGPSignatureHeader, headers)
+	if err != nil {
+		return err
+	}
+	if err := openpgp.DetachSignWithParams(armorWriter, s.signers(), r, params); err != nil {
+		return fmt.Errorf("failed to create detached signature: %w", err)
+	}
+	if err := armorWriter.Close(); err != nil {
+		return fmt.Errorf("failed to armor signature: %w", err)
+	}
+
+	return nil
+}
+
+// writeClearSignature writes a clear-text signature of the text read from r to w.
+// The text is read into memory and normalized first (see clearSignText) so the signed text
+// matches what verifiers display. headers are added to the armored signature block.
+func (s *GoPGPSigner) writeClearSignature(r io.Reader, w io.Writer, opts SignOptions, headers map[string]string, config *packet.Config) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("failed to read input: %w", err)
+	}
+	data, err = clearSignText(data, opts)
+	if err != nil {
+		return err
+	}
+
+	var privateKeys []*packet.PrivateKey
+	for _, entity := range s.signers() {
+		key, ok := entity.SigningKeyById(config.Now(), config.SigningKey(), config)
+		if !ok || key.PrivateKey == nil {
+			return fmt.Errorf("failed to create clear signature: no valid signing key")
+		}
+		privateKeys = append(privateKeys, key.PrivateKey)
+	}
+
+	plaintext, err := clearsign.EncodeMultiWithHeader(w, privateKeys, config, headers)
+	if err != nil {
+		return fmt.Errorf("failed to create clear signature: %w", err)
+	}
+	if _, err := plaintext.Write(data); err != nil {
+		return fmt.Errorf("failed to create clear signature: %w", err)
+	}
+	if err := plaintext.Close(); err != nil {
+		return fmt.Errorf("failed to create clear signature: %w", err)
+	}
+
+	return nil
+}
+
+// writeInlineSignature streams r into an inline (attached) signed message written to w.
+// opts.Filename is recorded in the message's literal data packet; it may be empty. With
+// opts.TextMode, the message holds UTF-8 text with CRLF line endings and a text signature;
+// opts.SignatureType text alone only changes the signature type.
+func (s *GoPGPSigner) writeInlineSignature(r io.Reader, w io.Writer, opts SignOptions, headers map[string]string, config *packet.Config) error {
+	var armorWriter io.WriteCloser
+	if opts.Armor {
+		var err error
+		armorWriter, err = s.armorWriter(w, constants.PGPMessageHeader, headers)
+		if err != nil {
+			return err
+		}
+		w = armorWriter
+	}
+
+	message, err := openpgp.SignWithParams(w, s.signers(), &openpgp.SignParams{
+		Hints:   &openpgp.FileHints{FileName: opts.Filename, IsUTF8: opts.TextMode, ModTime: time.Unix(0, 0)},
+		TextSig: opts.textSignature(),
+		Config:  config,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create signature: %w", err)
+	}
+	var body io.Writer = message
+	if opts.TextMode {
+		body = &crlfWriter{w: message}
+	}
+	if _, err := io.Copy(body, r); err != nil {
+		return fmt.Errorf("failed to create signature: %w", err)
+	}
+	if err := message.Close(); err != nil {
+		return fmt.Errorf("failed to create signature: %w", err)
+	}
+
+	if armorWriter != nil {
+		if err := armorWriter.Close(); err != nil {
+			return fmt.Errorf("failed to armor signature: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// armorWriter returns a writer that ASCII armors everything written to it as the given block type
+// with the given headers. Like gopenpgp, the armor checksum is omitted for v6 keys only.
+func (s *GoPGPSigner) armorWriter(w io.Writer, blockType string, headers map[string]string) (io.WriteCloser, error) {
+	checksum := s.privateKey.GetVersion() != 6
+
+	armorWriter, err := armor.EncodeWithChecksumOption(w, blockType, headers, checksum)
+	if err != nil {
+		return nil, fmt.Errorf("failed to armor signature: %w", err)
+	}
+	return armorWriter, nil
+}
+
+// armorHeaders returns the headers written into armored output. Like gopenpgp's default,
+// no Version header is ever written, so StripArmorVersion needs no handling here.
+func armorHeaders(opts SignOptions) map[string]string {
+	comment := opts.commentHeader()
+	if comment == "" {
+		return nil
+	}
+	return map[string]string{"Comment": comment}
+}
+
+// Verify checks a signature produced by SignFile against the signer's public key.
+func (s *GoPGPSigner) Verify(filePath, sigPath string, opts SignOptions) error {
+	publicKey, err := s.PublicKey()
+	if err != nil {
+		return fmt.Errorf("failed to derive public key: %w", err)
+	}
+	keyRing, err := crypto.NewKeyRing(publicKey)
+	if err != nil {
+		return fmt.Errorf("failed to create key ring: %w", err)
+	}
+
+	return verifyPGPSignature(keyRing, filePath, sigPath, opts)
+}
+
+// checkClearSignedFile checks that cleartext, the text decoded from a clear signature, is the
+// content of filePath as it is clear-signed with opts, ignoring trailing whitespace and line
+// ending differences. A valid clear signature only proves that its own text is signed, not
+// that the text is the file's.
+func checkClearSignedFile(cleartext []byte, filePath string, opts SignOptions) error {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	data, err = clearSignText(data, opts)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(signedCleartext(cleartext), signedCleartext(data)) {
+		return fmt.Errorf("clear-signed text does not match file content")
+	}
+	return nil
+}
+
+// messageDigest hashes the message of an inline signature written to it, so the message can
+// be compared with the signed file without holding either in memory. The message of a text
+// signature is hashed with CRLF line endings, as is the file it is compared with.
+type messageDigest struct {
+	hash hash.Hash
+	w    io.Writer
+}
+
+// newMessageDigest returns a messageDigest for the message of a signature made with opts.
+func newMessageDigest(opts SignOptions) *messageDigest {
+	h := sha256.New()
+	if opts.textSignature() {
+		return &messageDigest{hash: h, w: &crlfWriter{w: h}}
+	}
+	return &messageDigest{hash: h, w: h}
+}
+
+// Write hashes p.
+func (d *messageDigest) Write(p []byte) (int, error) {
+	return d.w.Write(p)
+}
+
+// checkFile checks that the message written to d is the content of filePath. A valid inline
+// signature only proves that its own message is signed, not that it is the file's.
+func (d *messageDigest) checkFile(filePath string, opts SignOptions) error {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+
+	file := newMessageDigest(opts)
+	if _, err := io.Copy(file, f); err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	if !bytes.Equal(d.hash.Sum(nil), file.hash.Sum(nil)) {
+		return fmt.Errorf("signed message does not match file content")
+	}
+	return nil
+}
+
+// verifyDetachedFile verifies the detached signature over filePath while streaming the file,
+// so large files are never held in memory.
+func verifyDetachedFile(verifyHandle crypto.PGPVerify, filePath, sigPath string, encoding int8) (*crypto.VerifyResult, error) {
+	signature, err := os.ReadFile(sigPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signature: %w", err)
+	}
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+
+	reader, err := verifyHandle.VerifyingReader(f, bytes.NewReader(signature), encoding)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify detached signature: %w", err)
+	}
+	result, err := reader.DiscardAllAndVerifySignature()
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify detached signature: %w", err)
+	}
+	return result, nil
+}
+
+// verifyClearSignedFile verifies the clear signature at sigPath and checks that its text is
+// the content of filePath.
+func verifyClearSignedFile(verifyHandle crypto.PGPVerify, filePath, sigPath string, opts SignOptions) (*crypto.VerifyResult, error) {
+	signature, err := os.ReadFile(sigPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signature: %w", err)
+	}
+	verified, err := verifyHandle.VerifyCleartext(signature)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify clear signature: %w", err)
+	}
+	if err := checkClearSignedFile(verified.Cleartext(), filePath, opts); err != nil {
+		return nil, err
+	}
+	return &verified.VerifyResult, nil
+}
+
+// verifyInlineFile verifies the inline signed message at sigPath and checks that its message
+// is the content of filePath, streaming both files.
+func verifyInlineFile(verifyHandle crypto.PGPVerify, filePath, sigPath string, encoding int8, opts SignOptions) (*crypto.VerifyResult, error) {
+	signature, err := os.Open(sigPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signature: %w", err)
+	}
+	defer signature.Close()
+
+	reader, err := verifyHandle.VerifyingReader(nil, signature, encoding)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify signature: %w", err)
+	}
+	message := newMessageDigest(opts)
+	if _, err := io.Copy(message, reader); err != nil {
+		return nil, fmt.Errorf("failed to verify signature: %w", err)
+	}
+	result, err := reader.VerifySignature()
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify signature: %w", err)
+	}
+	if err := message.checkFile(filePath, opts); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// verifyPGPSignature checks the signature at sigPath for filePath against the keys in keyRing.
+func verifyPGPSignature(keyRing *crypto.KeyRing, filePath, sigPath string, opts SignOptions) error {
+	verifyHandle, err := crypto.PGPWithProfile(pgpProfile(opts)).Verify().
+		VerificationKeys(keyRing).
+		New()
+	if err != nil {
+		return fmt.Errorf("failed to create verification handle: %w", err)
+	}
+
+	encoding := crypto.Bytes
+	if opts.Armor {
+		encoding = crypto.Armor
+	}
+
+	var result *crypto.VerifyResult
+	switch {
+	case opts.DetachSign:
+		result, err = verifyDetachedFile(verifyHandle, filePath, sigPath, encoding)
+	case opts.ClearSign:
+		result, err = verifyClearSignedFile(verifyHandle, filePath, sigPath, opts)
+	default:
+		result, err = verifyInlineFile(verifyHandle, filePath, sigPath, encoding, opts)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := result.SignatureError(); err != nil {
+		return fmt.Errorf("signature is invalid: %w", err)
+	}
+
+	return nil
+}
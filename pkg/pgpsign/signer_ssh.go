@@ -0,0 +1,251 @@
+package pgpsign
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// SSHSIG signature layout, as used by ssh-keygen -Y and git.
+const (
+	sshSigMagic     = "SSHSIG"
+	sshSigVersion   = 1
+	sshSigNamespace = "file" // Namespace ssh-keygen -Y sign uses for files
+	sshSigHash      = "sha512"
+
+	sshSigArmorBegin = "-----BEGIN SSH SIGNATURE-----"
+	sshSigArmorEnd   = "-----END SSH SIGNATURE-----"
+	sshSigLineLength = 70
+)
+
+// sshSigBlob is the signature blob that follows the SSHSIG magic preamble.
+type sshSigBlob struct {
+	Version       uint32
+	PublicKey     []byte
+	Namespace     string
+	Reserved      string
+	HashAlgorithm string
+	Signature     []byte
+}
+
+// sshSignedData is the message the SSHSIG signature is made over, following the magic preamble.
+type sshSignedData struct {
+	Namespace     string
+	Reserved      string
+	HashAlgorithm string
+	Hash          []byte
+}
+
+// SSHSigner implements Signer using an SSH private key. It writes armored SSHSIG signatures
+// that ssh-keygen -Y verify and git check against an allowed signers file.
+type SSHSigner struct {
+	signer ssh.Signer
+}
+
+// NewSSHSigner creates a new SSHSigner from an OpenSSH, PKCS#1, PKCS#8 or SEC 1 private key,
+// decrypting it with passphrase if it is encrypted.
+func NewSSHSigner(privateKey, passphrase string) (*SSHSigner, error) {
+	signer, err := ssh.ParsePrivateKey([]byte(privateKey))
+	var missing *ssh.PassphraseMissingError
+	if errors.As(err, &missing) {
+		if passphrase == "" {
+			return nil, fmt.Errorf("ssh private key is encrypted but no passphrase was provided")
+		}
+		signer, err = ssh.ParsePrivateKeyWithPassphrase([]byte(privateKey), []byte(passphrase))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ssh private key: %w", err)
+	}
+
+	return &SSHSigner{signer: signer}, nil
+}
+
+// Fingerprint returns the SHA256 fingerprint of the signing key as shown by ssh-keygen -l.
+func (s *SSHSigner) Fingerprint() string {
+	return ssh.FingerprintSHA256(s.signer.PublicKey())
+}
+
+// SignFile signs a file and writes the armored SSH signature next to it.
+func (s *SSHSigner) SignFile(filePath string, opts SignOptions) (SignResult, error) {
+	outputPath := getOutputPath(filePath, opts)
+	if err := checkOverwrite(outputPath, opts); err != nil {
+		return SignResult{}, err
+	}
+
+	in, err := os.Open(filePath)
+	if err != nil {
+		return SignResult{}, fmt.Errorf("failed to read file: %w", err)
+	}
+	defer in.Close()
+
+	out, err := createOutputFile(outputPath, opts.outputMode())
+	if err != nil {
+		return SignResult{}, fmt.Errorf("failed to create signature file: %w", err)
+	}
+
+	if err := s.SignStream(in, out, opts); err != nil {
+		out.Abort()
+		return SignResult{}, err
+	}
+
+	if err := out.Commit(); err != nil {
+		return SignResult{}, fmt.Errorf("failed to write signature: %w", err)
+	}
+
+	result := newSignResult(filePath, outputPath, nil, sshSigHash)
+	result.Fingerprint = s.Fingerprint()
+	return result, nil
+}
+
+// SignStream signs data read from r and writes the armored SSH signature to w.
+func (s *SSHSigner) SignStream(r io.Reader, w io.Writer, _ SignOptions) error {
+	digest := sha512.New()
+	if _, err := io.Copy(digest, r); err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+
+	message := sshSigMessage(sshSigNamespace, sshSigHash, digest.Sum(nil))
+
+	var signature *ssh.Signature
+	var err error
+	// RSA keys would default to SHA-1 signatures, which ssh-keygen no longer accepts.
+	if algorithmSigner, ok := s.signer.(ssh.AlgorithmSigner); ok && s.signer.PublicKey().Type() == ssh.KeyAlgoRSA {
+		signature, err = algorithmSigner.SignWithAlgorithm(rand.Reader, message, ssh.KeyAlgoRSASHA512)
+	} else {
+		signature, err = s.signer.Sign(rand.Reader, message)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to sign: %w", err)
+	}
+
+	blob := append([]byte(sshSigMagic), ssh.Marshal(sshSigBlob{
+		Version:       sshSigVersion,
+		PublicKey:     s.signer.PublicKey().Marshal(),
+		Namespace:     sshSigNamespace,
+		HashAlgorithm: sshSigHash,
+		Signature:     ssh.Marshal(signature),
+	})...)
+
+	if _, err := io.WriteString(w, armorSSHSignature(blob)); err != nil {
+		return fmt.Errorf("failed to write signature: %w", err)
+	}
+	return nil
+}
+
+// SignBytes signs data held in memory and returns the armored SSH signature.
+func (s *SSHSigner) SignBytes(data []byte, opts SignOptions) ([]byte, error) {
+	return signBytesWithStream(s.SignStream, data, opts)
+}
+
+// Verify checks the SSH signature at sigPath for filePath against the signing key and namespace.
+func (s *SSHSigner) Verify(filePath, sigPath string, _ SignOptions) error {
+	armored, err := os.ReadFile(sigPath)
+	if err != nil {
+		return fmt.Errorf("failed to read signature: %w", err)
+	}
+
+	sig, err := parseSSHSignature(armored)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(sig.PublicKey, s.signer.PublicKey().Marshal()) {
+		return fmt.Errorf("signature was not made by key %s", s.Fingerprint())
+	}
+	if sig.Namespace != sshSigNamespace {
+		return fmt.Errorf("signature namespace is %q, expected %q", sig.Namespace, sshSigNamespace)
+	}
+
+	var digest hash.Hash
+	switch sig.HashAlgorithm {
+	case "sha512":
+		digest = sha512.New()
+	case "sha256":
+		digest = sha256.New()
+	default:
+		return fmt.Errorf("unsupported ssh signature hash algorithm %q", sig.HashAlgorithm)
+	}
+
+	in, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	defer in.Close()
+	if _, err := io.Copy(digest, in); err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+
+	signature := new(ssh.Signature)
+	if err := ssh.Unmarshal(sig.Signature, signature); err != nil {
+		return fmt.Errorf("malformed ssh signature: %w", err)
+	}
+	message := sshSigMessage(sig.Namespace, sig.HashAlgorithm, digest.Sum(nil))
+	if err := s.signer.PublicKey().Verify(message, signature); err != nil {
+		return fmt.Errorf("signature is invalid: %w", err)
+	}
+
+	return nil
+}
+
+// sshSigMessage returns the data an SSHSIG signature over a file with the given digest signs.
+func sshSigMessage(namespace, hashAlgorithm string, digest []byte) []byte {
+	return append([]byte(sshSigMagic), ssh.Marshal(sshSignedData{
+		Namespace:     namespace,
+		HashAlgorithm: hashAlgorithm,
+		Hash:          digest,
+	})...)
+}
+
+// armorSSHSignature wraps an SSHSIG blob in the armor ssh-keygen writes.
+func armorSSHSignature(blob []byte) string {
+	encoded := base64.StdEncoding.EncodeToString(blob)
+
+	var b strings.Builder
+	b.WriteString(sshSigArmorBegin + "\n")
+	for len(encoded) > sshSigLineLength {
+		b.WriteString(encoded[:sshSigLineLength] + "\n")
+		encoded = encoded[sshSigLineLength:]
+	}
+	b.WriteString(encoded + "\n")
+	b.WriteString(sshSigArmorEnd + "\n")
+	return b.String()
+}
+
+// parseSSHSignature decodes an armored SSHSIG signature.
+func parseSSHSignature(armored []byte) (sshSigBlob, error) {
+	body, ok := strings.CutPrefix(strings.TrimSpace(string(armored)), sshSigArmorBegin)
+	if !ok {
+		return sshSigBlob{}, fmt.Errorf("malformed ssh signature: missing %s", sshSigArmorBegin)
+	}
+	body, ok = strings.CutSuffix(body, sshSigArmorEnd)
+	if !ok {
+		return sshSigBlob{}, fmt.Errorf("malformed ssh signature: missing %s", sshSigArmorEnd)
+	}
+
+	blob, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(body), ""))
+	if err != nil {
+		return sshSigBlob{}, fmt.Errorf("malformed ssh signature: %w", err)
+	}
+
+	rest, ok := bytes.CutPrefix(blob, []byte(sshSigMagic))
+	if !ok {
+		return sshSigBlob{}, fmt.Errorf("malformed ssh signature: missing %s preamble", sshSigMagic)
+	}
+	var sig sshSigBlob
+	if err := ssh.Unmarshal(rest, &sig); err != nil {
+		return sshSigBlob{}, fmt.Errorf("malformed ssh signature: %w", err)
+	}
+	if sig.Version != sshSigVersion {
+		return sshSigBlob{}, fmt.Errorf("unsupported ssh signature version %d", sig.Version)
+	}
+	return sig, nil
+}
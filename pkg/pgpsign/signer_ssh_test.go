@@ -0,0 +1,241 @@
+package pgpsign
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha512"
+	"encoding/pem"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// generateTestSSHKey returns a new OpenSSH private key, encrypted if passphrase is set, and its public key.
+func generateTestSSHKey(t *testing.T, key crypto.Signer, passphrase string) (string, ssh.PublicKey) {
+	t.Helper()
+
+	var block *pem.Block
+	var err error
+	if passphrase == "" {
+		block, err = ssh.MarshalPrivateKey(key, "test@test.com")
+	} else {
+		block, err = ssh.MarshalPrivateKeyWithPassphrase(key, "test@test.com", []byte(passphrase))
+	}
+	if err != nil {
+		t.Fatalf("failed to marshal ssh key: %v", err)
+	}
+
+	publicKey, err := ssh.NewPublicKey(key.Public())
+	if err != nil {
+		t.Fatalf("failed to derive public key: %v", err)
+	}
+	return string(pem.EncodeToMemory(block)), publicKey
+}
+
+func generateTestEd25519Key(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	return key
+}
+
+func TestNewSSHSigner(t *testing.T) {
+	plain, _ := generateTestSSHKey(t, generateTestEd25519Key(t), "")
+	encrypted, _ := generateTestSSHKey(t, generateTestEd25519Key(t), "secret")
+
+	tests := []struct {
+		name        string
+		key         string
+		passphrase  string
+		errContains string
+	}{
+		{name: "unencrypted", key: plain},
+		{name: "unencrypted with passphrase", key: plain, passphrase: "unused"},
+		{name: "encrypted", key: encrypted, passphrase: "secret"},
+		{name: "wrong passphrase", key: encrypted, passphrase: "wrong", errContains: "failed to parse ssh private key"},
+		{name: "missing passphrase", key: encrypted, errContains: "no passphrase"},
+		{name: "pgp key", key: generateTestKeyArmored(t, "Test", "test@test.com", ""), errContains: "failed to parse ssh private key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSSHSigner(tt.key, tt.passphrase)
+			if tt.errContains == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
+
+func TestSSHSigner_SignFile(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate rsa key: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		key       crypto.Signer
+		algorithm string
+	}{
+		{name: "ed25519", key: generateTestEd25519Key(t), algorithm: ssh.KeyAlgoED25519},
+		{name: "rsa", key: rsaKey, algorithm: ssh.KeyAlgoRSASHA512},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			privateKey, publicKey := generateTestSSHKey(t, tt.key, "secret")
+			signer, err := NewSSHSigner(privateKey, "secret")
+			if err != nil {
+				t.Fatalf("failed to create signer: %v", err)
+			}
+
+			file := filepath.Join(t.TempDir(), "release.tar.gz")
+			content := []byte("release content")
+			if err := os.WriteFile(file, content, 0o644); err != nil {
+				t.Fatalf("failed to write file: %v", err)
+			}
+
+			opts := SignOptions{Armor: true, DetachSign: true, Format: FormatSSH}
+			result, err := signer.SignFile(file, opts)
+			if err != nil {
+				t.Fatalf("failed to sign: %v", err)
+			}
+			if result.OutputPath != file+".sig" {
+				t.Errorf("expected signature %s.sig, got %s", file, result.OutputPath)
+			}
+			if result.Fingerprint != ssh.FingerprintSHA256(publicKey) {
+				t.Errorf("expected fingerprint %s, got %s", ssh.FingerprintSHA256(publicKey), result.Fingerprint)
+			}
+
+			armored, err := os.ReadFile(result.OutputPath)
+			if err != nil {
+				t.Fatalf("failed to read signature: %v", err)
+			}
+			lines := strings.Split(strings.TrimSpace(string(armored)), "\n")
+			if lines[0] != "-----BEGIN SSH SIGNATURE-----" || lines[len(lines)-1] != "-----END SSH SIGNATURE-----" {
+				t.Fatalf("unexpected armor:\n%s", armored)
+			}
+
+			sig, err := parseSSHSignature(armored)
+			if err != nil {
+				t.Fatalf("failed to parse signature: %v", err)
+			}
+			if sig.Version != 1 || sig.Namespace != "file" || sig.Reserved != "" || sig.HashAlgorithm != "sha512" {
+				t.Errorf("unexpected signature fields: version %d, namespace %q, reserved %q, hash %q",
+					sig.Version, sig.Namespace, sig.Reserved, sig.HashAlgorithm)
+			}
+			if !bytes.Equal(sig.PublicKey, publicKey.Marshal()) {
+				t.Error("signature does not embed the signing key")
+			}
+
+			signature := new(ssh.Signature)
+			if err := ssh.Unmarshal(sig.Signature, signature); err != nil {
+				t.Fatalf("failed to parse inner signature: %v", err)
+			}
+			if signature.Format != tt.algorithm {
+				t.Errorf("expected signature algorithm %s, got %s", tt.algorithm, signature.Format)
+			}
+			digest := sha512.Sum512(content)
+			message := append([]byte("SSHSIG"), ssh.Marshal(struct {
+				Namespace, Reserved, HashAlgorithm string
+				Hash                               []byte
+			}{"file", "", "sha512", digest[:]})...)
+			if err := publicKey.Verify(message, signature); err != nil {
+				t.Errorf("signature does not verify: %v", err)
+			}
+
+			if err := signer.Verify(file, result.OutputPath, opts); err != nil {
+				t.Errorf("Verify failed: %v", err)
+			}
+			if err := os.WriteFile(file, []byte("tampered"), 0o644); err != nil {
+				t.Fatalf("failed to write file: %v", err)
+			}
+			if err := signer.Verify(file, result.OutputPath, opts); err == nil {
+				t.Error("expected verification of a modified file to fail")
+			}
+		})
+	}
+}
+
+func TestSSHSigner_VerifiesWithSSHKeygen(t *testing.T) {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen not available")
+	}
+
+	privateKey, publicKey := generateTestSSHKey(t, generateTestEd25519Key(t), "")
+	signer, err := NewSSHSigner(privateKey, "")
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "release.txt")
+	content := []byte("release content")
+	if err := os.WriteFile(file, content, 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	result, err := signer.SignFile(file, SignOptions{DetachSign: true, Format: FormatSSH})
+	if err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+
+	allowedSigners := filepath.Join(dir, "allowed_signers")
+	entry := `test@test.com namespaces="file" ` + string(ssh.MarshalAuthorizedKey(publicKey))
+	if err := os.WriteFile(allowedSigners, []byte(entry), 0o644); err != nil {
+		t.Fatalf("failed to write allowed signers: %v", err)
+	}
+
+	cmd := exec.Command("ssh-keygen", "-Y", "verify", "-f", allowedSigners, "-I", "test@test.com", "-n", "file", "-s", result.OutputPath)
+	cmd.Stdin = bytes.NewReader(content)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Errorf("ssh-keygen rejected the signature: %v\n%s", err, output)
+	}
+}
+
+func TestRunSSH(t *testing.T) {
+	privateKey, _ := generateTestSSHKey(t, generateTestEd25519Key(t), "secret")
+
+	workDir := t.TempDir()
+	testFile := filepath.Join(workDir, "release.txt")
+	if err := os.WriteFile(testFile, []byte("release content"), 0o644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	args := Config{
+		PrivateKey:      privateKey,
+		Passphrase:      "secret",
+		Backend:         string(BackendSSH),
+		Files:           "*.txt",
+		Armor:           true,
+		VerifyAfterSign: true,
+		WorkDir:         workDir,
+	}
+	if err := run(context.Background(), args, nil, nil, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	armored, err := os.ReadFile(testFile + ".sig")
+	if err != nil {
+		t.Fatalf("expected ssh signature: %v", err)
+	}
+	if _, err := parseSSHSignature(armored); err != nil {
+		t.Errorf("invalid ssh signature: %v", err)
+	}
+}
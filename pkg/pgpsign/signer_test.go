@@ -0,0 +1,191 @@
+package pgpsign
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGetOutputExtension(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     SignOptions
+		expected string
+	}{
+		{
+			name:     "armor detach sign",
+			opts:     SignOptions{Armor: true, DetachSign: true},
+			expected: ".asc",
+		},
+		{
+			name:     "binary detach sign",
+			opts:     SignOptions{Armor: false, DetachSign: true},
+			expected: ".sig",
+		},
+		{
+			name:     "armor inline sign",
+			opts:     SignOptions{Armor: true},
+			expected: ".asc",
+		},
+		{
+			name:     "binary inline sign",
+			opts:     SignOptions{Armor: false},
+			expected: ".gpg",
+		},
+		{
+			name:     "clear sign",
+			opts:     SignOptions{ClearSign: true},
+			expected: ".asc",
+		},
+		{
+			name:     "clear sign ignores armor false",
+			opts:     SignOptions{Armor: false, ClearSign: true},
+			expected: ".asc",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := getOutputExtension(tt.opts)
+			if result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestSignOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name         string
+		opts         SignOptions
+		expectedMode string
+		errContains  string
+	}{
+		{name: "armored detached", opts: SignOptions{Armor: true, DetachSign: true}, expectedMode: "detached"},
+		{name: "binary detached", opts: SignOptions{DetachSign: true}, expectedMode: "detached"},
+		{name: "armored inline", opts: SignOptions{Armor: true}, expectedMode: "inline"},
+		{name: "binary inline", opts: SignOptions{}, expectedMode: "inline"},
+		{name: "clear sign", opts: SignOptions{Armor: true, ClearSign: true}, expectedMode: "clear-sign"},
+		{name: "clear sign with armor false", opts: SignOptions{ClearSign: true}, expectedMode: "clear-sign", errContains: "clear-sign cannot be combined with armor=false"},
+		{name: "detach and clear sign", opts: SignOptions{Armor: true, DetachSign: true, ClearSign: true}, expectedMode: "detached", errContains: "clear-sign cannot be combined with detach-sign"},
+		{name: "binary detach and clear sign", opts: SignOptions{DetachSign: true, ClearSign: true}, expectedMode: "detached", errContains: "clear-sign cannot be combined with detach-sign"},
+		{name: "minimal armor", opts: SignOptions{Armor: true, DetachSign: true, MinimalArmor: true}, expectedMode: "detached"},
+		{name: "minimal armor without armor", opts: SignOptions{DetachSign: true, MinimalArmor: true}, expectedMode: "detached", errContains: "minimal-armor requires armor"},
+		{name: "minimal armor with comment", opts: SignOptions{Armor: true, DetachSign: true, MinimalArmor: true, ArmorComment: "release"}, expectedMode: "detached", errContains: "minimal-armor cannot be combined with armor-comment"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if mode := signMode(tt.opts); mode != tt.expectedMode {
+				t.Errorf("expected mode %q, got %q", tt.expectedMode, mode)
+			}
+			err := tt.opts.Validate()
+			if tt.errContains == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
+
+func TestNewSigner_InvalidBackend(t *testing.T) {
+	_, err := NewSigner("invalid", "key", "", "", GnuPGOptions{})
+	if err == nil {
+		t.Error("expected error for invalid backend")
+	}
+}
+
+func TestGetOutputPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		filePath string
+		opts     SignOptions
+		expected string
+	}{
+		{
+			name:     "detached armor",
+			filePath: "/path/to/file.txt",
+			opts:     SignOptions{Armor: true, DetachSign: true},
+			expected: "/path/to/file.txt.asc",
+		},
+		{
+			name:     "detached binary",
+			filePath: "/path/to/file.txt",
+			opts:     SignOptions{Armor: false, DetachSign: true},
+			expected: "/path/to/file.txt.sig",
+		},
+		{
+			name:     "clear sign",
+			filePath: "/path/to/file.txt",
+			opts:     SignOptions{ClearSign: true},
+			expected: "/path/to/file.txt.asc",
+		},
+		{
+			name:     "inline armor",
+			filePath: "/path/to/file.txt",
+			opts:     SignOptions{Armor: true},
+			expected: "/path/to/file.txt.asc",
+		},
+		{
+			name:     "detached armor named by kind",
+			filePath: "/path/to/file.txt",
+			opts:     SignOptions{Armor: true, DetachSign: true, NameByKind: true},
+			expected: "/path/to/file.txt.sig",
+		},
+		{
+			name:     "inline armor named by kind",
+			filePath: "/path/to/file.txt",
+			opts:     SignOptions{Armor: true, NameByKind: true},
+			expected: "/path/to/file.txt.gpg",
+		},
+		{
+			name:     "clear sign named by kind",
+			filePath: "/path/to/file.txt",
+			opts:     SignOptions{ClearSign: true, NameByKind: true},
+			expected: "/path/to/file.txt.asc",
+		},
+		{
+			name:     "inline binary",
+			filePath: "/path/to/file.txt",
+			opts:     SignOptions{Armor: false},
+			expected: "/path/to/file.txt.gpg",
+		},
+		{
+			name:     "output directory",
+			filePath: "/work/bin/linux/app",
+			opts:     SignOptions{Armor: true, DetachSign: true, OutputDir: "/work/signatures", BaseDirs: []string{"/work"}},
+			expected: "/work/signatures/bin/linux/app.asc",
+		},
+		{
+			name:     "signature suffix",
+			filePath: "/path/to/file.txt",
+			opts:     SignOptions{Armor: true, DetachSign: true, SignatureSuffix: ".sig"},
+			expected: "/path/to/file.txt.sig",
+		},
+		{
+			name:     "signature name template",
+			filePath: "/path/to/app.tar.gz",
+			opts:     SignOptions{Armor: true, DetachSign: true, SignatureName: "{name}.{ext}.sign"},
+			expected: "/path/to/app.tar.gz.sign",
+		},
+		{
+			name:     "signature name template with key suffix",
+			filePath: "/path/to/app.zip",
+			opts:     SignOptions{DetachSign: true, SignatureName: "{name}-signature.{ext}.sig", KeySuffix: ".1A2B3C4D"},
+			expected: "/path/to/app-signature.zip.1A2B3C4D.sig",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := getOutputPath(tt.filePath, tt.opts)
+			if result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
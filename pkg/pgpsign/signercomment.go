@@ -0,0 +1,48 @@
+package pgpsign
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/ProtonMail/gopenpgp/v3/crypto"
+)
+
+// signerPlaceholder is replaced by the signer's primary user ID in the signer-comment input.
+const signerPlaceholder = "{signer}"
+
+// commentHeader returns the Comment header of armored output made with o. Clear-signed
+// output carries the signer comment instead of the armor comment if one is set.
+func (o SignOptions) commentHeader() string {
+	if o.ClearSign && o.SignerComment != "" {
+		return o.SignerComment
+	}
+	return o.ArmorComment
+}
+
+// renderSignerComment replaces {signer} in comment with the primary user ID of publicKey,
+// so readers of clear-signed output can see who signed it without verifying first.
+func renderSignerComment(comment string, publicKey *crypto.Key) (string, error) {
+	if !strings.Contains(comment, signerPlaceholder) {
+		return comment, nil
+	}
+
+	var userID string
+	if publicKey != nil {
+		userID = primaryUserID(publicKey)
+	}
+	if userID == "" {
+		return "", fmt.Errorf("signer-comment uses %s, but the user ID of the signing key is unknown", signerPlaceholder)
+	}
+	return strings.ReplaceAll(comment, signerPlaceholder, userID), nil
+}
+
+// primaryUserID returns the primary user ID of key, or an empty string if it has no valid one.
+func primaryUserID(key *crypto.Key) string {
+	_, identity := key.GetEntity().PrimaryIdentity(time.Now(), &packet.Config{})
+	if identity == nil {
+		return ""
+	}
+	return identity.Name
+}
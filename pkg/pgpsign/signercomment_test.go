@@ -0,0 +1,111 @@
+package pgpsign
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestRenderSignerComment(t *testing.T) {
+	signer, err := NewGoPGPSigner(generateTestKeyArmored(t, "Release Bot", "release@example.com", ""), "", "")
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+	publicKey, err := signer.PublicKey()
+	if err != nil {
+		t.Fatalf("failed to derive public key: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		comment     string
+		publicKey   bool
+		expected    string
+		errContains string
+	}{
+		{name: "plain", comment: "Release notes", expected: "Release notes"},
+		{name: "signer", comment: "Signed by {signer}", publicKey: true, expected: "Signed by Release Bot <release@example.com>"},
+		{name: "unknown signer", comment: "Signed by {signer}", errContains: "user ID of the signing key is unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := publicKey
+			if !tt.publicKey {
+				key = nil
+			}
+			got, err := renderSignerComment(tt.comment, key)
+			if tt.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Fatalf("expected error containing %q, got %v", tt.errContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRunSignerComment(t *testing.T) {
+	workDir := t.TempDir()
+	notes := filepath.Join(workDir, "NOTES.md")
+	if err := os.WriteFile(notes, []byte("# Release 1.0\n\n- Fixed things\n"), 0o644); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+	armoredKey := generateTestKeyArmored(t, "Release Bot", "release@example.com", "")
+
+	args := Config{
+		PrivateKey:    armoredKey,
+		Backend:       string(BackendGoPGP),
+		Files:         "NOTES.md",
+		SignModes:     "clear,detached",
+		Armor:         true,
+		ArmorComment:  "Built by release pipeline 42",
+		SignerComment: "Signed by {signer}",
+		WorkDir:       workDir,
+	}
+	if err := run(context.Background(), args, nil, nil, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	clear, err := os.ReadFile(notes + ".asc")
+	if err != nil {
+		t.Fatalf("failed to read clear signature: %v", err)
+	}
+	// Lines starting with a dash are dash-escaped in the signed text.
+	clearSigned := regexp.MustCompile(`^-----BEGIN PGP SIGNED MESSAGE-----\nHash: SHA\d+\n\n# Release 1\.0\n\n- - Fixed things\n\n?-----BEGIN PGP SIGNATURE-----\n`)
+	if !clearSigned.Match(clear) {
+		t.Errorf("expected a well-formed clear-signed message, got:\n%s", clear)
+	}
+	block := string(clear[strings.LastIndex(string(clear), "-----BEGIN PGP SIGNATURE-----"):])
+	if !strings.Contains(block, "\nComment: Signed by Release Bot <release@example.com>\n") {
+		t.Errorf("expected the signer comment in the signature block, got:\n%s", block)
+	}
+	if strings.Contains(block, "pipeline 42") {
+		t.Errorf("expected the signer comment to replace the armor comment, got:\n%s", block)
+	}
+
+	detached, err := os.ReadFile(notes + ".sig")
+	if err != nil {
+		t.Fatalf("failed to read detached signature: %v", err)
+	}
+	if !strings.Contains(string(detached), "\nComment: Built by release pipeline 42\n") {
+		t.Errorf("expected the armor comment in the detached signature, got:\n%s", detached)
+	}
+
+	signer, err := NewGoPGPSigner(armoredKey, "", "")
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+	if err := signer.Verify(notes, notes+".asc", SignOptions{Armor: true, ClearSign: true}); err != nil {
+		t.Errorf("clear-signed message with comment does not verify: %v", err)
+	}
+}
@@ -0,0 +1,264 @@
+package pgpsign
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ProtonMail/gopenpgp/v3/crypto"
+)
+
+// signingKey pairs a signer with the options and bundle writer used for its signatures.
+type signingKey struct {
+	signer  Signer
+	opts    SignOptions
+	bundles *bundleWriter
+	keyID   string // Long ID of the primary key; empty if the signer does not expose it
+
+	fingerprint string // Fingerprint of the primary key; empty if the signer does not expose it
+}
+
+// loadSigningKeys returns the keys every file is signed with. An injected signer is used
+// as the only key; otherwise one signer is created per key in the private-key input.
+// With several output kinds, each key is listed once per kind.
+func loadSigningKeys(ctx context.Context, args Config, signer Signer, opts SignOptions, kinds []OutputKind, log *slog.Logger) ([]signingKey, error) {
+	var keys []signingKey
+	if signer != nil {
+		keys = []signingKey{{signer: signer, opts: opts}}
+	} else {
+		var err error
+		keys, err = newSigningKeysFromInputs(ctx, args, opts, log)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.SkipKeyValidation {
+		log.Warn("Key validation is skipped: keys without key flags can sign and gpg trusts every key; do not use this for release keys")
+	}
+
+	for _, key := range keys {
+		if err := checkKeyExpiry(key.signer, args.ExpiryWarnWindow, time.Now(), log); err != nil {
+			return nil, err
+		}
+	}
+
+	keys = expandOutputKinds(keys, kinds)
+
+	for i := range keys {
+		format := bundleFormatFor(BundleFormat(args.BundleFormat), kinds, keys[i].opts)
+		bundles, err := newBundleWriter(format, keys[i].signer, keys[i].opts)
+		if err != nil {
+			return nil, err
+		}
+		keys[i].bundles = bundles
+		publicKey := publicKeyOf(keys[i].signer)
+		if publicKey != nil {
+			keys[i].keyID = formatKeyID(publicKey.GetKeyID())
+			keys[i].fingerprint = strings.ToUpper(publicKey.GetFingerprint())
+		}
+		if fingerprinter, ok := keys[i].signer.(Fingerprinter); ok && fingerprinter.Fingerprint() != "" {
+			keys[i].fingerprint = fingerprinter.Fingerprint()
+		}
+		if keys[i].opts.ClearSign {
+			if keys[i].opts.SignerComment, err = renderSignerComment(keys[i].opts.SignerComment, publicKey); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	reportKeyFingerprints(args, keys, log)
+	return keys, nil
+}
+
+// reportKeyFingerprints logs the fingerprint of every signing key and sets the key-fingerprint
+// output, one per line, so the key that signed a release can be audited afterwards.
+func reportKeyFingerprints(args Config, keys []signingKey, log *slog.Logger) {
+	var fingerprints []string
+	for _, key := range keys {
+		// Keys expanded for several sign modes share their fingerprint.
+		if key.fingerprint == "" || slices.Contains(fingerprints, key.fingerprint) {
+			continue
+		}
+		fingerprints = append(fingerprints, key.fingerprint)
+		log.Info("Signing key loaded", slog.String("fingerprint", key.fingerprint))
+	}
+	if len(fingerprints) > 0 {
+		args.setActionOutput("key-fingerprint", strings.Join(fingerprints, "\n"))
+	}
+}
+
+// newSigningKeysFromInputs loads the private key input and creates a signer for each key in it.
+// With more than one key, each key's signatures get a suffix derived from its short key ID.
+func newSigningKeysFromInputs(ctx context.Context, args Config, opts SignOptions, log *slog.Logger) ([]signingKey, error) {
+	backend := SignerBackend(args.Backend)
+	if formatBackend, ok := formatBackends[opts.Format]; ok {
+		backend = formatBackend
+	}
+	log.Debug("Creating signer", slog.String("backend", string(backend)))
+
+	if args.KeyDir != "" {
+		passphrase, err := resolvePassphrase(args)
+		if err != nil {
+			return nil, err
+		}
+		materials, err := loadKeyDir(args.KeyDir, passphrase, log)
+		if err != nil {
+			return nil, err
+		}
+		return newGoPGPSigningKeys(materials, opts, log)
+	}
+
+	privateKey, err := resolveKeyMaterial(args.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	// Only PGP keys are decoded; minisign and SSH keys use encodings of their own.
+	if opts.Format == FormatPGP || opts.Format == "" {
+		privateKey, err = decodeKeyMaterial(privateKey, KeyEncoding(args.KeyEncoding))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	passphrase, err := resolvePassphrase(args)
+	if err != nil {
+		return nil, err
+	}
+
+	armoredKeys := splitArmoredKeys(privateKey)
+	if len(armoredKeys) <= 1 {
+		passphraseFile, err := gpgPassphraseFile(args)
+		if err != nil {
+			return nil, err
+		}
+		gpg := GnuPGOptions{Context: ctx, Timeout: args.GPGTimeout, Log: log, Home: args.GnuPGHome, TempDir: args.TempDir, PassphraseFile: passphraseFile, SkipImport: args.SkipImport}
+		signer, err := NewSigner(backend, privateKey, passphrase, args.KeyID, gpg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create signer: %w", err)
+		}
+		log.Debug("Signer created successfully")
+		return []signingKey{{signer: signer, opts: opts}}, nil
+	}
+
+	if backend != BackendGoPGP {
+		return nil, fmt.Errorf("signing with multiple keys is only supported by the %s backend", BackendGoPGP)
+	}
+	if args.KeyID != "" {
+		return nil, fmt.Errorf("key-id cannot be combined with multiple private keys")
+	}
+
+	materials := make([]keyMaterial, 0, len(armoredKeys))
+	for i, armoredKey := range armoredKeys {
+		materials = append(materials, keyMaterial{name: fmt.Sprintf("private key %d", i+1), armored: armoredKey, passphrase: passphrase})
+	}
+	return newGoPGPSigningKeys(materials, opts, log)
+}
+
+// newGoPGPSigningKeys creates a gopgp signer for each key. With more than one key, each
+// key's signatures get a suffix derived from its short key ID.
+func newGoPGPSigningKeys(materials []keyMaterial, opts SignOptions, log *slog.Logger) ([]signingKey, error) {
+	keys := make([]signingKey, 0, len(materials))
+	seen := make(map[string]bool)
+	for _, material := range materials {
+		signer, err := NewGoPGPSigner(material.armored, material.passphrase, "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create signer for %s: %w", material.name, err)
+		}
+
+		suffix := "." + shortKeyID(signer.privateKey)
+		if seen[suffix] {
+			return nil, fmt.Errorf("%s duplicates key %s", material.name, strings.TrimPrefix(suffix, "."))
+		}
+		seen[suffix] = true
+
+		keyOpts := opts
+		if len(materials) > 1 {
+			keyOpts.KeySuffix = suffix
+		}
+		keys = append(keys, signingKey{signer: signer, opts: keyOpts})
+		log.Debug("Signer created successfully", slog.String("key_id", signer.privateKey.GetHexKeyID()))
+	}
+
+	return keys, nil
+}
+
+// publicKeyOf returns the public key of signer, or nil if the signer does not expose it.
+func publicKeyOf(signer Signer) *crypto.Key {
+	provider, ok := signer.(PublicKeyProvider)
+	if !ok {
+		return nil
+	}
+	publicKey, err := provider.PublicKey()
+	if err != nil {
+		return nil
+	}
+	return publicKey
+}
+
+// validateKeyInputs checks that a private key is given, or that skip-import selects a key
+// already in gpg's keyring. No key is needed with list-only.
+func validateKeyInputs(args Config) error {
+	// Listing files never loads a key, and verify mode checks its key in validateVerifyMode.
+	if args.ListOnly || isVerifyMode(args) {
+		return nil
+	}
+	if args.KeyDir != "" {
+		return validateKeyDir(args)
+	}
+	if !args.SkipImport {
+		if strings.TrimSpace(args.PrivateKey) == "" {
+			return fmt.Errorf("private-key is required unless key-dir or skip-import is set")
+		}
+		return nil
+	}
+	if SignerBackend(args.Backend) != BackendGnuPG {
+		return fmt.Errorf("skip-import is only supported by the %s backend", BackendGnuPG)
+	}
+	if args.KeyID == "" {
+		return fmt.Errorf("skip-import requires key-id to select the key in the gpg keyring")
+	}
+	return nil
+}
+
+// uniquePublicKeys returns the public key of every signing key once, in order.
+// Keys whose signer does not expose its public key are left out.
+func uniquePublicKeys(keys []signingKey) []*crypto.Key {
+	var publicKeys []*crypto.Key
+	seen := make(map[string]bool)
+	for _, key := range keys {
+		publicKey := publicKeyOf(key.signer)
+		if publicKey == nil || seen[publicKey.GetFingerprint()] {
+			continue
+		}
+		seen[publicKey.GetFingerprint()] = true
+		publicKeys = append(publicKeys, publicKey)
+	}
+	return publicKeys
+}
+
+// splitArmoredKeys splits input into its armored private key blocks.
+// Input without more than one private key block is returned as a single element.
+func splitArmoredKeys(input string) []string {
+	const header = "-----" + privateKeyHeader + " BLOCK-----"
+
+	if strings.Count(input, header) <= 1 {
+		return []string{input}
+	}
+
+	var blocks []string
+	for _, part := range strings.SplitAfter(input, "-----END PGP PRIVATE KEY BLOCK-----") {
+		if start := strings.Index(part, header); start >= 0 {
+			blocks = append(blocks, part[start:])
+		}
+	}
+	return blocks
+}
+
+// shortKeyID returns the last eight hex digits of the primary key ID.
+func shortKeyID(key *crypto.Key) string {
+	return formatKeyID(key.GetKeyID())[8:]
+}
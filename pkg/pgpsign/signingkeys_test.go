@@ -0,0 +1,206 @@
+package pgpsign
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v3/crypto"
+)
+
+func TestSplitArmoredKeys(t *testing.T) {
+	first := generateTestKeyArmored(t, "First", "first@test.com", "")
+	second := generateTestKeyArmored(t, "Second", "second@test.com", "")
+
+	if blocks := splitArmoredKeys(first); len(blocks) != 1 || blocks[0] != first {
+		t.Errorf("expected single key to be returned unchanged, got %d blocks", len(blocks))
+	}
+
+	blocks := splitArmoredKeys(first + "\n" + second)
+	if len(blocks) != 2 {
+		t.Fatalf("expected 2 blocks, got %d", len(blocks))
+	}
+	for i, block := range blocks {
+		if _, err := crypto.NewKeyFromArmored(block); err != nil {
+			t.Errorf("block %d is not a valid key: %v", i, err)
+		}
+	}
+}
+
+func TestNewSigningKeysFromInputs_Validation(t *testing.T) {
+	keys := generateTestKeyArmored(t, "First", "first@test.com", "") +
+		generateTestKeyArmored(t, "Second", "second@test.com", "")
+	duplicate := generateTestKeyArmored(t, "Dup", "dup@test.com", "")
+
+	tests := []struct {
+		name string
+		args Config
+	}{
+		{name: "gnupg backend", args: Config{PrivateKey: keys, Backend: string(BackendGnuPG)}},
+		{name: "with key-id", args: Config{PrivateKey: keys, Backend: string(BackendGoPGP), KeyID: "0123456789ABCDEF"}},
+		{name: "same key twice", args: Config{PrivateKey: duplicate + duplicate, Backend: string(BackendGoPGP)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := newSigningKeysFromInputs(context.Background(), tt.args, SignOptions{}, slog.New(slog.DiscardHandler)); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestRunMultipleKeys(t *testing.T) {
+	firstKey := generateTestKeyArmored(t, "First", "first@test.com", "")
+	secondKey := generateTestKeyArmored(t, "Second", "second@test.com", "")
+
+	workDir := t.TempDir()
+	testFile := filepath.Join(workDir, "release.txt")
+	content := []byte("release content")
+	if err := os.WriteFile(testFile, content, 0o644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	args := Config{
+		PrivateKey: firstKey + "\n" + secondKey,
+		Backend:    string(BackendGoPGP),
+		Files:      "*.txt",
+		Armor:      true,
+		DetachSign: true,
+		WorkDir:    workDir,
+	}
+	if err := run(context.Background(), args, nil, nil, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	signatures := make(map[string]bool)
+	for _, armoredKey := range []string{firstKey, secondKey} {
+		key, err := crypto.NewKeyFromArmored(armoredKey)
+		if err != nil {
+			t.Fatalf("failed to parse key: %v", err)
+		}
+
+		sigPath := testFile + "." + shortKeyID(key) + ".asc"
+		sig, err := os.ReadFile(sigPath)
+		if err != nil {
+			t.Fatalf("expected signature %s: %v", sigPath, err)
+		}
+		signatures[string(sig)] = true
+
+		publicKey, err := key.ToPublic()
+		if err != nil {
+			t.Fatalf("failed to get public key: %v", err)
+		}
+		verifyHandle, err := crypto.PGP().Verify().VerificationKey(publicKey).New()
+		if err != nil {
+			t.Fatalf("failed to create verification handle: %v", err)
+		}
+		result, err := verifyHandle.VerifyDetached(content, sig, crypto.Armor)
+		if err != nil {
+			t.Fatalf("failed to verify %s: %v", sigPath, err)
+		}
+		if err := result.SignatureError(); err != nil {
+			t.Errorf("signature %s is invalid: %v", sigPath, err)
+		}
+	}
+
+	if len(signatures) != 2 {
+		t.Error("expected two distinct signatures")
+	}
+	if _, err := os.Stat(testFile + ".asc"); err == nil {
+		t.Error("expected no unsuffixed signature when signing with multiple keys")
+	}
+}
+
+func TestRunSetsKeyFingerprintOutput(t *testing.T) {
+	firstKey := generateTestKeyArmored(t, "First", "first@test.com", "")
+	secondKey := generateTestKeyArmored(t, "Second", "second@test.com", "")
+
+	var fingerprints []string
+	for _, armoredKey := range []string{firstKey, secondKey} {
+		key, err := crypto.NewKeyFromArmored(armoredKey)
+		if err != nil {
+			t.Fatalf("failed to parse key: %v", err)
+		}
+		fingerprints = append(fingerprints, strings.ToUpper(key.GetFingerprint()))
+	}
+
+	tests := []struct {
+		name     string
+		keys     string
+		expected []string
+	}{
+		{name: "single key", keys: firstKey, expected: fingerprints[:1]},
+		{name: "multiple keys", keys: firstKey + "\n" + secondKey, expected: fingerprints},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workDir := t.TempDir()
+			writeTestFiles(t, workDir, "release.txt")
+			outputFile := filepath.Join(t.TempDir(), "output")
+			t.Setenv("GITHUB_OUTPUT", outputFile)
+
+			args := Config{
+				PrivateKey: tt.keys,
+				Backend:    string(BackendGoPGP),
+				Files:      "*.txt",
+				Armor:      true,
+				DetachSign: true,
+				WorkDir:    workDir,
+			}
+			if err := run(context.Background(), args, nil, nil, nil); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			outputs, err := os.ReadFile(outputFile)
+			if err != nil {
+				t.Fatalf("failed to read outputs: %v", err)
+			}
+			if !strings.Contains(string(outputs), "key-fingerprint") {
+				t.Fatalf("expected key-fingerprint output, got:\n%s", outputs)
+			}
+			for _, fingerprint := range tt.expected {
+				if !strings.Contains(string(outputs), fingerprint) {
+					t.Errorf("expected fingerprint %s in outputs, got:\n%s", fingerprint, outputs)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateKeyInputs(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        Config
+		errContains string
+	}{
+		{name: "private key", args: Config{PrivateKey: "key"}},
+		{name: "missing private key", args: Config{}, errContains: "private-key is required"},
+		{name: "skip import", args: Config{SkipImport: true, Backend: "gnupg", KeyID: "90479FD5373C5F7E"}},
+		{name: "skip import with gopgp", args: Config{SkipImport: true, Backend: "gopgp", KeyID: "90479FD5373C5F7E"}, errContains: "only supported by the gnupg backend"},
+		{name: "skip import without key id", args: Config{SkipImport: true, Backend: "gnupg"}, errContains: "requires key-id"},
+		{name: "key dir", args: Config{KeyDir: "keys", Backend: "gopgp"}},
+		{name: "key dir with private key", args: Config{KeyDir: "keys", PrivateKey: "key"}, errContains: "cannot be combined"},
+		{name: "key dir with key id", args: Config{KeyDir: "keys", KeyID: "90479FD5373C5F7E"}, errContains: "cannot be combined"},
+		{name: "key dir with gnupg", args: Config{KeyDir: "keys", Backend: "gnupg"}, errContains: "only supported by the gopgp backend"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateKeyInputs(tt.args)
+			if tt.errContains == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package pgpsign
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+)
+
+// stdinFiles is the files entry that signs data piped to stdin instead of files on disk.
+const stdinFiles = "-"
+
+// signInput and signOutput are the streams stdin mode reads data from and writes
+// the signature to when no output file is set; tests replace them.
+var (
+	signInput  io.Reader = os.Stdin
+	signOutput io.Writer = os.Stdout
+)
+
+// isStdinMode reports whether the files input selects signing data piped to stdin.
+func isStdinMode(args Config) bool {
+	return slices.Contains(parseMultilineInput(args.Files), stdinFiles)
+}
+
+// SignsToStdout reports whether the run writes the signature of stdin to stdout, so logs
+// have to go elsewhere.
+func (args Config) SignsToStdout() bool {
+	return isStdinMode(args) && args.Output == ""
+}
+
+// validateStdinMode rejects inputs that cannot be combined with signing stdin.
+// It runs before the passphrase is read, so stdin is not consumed twice.
+func validateStdinMode(args Config) error {
+	if !isStdinMode(args) {
+		if args.Output != "" {
+			return fmt.Errorf("output is only supported when signing stdin with files set to -")
+		}
+		return nil
+	}
+	if len(parseMultilineInput(args.Files)) != 1 || args.FilesFrom != "" {
+		return fmt.Errorf("files - cannot be combined with other file patterns or files-from")
+	}
+	if args.PassphraseFile == stdinPassphrase || (args.PassphraseFD != nil && *args.PassphraseFD == 0) {
+		return fmt.Errorf("cannot read both the passphrase and the data to sign from stdin")
+	}
+	if args.TarMembers || args.SignAndVerify || args.VerifyAfterSign || args.VerifyKeyring != "" || args.AssertReproducible || BundleFormat(args.BundleFormat) != BundleNone || args.OutputDir != "" {
+		return fmt.Errorf("signing stdin does not support tar-members, sign-and-verify, verify-after-sign, verify-keyring, assert-reproducible, bundle-format or output-dir")
+	}
+	return nil
+}
+
+// runStdin signs data read from stdin and writes the signature to the output input, or to stdout.
+func runStdin(_ context.Context, args Config, keys []signingKey, workDir string, log *slog.Logger) error {
+	if len(keys) != 1 {
+		return fmt.Errorf("signing stdin supports a single signing key and sign mode")
+	}
+	signer, opts := keys[0].signer, keys[0].opts
+
+	if args.Output == "" {
+		log.Debug("Signing stdin", slog.String("output", "stdout"))
+		if args.DryRun {
+			return nil
+		}
+		if err := signer.SignStream(signInput, signOutput, opts); err != nil {
+			return fmt.Errorf("failed to sign stdin: %w", err)
+		}
+		return nil
+	}
+
+	outputPath := resolvePath(workDir, args.Output)
+	log.Info("Signing stdin", slog.String("output", outputPath))
+	if args.DryRun {
+		return nil
+	}
+
+	if err := signStdinToFile(signer, outputPath, opts); err != nil {
+		return err
+	}
+	args.setSignatureOutputs([]string{outputPath})
+	log.Info("Signed stdin", slog.String("signature", outputPath))
+	return nil
+}
+
+// signStdinToFile signs data read from stdin and writes the signature to outputPath,
+// which is left untouched if signing fails.
+func signStdinToFile(signer Signer, outputPath string, opts SignOptions) error {
+	out, err := createOutputFile(outputPath, opts.outputMode())
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	if err := signer.SignStream(signInput, out, opts); err != nil {
+		out.Abort()
+		return fmt.Errorf("failed to sign stdin: %w", err)
+	}
+	if err := out.Commit(); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	return nil
+}
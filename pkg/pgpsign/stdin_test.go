@@ -0,0 +1,111 @@
+package pgpsign
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setStdinMode replaces the streams used for signing stdin for the duration of the test.
+func setStdinMode(t *testing.T, input string) *bytes.Buffer {
+	t.Helper()
+	var output bytes.Buffer
+	prevInput, prevOutput := signInput, signOutput
+	signInput, signOutput = strings.NewReader(input), &output
+	t.Cleanup(func() { signInput, signOutput = prevInput, prevOutput })
+	return &output
+}
+
+func TestRunSignsStdin(t *testing.T) {
+	armoredKey := generateTestKeyArmored(t, "Test", "test@test.com", "")
+	signer, err := NewGoPGPSigner(armoredKey, "", "")
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+	const content = "streamed artifact\n"
+
+	tests := []struct {
+		name       string
+		detachSign bool
+		clearSign  bool
+		output     string
+	}{
+		{name: "detached to stdout", detachSign: true},
+		{name: "clear-signed to stdout", clearSign: true},
+		{name: "detached to output file", detachSign: true, output: "artifact.asc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workDir := t.TempDir()
+			stdout := setStdinMode(t, content)
+
+			args := Config{
+				PrivateKey: "key",
+				Files:      "-",
+				Armor:      true,
+				DetachSign: tt.detachSign,
+				ClearSign:  tt.clearSign,
+				WorkDir:    workDir,
+				Output:     tt.output,
+			}
+			if err := run(context.Background(), args, signer, &MockFileFinder{}, nil); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			sigPath := filepath.Join(workDir, "artifact.asc")
+			if tt.output == "" {
+				if err := os.WriteFile(sigPath, stdout.Bytes(), 0o644); err != nil {
+					t.Fatalf("failed to write signature: %v", err)
+				}
+			} else if stdout.Len() != 0 {
+				t.Errorf("expected nothing on stdout with an output file, got %q", stdout.String())
+			}
+
+			dataPath := filepath.Join(workDir, "artifact")
+			if err := os.WriteFile(dataPath, []byte(content), 0o644); err != nil {
+				t.Fatalf("failed to write data: %v", err)
+			}
+			opts := SignOptions{Armor: true, DetachSign: tt.detachSign, ClearSign: tt.clearSign}
+			if err := signer.Verify(dataPath, sigPath, opts); err != nil {
+				t.Errorf("signature of stdin does not verify: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateStdinMode(t *testing.T) {
+	stdinFD := 0
+
+	tests := []struct {
+		name        string
+		args        Config
+		errContains string
+	}{
+		{name: "stdin", args: Config{Files: "-", Output: "out.asc"}},
+		{name: "files", args: Config{Files: "*.txt"}},
+		{name: "output without stdin", args: Config{Files: "*.txt", Output: "out.asc"}, errContains: "only supported when signing stdin"},
+		{name: "mixed with patterns", args: Config{Files: "-\n*.txt"}, errContains: "cannot be combined with other file patterns"},
+		{name: "passphrase from stdin", args: Config{Files: "-", PassphraseFile: "-"}, errContains: "both the passphrase and the data"},
+		{name: "passphrase fd 0", args: Config{Files: "-", PassphraseFD: &stdinFD}, errContains: "both the passphrase and the data"},
+		{name: "verification", args: Config{Files: "-", SignAndVerify: true}, errContains: "does not support"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateStdinMode(tt.args)
+			if tt.errContains == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
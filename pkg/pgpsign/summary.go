@@ -0,0 +1,162 @@
+package pgpsign
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Verification outcomes recorded in a SignResult.
+const (
+	VerificationPassed = "passed"
+	VerificationFailed = "failed"
+)
+
+// Signing outcomes recorded in a SignResult.
+const (
+	StatusSigned       = "signed"
+	StatusSkipped      = "skipped"
+	StatusFailed       = "failed"
+	StatusNotAttempted = "not_attempted"
+)
+
+// appendStepSummary appends the run summary to the file named by GITHUB_STEP_SUMMARY.
+// It does nothing if the variable is unset, and failures only produce a warning.
+func appendStepSummary(workDirs []string, results []SignResult, log *slog.Logger) {
+	summaryFile := os.Getenv("GITHUB_STEP_SUMMARY")
+	if summaryFile == "" {
+		return
+	}
+
+	// Paths below a working directory are shown relative to it to keep the table readable.
+	for i := range results {
+		results[i].File = relativeTo(workDirs, results[i].File)
+		results[i].OutputPath = relativeTo(workDirs, results[i].OutputPath)
+	}
+
+	f, err := os.OpenFile(summaryFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		log.Warn("Failed to open step summary", slog.String("error", err.Error()))
+		return
+	}
+	defer f.Close()
+
+	if err := writeStepSummary(f, results); err != nil {
+		log.Warn("Failed to write step summary", slog.String("error", err.Error()))
+	}
+}
+
+// writeStepSummary writes a markdown table of the written signatures to w, followed by the
+// totals and, if signatures were verified, the verification outcome.
+func writeStepSummary(w io.Writer, results []SignResult) error {
+	verified, failed := 0, 0
+	for _, result := range results {
+		switch result.Verification {
+		case VerificationPassed:
+			verified++
+		case VerificationFailed:
+			verified++
+			failed++
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "### PGP Signatures\n\n")
+
+	if len(results) > 0 {
+		header, separator := "| File | Signature | Size | Key ID |", "|------|-----------|------|--------|"
+		if verified > 0 {
+			header, separator = header+" Verified |", separator+"----------|"
+		}
+		fmt.Fprintf(bw, "%s\n%s\n", header, separator)
+
+		for _, result := range results {
+			keyID := "-"
+			if result.KeyID != "" {
+				keyID = markdownCode(result.KeyID)
+			}
+			fmt.Fprintf(bw, "| %s | %s | %s | %s |",
+				markdownCode(result.File), markdownCode(result.OutputPath), formatSize(result.Bytes), keyID)
+			if verified > 0 {
+				fmt.Fprintf(bw, " %s |", verificationMark(result.Verification))
+			}
+			fmt.Fprintln(bw)
+		}
+		fmt.Fprintln(bw)
+	}
+
+	fmt.Fprintf(bw, "**Signatures written:** %d", len(results))
+	if len(results) > 0 {
+		fmt.Fprintf(bw, " (%s signed)", formatSize(signedBytes(results)))
+	}
+	fmt.Fprintln(bw)
+	if verified > 0 {
+		if failed > 0 {
+			fmt.Fprintf(bw, "\n**Verification:** failed for %d of %d\n", failed, verified)
+		} else {
+			fmt.Fprintf(bw, "\n**Verification:** passed (%d of %d)\n", verified, verified)
+		}
+	}
+	fmt.Fprintln(bw)
+
+	return bw.Flush()
+}
+
+// signedBytes returns the total size of the signed files. Files signed with several keys
+// or in several modes are counted once.
+func signedBytes(results []SignResult) int64 {
+	seen := make(map[string]bool)
+	var total int64
+	for _, result := range results {
+		if !seen[result.File] {
+			seen[result.File] = true
+			total += result.Bytes
+		}
+	}
+	return total
+}
+
+// verificationMark renders a verification outcome for the summary table.
+func verificationMark(verification string) string {
+	switch verification {
+	case VerificationPassed:
+		return "✅"
+	case VerificationFailed:
+		return "❌"
+	default:
+		return "-"
+	}
+}
+
+// markdownCode formats s as inline code that is safe inside a table cell.
+func markdownCode(s string) string {
+	return "`" + strings.ReplaceAll(s, "|", `\|`) + "`"
+}
+
+// formatSize formats a byte count using binary units.
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+// relativeTo returns p relative to the first of bases it lies below, and p unchanged otherwise.
+func relativeTo(bases []string, p string) string {
+	rel, ok := relativeToRoot(bases, p)
+	if !ok {
+		return p
+	}
+	return filepath.ToSlash(rel)
+}
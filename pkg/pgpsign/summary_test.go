@@ -0,0 +1,130 @@
+package pgpsign
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteStepSummary(t *testing.T) {
+	tests := []struct {
+		name     string
+		results  []SignResult
+		expected string
+	}{
+		{
+			name: "single file",
+			results: []SignResult{
+				{File: "dist/app.tar.gz", OutputPath: "dist/app.tar.gz.asc", Bytes: 2048, KeyID: "0123456789ABCDEF"},
+			},
+			expected: "### PGP Signatures\n\n" +
+				"| File | Signature | Size | Key ID |\n" +
+				"|------|-----------|------|--------|\n" +
+				"| `dist/app.tar.gz` | `dist/app.tar.gz.asc` | 2.0 KiB | `0123456789ABCDEF` |\n" +
+				"\n" +
+				"**Signatures written:** 1 (2.0 KiB signed)\n\n",
+		},
+		{
+			name: "multiple files with verification",
+			results: []SignResult{
+				{File: "a.bin", OutputPath: "a.bin.sig", Bytes: 10, KeyID: "0123456789ABCDEF", Verification: VerificationPassed},
+				{File: "b.bin", OutputPath: "b.bin.sig", Bytes: 3 << 20, Verification: VerificationFailed},
+			},
+			expected: "### PGP Signatures\n\n" +
+				"| File | Signature | Size | Key ID | Verified |\n" +
+				"|------|-----------|------|--------|----------|\n" +
+				"| `a.bin` | `a.bin.sig` | 10 B | `0123456789ABCDEF` | ✅ |\n" +
+				"| `b.bin` | `b.bin.sig` | 3.0 MiB | - | ❌ |\n" +
+				"\n" +
+				"**Signatures written:** 2 (3.0 MiB signed)\n\n" +
+				"**Verification:** failed for 1 of 2\n\n",
+		},
+		{
+			name: "file signed with two keys",
+			results: []SignResult{
+				{File: "a.bin", OutputPath: "a.bin.1111111111111111.asc", Bytes: 512},
+				{File: "a.bin", OutputPath: "a.bin.2222222222222222.asc", Bytes: 512},
+			},
+			expected: "### PGP Signatures\n\n" +
+				"| File | Signature | Size | Key ID |\n" +
+				"|------|-----------|------|--------|\n" +
+				"| `a.bin` | `a.bin.1111111111111111.asc` | 512 B | - |\n" +
+				"| `a.bin` | `a.bin.2222222222222222.asc` | 512 B | - |\n" +
+				"\n" +
+				"**Signatures written:** 2 (512 B signed)\n\n",
+		},
+		{
+			name:     "no signatures",
+			expected: "### PGP Signatures\n\n**Signatures written:** 0\n\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeStepSummary(&buf, tt.results); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if buf.String() != tt.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tt.expected, buf.String())
+			}
+		})
+	}
+}
+
+func TestRunWritesStepSummary(t *testing.T) {
+	workDir := t.TempDir()
+	summaryPath := filepath.Join(t.TempDir(), "summary.md")
+	t.Setenv("GITHUB_STEP_SUMMARY", summaryPath)
+
+	files := []string{filepath.Join(workDir, "a.txt"), filepath.Join(workDir, "b.txt")}
+	for _, file := range files {
+		if err := os.WriteFile(file, []byte("content"), 0o644); err != nil {
+			t.Fatalf("failed to create test file: %v", err)
+		}
+	}
+
+	args := Config{
+		PrivateKey:    "key",
+		Files:         "*.txt",
+		Armor:         true,
+		DetachSign:    true,
+		WorkDir:       workDir,
+		SignAndVerify: true,
+	}
+	if err := run(context.Background(), args, &MockSigner{}, &MockFileFinder{Files: files}, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(summaryPath)
+	if err != nil {
+		t.Fatalf("expected step summary to be written: %v", err)
+	}
+	for _, want := range []string{
+		"| `a.txt` | `a.txt.asc` | 7 B | - | ✅ |",
+		"| `b.txt` | `b.txt.asc` | 7 B | - | ✅ |",
+		"**Verification:** passed (2 of 2)",
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("expected summary to contain %q, got:\n%s", want, data)
+		}
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := map[int64]string{
+		0:       "0 B",
+		1023:    "1023 B",
+		1024:    "1.0 KiB",
+		1536:    "1.5 KiB",
+		5 << 30: "5.0 GiB",
+	}
+	for size, expected := range tests {
+		if got := formatSize(size); got != expected {
+			t.Errorf("formatSize(%d): expected %q, got %q", size, expected, got)
+		}
+	}
+}
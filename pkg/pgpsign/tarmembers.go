@@ -0,0 +1,217 @@
+package pgpsign
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// runTarMembers signs members of the configured archive instead of files on disk.
+func runTarMembers(ctx context.Context, args Config, keys []signingKey, workDir string, log *slog.Logger) error {
+	if args.Archive == "" {
+		return fmt.Errorf("tar-members mode requires an archive")
+	}
+	if len(keys) != 1 {
+		return fmt.Errorf("tar-members mode supports a single signing key")
+	}
+	signer, opts := keys[0].signer, keys[0].opts
+	if opts.ClearSign {
+		return fmt.Errorf("tar-members mode only supports detached signatures")
+	}
+	if opts.FlatOutput {
+		return fmt.Errorf("tar-members mode mirrors member paths and does not support signature-layout flat")
+	}
+	if args.SignAndVerify || args.VerifyKeyring != "" || args.AssertReproducible || BundleFormat(args.BundleFormat) != BundleNone || args.DryRun || args.SkipExisting || args.FilesFrom != "" {
+		return fmt.Errorf("tar-members mode does not support sign-and-verify, verify-keyring, assert-reproducible, bundle-format, dry-run, skip-existing or files-from")
+	}
+	opts.DetachSign = true
+	// Members are placed below the output directory by memberOutputPath.
+	opts.OutputDir = ""
+
+	archivePath := resolvePath(workDir, args.Archive)
+	outputDir := workDir
+	if args.OutputDir != "" {
+		outputDir = resolvePath(workDir, args.OutputDir)
+	}
+
+	patterns := parseMultilineInput(args.Files)
+	excludes, err := userExcludes(args, workDir)
+	if err != nil {
+		return err
+	}
+	if args.CaseInsensitive {
+		patterns, excludes = foldCasePatterns(patterns), foldCasePatterns(excludes)
+	}
+
+	log.Debug("Signing archive members",
+		slog.String("archive", archivePath),
+		slog.String("output_dir", outputDir),
+		slog.Any("patterns", patterns),
+		slog.Any("excludes", excludes),
+	)
+
+	signatures, err := signTarMembers(ctx, signer, archivePath, outputDir, patterns, excludes, opts, log)
+	if err != nil {
+		return err
+	}
+	args.setSignatureOutputs(signatures)
+
+	if len(signatures) == 0 {
+		log.Warn("No archive members matched the specified patterns")
+		return nil
+	}
+
+	log.Info("Successfully signed all archive members", slog.Int("count", len(signatures)))
+	return nil
+}
+
+// signTarMembers streams every regular member of a tar or tar.gz archive whose name matches
+// the patterns through the signer. Detached signatures are written below outputDir,
+// mirroring the member path. It returns the paths of the written signatures. Once ctx is
+// done no further members are signed.
+func signTarMembers(ctx context.Context, signer Signer, archivePath, outputDir string, patterns, excludes []string, opts SignOptions, log *slog.Logger) ([]string, error) {
+	filePatterns, err := parseFilePatterns(patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer f.Close()
+
+	r, err := openArchiveReader(f)
+	if err != nil {
+		return nil, err
+	}
+
+	tr := tar.NewReader(r)
+
+	var signatures []string
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+
+		if !header.FileInfo().Mode().IsRegular() {
+			continue
+		}
+
+		name := path.Clean(strings.TrimPrefix(header.Name, "./"))
+		if !matchMember(name, filePatterns, excludes) {
+			continue
+		}
+
+		outputPath, err := memberOutputPath(outputDir, name, opts)
+		if err != nil {
+			return nil, err
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("signing cancelled with %d archive members signed: %w", len(signatures), err)
+		}
+
+		log.Info("Signing archive member", slog.String("member", name))
+		if err := signMember(signer, tr, outputPath, opts); err != nil {
+			return nil, fmt.Errorf("failed to sign archive member %s: %w", name, err)
+		}
+		log.Debug("Archive member signed successfully",
+			slog.String("member", name),
+			slog.String("signature", outputPath),
+		)
+
+		signatures = append(signatures, outputPath)
+	}
+
+	return signatures, nil
+}
+
+// openArchiveReader returns a reader for the tar stream, transparently decompressing gzip.
+func openArchiveReader(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(2)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+
+	if len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress archive: %w", err)
+		}
+		return gz, nil
+	}
+
+	return br, nil
+}
+
+// matchMember reports whether an archive member name matches a pattern without being
+// excluded by the global excludes or that pattern's scoped excludes.
+func matchMember(name string, patterns []FilePattern, excludes []string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern.Include, name); matched &&
+			!shouldExclude(filepath.FromSlash(name), ".", pattern.excludesWith(excludes)) {
+			return true
+		}
+	}
+	return false
+}
+
+// memberOutputPath returns where the signature for an archive member is written.
+// Members that would resolve outside outputDir are rejected.
+func memberOutputPath(outputDir, name string, opts SignOptions) (string, error) {
+	localName := filepath.FromSlash(name)
+	if !filepath.IsLocal(localName) {
+		return "", fmt.Errorf("archive member %q escapes the output directory", name)
+	}
+	if err := checkOutputTarget(localName, opts); err != nil {
+		return "", err
+	}
+	return getOutputPath(filepath.Join(outputDir, localName), opts), nil
+}
+
+// signMember writes the detached signature for the member content read from r to outputPath.
+func signMember(signer Signer, r io.Reader, outputPath string, opts SignOptions) error {
+	if err := checkOverwrite(outputPath, opts); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0o755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	out, err := createOutputFile(outputPath, opts.outputMode())
+	if err != nil {
+		return fmt.Errorf("failed to create signature file: %w", err)
+	}
+
+	if err := signer.SignStream(r, out, opts); err != nil {
+		out.Abort()
+		return err
+	}
+
+	if err := out.Commit(); err != nil {
+		return fmt.Errorf("failed to write signature: %w", err)
+	}
+
+	return nil
+}
+
+// resolvePath makes p absolute relative to base unless it already is.
+func resolvePath(base, p string) string {
+	if filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(base, p)
+}
@@ -0,0 +1,160 @@
+package pgpsign
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v3/crypto"
+)
+
+func writeTestArchive(t *testing.T, path string, compress bool, members map[string]string) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	if err := tw.WriteHeader(&tar.Header{Name: "dist/", Typeflag: tar.TypeDir, Mode: 0o755}); err != nil {
+		t.Fatalf("failed to write directory header: %v", err)
+	}
+	for name, content := range members {
+		header := &tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatalf("failed to write header: %v", err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatalf("failed to write member: %v", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("failed to close archive: %v", err)
+	}
+
+	data := buf.Bytes()
+	if compress {
+		var gzBuf bytes.Buffer
+		gw := gzip.NewWriter(&gzBuf)
+		if _, err := gw.Write(data); err != nil {
+			t.Fatalf("failed to compress archive: %v", err)
+		}
+		if err := gw.Close(); err != nil {
+			t.Fatalf("failed to compress archive: %v", err)
+		}
+		data = gzBuf.Bytes()
+	}
+
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("failed to write archive: %v", err)
+	}
+}
+
+func TestSignTarMembers(t *testing.T) {
+	tests := []struct {
+		name     string
+		compress bool
+		opts     SignOptions
+		encoding int8
+	}{
+		{name: "tar with binary signatures", opts: SignOptions{DetachSign: true}, encoding: crypto.Bytes},
+		{name: "tar.gz with armored signatures", compress: true, opts: SignOptions{Armor: true, DetachSign: true}, encoding: crypto.Armor},
+	}
+
+	armoredKey := generateTestKeyArmored(t, "Test", "test@test.com", "")
+	signer, err := NewGoPGPSigner(armoredKey, "", "")
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+	publicKey, err := signer.PublicKey()
+	if err != nil {
+		t.Fatalf("failed to get public key: %v", err)
+	}
+
+	members := map[string]string{
+		"dist/app.bin":    "binary content",
+		"./dist/lib.so":   "library content",
+		"dist/README.md":  "readme",
+		"other/notes.txt": "not matched",
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			archivePath := filepath.Join(tmpDir, "release.tar")
+			writeTestArchive(t, archivePath, tt.compress, members)
+			outputDir := filepath.Join(tmpDir, "sigs")
+
+			signatures, err := signTarMembers(context.Background(), signer, archivePath, outputDir, []string{"dist/*"}, []string{"*.md"}, tt.opts, slog.New(slog.DiscardHandler))
+			if err != nil {
+				t.Fatalf("failed to sign archive members: %v", err)
+			}
+			if len(signatures) != 2 {
+				t.Fatalf("expected 2 signatures, got %d: %v", len(signatures), signatures)
+			}
+
+			verifyHandle, err := crypto.PGP().Verify().VerificationKey(publicKey).New()
+			if err != nil {
+				t.Fatalf("failed to create verification handle: %v", err)
+			}
+
+			for _, name := range []string{"dist/app.bin", "dist/lib.so"} {
+				content := members[name]
+				if content == "" {
+					content = members["./"+name]
+				}
+
+				sigPath := getOutputPath(filepath.Join(outputDir, filepath.FromSlash(name)), tt.opts)
+				sig, err := os.ReadFile(sigPath)
+				if err != nil {
+					t.Fatalf("expected signature for %s: %v", name, err)
+				}
+
+				result, err := verifyHandle.VerifyDetached([]byte(content), sig, tt.encoding)
+				if err != nil {
+					t.Fatalf("failed to verify signature for %s: %v", name, err)
+				}
+				if err := result.SignatureError(); err != nil {
+					t.Errorf("signature for %s is invalid: %v", name, err)
+				}
+			}
+		})
+	}
+}
+
+func TestSignTarMembers_RejectsEscapingMembers(t *testing.T) {
+	tmpDir := t.TempDir()
+	archivePath := filepath.Join(tmpDir, "evil.tar")
+	writeTestArchive(t, archivePath, false, map[string]string{"../escape.txt": "content"})
+
+	mock := &MockSigner{}
+	_, err := signTarMembers(context.Background(), mock, archivePath, filepath.Join(tmpDir, "sigs"), []string{"../*"}, nil, SignOptions{DetachSign: true}, slog.New(slog.DiscardHandler))
+	if err == nil {
+		t.Fatal("expected error for member escaping the output directory")
+	}
+	if _, statErr := os.Stat(filepath.Join(tmpDir, "escape.txt.sig")); statErr == nil {
+		t.Error("signature must not be written outside the output directory")
+	}
+}
+
+func TestRunTarMembers_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		args Config
+	}{
+		{name: "missing archive", args: Config{TarMembers: true, DetachSign: true}},
+		{name: "clear-sign", args: Config{TarMembers: true, Archive: "a.tar", ClearSign: true}},
+		{name: "bundle", args: Config{TarMembers: true, Archive: "a.tar", DetachSign: true, BundleFormat: string(BundleSigstorePGP)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys := []signingKey{{signer: &MockSigner{}, opts: SignOptions{DetachSign: tt.args.DetachSign, ClearSign: tt.args.ClearSign}}}
+			if err := runTarMembers(context.Background(), tt.args, keys, t.TempDir(), slog.New(slog.DiscardHandler)); err == nil {
+				t.Error("expected validation error")
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package pgpsign
+
+import (
+	"fmt"
+	"os"
+)
+
+// checkTempDir makes sure the temp-dir input names a writable directory, so a wrong path
+// fails the run at startup instead of when the first temporary file is needed. An empty
+// dir selects the system temp directory and is not checked.
+//
+// Signatures and other outputs are not written there: they are staged next to their
+// destination, where renaming them into place is atomic.
+func checkTempDir(dir string) error {
+	if dir == "" {
+		return nil
+	}
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("temp-dir %s: %w", dir, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("temp-dir %s is not a directory", dir)
+	}
+
+	f, err := os.CreateTemp(dir, ".pgp-sign-check-*")
+	if err != nil {
+		return fmt.Errorf("temp-dir %s is not writable: %w", dir, err)
+	}
+	f.Close()
+	if err := os.Remove(f.Name()); err != nil {
+		return fmt.Errorf("temp-dir %s: failed to remove check file: %w", dir, err)
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package pgpsign
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckTempDir(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		dir         string
+		errContains string
+	}{
+		{name: "system default", dir: ""},
+		{name: "writable directory", dir: dir},
+		{name: "missing directory", dir: filepath.Join(dir, "missing"), errContains: "no such file or directory"},
+		{name: "file", dir: file, errContains: "is not a directory"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkTempDir(tt.dir)
+			if tt.errContains == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Fatalf("expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("failed to read directory: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected the check to leave no files behind, got %d entries", len(entries))
+	}
+}
+
+func TestCheckTempDir_ReadOnly(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("permissions are not enforced for root")
+	}
+	dir := t.TempDir()
+	if err := os.Chmod(dir, 0o555); err != nil {
+		t.Fatalf("failed to make directory read-only: %v", err)
+	}
+	t.Cleanup(func() { os.Chmod(dir, 0o755) })
+
+	if err := checkTempDir(dir); err == nil || !strings.Contains(err.Error(), "is not writable") {
+		t.Fatalf("expected a not writable error, got %v", err)
+	}
+}
+
+func TestPrepareGnuPGHome_TempDir(t *testing.T) {
+	tempDir := t.TempDir()
+	args := Config{Backend: string(BackendGnuPG), TempDir: tempDir}
+
+	cleanup, err := prepareGnuPGHome(&args, slog.New(slog.DiscardHandler))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer cleanup()
+
+	if filepath.Dir(args.GnuPGHome) != tempDir {
+		t.Errorf("expected gpg home below %s, got %s", tempDir, args.GnuPGHome)
+	}
+}
+
+func TestRunGnuPGTempDir(t *testing.T) {
+	envFile := filepath.Join(t.TempDir(), "env")
+	installFakeGPG(t, `echo "$GNUPGHOME $TMPDIR" >> '`+envFile+`'; cat > /dev/null
+case "$*" in *--list-secret-keys*) echo "sec:u:255:22:0123456789ABCDEF:1700000000::::::scESC:"; exit 0;; esac
+while [ $# -gt 0 ]; do [ "$1" = --output ] && : > "$2"; shift; done`)
+
+	tempDir := t.TempDir()
+	workDir := t.TempDir()
+	writeTestFiles(t, workDir, "app.bin")
+	args := Config{
+		PrivateKey: generateTestKeyArmored(t, "Test", "test@test.com", ""),
+		Backend:    string(BackendGnuPG),
+		Files:      "*.bin",
+		DetachSign: true,
+		WorkDir:    workDir,
+		TempDir:    tempDir,
+	}
+	if err := run(context.Background(), args, nil, nil, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	recorded, err := os.ReadFile(envFile)
+	if err != nil {
+		t.Fatalf("failed to read recorded environment: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(recorded)), "\n")
+	if len(lines) < 2 {
+		t.Fatalf("expected import and sign invocations, got %v", lines)
+	}
+	for _, line := range lines {
+		home, tmp, _ := strings.Cut(line, " ")
+		if filepath.Dir(home) != tempDir || tmp != tempDir {
+			t.Errorf("expected gpg home and TMPDIR below %s, got %q", tempDir, line)
+		}
+	}
+
+	// The temporary gpg home is removed after the run.
+	entries, err := os.ReadDir(tempDir)
+	if err != nil {
+		t.Fatalf("failed to read temp dir: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected temp dir to be empty after the run, got %d entries", len(entries))
+	}
+}
+
+func TestRunRejectsMissingTempDir(t *testing.T) {
+	args := Config{PrivateKey: "key", Files: "*", TempDir: filepath.Join(t.TempDir(), "missing")}
+	if err := run(context.Background(), args, &MockSigner{}, &MockFileFinder{}, nil); err == nil || !strings.Contains(err.Error(), "temp-dir") {
+		t.Fatalf("expected temp-dir error, got %v", err)
+	}
+}
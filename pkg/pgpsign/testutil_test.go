@@ -0,0 +1,149 @@
+package pgpsign
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/gopenpgp/v3/crypto"
+)
+
+// run signs with args like the CLI does, using signer, finder and log in place of the ones
+// args sets.
+func run(ctx context.Context, args Config, signer Signer, finder FileFinder, log *slog.Logger) error {
+	args.Signer, args.Finder, args.Logger = signer, finder, log
+	args.GitHubActions = true
+	_, err := Sign(ctx, args)
+	return err
+}
+
+// generateTestKeyArmored creates a test PGP key and returns its armored form.
+func generateTestKeyArmored(t *testing.T, name, email, passphrase string) string {
+	t.Helper()
+
+	pgp := crypto.PGP()
+	keyGenHandle := pgp.KeyGeneration().AddUserId(name, email).New()
+
+	key, err := keyGenHandle.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate test key: %v", err)
+	}
+
+	// If passphrase is provided, lock the key
+	if passphrase != "" {
+		lockedKey, err := pgp.LockKey(key, []byte(passphrase))
+		if err != nil {
+			t.Fatalf("failed to lock key with passphrase: %v", err)
+		}
+		key = lockedKey
+	}
+
+	armored, err := key.Armor()
+	if err != nil {
+		t.Fatalf("failed to armor key: %v", err)
+	}
+
+	return armored
+}
+
+// generateTestKeyWithLifetime creates an unencrypted test key created at created that expires after lifetime.
+func generateTestKeyWithLifetime(t *testing.T, created time.Time, lifetime time.Duration) string {
+	t.Helper()
+
+	key, err := crypto.PGP().KeyGeneration().
+		AddUserId("Expiring", "expiring@test.com").
+		GenerationTime(created.Unix()).
+		Lifetime(int32(lifetime.Seconds())).
+		New().
+		GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate test key: %v", err)
+	}
+
+	armored, err := key.Armor()
+	if err != nil {
+		t.Fatalf("failed to armor key: %v", err)
+	}
+
+	return armored
+}
+
+// MockSigner implements Signer for testing. It is safe for concurrent use.
+type MockSigner struct {
+	mu sync.Mutex
+
+	SignedFiles   []string
+	SignedData    [][]byte
+	SignedOpts    []SignOptions
+	VerifiedFiles []string
+	Err           error
+	VerifyErr     error
+}
+
+func (m *MockSigner) SignFile(filePath string, opts SignOptions) (SignResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.Err != nil {
+		return SignResult{}, m.Err
+	}
+	m.SignedFiles = append(m.SignedFiles, filePath)
+	m.SignedOpts = append(m.SignedOpts, opts)
+	return newSignResult(filePath, getOutputPath(filePath, opts), nil, opts.DigestAlgorithm), nil
+}
+
+func (m *MockSigner) SignStream(r io.Reader, w io.Writer, opts SignOptions) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.Err != nil {
+		return m.Err
+	}
+	if _, err := io.Copy(io.Discard, r); err != nil {
+		return err
+	}
+	m.SignedOpts = append(m.SignedOpts, opts)
+	_, err := io.WriteString(w, "signature")
+	return err
+}
+
+func (m *MockSigner) SignBytes(data []byte, opts SignOptions) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	m.SignedData = append(m.SignedData, data)
+	m.SignedOpts = append(m.SignedOpts, opts)
+	return []byte("signature"), nil
+}
+
+func (m *MockSigner) Verify(filePath, sigPath string, opts SignOptions) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.VerifyErr != nil {
+		return m.VerifyErr
+	}
+	m.VerifiedFiles = append(m.VerifiedFiles, filePath)
+	return nil
+}
+
+// MockFileFinder implements FileFinder for testing.
+type MockFileFinder struct {
+	Files       []string
+	Err         error
+	LastWorkDir string
+}
+
+func (m *MockFileFinder) FindFiles(workDir string, patterns []string, excludes []string) ([]string, error) {
+	m.LastWorkDir = workDir
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	return m.Files, nil
+}
@@ -0,0 +1,63 @@
+package pgpsign
+
+import (
+	"bytes"
+	"io"
+)
+
+// clearSignText returns data as it is clear-signed with opts: BOM-free UTF-8 and, with
+// TextMode, with every CRLF line ending converted to LF so the text uses one line ending
+// throughout. The signature is the same either way, as OpenPGP hashes text with CRLF.
+func clearSignText(data []byte, opts SignOptions) ([]byte, error) {
+	data, err := normalizeCleartext(data, opts.CleartextEncoding)
+	if err != nil {
+		return nil, err
+	}
+	if opts.TextMode {
+		data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	}
+	return data, nil
+}
+
+// signedCleartext returns the text a clear signature of data covers, in the form the
+// clearsign decoder returns it: LF line endings, trailing spaces and tabs removed from
+// each line and no final line ending. Comparing this form of the file with the decoded
+// text of a clear signature tells whether the signature was made over the file.
+func signedCleartext(data []byte) []byte {
+	lines := bytes.Split(bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n")), []byte("\n"))
+	for i, line := range lines {
+		lines[i] = bytes.TrimRight(line, " \t")
+	}
+	return bytes.TrimSuffix(bytes.Join(lines, []byte("\n")), []byte("\n"))
+}
+
+// crlfText returns data with LF line endings converted to CRLF, as crlfWriter writes it.
+func crlfText(data []byte) []byte {
+	var buf bytes.Buffer
+	_, _ = (&crlfWriter{w: &buf}).Write(data)
+	return buf.Bytes()
+}
+
+// crlfWriter writes everything written to it to w with LF line endings converted to
+// CRLF, the canonical line ending OpenPGP text mode stores text with. Existing CRLF line
+// endings are kept, so text with mixed line endings comes out consistent.
+type crlfWriter struct {
+	w      io.Writer
+	lastCR bool // The previous byte written was a CR
+}
+
+// Write converts the line endings in p and writes the result to the underlying writer.
+func (c *crlfWriter) Write(p []byte) (int, error) {
+	out := make([]byte, 0, len(p)+len(p)/16)
+	for _, b := range p {
+		if b == '\n' && !c.lastCR {
+			out = append(out, '\r')
+		}
+		out = append(out, b)
+		c.lastCR = b == '\r'
+	}
+	if _, err := c.w.Write(out); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,123 @@
+package pgpsign
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+)
+
+func TestCRLFWriter(t *testing.T) {
+	tests := []struct {
+		name     string
+		chunks   []string
+		expected string
+	}{
+		{name: "lf", chunks: []string{"a\nb\n"}, expected: "a\r\nb\r\n"},
+		{name: "crlf kept", chunks: []string{"a\r\nb\r\n"}, expected: "a\r\nb\r\n"},
+		{name: "mixed", chunks: []string{"a\r\nb\nc"}, expected: "a\r\nb\r\nc"},
+		{name: "crlf split across writes", chunks: []string{"a\r", "\nb\n"}, expected: "a\r\nb\r\n"},
+		{name: "lone cr kept", chunks: []string{"a\rb\n"}, expected: "a\rb\r\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w := &crlfWriter{w: &buf}
+			for _, chunk := range tt.chunks {
+				if n, err := w.Write([]byte(chunk)); err != nil || n != len(chunk) {
+					t.Fatalf("Write(%q) = %d, %v", chunk, n, err)
+				}
+			}
+			if buf.String() != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, buf.String())
+			}
+		})
+	}
+}
+
+func TestClearSignText(t *testing.T) {
+	mixed := []byte("one\r\ntwo\nthree\r\n")
+
+	unchanged, err := clearSignText(mixed, SignOptions{})
+	if err != nil || !bytes.Equal(unchanged, mixed) {
+		t.Errorf("expected text unchanged without text mode, got %q, %v", unchanged, err)
+	}
+
+	normalized, err := clearSignText(mixed, SignOptions{TextMode: true})
+	if err != nil || string(normalized) != "one\ntwo\nthree\n" {
+		t.Errorf("expected LF line endings in text mode, got %q, %v", normalized, err)
+	}
+}
+
+func TestGoPGPSigner_TextMode(t *testing.T) {
+	signer, err := NewGoPGPSigner(generateTestKeyArmored(t, "Test", "test@test.com", ""), "", "")
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+
+	testFile := filepath.Join(t.TempDir(), "NOTES.txt")
+	if err := os.WriteFile(testFile, []byte("Release notes\r\n\nFixed things\nWindows line\r\n"), 0o644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	t.Run("clear-sign", func(t *testing.T) {
+		opts := SignOptions{ClearSign: true, TextMode: true}
+		if _, err := signer.SignFile(testFile, opts); err != nil {
+			t.Fatalf("failed to sign file: %v", err)
+		}
+		outputPath := getOutputPath(testFile, opts)
+		signed, err := os.ReadFile(outputPath)
+		if err != nil {
+			t.Fatalf("failed to read clear-signed file: %v", err)
+		}
+		if bytes.Contains(signed, []byte("\r")) {
+			t.Errorf("expected only LF line endings in clear-signed file, got %q", signed)
+		}
+		if err := signer.Verify(testFile, outputPath, opts); err != nil {
+			t.Errorf("failed to verify with LF line endings: %v", err)
+		}
+
+		// A checkout with CRLF line endings must verify as well.
+		crlfPath := filepath.Join(t.TempDir(), "NOTES.txt.asc")
+		if err := os.WriteFile(crlfPath, crlfText(signed), 0o644); err != nil {
+			t.Fatalf("failed to write CRLF copy: %v", err)
+		}
+		if err := signer.Verify(testFile, crlfPath, opts); err != nil {
+			t.Errorf("failed to verify with CRLF line endings: %v", err)
+		}
+	})
+
+	t.Run("inline", func(t *testing.T) {
+		opts := SignOptions{TextMode: true}
+		if _, err := signer.SignFile(testFile, opts); err != nil {
+			t.Fatalf("failed to sign file: %v", err)
+		}
+		outputPath := getOutputPath(testFile, opts)
+
+		literal := readLiteralData(t, outputPath)
+		if literal.Format != 'u' {
+			t.Errorf("expected UTF-8 text literal data, got format %q", literal.Format)
+		}
+		if sig := readSignaturePacket(t, outputPath); sig.SigType != packet.SigTypeText {
+			t.Errorf("expected a text signature, got type %d", sig.SigType)
+		}
+		if err := signer.Verify(testFile, outputPath, opts); err != nil {
+			t.Errorf("failed to verify: %v", err)
+		}
+	})
+}
+
+func TestGnuPGSigner_BuildArgsTextMode(t *testing.T) {
+	signer := &GnuPGSigner{}
+
+	if args := strings.Join(signer.buildArgs(SignOptions{TextMode: true}, 0), " "); !strings.Contains(args, "--textmode --sign") {
+		t.Errorf("expected --textmode for inline signatures, got %s", args)
+	}
+	if args := strings.Join(signer.buildArgs(SignOptions{TextMode: true, DetachSign: true}, 0), " "); strings.Contains(args, "--textmode") {
+		t.Errorf("expected no --textmode for detached signatures, got %s", args)
+	}
+}
@@ -0,0 +1,34 @@
+package pgpsign
+
+import (
+	"log/slog"
+	"time"
+)
+
+// throughput returns the rate of processing size bytes in elapsed, in bytes per second.
+// It is zero if no time was measured.
+func throughput(size int64, elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(size) / elapsed.Seconds()
+}
+
+// logSigningTime logs at debug level how long signing files took in total, with the
+// throughput over the signatures written, and how long the run has taken since startTime.
+func (fs *fileSigner) logSigningTime(files []string, elapsed time.Duration) {
+	var size int64
+	signed := 0
+	for _, result := range fs.allResults(files) {
+		if result.Status == StatusSigned {
+			size += result.Bytes
+			signed++
+		}
+	}
+	fs.log.Debug("Signing finished",
+		slog.Int("signatures", signed),
+		slog.Duration("duration", elapsed),
+		slog.Float64("bytes_per_second", throughput(size, elapsed)),
+		slog.Duration("run_duration", time.Since(startTime)),
+	)
+}
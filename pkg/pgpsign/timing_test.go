@@ -0,0 +1,53 @@
+package pgpsign
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestThroughput(t *testing.T) {
+	if got := throughput(1000, 2*time.Second); got != 500 {
+		t.Errorf("expected 500 bytes per second, got %v", got)
+	}
+	if got := throughput(1000, 0); got != 0 {
+		t.Errorf("expected 0 without a measured duration, got %v", got)
+	}
+}
+
+func TestRunLogsSigningTime(t *testing.T) {
+	workDir := t.TempDir()
+	writeTestFiles(t, workDir, "a.bin", "b.bin")
+
+	var logs bytes.Buffer
+	log := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	args := Config{
+		PrivateKey: "key",
+		Files:      "*.bin",
+		DetachSign: true,
+		WorkDir:    workDir,
+	}
+	if err := run(context.Background(), args, &MockSigner{}, nil, log); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, name := range []string{"a.bin", "b.bin"} {
+		file := filepath.Join(workDir, name)
+		found := false
+		for line := range strings.Lines(logs.String()) {
+			if strings.Contains(line, "File signed successfully") && strings.Contains(line, "file="+file+" ") {
+				found = strings.Contains(line, " duration=") && strings.Contains(line, " bytes_per_second=")
+			}
+		}
+		if !found {
+			t.Errorf("expected a duration and throughput for %s, got:\n%s", name, logs.String())
+		}
+	}
+	if !strings.Contains(logs.String(), `msg="Signing finished" signatures=2 duration=`) {
+		t.Errorf("expected the total signing time, got:\n%s", logs.String())
+	}
+}
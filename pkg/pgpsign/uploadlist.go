@@ -0,0 +1,66 @@
+package pgpsign
+
+import (
+	"bufio"
+	"fmt"
+	"path/filepath"
+)
+
+// writeUploadList writes the paths of all written signatures, one per line, to listPath.
+// The file is meant for the path input of actions/upload-artifact, which avoids the
+// size limits of step outputs for large releases. Bundles and sidecar header files are
+// listed after their signature, and source files are listed before it when includeSources is set.
+func writeUploadList(listPath string, files []string, keys []signingKey, includeSources bool) error {
+	f, err := createOutputFile(listPath, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to create upload list: %w", err)
+	}
+
+	w := bufio.NewWriter(f)
+	for _, file := range files {
+		var paths []string
+		if includeSources {
+			paths = append(paths, file)
+		}
+		paths = append(paths, signatureFiles(file, keys)...)
+
+		for _, p := range paths {
+			absPath, err := filepath.Abs(p)
+			if err != nil {
+				f.Abort()
+				return fmt.Errorf("failed to resolve path %s: %w", p, err)
+			}
+			if _, err := fmt.Fprintln(w, absPath); err != nil {
+				f.Abort()
+				return fmt.Errorf("failed to write upload list: %w", err)
+			}
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		f.Abort()
+		return fmt.Errorf("failed to write upload list: %w", err)
+	}
+	if err := f.Commit(); err != nil {
+		return fmt.Errorf("failed to write upload list: %w", err)
+	}
+
+	return nil
+}
+
+// signatureFiles returns the paths of the files written for file with every key: each
+// signature, followed by its bundle and sidecar header file if they are written.
+func signatureFiles(file string, keys []signingKey) []string {
+	var paths []string
+	for _, key := range keys {
+		sigPath := getOutputPath(file, key.opts)
+		paths = append(paths, sigPath)
+		if key.bundles != nil {
+			paths = append(paths, key.bundles.Path(file, key.opts))
+		}
+		if metaPath := sidecarMetaPath(sigPath, key.opts); metaPath != "" {
+			paths = append(paths, metaPath)
+		}
+	}
+	return paths
+}
@@ -0,0 +1,87 @@
+package pgpsign
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteUploadList(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := []string{filepath.Join(tmpDir, "a.tar.gz"), filepath.Join(tmpDir, "b.zip")}
+	bundles := &bundleWriter{format: BundleSigstorePGP}
+
+	tests := []struct {
+		name           string
+		opts           SignOptions
+		bundles        *bundleWriter
+		includeSources bool
+		expected       []string
+	}{
+		{
+			name:     "signatures only",
+			opts:     SignOptions{Armor: true, DetachSign: true},
+			expected: []string{files[0] + ".asc", files[1] + ".asc"},
+		},
+		{
+			name:           "with sources",
+			opts:           SignOptions{DetachSign: true},
+			includeSources: true,
+			expected:       []string{files[0], files[0] + ".sig", files[1], files[1] + ".sig"},
+		},
+		{
+			name:     "with bundles",
+			opts:     SignOptions{Armor: true, DetachSign: true},
+			bundles:  bundles,
+			expected: []string{files[0] + ".asc", files[0] + ".sigstore.json", files[1] + ".asc", files[1] + ".sigstore.json"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listPath := filepath.Join(t.TempDir(), "upload.txt")
+			keys := []signingKey{{opts: tt.opts, bundles: tt.bundles}}
+			if err := writeUploadList(listPath, files, keys, tt.includeSources); err != nil {
+				t.Fatalf("failed to write upload list: %v", err)
+			}
+
+			data, err := os.ReadFile(listPath)
+			if err != nil {
+				t.Fatalf("failed to read upload list: %v", err)
+			}
+
+			expected := strings.Join(tt.expected, "\n") + "\n"
+			if string(data) != expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", expected, data)
+			}
+		})
+	}
+}
+
+func TestWriteUploadList_ManyEntries(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := make([]string, 5000)
+	for i := range files {
+		files[i] = filepath.Join(tmpDir, fmt.Sprintf("artifact-%04d.bin", i))
+	}
+
+	listPath := filepath.Join(tmpDir, "upload.txt")
+	if err := writeUploadList(listPath, files, []signingKey{{opts: SignOptions{DetachSign: true}}}, true); err != nil {
+		t.Fatalf("failed to write upload list: %v", err)
+	}
+
+	data, err := os.ReadFile(listPath)
+	if err != nil {
+		t.Fatalf("failed to read upload list: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 2*len(files) {
+		t.Fatalf("expected %d entries, got %d", 2*len(files), len(lines))
+	}
+	if lines[len(lines)-1] != files[len(files)-1]+".sig" {
+		t.Errorf("unexpected last entry: %s", lines[len(lines)-1])
+	}
+}
@@ -0,0 +1,42 @@
+package pgpsign
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+// verifySignatures runs an independent verification pass over the signatures written for files.
+// Every file is checked and reported; the files whose signature fails to verify are returned
+// together with an error describing the failures.
+func verifySignatures(verifier Verifier, files []string, opts SignOptions, log *slog.Logger) ([]string, error) {
+	log.Info("Verifying signatures", slog.Int("count", len(files)))
+
+	var failed []string
+	var errs []error
+	for _, file := range files {
+		sigPath := getOutputPath(file, opts)
+		if err := verifier.Verify(file, sigPath, opts); err != nil {
+			log.Error("Signature verification failed",
+				slog.String("file", file),
+				slog.String("signature", sigPath),
+				slog.String("error", err.Error()),
+			)
+			failed = append(failed, file)
+			errs = append(errs, fmt.Errorf("%s: %w", file, err))
+			continue
+		}
+		log.Info("Signature verified", slog.String("file", file), slog.String("signature", sigPath))
+	}
+
+	log.Info("Verification report",
+		slog.Int("verified", len(files)-len(errs)),
+		slog.Int("failed", len(errs)),
+	)
+
+	if len(errs) > 0 {
+		return failed, fmt.Errorf("verification failed for %d of %d files: %w", len(errs), len(files), errors.Join(errs...))
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,199 @@
+package pgpsign
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/ProtonMail/gopenpgp/v3/crypto"
+)
+
+// RunMode selects whether the action signs the matched files or verifies their signatures.
+type RunMode string
+
+const (
+	// RunModeSign signs the matched files.
+	RunModeSign RunMode = "sign"
+	// RunModeVerify verifies the existing detached signatures of the matched files.
+	RunModeVerify RunMode = "verify"
+)
+
+// parseRunMode parses the mode input. An empty value selects RunModeSign.
+func parseRunMode(value string) (RunMode, error) {
+	switch mode := RunMode(strings.ToLower(strings.TrimSpace(value))); mode {
+	case "":
+		return RunModeSign, nil
+	case RunModeSign, RunModeVerify:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown mode %q, expected sign or verify", value)
+	}
+}
+
+// isVerifyMode reports whether the run verifies existing signatures instead of signing.
+func isVerifyMode(args Config) bool {
+	mode, err := parseRunMode(args.Mode)
+	return err == nil && mode == RunModeVerify
+}
+
+// validateVerifyMode checks the mode input and rejects verify mode together with inputs
+// that only make sense when signing.
+func validateVerifyMode(args Config) error {
+	mode, err := parseRunMode(args.Mode)
+	if err != nil {
+		return err
+	}
+	if mode != RunModeVerify {
+		if args.PublicKey != "" {
+			return fmt.Errorf("public-key is only used with mode verify")
+		}
+		return nil
+	}
+	if inputMode(args) != nil {
+		return fmt.Errorf("mode verify cannot be combined with tar-members, image-digests or signing stdin")
+	}
+	if args.ListOnly || args.DryRun {
+		return fmt.Errorf("mode verify cannot be combined with list-only or dry-run")
+	}
+	if format, err := parseSignatureFormat(args.Format); err == nil && format != FormatPGP {
+		return fmt.Errorf("mode verify only supports pgp signatures")
+	}
+	if strings.TrimSpace(args.PublicKey) == "" && strings.TrimSpace(args.PrivateKey) == "" {
+		return fmt.Errorf("mode verify requires public-key or private-key")
+	}
+	return nil
+}
+
+// runVerify checks that every matched file has a detached signature that verifies against
+// the public key, and fails the run if any signature is missing or invalid. Nothing is signed.
+func runVerify(ctx context.Context, args Config, finder FileFinder, workDirs []string, opts SignOptions, log *slog.Logger) error {
+	verifier, err := newVerifyModeVerifier(args)
+	if err != nil {
+		return err
+	}
+	opts.DetachSign, opts.ClearSign = true, false
+
+	files, err := matchFiles(ctx, args, finder, workDirs, opts, log)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		log.Warn("No files matched the specified patterns")
+		return nil
+	}
+
+	return verifyExistingSignatures(verifier, files, opts, log)
+}
+
+// newVerifyModeVerifier returns a verifier trusting the public-key input, or the public
+// part of the private-key input if no public key is given.
+func newVerifyModeVerifier(args Config) (*KeyringVerifier, error) {
+	if strings.TrimSpace(args.PublicKey) != "" {
+		publicKey, err := resolveKeyMaterial(args.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		return NewKeyringVerifier(publicKey)
+	}
+
+	privateKey, err := resolveKeyMaterial(args.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	if privateKey, err = decodeKeyMaterial(privateKey, KeyEncoding(args.KeyEncoding)); err != nil {
+		return nil, err
+	}
+
+	keyRing, err := crypto.NewKeyRing(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create key ring: %w", err)
+	}
+	for _, armored := range splitArmoredKeys(privateKey) {
+		key, err := crypto.NewKeyFromArmored(armored)
+		if err != nil {
+			return nil, notPrivateKeyError(armored, err)
+		}
+		publicKey, err := key.ToPublic()
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive public key: %w", err)
+		}
+		if err := keyRing.AddKey(publicKey); err != nil {
+			return nil, fmt.Errorf("failed to add key %s to key ring: %w", publicKey.GetFingerprint(), err)
+		}
+	}
+	return &KeyringVerifier{keyRing: keyRing}, nil
+}
+
+// verifyExistingSignatures verifies the detached signature of every file, reporting each
+// file as verified, failed or missing its signature. All files are checked before an error
+// listing the failures is returned.
+func verifyExistingSignatures(verifier Verifier, files []string, opts SignOptions, log *slog.Logger) error {
+	log.Info("Verifying existing signatures", slog.Int("count", len(files)))
+
+	var errs []error
+	missing := 0
+	for _, file := range files {
+		sigPath, ok := findSignature(file, opts)
+		if !ok {
+			log.Error("Signature not found", slog.String("file", file), slog.Any("candidates", signatureCandidates(file, opts)))
+			missing++
+			errs = append(errs, fmt.Errorf("%s: no signature found", file))
+			continue
+		}
+
+		sigOpts := opts
+		sigOpts.Armor = isArmoredSignature(sigPath)
+		if err := verifier.Verify(file, sigPath, sigOpts); err != nil {
+			log.Error("Signature verification failed",
+				slog.String("file", file),
+				slog.String("signature", sigPath),
+				slog.String("error", err.Error()),
+			)
+			errs = append(errs, fmt.Errorf("%s: %w", file, err))
+			continue
+		}
+		log.Info("Signature verified", slog.String("file", file), slog.String("signature", sigPath))
+	}
+
+	log.Info("Verification report",
+		slog.Int("verified", len(files)-len(errs)),
+		slog.Int("failed", len(errs)-missing),
+		slog.Int("missing", missing),
+	)
+
+	if len(errs) > 0 {
+		return fmt.Errorf("verification failed for %d of %d files: %w", len(errs), len(files), errors.Join(errs...))
+	}
+	return nil
+}
+
+// signatureCandidates returns the paths where the detached signature of file may be:
+// the armored name, then the binary one. They are the same with signature-suffix.
+func signatureCandidates(file string, opts SignOptions) []string {
+	armored, binary := opts, opts
+	armored.Armor, binary.Armor = true, false
+	return slices.Compact([]string{getOutputPath(file, armored), getOutputPath(file, binary)})
+}
+
+// findSignature returns the first signature candidate of file that exists.
+func findSignature(file string, opts SignOptions) (string, bool) {
+	for _, candidate := range signatureCandidates(file, opts) {
+		if info, err := os.Stat(candidate); err == nil && info.Mode().IsRegular() {
+			return candidate, true
+		}
+	}
+	return "", false
+}
+
+// isArmoredSignature reports whether the signature file at path is ASCII armored, with or
+// without a sidecar header before the armor. Unreadable files are treated as binary;
+// verifying them reports the read error.
+func isArmoredSignature(path string) bool {
+	data, err := os.ReadFile(path)
+	return err == nil && bytes.HasPrefix(bytes.TrimSpace(skipSidecarHeader(bytes.TrimSpace(data))), []byte(armorHeader))
+}
@@ -0,0 +1,188 @@
+package pgpsign
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeVerifyModeTree signs app.bin with an armored and lib.bin with a binary detached
+// signature, gives bad.bin the signature of other content and leaves missing.bin unsigned.
+func writeVerifyModeTree(t *testing.T, privateKey string) string {
+	t.Helper()
+	dir := t.TempDir()
+	writeTestFiles(t, dir, "app.bin", "lib.bin", "bad.bin", "missing.bin")
+
+	signer, err := NewGoPGPSigner(privateKey, "", "")
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+	for name, armor := range map[string]bool{"app.bin": true, "lib.bin": false, "bad.bin": true} {
+		if _, err := signer.SignFile(filepath.Join(dir, name), SignOptions{Armor: armor, DetachSign: true}); err != nil {
+			t.Fatalf("failed to sign %s: %v", name, err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "bad.bin"), []byte("tampered"), 0o644); err != nil {
+		t.Fatalf("failed to tamper with file: %v", err)
+	}
+	return dir
+}
+
+func TestParseRunMode(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected RunMode
+		wantErr  bool
+	}{
+		{value: "", expected: RunModeSign},
+		{value: "sign", expected: RunModeSign},
+		{value: " Verify ", expected: RunModeVerify},
+		{value: "check", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			mode, err := parseRunMode(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error for %q", tt.value)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if mode != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, mode)
+			}
+		})
+	}
+}
+
+func TestValidateVerifyMode(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        Config
+		errContains string
+	}{
+		{name: "sign", args: Config{Mode: "sign"}},
+		{name: "verify with public key", args: Config{Mode: "verify", PublicKey: "key"}},
+		{name: "verify with private key", args: Config{Mode: "verify", PrivateKey: "key"}},
+		{name: "verify without key", args: Config{Mode: "verify"}, errContains: "requires public-key or private-key"},
+		{name: "public key when signing", args: Config{Mode: "sign", PublicKey: "key"}, errContains: "only used with mode verify"},
+		{name: "unknown mode", args: Config{Mode: "check"}, errContains: "unknown mode"},
+		{name: "dry-run", args: Config{Mode: "verify", PublicKey: "key", DryRun: true}, errContains: "cannot be combined with list-only or dry-run"},
+		{name: "tar-members", args: Config{Mode: "verify", PublicKey: "key", TarMembers: true}, errContains: "cannot be combined with tar-members"},
+		{name: "minisign", args: Config{Mode: "verify", PublicKey: "key", Format: "minisign"}, errContains: "only supports pgp signatures"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateVerifyMode(tt.args)
+			if tt.errContains == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
+
+func TestVerifyExistingSignatures(t *testing.T) {
+	privateKey := generateTestKeyArmored(t, "Release", "release@test.com", "")
+	dir := writeVerifyModeTree(t, privateKey)
+	verifier, err := NewKeyringVerifier(exportTestPublicKey(t, privateKey))
+	if err != nil {
+		t.Fatalf("failed to create verifier: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		files       []string
+		errContains []string
+	}{
+		{name: "armored and binary signatures", files: []string{"app.bin", "lib.bin"}},
+		{name: "invalid signature", files: []string{"app.bin", "bad.bin"}, errContains: []string{"1 of 2 files", "bad.bin"}},
+		{name: "missing signature", files: []string{"missing.bin"}, errContains: []string{"1 of 1 files", "missing.bin: no signature found"}},
+		{
+			name:        "all files are checked",
+			files:       []string{"app.bin", "lib.bin", "bad.bin", "missing.bin"},
+			errContains: []string{"2 of 4 files", "bad.bin", "missing.bin"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var files []string
+			for _, name := range tt.files {
+				files = append(files, filepath.Join(dir, name))
+			}
+
+			err := verifyExistingSignatures(verifier, files, SignOptions{DetachSign: true}, slog.New(slog.DiscardHandler))
+			if len(tt.errContains) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			for _, want := range tt.errContains {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("expected error containing %q, got %v", want, err)
+				}
+			}
+		})
+	}
+}
+
+func TestRunVerifyMode(t *testing.T) {
+	privateKey := generateTestKeyArmored(t, "Release", "release@test.com", "")
+	otherKey := generateTestKeyArmored(t, "Other", "other@test.com", "")
+	dir := writeVerifyModeTree(t, privateKey)
+
+	tests := []struct {
+		name        string
+		args        Config
+		errContains string
+	}{
+		{name: "public key", args: Config{PublicKey: exportTestPublicKey(t, privateKey), Files: "app.bin\nlib.bin"}},
+		{name: "derived from private key", args: Config{PrivateKey: privateKey, Files: "app.bin\nlib.bin"}},
+		{name: "wrong public key", args: Config{PublicKey: exportTestPublicKey(t, otherKey), Files: "app.bin"}, errContains: "1 of 1 files"},
+		{name: "invalid and missing signatures", args: Config{PrivateKey: privateKey, Files: "*.bin"}, errContains: "2 of 4 files"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.args.Mode = string(RunModeVerify)
+			tt.args.Backend = string(BackendGoPGP)
+			tt.args.WorkDir = dir
+
+			err := run(context.Background(), tt.args, nil, &DefaultFileFinder{}, nil)
+			if tt.errContains == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Fatalf("expected error containing %q, got %v", tt.errContains, err)
+			}
+
+			// Verifying never writes signatures.
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatalf("failed to read directory: %v", err)
+			}
+			if len(entries) != 7 {
+				t.Errorf("expected the 4 files and 3 signatures only, got %d entries", len(entries))
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package pgpsign
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// resolveWorkDirs returns the working directories listed in the newline separated workdir
+// input, or the single directory from resolveWorkDir if none are listed. The first one is
+// the primary working directory that single paths such as report-file are resolved against.
+func resolveWorkDirs(input string, githubActions bool) ([]string, error) {
+	workDirs := parseMultilineInput(input)
+	if len(workDirs) > 0 {
+		return workDirs, nil
+	}
+
+	workDir, err := resolveWorkDir(githubActions)
+	if err != nil {
+		return nil, err
+	}
+	return []string{workDir}, nil
+}
+
+// globRoots returns the directories the files patterns are matched in: the glob-base input
+// if it is set, otherwise the working directories. Glob-base takes precedence over every
+// working directory for matching only; signature paths, output-dir mirroring and outputs
+// are still computed relative to the working directories, and other relative inputs such
+// as files-from are still resolved against the primary one.
+func globRoots(args Config, workDirs []string) []string {
+	if args.GlobBase != "" {
+		return []string{args.GlobBase}
+	}
+	return workDirs
+}
+
+// checkGlobBase makes sure the glob-base input names a directory. Like workdir, a relative
+// glob-base is resolved against the current directory, not against the working directory.
+func checkGlobBase(dir string) error {
+	if dir == "" {
+		return nil
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("glob-base %s: %w", dir, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("glob-base %s is not a directory", dir)
+	}
+	return nil
+}
+
+// findFilesInRoots runs finder in each working directory and merges the matches in order.
+// A file found below several working directories, compared by absolute path, is returned once.
+func findFilesInRoots(finder FileFinder, workDirs, patterns, excludes []string) ([]string, error) {
+	var files []string
+	seen := make(map[string]bool)
+
+	for _, workDir := range workDirs {
+		matches, err := finder.FindFiles(workDir, patterns, excludes)
+		if err != nil {
+			return nil, fmt.Errorf("in %s: %w", workDir, err)
+		}
+		for _, file := range matches {
+			key, err := filepath.Abs(file)
+			if err != nil {
+				key = filepath.Clean(file)
+			}
+			if !seen[key] {
+				seen[key] = true
+				files = append(files, file)
+			}
+		}
+	}
+
+	return files, nil
+}
+
+// relativeToRoot returns p relative to the first of workDirs it is below.
+func relativeToRoot(workDirs []string, p string) (string, bool) {
+	for _, workDir := range workDirs {
+		rel, err := filepath.Rel(workDir, p)
+		if err == nil && filepath.IsLocal(rel) {
+			return rel, true
+		}
+	}
+	return "", false
+}
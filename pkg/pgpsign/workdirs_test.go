@@ -0,0 +1,238 @@
+package pgpsign
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// writeTestFiles creates the files below dir, including their parent directories.
+func writeTestFiles(t testing.TB, dir string, files ...string) {
+	t.Helper()
+	for _, file := range files {
+		path := filepath.Join(dir, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(file), 0o644); err != nil {
+			t.Fatalf("failed to create file: %v", err)
+		}
+	}
+}
+
+func TestResolveWorkDirs(t *testing.T) {
+	t.Setenv("GITHUB_WORKSPACE", "/workspace")
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get working directory: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		input         string
+		githubActions bool
+		want          []string
+	}{
+		{name: "empty falls back to GITHUB_WORKSPACE", input: "", githubActions: true, want: []string{"/workspace"}},
+		{name: "blank lines only", input: "\n  \n", githubActions: true, want: []string{"/workspace"}},
+		{name: "empty outside GitHub Actions", input: "", want: []string{cwd}},
+		{name: "single", input: "/build", githubActions: true, want: []string{"/build"}},
+		{name: "multiple", input: "/build/linux\n\n  /build/darwin  \n", want: []string{"/build/linux", "/build/darwin"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveWorkDirs(tt.input, tt.githubActions)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("resolveWorkDirs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindFilesInRoots(t *testing.T) {
+	linux, darwin := t.TempDir(), t.TempDir()
+	writeTestFiles(t, linux, "app.tar.gz", "checksums.txt")
+	writeTestFiles(t, darwin, "app.tar.gz")
+
+	finder := &DefaultFileFinder{}
+	files, err := findFilesInRoots(finder, []string{linux, darwin, linux}, []string{"*.tar.gz", "*.txt"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{
+		filepath.Join(linux, "app.tar.gz"),
+		filepath.Join(linux, "checksums.txt"),
+		filepath.Join(darwin, "app.tar.gz"),
+	}
+	if !slices.Equal(files, want) {
+		t.Errorf("expected %v, got %v", want, files)
+	}
+}
+
+func TestFindFilesInRoots_NestedRoots(t *testing.T) {
+	root := t.TempDir()
+	writeTestFiles(t, root, "dist/app.bin")
+
+	files, err := findFilesInRoots(&DefaultFileFinder{}, []string{root, filepath.Join(root, "dist")}, []string{"**/*.bin"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(files) != 1 {
+		t.Errorf("expected a file below two roots to be returned once, got %v", files)
+	}
+}
+
+func TestRunMultipleWorkDirs(t *testing.T) {
+	linux, darwin := t.TempDir(), t.TempDir()
+	writeTestFiles(t, linux, "dist/app.tar.gz")
+	writeTestFiles(t, darwin, "dist/app.tar.gz", "build/app.tar.gz")
+	outputDir := t.TempDir()
+
+	signer := &MockSigner{}
+	args := Config{
+		PrivateKey: "unused",
+		Files:      "**/app.tar.gz",
+		DetachSign: true,
+		Armor:      true,
+		WorkDir:    linux + "\n" + darwin,
+		OutputDir:  outputDir,
+	}
+
+	// Both roots contain dist/app.tar.gz, which would mirror to the same signature.
+	if err := run(context.Background(), args, signer, nil, nil); err == nil {
+		t.Fatal("expected an error for signatures colliding in output-dir")
+	}
+
+	if err := os.Remove(filepath.Join(darwin, "dist", "app.tar.gz")); err != nil {
+		t.Fatalf("failed to remove file: %v", err)
+	}
+	if err := run(context.Background(), args, signer, nil, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{filepath.Join(linux, "dist", "app.tar.gz"), filepath.Join(darwin, "build", "app.tar.gz")}
+	if !slices.Equal(signer.SignedFiles, want) {
+		t.Fatalf("expected %v to be signed, got %v", want, signer.SignedFiles)
+	}
+	for i, file := range []string{"dist/app.tar.gz.asc", "build/app.tar.gz.asc"} {
+		if got, want := getOutputPath(signer.SignedFiles[i], signer.SignedOpts[i]), filepath.Join(outputDir, filepath.FromSlash(file)); got != want {
+			t.Errorf("expected signature %s relative to its own root, got %s", want, got)
+		}
+	}
+}
+
+func TestGlobRoots(t *testing.T) {
+	workDirs := []string{"/build/linux", "/build/darwin"}
+	if got := globRoots(Config{}, workDirs); !slices.Equal(got, workDirs) {
+		t.Errorf("expected the working directories without glob-base, got %v", got)
+	}
+	if got := globRoots(Config{GlobBase: "/repo"}, workDirs); !slices.Equal(got, []string{"/repo"}) {
+		t.Errorf("expected glob-base to replace every working directory, got %v", got)
+	}
+}
+
+func TestCheckGlobBase(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, "file.txt")
+
+	if err := checkGlobBase(""); err != nil {
+		t.Errorf("unexpected error for empty glob-base: %v", err)
+	}
+	if err := checkGlobBase(dir); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := checkGlobBase(filepath.Join(dir, "missing")); err == nil {
+		t.Error("expected an error for a missing directory")
+	}
+	if err := checkGlobBase(filepath.Join(dir, "file.txt")); err == nil {
+		t.Error("expected an error for a file")
+	}
+}
+
+func TestRunGlobBase(t *testing.T) {
+	repo := t.TempDir()
+	writeTestFiles(t, repo, "build/app.tar.gz", "build/lib/core.bin", "docs/core.bin")
+	workDir := filepath.Join(repo, "build")
+	outputDir := t.TempDir()
+
+	tests := []struct {
+		name     string
+		files    string
+		globBase string
+		want     []string
+	}{
+		{
+			name:     "patterns relative to glob-base",
+			files:    "build/*.tar.gz\nbuild/**/*.bin",
+			globBase: repo,
+			want:     []string{"app.tar.gz", "lib/core.bin"},
+		},
+		{
+			name:  "patterns relative to workdir without glob-base",
+			files: "*.tar.gz\n**/*.bin",
+			want:  []string{"app.tar.gz", "lib/core.bin"},
+		},
+		{
+			name:     "workdir-relative patterns do not match under glob-base",
+			files:    "*.tar.gz",
+			globBase: repo,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer := &MockSigner{}
+			args := Config{
+				PrivateKey: "unused",
+				Files:      tt.files,
+				GlobBase:   tt.globBase,
+				DetachSign: true,
+				Armor:      true,
+				WorkDir:    workDir,
+				OutputDir:  outputDir,
+			}
+			if err := run(context.Background(), args, signer, nil, nil); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(signer.SignedFiles) != len(tt.want) {
+				t.Fatalf("expected %d signed files, got %v", len(tt.want), signer.SignedFiles)
+			}
+			for i, file := range tt.want {
+				if got, want := signer.SignedFiles[i], filepath.Join(workDir, filepath.FromSlash(file)); got != want {
+					t.Errorf("expected %s to be signed, got %s", want, got)
+				}
+				// Output-dir mirrors relative to the working directory, not to glob-base.
+				if got, want := getOutputPath(signer.SignedFiles[i], signer.SignedOpts[i]), filepath.Join(outputDir, filepath.FromSlash(file)+".asc"); got != want {
+					t.Errorf("expected signature %s, got %s", want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestRelativeTo(t *testing.T) {
+	bases := []string{"/build/linux", "/build/darwin"}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "/build/linux/app.bin", want: "app.bin"},
+		{path: "/build/darwin/dist/app.bin", want: "dist/app.bin"},
+		{path: "/other/app.bin", want: "/other/app.bin"},
+	}
+
+	for _, tt := range tests {
+		if got := relativeTo(bases, tt.path); got != tt.want {
+			t.Errorf("relativeTo(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
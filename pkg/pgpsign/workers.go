@@ -0,0 +1,126 @@
+package pgpsign
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+// validateWorkerInputs checks the parallel signing inputs.
+func validateWorkerInputs(concurrency, maxCPUPercent int) error {
+	if concurrency < 0 {
+		return fmt.Errorf("concurrency must not be negative, got %d", concurrency)
+	}
+	if maxCPUPercent < 0 || maxCPUPercent > 100 {
+		return fmt.Errorf("max-cpu-percent must be between 0 and 100, got %d", maxCPUPercent)
+	}
+	return nil
+}
+
+// availableCPUs returns the number of CPUs the process may use.
+// GOMAXPROCS already accounts for cgroup CPU limits on Linux, so containerized
+// runners report their quota rather than the host CPU count.
+func availableCPUs() int {
+	return runtime.GOMAXPROCS(0)
+}
+
+// resolveWorkers returns the number of files signed in parallel.
+// A maxCPUPercent above zero caps the workers to that share of cpus, rounded down
+// but never below one; the more restrictive of concurrency and the cap wins.
+func resolveWorkers(concurrency, maxCPUPercent, cpus int) int {
+	workers := max(concurrency, 1)
+	if maxCPUPercent > 0 {
+		workers = min(workers, max(cpus*maxCPUPercent/100, 1))
+	}
+	return workers
+}
+
+// forEachFile calls fn for every file using up to workers goroutines.
+// By default no further files are started once fn fails, and the error of the
+// earliest failing file in the list is returned. With continueOnError every file
+// is processed and the errors of all failing files are joined in list order.
+// Once ctx is cancelled no further files are started, even with continueOnError;
+// calls already running are waited for and ctx's error is returned. ctx is only
+// checked before starting a file, so a cancellation after the last file was started
+// does not fail a run whose files all succeed.
+func forEachFile(ctx context.Context, files []string, workers int, continueOnError bool, fn func(file string) error) error {
+	errs := make([]error, len(files))
+
+	if workers <= 1 {
+		for i, file := range files {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			errs[i] = fn(file)
+			if errs[i] != nil && !continueOnError {
+				return errs[i]
+			}
+		}
+		return errors.Join(errs...)
+	}
+
+	jobs := make(chan int)
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		failed bool
+	)
+
+	for range min(workers, len(files)) {
+		wg.Go(func() {
+			for i := range jobs {
+				if err := fn(files[i]); err != nil {
+					errs[i] = err
+					mu.Lock()
+					failed = true
+					mu.Unlock()
+				}
+			}
+		})
+	}
+
+	cancelled := dispatchFiles(ctx, len(files), jobs, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return failed && !continueOnError
+	})
+	wg.Wait()
+
+	if cancelled != nil {
+		return cancelled
+	}
+
+	if continueOnError {
+		return errors.Join(errs...)
+	}
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dispatchFiles sends the indexes of n files to jobs until stop reports a failure, then
+// closes jobs. It returns ctx's error if ctx was cancelled before every file was sent.
+func dispatchFiles(ctx context.Context, n int, jobs chan<- int, stop func() bool) error {
+	defer close(jobs)
+
+	for i := range n {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if stop() {
+			return nil
+		}
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
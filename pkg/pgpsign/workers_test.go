@@ -0,0 +1,202 @@
+package pgpsign
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestResolveWorkers(t *testing.T) {
+	tests := []struct {
+		name          string
+		concurrency   int
+		maxCPUPercent int
+		cpus          int
+		expected      int
+	}{
+		{name: "sequential by default", concurrency: 1, cpus: 8, expected: 1},
+		{name: "zero concurrency is sequential", concurrency: 0, cpus: 8, expected: 1},
+		{name: "no cap", concurrency: 16, cpus: 4, expected: 16},
+		{name: "cap is more restrictive", concurrency: 8, maxCPUPercent: 50, cpus: 4, expected: 2},
+		{name: "concurrency is more restrictive", concurrency: 2, maxCPUPercent: 50, cpus: 16, expected: 2},
+		{name: "cap rounds down", concurrency: 8, maxCPUPercent: 30, cpus: 10, expected: 3},
+		{name: "cap never below one", concurrency: 8, maxCPUPercent: 10, cpus: 2, expected: 1},
+		{name: "full cap", concurrency: 32, maxCPUPercent: 100, cpus: 4, expected: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := resolveWorkers(tt.concurrency, tt.maxCPUPercent, tt.cpus)
+			if result != tt.expected {
+				t.Errorf("expected %d workers, got %d", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestValidateWorkerInputs(t *testing.T) {
+	tests := []struct {
+		name          string
+		concurrency   int
+		maxCPUPercent int
+		expectError   bool
+	}{
+		{name: "defaults", concurrency: 1},
+		{name: "capped", concurrency: 4, maxCPUPercent: 50},
+		{name: "negative concurrency", concurrency: -1, expectError: true},
+		{name: "negative percent", concurrency: 1, maxCPUPercent: -5, expectError: true},
+		{name: "percent above 100", concurrency: 1, maxCPUPercent: 150, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateWorkerInputs(tt.concurrency, tt.maxCPUPercent)
+			if tt.expectError && err == nil {
+				t.Error("expected error")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestForEachFile_Parallel(t *testing.T) {
+	files := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+
+	var (
+		mu        sync.Mutex
+		seen      []string
+		running   atomic.Int32
+		maxActive atomic.Int32
+	)
+
+	err := forEachFile(context.Background(), files, 3, false, func(file string) error {
+		active := running.Add(1)
+		defer running.Add(-1)
+		for {
+			current := maxActive.Load()
+			if active <= current || maxActive.CompareAndSwap(current, active) {
+				break
+			}
+		}
+
+		mu.Lock()
+		seen = append(seen, file)
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	slices.Sort(seen)
+	if !slices.Equal(seen, files) {
+		t.Errorf("expected every file once, got %v", seen)
+	}
+	if maxActive.Load() > 3 {
+		t.Errorf("expected at most 3 concurrent workers, got %d", maxActive.Load())
+	}
+}
+
+func TestForEachFile_Error(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	for _, workers := range []int{1, 4} {
+		err := forEachFile(context.Background(), []string{"a", "b", "c"}, workers, false, func(file string) error {
+			if file == "b" {
+				return errFailed
+			}
+			return nil
+		})
+		if !errors.Is(err, errFailed) {
+			t.Errorf("workers=%d: expected error, got %v", workers, err)
+		}
+	}
+}
+
+func TestForEachFile_ContinueOnError(t *testing.T) {
+	errB := errors.New("b failed")
+	errD := errors.New("d failed")
+
+	for _, workers := range []int{1, 3} {
+		var (
+			mu   sync.Mutex
+			seen []string
+		)
+		err := forEachFile(context.Background(), []string{"a", "b", "c", "d", "e"}, workers, true, func(file string) error {
+			mu.Lock()
+			seen = append(seen, file)
+			mu.Unlock()
+			switch file {
+			case "b":
+				return errB
+			case "d":
+				return errD
+			}
+			return nil
+		})
+
+		if !errors.Is(err, errB) || !errors.Is(err, errD) {
+			t.Errorf("workers=%d: expected both errors, got %v", workers, err)
+		}
+		if len(seen) != 5 {
+			t.Errorf("workers=%d: expected all files to be processed, got %v", workers, seen)
+		}
+	}
+}
+
+func TestForEachFile_Cancelled(t *testing.T) {
+	for _, continueOnError := range []bool{false, true} {
+		for _, workers := range []int{1, 3} {
+			ctx, cancel := context.WithCancel(context.Background())
+			var processed atomic.Int32
+			err := forEachFile(ctx, []string{"a", "b", "c", "d", "e", "f", "g", "h"}, workers, continueOnError, func(file string) error {
+				if processed.Add(1) == 2 {
+					cancel()
+				}
+				return nil
+			})
+			cancel()
+
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("workers=%d continueOnError=%v: expected cancellation error, got %v", workers, continueOnError, err)
+			}
+			// In parallel, files already handed to another worker and at most one the dispatcher
+			// was offering when the context was cancelled still finish; no others are started.
+			limit := int32(2)
+			if workers > 1 {
+				limit += int32(workers)
+			}
+			if n := processed.Load(); n > limit {
+				t.Errorf("workers=%d continueOnError=%v: expected at most %d files after cancelling, got %d", workers, continueOnError, limit, n)
+			}
+		}
+	}
+}
+
+func TestForEachFile_CancelledAfterLastFile(t *testing.T) {
+	files := []string{"a", "b", "c"}
+
+	for _, workers := range []int{1, 3} {
+		ctx, cancel := context.WithCancel(context.Background())
+		var processed atomic.Int32
+		err := forEachFile(ctx, files, workers, false, func(file string) error {
+			if processed.Add(1) == int32(len(files)) {
+				cancel()
+			}
+			return nil
+		})
+		cancel()
+
+		if err != nil {
+			t.Errorf("workers=%d: expected no error once every file succeeded, got %v", workers, err)
+		}
+		if n := processed.Load(); n != int32(len(files)) {
+			t.Errorf("workers=%d: expected %d files, got %d", workers, len(files), n)
+		}
+	}
+}